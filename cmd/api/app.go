@@ -2,10 +2,16 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"sort"
+	"sync"
 	"syscall"
 	"time"
 
@@ -14,164 +20,670 @@ import (
 	"github.com/example/go-clean-architecture/internal/handler"
 	"github.com/example/go-clean-architecture/internal/repository"
 	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/example/go-clean-architecture/pkg/cache"
+	"github.com/example/go-clean-architecture/pkg/feature"
+	"github.com/example/go-clean-architecture/pkg/jsonnum"
+	"github.com/example/go-clean-architecture/pkg/logging"
+	"github.com/example/go-clean-architecture/pkg/middleware"
 	"github.com/example/go-clean-architecture/pkg/monitoring"
+	"github.com/example/go-clean-architecture/pkg/notify"
+	"github.com/example/go-clean-architecture/pkg/secrets"
+	"github.com/example/go-clean-architecture/pkg/utils"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/redis/go-redis/v9"
 )
 
+// emailMXCacheTTL is how long MX lookup results are reused.
+const emailMXCacheTTL = time.Hour
+
 // App represents the application with all its components.
 type App struct {
 	fiberApp      *fiber.App
 	db            *driver.DB
 	mongo         *driver.Mongo
+	redis         *redis.Client
 	memoryMonitor *monitoring.MemoryMonitor
 	memoryLogRepo *repository.MemoryLogRepository
+	memoryLogger  *memoryLogger
+	startTime     time.Time
+	requests      *middleware.RequestCounter
 	userRepo      repository.UserRepository
 	userUsecase   usecase.UserUsecase
-	userHandler   *handler.UserHandler
+	tlsConfig     *tls.Config
+	h2cServer     *http.Server
+	logger        *slog.Logger
 	ctx           context.Context
 	cancel        context.CancelFunc
+	background    sync.WaitGroup
+	shutdown      *ShutdownManager
 }
 
 // newApp initializes and wires all application components.
-func newApp() (*App, error) {
+func newApp(config Config) (*App, error) {
+	startTime := time.Now()
+
+	if err := config.logFormat.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid LOG_FORMAT: %w", err)
+	}
+
+	for _, secret := range []secrets.Env{config.databaseURL, config.mongoURL} {
+		if err := secret.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate the TLS files before connecting to anything, so a bad path fails fast.
+	tlsConfig, err := newTLSConfig(config.tlsCert, config.tlsKey, config.tlsMinVersion)
+	if err != nil {
+		return nil, err
+	}
+	if config.h2c && tlsConfig != nil {
+		return nil, errors.New("SERVER_H2C serves cleartext HTTP/2 and cannot be combined with TLS_CERT and TLS_KEY")
+	}
+	if err := config.pagination().Validate(); err != nil {
+		return nil, fmt.Errorf("invalid DEFAULT_PAGE_SIZE or MAX_PAGE_SIZE: %w", err)
+	}
+	if err := usecase.ValidateRoles(config.roles(), config.defaultRole); err != nil {
+		return nil, fmt.Errorf("invalid USER_ROLES or DEFAULT_ROLE: %w", err)
+	}
+	jsonnum.LargeUintsAsStrings = config.jsonLargeUintsAsStrings
+	if err := config.alertThreshold.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid MEMORY_ALERT_MODE or MEMORY_ALERT_THRESHOLD: %w", err)
+	}
+	if config.memoryLogInterval <= 0 {
+		return nil, fmt.Errorf("invalid MEMORY_LOG_INTERVAL: must be positive, got %s", config.memoryLogInterval)
+	}
+	if config.memoryLogBatchSize > config.memoryLogBuffer {
+		return nil, fmt.Errorf("invalid MEMORY_LOG_BATCH_SIZE: %d samples do not fit in MEMORY_LOG_BUFFER_SIZE (%d)",
+			config.memoryLogBatchSize, config.memoryLogBuffer)
+	}
+	if len(config.corsAllowOrigins) > 0 {
+		if err := config.corsConfig().Validate(); err != nil {
+			return nil, fmt.Errorf("invalid CORS settings: %w", err)
+		}
+	}
+	if config.pathNormalization != pathNormalizationDisabled {
+		if err := config.pathNormalizerConfig().Validate(); err != nil {
+			return nil, fmt.Errorf("invalid PATH_NORMALIZATION: %w", err)
+		}
+	}
+	features, err := feature.Load(defaultFeatures, os.LookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feature flag: %w", err)
+	}
+
 	// Create context for graceful shutdown.
 	ctx, cancel := context.WithCancel(context.Background())
+	logger := logging.NewWithFormat(os.Stdout, config.logFormat)
+	logger.Info("feature flags loaded", "features", features.String())
 
-	// Initialize memory monitor (alerts at 80% memory usage).
-	memoryMonitor := monitoring.NewMemoryMonitor(0.8)
-	memoryMonitor.SetAlertHandler(func(stats monitoring.MemoryStats) {
-		log.Printf("WARN: High memory usage detected - Alloc: %s, Sys: %s",
-			monitoring.FormatBytes(stats.Alloc),
-			monitoring.FormatBytes(stats.Sys))
-	})
+	// Initialize memory monitor (by default, alerts at 80% memory usage).
+	memoryMonitor := monitoring.NewMemoryMonitor(0)
+	memoryMonitor.SetAlertThreshold(config.alertThreshold)
+	memoryMonitor.SetAlertCooldown(config.alertCooldown)
 
 	// Initialize PostgreSQL database.
-	db, err := driver.NewDatabase()
+	db, err := driver.NewDatabase(driver.DatabaseConfig{
+		URL:              config.databaseURL.Value,
+		MaxOpenConns:     config.dbMaxOpenConns,
+		MaxIdleConns:     config.dbMaxIdleConns,
+		ConnMaxLifetime:  config.dbConnMaxLifetime,
+		Logger:           logger,
+		SlowQuery:        config.dbSlowQuery,
+		ReplicaURLs:      config.dbReplicaURLs,
+		StatementTimeout: config.dbStatementTimeout,
+	})
 	if err != nil {
 		cancel()
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// Run auto migration for required entities.
-	if err := db.AutoMigrate(&entity.User{}); err != nil {
+	// Bring the schema up to date.
+	migrations, err := migrateDatabase(ctx, db, config.dbAutoMigrate)
+	if err != nil {
 		cancel()
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
-	// Initialize MongoDB.
-	mongo, err := driver.NewMongo()
+	// Initialize MongoDB, which memory logs, audit logs, heap profiles and
+	// user tokens are stored in.
+	mongo, err := connectMongo(config.mongoMode, config.emailVerificationRequired, func() (*driver.Mongo, error) {
+		return driver.NewMongo(driver.MongoConfig{
+			URL:            config.mongoURL.Value,
+			ReplicaSet:     config.mongoReplicaSet,
+			ReadPreference: config.mongoReadPref,
+			WriteConcern:   config.mongoWriteConcern,
+			Logger:         logger,
+			SlowCommand:    config.mongoSlowCommand,
+		})
+	})
 	if err != nil {
 		cancel()
-		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+		return nil, err
 	}
 
 	// Initialize repositories and use cases.
-	memoryLogRepo := repository.NewMemoryLogRepository(mongo)
-	userRepo := repository.NewUserRepository(db)
-	userUsecase := usecase.NewUserUsecase(userRepo)
+	var (
+		memoryLogRepo *repository.MemoryLogRepository
+		userTokenRepo *repository.UserTokenRepository
+		auditLogRepo  *repository.AuditLogRepository
+		memoryLogger  *memoryLogger
+	)
+	if mongo != nil {
+		memoryLogRepo = repository.NewMemoryLogRepository(mongo)
+		memoryLogRepo.SetOperationTimeout(config.mongoOperationTimeout)
+		memoryLogRepo.SetWriteRetries(config.mongoWriteRetries)
+		memoryLogRepo.SetMonthlyBuckets(config.memoryLogMonthlyBuckets)
+		userTokenRepo = repository.NewUserTokenRepository(mongo)
+		auditLogRepo = repository.NewAuditLogRepository(mongo)
 
-	// Initialize HTTP handlers.
-	userHandler := handler.NewUserHandler(userUsecase)
+		// Ensure memory log indexes exist; queries still work without them, only slower.
+		indexCtx, indexCancel := context.WithTimeout(ctx, 10*time.Second)
+		if err := memoryLogRepo.EnsureIndexes(indexCtx); err != nil {
+			log.Printf("WARN: Failed to create memory log indexes: %v", err)
+		}
+		// Expired verification tokens are also rejected on use, so a missing TTL index only leaves them stored.
+		if err := userTokenRepo.EnsureIndexes(indexCtx); err != nil {
+			log.Printf("WARN: Failed to create user token indexes: %v", err)
+		}
+		indexCancel()
+		memoryLogger = newMemoryLogger(memoryLogRepo, config.memoryLogInterval, config.memoryLogBuffer)
+		memoryLogger.setBatching(config.memoryLogBatchSize, config.memoryLogBatchWait)
+	}
 
-	// Initialize Fiber app with middleware.
-	fiberApp := fiber.New()
-	fiberApp.Use(logger.New())
-	fiberApp.Use(monitoring.MemoryMiddleware(memoryMonitor))
-	fiberApp.Use(monitoring.SimpleGoroutineMiddleware())
+	// Report memory alerts through the log and the configured channels.
+	var webhookAlert func(monitoring.MemoryStats)
+	if config.alertWebhook != "" {
+		webhookAlert = monitoring.WebhookAlertHandler(config.alertWebhook)
+	}
+	var profiler *heapProfiler
+	if config.heapProfileAlert && mongo != nil {
+		profiler = newHeapProfiler(repository.NewHeapProfileRepository(mongo), config.heapProfileEvery)
+	} else if config.heapProfileAlert {
+		log.Printf("WARN: HEAP_PROFILE_ON_ALERT is ignored without MongoDB")
+	}
+	memoryMonitor.SetAlertHandler(func(stats monitoring.MemoryStats) {
+		log.Printf("WARN: High memory usage detected - Alloc: %s, Sys: %s, threshold: %s",
+			monitoring.FormatBytes(stats.Alloc),
+			monitoring.FormatBytes(stats.Sys),
+			config.alertThreshold)
+		if webhookAlert != nil {
+			webhookAlert(stats)
+		}
+		if profiler != nil {
+			profiler.onAlert(stats)
+		}
+	})
 
-	// Register pprof routes for profiling.
-	monitoring.RegisterPprofRoutes(fiberApp)
+	userRepo := repository.NewUserRepository(db, repository.WithReadOnlyRetries(config.dbReadOnlyRetries, config.dbReadOnlyRetryDelay))
 
-	return &App{
+	// Wrap the user repository with the configured cache backend. A memory
+	// cache of size 0 disables caching rather than growing without bound.
+	var redisClient *redis.Client
+	switch config.userCacheBackend {
+	case "memory":
+		if config.userCacheSize <= 0 {
+			break
+		}
+		userCache := cache.NewMemoryCache(config.userCacheSize)
+		userRepo = repository.NewCachingUserRepository(userRepo, userCache, config.userCacheTTL)
+	case "redis":
+		redisClient, err = driver.NewRedis(config.redisURL)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to configure Redis: %w", err)
+		}
+		userCache := cache.NewRedisCache(redisClient, "go_clean_arch:")
+		userRepo = repository.NewCachingUserRepository(userRepo, userCache, config.userCacheTTL)
+	}
+	// New passwords use the configured algorithm; hashes from the other one
+	// still verify and are replaced on the next login.
+	passwordHasher := config.passwordHasher
+	userOpts := []usecase.UserUsecaseOption{
+		usecase.WithFirstUserAdmin(config.firstUserAdmin),
+		usecase.WithRoles(config.roles()...),
+		usecase.WithDefaultRole(config.defaultRole),
+		usecase.WithHasher(passwordHasher),
+		usecase.WithPasswordPolicy(config.passwordPolicy),
+		usecase.WithMaxUsers(int64(config.maxUsers)),
+		usecase.WithRequireVerifiedEmail(config.emailVerificationRequired),
+	}
+	var emailDomains usecase.EmailDomainChecker
+	if config.emailMXCheck {
+		emailDomains = utils.NewMXChecker(nil, config.emailMXTimeout, emailMXCacheTTL)
+		userOpts = append(userOpts, usecase.WithEmailDomainCheck(emailDomains))
+	}
+	// Wrap the core usecase with logging, metrics, the audit trail and email
+	// verification, outermost first. The last two need MongoDB.
+	usecaseMetrics := usecase.NewUsecaseMetrics()
+	decorators := []usecase.UserUsecaseDecorator{
+		usecase.LoggingDecorator(logger),
+		usecase.MetricsDecorator(usecaseMetrics),
+	}
+	var (
+		emailVerifier    *usecase.EmailVerifier
+		passwordResetter *usecase.PasswordResetter
+	)
+	if mongo != nil {
+		emailVerifier = usecase.NewEmailVerifier(userRepo, userTokenRepo, notify.NewLogNotifier(logger), passwordHasher,
+			config.publicURL+"/users/verify", config.emailVerificationTTL)
+		emailVerifier.SetKeepOldEmail(config.emailChangeKeepOld)
+		emailVerifier.SetEmailDomainCheck(emailDomains)
+		emailVerifier.SetAuditLog(auditLogRepo)
+		passwordResetter = usecase.NewPasswordResetter(userRepo, userTokenRepo, notify.NewLogNotifier(logger), passwordHasher,
+			config.passwordResetURL, config.passwordResetTTL)
+		passwordResetter.SetPasswordPolicy(config.passwordPolicy)
+		passwordResetter.SetAuditLog(auditLogRepo)
+		decorators = append(decorators,
+			usecase.AuditDecorator(auditLogRepo),
+			usecase.VerificationDecorator(emailVerifier),
+		)
+	}
+	userUsecase := usecase.Chain(usecase.NewUserUsecase(userRepo, userOpts...), decorators...)
+
+	// Build the HTTP application from the wired components.
+	requests := middleware.NewRequestCounter()
+	dependencies := []dependency{{name: "database", pinger: db}}
+	if mongo != nil {
+		dependencies = append(dependencies, dependency{name: "mongo", pinger: mongo})
+	} else {
+		dependencies = append(dependencies, dependency{name: "mongo"})
+	}
+	for i, replica := range db.Replicas() {
+		dependencies = append(dependencies, dependency{name: fmt.Sprintf("database_replica_%d", i+1), pinger: replica})
+	}
+	deps := appDeps{
+		config:         config,
+		db:             db,
+		memoryMonitor:  memoryMonitor,
+		dependencies:   dependencies,
+		userUsecase:    userUsecase,
+		startTime:      startTime,
+		requests:       requests,
+		usecaseMetrics: usecaseMetrics,
+		features:       features,
+		logger:         logger,
+	}
+	// Leave the MongoDB-backed components nil rather than typed nil pointers,
+	// so the routes needing them are skipped.
+	if mongo != nil {
+		deps.memoryLogging = memoryLogger
+		deps.memoryLogs = memoryLogRepo
+		deps.auditLogs = auditLogRepo
+		deps.emailVerifier = emailVerifier
+		deps.passwordResetter = passwordResetter
+	}
+	fiberApp := newFiberApp(deps)
+
+	// Confirm the critical dependencies answer and summarize the startup state in one entry.
+	if err := runStartupSelfCheck(ctx, logger, dependencies, migrations, len(registeredRoutes(fiberApp)), config); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	app := &App{
 		fiberApp:      fiberApp,
 		db:            db,
 		mongo:         mongo,
+		redis:         redisClient,
 		memoryMonitor: memoryMonitor,
 		memoryLogRepo: memoryLogRepo,
+		memoryLogger:  memoryLogger,
+		startTime:     startTime,
+		requests:      requests,
 		userRepo:      userRepo,
 		userUsecase:   userUsecase,
-		userHandler:   userHandler,
+		tlsConfig:     tlsConfig,
+		logger:        logger,
 		ctx:           ctx,
 		cancel:        cancel,
-	}, nil
+		shutdown:      NewShutdownManager(logger),
+	}
+	if config.h2c {
+		app.h2cServer = newH2CServer(fiberApp)
+	}
+	app.registerShutdownSteps(config)
+	return app, nil
+}
+
+// appDeps holds the already-constructed components the HTTP application
+// depends on. The MongoDB-backed ones are nil when MongoDB is unavailable.
+type appDeps struct {
+	config           Config
+	db               dbStatsProvider
+	memoryMonitor    *monitoring.MemoryMonitor
+	memoryLogging    memoryLoggingHealth
+	memoryLogs       handler.MemoryLogStore
+	auditLogs        handler.AuditLogReader
+	emailVerifier    handler.EmailVerifier
+	passwordResetter handler.PasswordResetter
+	dependencies     []dependency
+	userUsecase      usecase.UserUsecase
+	usecaseMetrics   *usecase.UsecaseMetrics
+	startTime        time.Time
+	requests         *middleware.RequestCounter
+	features         *feature.Set
+	logger           *slog.Logger
+}
+
+// newFiberApp creates the Fiber app with its middleware and routes.
+func newFiberApp(deps appDeps) *fiber.App {
+	fiberApp := fiber.New(fiber.Config{
+		EnableTrustedProxyCheck: len(deps.config.trustedProxies) > 0,
+		TrustedProxies:          deps.config.trustedProxies,
+		ProxyHeader:             fiber.HeaderXForwardedFor,
+		// Routes only match their canonical path, in its exact case; the
+		// path normalizer maps /users/ and //users onto /users.
+		StrictRouting: true,
+		CaseSensitive: true,
+	})
+	fiberApp.Use(deps.requests.Handler())
+	fiberApp.Use(middleware.NewRequestID())
+	if deps.config.securityHeaders {
+		fiberApp.Use(middleware.SecurityHeaders(middleware.SecurityHeadersConfig{
+			ContentSecurityPolicy: deps.config.contentSecurityPolicy,
+			ReferrerPolicy:        deps.config.referrerPolicy,
+		}))
+	}
+	fiberApp.Use(logger.New(logger.Config{
+		Format: "${time} | ${locals:" + middleware.RequestIDKey + "} | ${status} | ${latency} | ${ip} | ${method} | ${path} | ${error}\n",
+		CustomTags: map[string]logger.LogFunc{
+			logger.TagIP: func(output logger.Buffer, c *fiber.Ctx, _ *logger.Data, _ string) (int, error) {
+				return output.WriteString(middleware.ClientIP(c))
+			},
+		},
+	}))
+	if len(deps.config.corsAllowOrigins) > 0 {
+		fiberApp.Use(middleware.CORS(deps.config.corsConfig()))
+	}
+	if deps.config.pathNormalization != pathNormalizationDisabled {
+		fiberApp.Use(middleware.NormalizePath(deps.config.pathNormalizerConfig()))
+	}
+	// Probes bypass the limit, so a saturated server is not restarted or
+	// pulled from the load balancer for being busy.
+	fiberApp.Use(middleware.NewConcurrencyLimiter(deps.config.maxConcurrent, "/livez", "/readyz"))
+	fiberApp.Use(handler.RecoverMiddleware(deps.logger))
+	fiberApp.Use(compress.New(compress.Config{Level: deps.config.compressionLevel}))
+	if deps.config.logRequestBodies {
+		redactFields := deps.config.logRedactFields
+		if len(redactFields) == 0 {
+			redactFields = logging.DefaultRedactFields
+		}
+		fiberApp.Use(middleware.RequestBodyLogger(deps.logger, redactFields))
+	}
+	fiberApp.Use(monitoring.MemoryMiddleware(deps.memoryMonitor,
+		monitoring.WithSampleRate(deps.config.memorySampleRate),
+		monitoring.WithExposeHeaders(deps.config.exposeMemoryHeaders),
+		monitoring.WithLegacyHeaders(deps.config.memoryLegacyHeaders)))
+
+	setupRoutes(fiberApp, deps)
+
+	return fiberApp
 }
 
 // startMemoryMonitoring starts the periodic memory monitoring loop.
 func (app *App) startMemoryMonitoring() {
-	go app.memoryMonitor.StartMonitoring(app.ctx, 30*time.Second)
+	app.goBackground(func() {
+		app.memoryMonitor.StartMonitoring(app.ctx, 30*time.Second)
+	})
 }
 
-// startMemoryLogging starts periodic memory logging to MongoDB.
+// startMemoryLogging starts periodic memory logging to MongoDB, unless MongoDB is unavailable.
 func (app *App) startMemoryLogging() {
+	if app.memoryLogger == nil {
+		return
+	}
+	app.goBackground(func() {
+		app.memoryLogger.run(app.ctx, app.sampleMemoryLog)
+	})
+}
+
+// goBackground runs fn in a goroutine that shutdown waits for. fn must
+// return once the application context is cancelled.
+func (app *App) goBackground(fn func()) {
+	app.background.Add(1)
 	go func() {
-		ticker := time.NewTicker(1 * time.Minute)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-app.ctx.Done():
-				return
-			case <-ticker.C:
-				stats := app.memoryMonitor.GetMemoryStats()
-				log.Printf("MEMORY STATS - Alloc: %s, TotalAlloc: %s, Sys: %s, NumGC: %d, GCCPUFraction: %.4f, NumGoroutine: %d",
-					monitoring.FormatBytes(stats.Alloc),
-					monitoring.FormatBytes(stats.TotalAlloc),
-					monitoring.FormatBytes(stats.Sys),
-					stats.NumGC,
-					stats.GCCPUFraction,
-					stats.NumGoroutine)
-
-				// Store memory stats in MongoDB.
-				memoryLog := &entity.MemoryLog{
-					Alloc:         stats.Alloc,
-					TotalAlloc:    stats.TotalAlloc,
-					Sys:           stats.Sys,
-					NumGC:         stats.NumGC,
-					GCCPUFraction: stats.GCCPUFraction,
-					NumGoroutine:  stats.NumGoroutine,
-				}
-
-				if err := app.memoryLogRepo.Create(memoryLog); err != nil {
-					log.Printf("ERROR: Failed to store memory log in MongoDB: %v", err)
-				}
-			}
-		}
+		defer app.background.Done()
+		fn()
 	}()
 }
 
-// startServer starts the Fiber HTTP server.
-func (app *App) startServer(port string) error {
-	log.Printf("Server starting on port %s", port)
-	return app.fiberApp.Listen(":" + port)
+// sampleMemoryLog logs the current memory statistics and returns them as a memory log.
+func (app *App) sampleMemoryLog() *entity.MemoryLog {
+	stats := app.memoryMonitor.GetMemoryStats()
+	log.Printf("MEMORY STATS - Alloc: %s, TotalAlloc: %s, Sys: %s, NumGC: %d, GCCPUFraction: %.4f, NumGoroutine: %d",
+		monitoring.FormatBytes(stats.Alloc),
+		monitoring.FormatBytes(stats.TotalAlloc),
+		monitoring.FormatBytes(stats.Sys),
+		stats.NumGC,
+		stats.GCCPUFraction,
+		stats.NumGoroutine)
+
+	return &entity.MemoryLog{
+		Timestamp:     time.Now(),
+		Alloc:         stats.Alloc,
+		TotalAlloc:    stats.TotalAlloc,
+		Sys:           stats.Sys,
+		NumGC:         stats.NumGC,
+		GCCPUFraction: stats.GCCPUFraction,
+		NumGoroutine:  stats.NumGoroutine,
+	}
+}
+
+// startServer starts the Fiber HTTP server on host and port, or on a Unix
+// domain socket when host is a path, optionally on an SO_REUSEPORT listener.
+// Connections are served over HTTPS when a TLS certificate is configured,
+// or additionally over cleartext HTTP/2 when h2c is enabled.
+func (app *App) startServer(host, port string, reusePort bool) error {
+	network, addr := listenAddress(host, port)
+	ln, err := newListener(network, addr, reusePort)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Server starting on %s %s (SO_REUSEPORT: %t, TLS: %t, h2c: %t)", network, addr, reusePort, app.tlsConfig != nil, app.h2cServer != nil)
+	if app.h2cServer != nil {
+		if err := app.h2cServer.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+	return app.fiberApp.Listener(withTLS(ln, app.tlsConfig))
+}
+
+// routeEntry is a registered route's method and path.
+type routeEntry struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
 }
 
-// printRoutes logs all registered routes for debugging purposes.
-func (app *App) printRoutes() {
-	fmt.Println("Registered routes:")
-	for _, route := range app.fiberApp.GetRoutes() {
-		fmt.Printf("Method: %s, Path: %s\n", route.Method, route.Path)
+// printRoutes logs the registered routes for debugging purposes, one entry
+// per route or, with asJSON, a single entry listing them all for tooling.
+func (app *App) printRoutes(asJSON bool) {
+	routes := registeredRoutes(app.fiberApp)
+	if asJSON {
+		app.logger.Info("registered routes", "count", len(routes), "routes", routes)
+		return
+	}
+
+	for _, route := range routes {
+		app.logger.Info("registered route", "method", route.Method, "path", route.Path)
 	}
 }
 
-// waitForShutdown blocks until an interrupt signal is received and performs graceful shutdown.
-func (app *App) waitForShutdown() {
+// registeredRoutes returns the app's routes sorted by path, then method.
+// Middleware mounts are skipped, and so are the HEAD routes Fiber registers
+// automatically alongside every GET route.
+func registeredRoutes(fiberApp *fiber.App) []routeEntry {
+	seen := make(map[routeEntry]bool)
+	for _, route := range fiberApp.GetRoutes(true) {
+		seen[routeEntry{Method: route.Method, Path: route.Path}] = true
+	}
+
+	routes := make([]routeEntry, 0, len(seen))
+	for route := range seen {
+		if route.Method == fiber.MethodHead && seen[routeEntry{Method: fiber.MethodGet, Path: route.Path}] {
+			continue
+		}
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// waitForShutdown blocks until an interrupt signal is received or the server
+// stops on its own, then runs the shutdown sequence. It returns the error the
+// server stopped with, if any.
+func (app *App) waitForShutdown(serverErr <-chan error) error {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	<-sigChan
-	log.Println("Shutting down server...")
-
-	app.cancel()
-	time.Sleep(2 * time.Second)
+	var err error
+	select {
+	case <-sigChan:
+		log.Println("Shutting down server...")
+	case err = <-serverErr:
+		log.Printf("ERROR: Server stopped, shutting down: %v", err)
+	}
 
+	if shutdownErr := app.shutdown.Shutdown(context.Background()); shutdownErr != nil {
+		log.Printf("WARN: Shutdown finished with errors: %v", shutdownErr)
+	}
 	log.Println("Server shutdown complete")
+	return err
 }
 
-// cleanup releases all resources associated with the application.
-func (app *App) cleanup() {
+// registerShutdownSteps registers the teardown of every component, which
+// then runs in the reverse order: stop accepting requests, drain the
+// in-flight ones, stop background goroutines, flush memory logs and
+// finally close the MongoDB, Redis and database connections. Stopping the
+// server and draining requests share the configured shutdown timeout.
+func (app *App) registerShutdownSteps(config Config) {
+	timeout := config.shutdownTimeout
+	app.shutdown.Register("database", shutdownStepTimeout, func(context.Context) error {
+		return app.db.Close()
+	})
+	if app.redis != nil {
+		app.shutdown.Register("redis", shutdownStepTimeout, func(context.Context) error {
+			return app.redis.Close()
+		})
+	}
 	if app.mongo != nil {
-		app.mongo.Close()
+		app.shutdown.Register("mongo", shutdownStepTimeout, func(ctx context.Context) error {
+			return app.mongo.Client.Disconnect(ctx)
+		})
+	}
+	if app.memoryLogger != nil {
+		// Flush after the logging loop stops, so the final sample is the last one.
+		app.shutdown.Register("memory log flush", config.memoryLogFlushTimeout, func(ctx context.Context) error {
+			var final *entity.MemoryLog
+			if config.memoryLogFinalSample {
+				final = app.sampleMemoryLog()
+			}
+			return app.memoryLogger.flushFinal(ctx, final)
+		})
+	}
+	app.shutdown.Register("background tasks", shutdownStepTimeout, func(ctx context.Context) error {
+		app.cancel()
+		done := make(chan struct{})
+		go func() {
+			app.background.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	app.shutdown.Register("in-flight requests", timeout, func(context.Context) error {
+		drainInFlight(app.requests, timeout)
+		return nil
+	})
+	app.shutdown.Register("http server", timeout, func(ctx context.Context) error {
+		if app.h2cServer != nil {
+			return app.h2cServer.Shutdown(ctx)
+		}
+		return app.fiberApp.ShutdownWithContext(ctx)
+	})
+}
+
+// drainInFlight waits up to timeout for in-flight requests to finish.
+func drainInFlight(requests *middleware.RequestCounter, timeout time.Duration) {
+	if remaining := requests.WaitIdle(timeout); remaining > 0 {
+		log.Printf("WARN: Shutdown timeout of %s reached with %d request(s) still in flight", timeout, remaining)
+	}
+}
+
+// migrateDatabase applies the pending versioned migrations and returns the
+// applied ones. autoMigrate switches to GORM's AutoMigrate instead, which is
+// convenient in development and tests but cannot roll back or drop columns.
+func migrateDatabase(ctx context.Context, db *driver.DB, autoMigrate bool) ([]string, error) {
+	if autoMigrate {
+		if err := db.AutoMigrate(&entity.User{}); err != nil {
+			return nil, err
+		}
+		return []string{entity.User{}.TableName()}, nil
+	}
+
+	migrator, err := db.Migrator()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		return nil, err
+	}
+	applied, err := migrator.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(applied))
+	for i, migration := range applied {
+		names[i] = migration.String()
+	}
+	return names, nil
+}
+
+// MongoDB modes selected with MONGO_MODE.
+const (
+	mongoModeRequired = "required"
+	mongoModeOptional = "optional"
+	mongoModeDisabled = "disabled"
+)
+
+// connectMongo connects to MongoDB according to mode. It returns a nil
+// connection without an error when MongoDB is disabled, or optional and
+// unreachable; the features stored in MongoDB are then turned off. Email
+// verification cannot be required without MongoDB, since it stores the tokens.
+func connectMongo(mode string, verificationRequired bool, connect func() (*driver.Mongo, error)) (*driver.Mongo, error) {
+	switch mode {
+	case mongoModeRequired, mongoModeOptional, mongoModeDisabled:
+	default:
+		return nil, fmt.Errorf("invalid MONGO_MODE %q: must be %s, %s or %s", mode, mongoModeRequired, mongoModeOptional, mongoModeDisabled)
+	}
+	if mode != mongoModeRequired && verificationRequired {
+		return nil, fmt.Errorf("EMAIL_VERIFICATION_REQUIRED needs MongoDB, so MONGO_MODE must be %s", mongoModeRequired)
+	}
+
+	if mode == mongoModeDisabled {
+		log.Printf("WARN: MongoDB is disabled; memory logs, audit logs, heap profiles, email verification and password resets are turned off")
+		return nil, nil
+	}
+	mongo, err := connect()
+	if err == nil {
+		return mongo, nil
+	}
+	if mode == mongoModeRequired {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
 	}
-	app.cancel()
+	log.Printf("WARN: MongoDB is unavailable, continuing without it; memory logs, audit logs, heap profiles, email verification and password resets are turned off: %v", err)
+	return nil, nil
 }
@@ -0,0 +1,827 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	projectdocs "github.com/example/go-clean-architecture/docs"
+	"github.com/example/go-clean-architecture/internal/driver"
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/example/go-clean-architecture/pkg/feature"
+	"github.com/example/go-clean-architecture/pkg/jsonschema"
+	"github.com/example/go-clean-architecture/pkg/logging"
+	"github.com/example/go-clean-architecture/pkg/middleware"
+	"github.com/example/go-clean-architecture/pkg/monitoring"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDBStats reports an idle connection pool.
+type fakeDBStats struct{}
+
+func (fakeDBStats) Stats() sql.DBStats {
+	return sql.DBStats{MaxOpenConnections: 25}
+}
+
+// stubUserUsecase serves a single user and rejects all credentials.
+type stubUserUsecase struct {
+	usecase.UserUsecase
+}
+
+func (stubUserUsecase) GetUserByID(_ context.Context, id uint) (*entity.UserResponse, error) {
+	if id != 1 {
+		return nil, errors.New("record not found")
+	}
+	return &entity.UserResponse{ID: 1, Name: "John Doe", Email: "john.doe@example.com", Role: entity.RoleUser}, nil
+}
+
+func (stubUserUsecase) ListUsers(context.Context, repository.UserFilter, int, int) ([]entity.UserResponse, error) {
+	return []entity.UserResponse{{ID: 1, Name: "John Doe", Email: "john.doe@example.com", Role: entity.RoleUser}}, nil
+}
+
+func (stubUserUsecase) CountUsers(context.Context) (int64, error) {
+	return 1, nil
+}
+
+func (stubUserUsecase) Authenticate(_ context.Context, email, password string) (*entity.UserResponse, error) {
+	return nil, usecase.ErrInvalidCredentials
+}
+
+// newTestFiberApp builds the HTTP app from in-process fakes.
+func newTestFiberApp() *fiber.App {
+	return newFiberApp(newTestAppDeps())
+}
+
+// fakePinger is a dependency that is up unless err is set.
+type fakePinger struct {
+	err error
+}
+
+func (p fakePinger) Ping(context.Context) error {
+	return p.err
+}
+
+// emptyMemoryLogs is a memory log store with no entries.
+type emptyMemoryLogs struct{}
+
+func (emptyMemoryLogs) FindByID(context.Context, string) (*entity.MemoryLog, error) {
+	return nil, repository.ErrMemoryLogNotFound
+}
+
+func (emptyMemoryLogs) FindByTimeRange(context.Context, time.Time, time.Time) ([]*entity.MemoryLog, error) {
+	return nil, nil
+}
+
+func (emptyMemoryLogs) DeleteOlderThan(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+// emptyAuditLogs is an audit log store with no entries.
+type emptyAuditLogs struct{}
+
+func (emptyAuditLogs) Find(repository.AuditLogFilter, int) ([]*entity.AuditLog, error) {
+	return []*entity.AuditLog{}, nil
+}
+
+// noEmailVerifier rejects every verification token.
+type noEmailVerifier struct{}
+
+func (noEmailVerifier) Verify(context.Context, string) (*entity.UserResponse, error) {
+	return nil, usecase.ErrInvalidToken
+}
+
+// noPasswordResetter accepts reset requests and rejects every reset token.
+type noPasswordResetter struct{}
+
+func (noPasswordResetter) RequestReset(context.Context, string) error {
+	return nil
+}
+
+func (noPasswordResetter) ConfirmReset(context.Context, string, string) error {
+	return usecase.ErrInvalidToken
+}
+
+// newTestAppDeps returns application dependencies backed by in-process fakes.
+func newTestAppDeps() appDeps {
+	return appDeps{
+		db:               fakeDBStats{},
+		memoryMonitor:    monitoring.NewMemoryMonitor(0.8),
+		memoryLogging:    newMemoryLogger(&flakyMemoryLogStore{}, time.Minute, 10),
+		memoryLogs:       emptyMemoryLogs{},
+		auditLogs:        emptyAuditLogs{},
+		emailVerifier:    noEmailVerifier{},
+		passwordResetter: noPasswordResetter{},
+		dependencies: []dependency{
+			{name: "database", pinger: fakePinger{}},
+			{name: "mongo", pinger: fakePinger{}},
+		},
+		userUsecase:    stubUserUsecase{},
+		usecaseMetrics: usecase.NewUsecaseMetrics(),
+		startTime:      time.Now(),
+		requests:       middleware.NewRequestCounter(),
+		features:       feature.NewSet(defaultFeatures),
+		logger:         logging.New(io.Discard),
+	}
+}
+
+func TestNewFiberApp_Routes(t *testing.T) {
+	app := newTestFiberApp()
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{fiber.MethodGet, "/health", fiber.StatusOK},
+		{fiber.MethodGet, "/livez", fiber.StatusOK},
+		{fiber.MethodGet, "/readyz", fiber.StatusOK},
+		{fiber.MethodGet, "/health/memory", fiber.StatusOK},
+		{fiber.MethodGet, "/health/full", fiber.StatusOK},
+		{fiber.MethodGet, "/debug/runtime", fiber.StatusOK},
+		{fiber.MethodGet, "/debug/pprof/", fiber.StatusUnauthorized},
+		{fiber.MethodPost, "/debug/gc", fiber.StatusUnauthorized},
+		{fiber.MethodGet, "/debug/stacks", fiber.StatusUnauthorized},
+		{fiber.MethodGet, "/debug/usecase-metrics", fiber.StatusUnauthorized},
+		{fiber.MethodGet, "/memory-logs/65f1a2b3c4d5e6f708192a3b", fiber.StatusNotFound},
+		{fiber.MethodDelete, "/memory-logs?all=true", fiber.StatusUnauthorized},
+		{fiber.MethodGet, "/memory-logs/prometheus", fiber.StatusUnauthorized},
+		{fiber.MethodGet, "/audit-logs", fiber.StatusUnauthorized},
+		{fiber.MethodGet, "/users", fiber.StatusUnauthorized},
+		{fiber.MethodGet, "/users?email=john@example.com", fiber.StatusUnauthorized},
+		{fiber.MethodGet, "/users/all", fiber.StatusUnauthorized},
+		{fiber.MethodGet, "/users/1", fiber.StatusOK},
+		{fiber.MethodGet, "/users/count", fiber.StatusOK},
+		{fiber.MethodGet, "/users/verify?token=unknown", fiber.StatusBadRequest},
+		{fiber.MethodPost, "/users/password-reset/request", fiber.StatusBadRequest},
+		{fiber.MethodPost, "/users/password-reset/confirm", fiber.StatusBadRequest},
+		{fiber.MethodGet, "/users/2", fiber.StatusNotFound},
+		{fiber.MethodPut, "/users/1", fiber.StatusUnauthorized},
+		{fiber.MethodDelete, "/users/1", fiber.StatusUnauthorized},
+		{fiber.MethodPost, "/users/1/password", fiber.StatusUnauthorized},
+		{fiber.MethodPost, "/users/1/email", fiber.StatusUnauthorized},
+		{fiber.MethodGet, "/schemas/UserResponse", fiber.StatusOK},
+		{fiber.MethodGet, "/schemas/Unknown", fiber.StatusNotFound},
+		{fiber.MethodGet, "/test", fiber.StatusOK},
+		{fiber.MethodGet, "/features", fiber.StatusUnauthorized},
+		{fiber.MethodGet, "/does-not-exist", fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+// adminUserUsecase authenticates every request as an admin.
+type adminUserUsecase struct {
+	stubUserUsecase
+}
+
+func (adminUserUsecase) Authenticate(_ context.Context, email, password string) (*entity.UserResponse, error) {
+	return &entity.UserResponse{ID: 2, Name: "Admin", Email: email, Role: entity.RoleAdmin}, nil
+}
+
+func TestNewFiberApp_DisabledFeatures(t *testing.T) {
+	deps := newTestAppDeps()
+	deps.features = feature.NewSet(map[string]bool{featureSchemas: false, featureTestRoute: true})
+	app := newFiberApp(deps)
+
+	for _, route := range registeredRoutes(app) {
+		assert.NotEqual(t, "/schemas/:name", route.Path, "disabled features register no routes")
+	}
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/schemas/UserResponse", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/test", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestNewFiberApp_Features(t *testing.T) {
+	deps := newTestAppDeps()
+	deps.userUsecase = adminUserUsecase{}
+	deps.features = feature.NewSet(map[string]bool{featureSchemas: false, featureTestRoute: true})
+	app := newFiberApp(deps)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/features", nil)
+	req.SetBasicAuth("admin@example.com", "secret")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Features map[string]bool `json:"features"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, map[string]bool{featureSchemas: false, featureTestRoute: true}, body.Features)
+}
+
+func TestNewFiberApp_DeleteMemoryLogs(t *testing.T) {
+	deps := newTestAppDeps()
+	deps.userUsecase = adminUserUsecase{}
+	app := newFiberApp(deps)
+
+	req := httptest.NewRequest(fiber.MethodDelete, "/memory-logs?before=2024-03-15T12:00:00Z", nil)
+	req.SetBasicAuth("admin@example.com", "secret")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Deleted int64 `json:"deleted"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Zero(t, body.Deleted)
+}
+
+func TestNewFiberApp_WithoutMongo(t *testing.T) {
+	deps := newTestAppDeps()
+	deps.memoryLogging = nil
+	deps.memoryLogs = nil
+	deps.auditLogs = nil
+	deps.emailVerifier = nil
+	deps.passwordResetter = nil
+	deps.dependencies = []dependency{
+		{name: "database", pinger: fakePinger{}},
+		{name: "mongo"},
+	}
+	app := newFiberApp(deps)
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{fiber.MethodGet, "/health", fiber.StatusOK},
+		{fiber.MethodGet, "/health/full", fiber.StatusOK},
+		{fiber.MethodGet, "/users/1", fiber.StatusOK},
+		{fiber.MethodGet, "/users/count", fiber.StatusOK},
+		{fiber.MethodGet, "/memory-logs/65f1a2b3c4d5e6f708192a3b", fiber.StatusNotFound},
+		{fiber.MethodDelete, "/memory-logs?all=true", fiber.StatusNotFound},
+		{fiber.MethodGet, "/audit-logs", fiber.StatusNotFound},
+		{fiber.MethodPost, "/users/password-reset/request", fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+
+	// Readiness reports MongoDB as disabled without failing
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/readyz", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, map[string]interface{}{"database": "up", "mongo": "disabled"}, body["checks"])
+
+	// The full health report leaves out the subsystems that need MongoDB
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/health/full", nil))
+	require.NoError(t, err)
+	var full struct {
+		Status string                 `json:"status"`
+		Checks map[string]interface{} `json:"checks"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&full))
+	assert.Equal(t, "healthy", full.Status)
+	assert.Contains(t, full.Checks, "database")
+	assert.NotContains(t, full.Checks, "mongo")
+	assert.NotContains(t, full.Checks, "memory_logging")
+}
+
+func TestConnectMongo(t *testing.T) {
+	errDown := errors.New("server selection timeout")
+	up := func() (*driver.Mongo, error) { return &driver.Mongo{}, nil }
+	down := func() (*driver.Mongo, error) { return nil, errDown }
+	unused := func() (*driver.Mongo, error) {
+		t.Fatal("connected to MongoDB while it is disabled")
+		return nil, nil
+	}
+
+	tests := []struct {
+		name         string
+		mode         string
+		verification bool
+		connect      func() (*driver.Mongo, error)
+		connected    bool
+		err          string
+	}{
+		{"required and up", mongoModeRequired, false, up, true, ""},
+		{"required and down", mongoModeRequired, false, down, false, "failed to connect to MongoDB"},
+		{"optional and up", mongoModeOptional, false, up, true, ""},
+		{"optional and down", mongoModeOptional, false, down, false, ""},
+		{"disabled", mongoModeDisabled, false, unused, false, ""},
+		{"disabled with required verification", mongoModeDisabled, true, unused, false, "EMAIL_VERIFICATION_REQUIRED needs MongoDB"},
+		{"invalid mode", "sometimes", false, unused, false, "invalid MONGO_MODE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mongo, err := connectMongo(tt.mode, tt.verification, tt.connect)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.connected, mongo != nil)
+		})
+	}
+}
+
+func TestHealthCheck_ReportsUptimeAndRequestsServed(t *testing.T) {
+	deps := newTestAppDeps()
+	deps.startTime = time.Now().Add(-time.Hour)
+	app := newFiberApp(deps)
+
+	for i := 0; i < 2; i++ {
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users/1", nil))
+		require.NoError(t, err)
+	}
+	before := deps.requests.Total()
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/health", nil))
+	require.NoError(t, err)
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	assert.Equal(t, uint64(2), before)
+	assert.Equal(t, float64(3), body["requestsServed"])
+	assert.Equal(t, "1h0m0s", body["uptime"])
+	assert.GreaterOrEqual(t, body["uptimeSeconds"], float64(3600))
+}
+
+func TestNewFiberApp_CompressesResponses(t *testing.T) {
+	app := newTestFiberApp()
+
+	tests := []struct {
+		acceptEncoding  string
+		contentEncoding string
+	}{
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run("Accept-Encoding "+tt.acceptEncoding, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, "/openapi.json", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set(fiber.HeaderAcceptEncoding, tt.acceptEncoding)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, tt.contentEncoding, resp.Header.Get(fiber.HeaderContentEncoding))
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if tt.contentEncoding == "gzip" {
+				reader, err := gzip.NewReader(bytes.NewReader(body))
+				require.NoError(t, err)
+				body, err = io.ReadAll(reader)
+				require.NoError(t, err)
+			}
+			if tt.contentEncoding != "deflate" {
+				assert.True(t, json.Valid(body))
+			}
+		})
+	}
+}
+
+func TestNewFiberApp_MemoryHeaders(t *testing.T) {
+	for _, expose := range []bool{false, true} {
+		t.Run(fmt.Sprintf("EXPOSE_MEMORY_HEADERS=%t", expose), func(t *testing.T) {
+			deps := newTestAppDeps()
+			deps.config.exposeMemoryHeaders = expose
+			deps.config.memoryLegacyHeaders = true
+			deps.config.memorySampleRate = 1
+			app := newFiberApp(deps)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/livez", nil))
+			require.NoError(t, err)
+
+			for _, header := range []string{"X-Memory-Before", "X-Memory-Diff", "X-Goroutines-After"} {
+				assert.Equal(t, expose, resp.Header.Get(header) != "", header)
+			}
+			assert.Equal(t, expose, strings.Contains(resp.Header.Get(monitoring.HeaderServerTiming), "mem;"))
+		})
+	}
+}
+
+func TestNewFiberApp_CORS(t *testing.T) {
+	deps := newTestAppDeps()
+	deps.config.corsAllowOrigins = []string{"https://app.example.com"}
+	deps.config.corsAllowCredentials = true
+	deps.config.corsMaxAge = time.Hour
+	app := newFiberApp(deps)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/livez", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://app.example.com")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://app.example.com", resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "true", resp.Header.Get(fiber.HeaderAccessControlAllowCredentials))
+	exposed := strings.Split(resp.Header.Get(fiber.HeaderAccessControlExposeHeaders), ",")
+	for _, header := range []string{fiber.HeaderXRequestID, monitoring.HeaderServerTiming, "X-Memory-Before", "X-Memory-After", "X-Memory-Diff"} {
+		assert.Contains(t, exposed, header)
+	}
+
+	// Preflights are answered before authentication
+	req = httptest.NewRequest(fiber.MethodOptions, "/audit-logs", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://app.example.com")
+	req.Header.Set(fiber.HeaderAccessControlRequestMethod, fiber.MethodGet)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "3600", resp.Header.Get(fiber.HeaderAccessControlMaxAge))
+}
+
+func TestNewFiberApp_CORSExposeHeaders(t *testing.T) {
+	deps := newTestAppDeps()
+	deps.config.corsAllowOrigins = []string{"*"}
+	deps.config.corsExposeHeaders = []string{"X-Request-ID", "X-Custom"}
+	app := newFiberApp(deps)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/livez", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://app.example.com")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "*", resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "X-Request-ID,X-Custom", resp.Header.Get(fiber.HeaderAccessControlExposeHeaders))
+}
+
+func TestNewFiberApp_CORSDisabledByDefault(t *testing.T) {
+	app := newTestFiberApp()
+
+	req := httptest.NewRequest(fiber.MethodGet, "/livez", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://app.example.com")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Empty(t, resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	assert.Empty(t, resp.Header.Get(fiber.HeaderAccessControlExposeHeaders))
+}
+
+func TestNewFiberApp_RequireIfMatch(t *testing.T) {
+	deps := newTestAppDeps()
+	deps.config.requireIfMatch = true
+	deps.userUsecase = adminUserUsecase{}
+	app := newFiberApp(deps)
+
+	req := httptest.NewRequest(fiber.MethodPut, "/users/1", strings.NewReader(`{"name":"John Doe","email":"john.doe@example.com"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.SetBasicAuth("admin@example.com", "secret")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusPreconditionRequired, resp.StatusCode)
+}
+
+func TestNewFiberApp_TrailingSlashes(t *testing.T) {
+	app := newTestFiberApp()
+
+	for _, path := range []string{"/users/count", "/users/1", "/livez"} {
+		t.Run(path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+			require.NoError(t, err)
+			require.Equal(t, fiber.StatusOK, resp.StatusCode)
+			want, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			for _, variant := range []string{path + "/", "/" + path, path + "//"} {
+				resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, variant, nil))
+				require.NoError(t, err)
+				assert.Equal(t, fiber.StatusOK, resp.StatusCode, variant)
+				got, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.Equal(t, string(want), string(got), variant)
+			}
+		})
+	}
+
+	// Write routes are normalized too, reaching the handler's validation
+	for _, path := range []string{"/users", "/users/"} {
+		req := httptest.NewRequest(fiber.MethodPost, path, strings.NewReader("{"))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, path)
+	}
+
+	// Paths are case sensitive
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/USERS/1", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestNewFiberApp_PathNormalizationModes(t *testing.T) {
+	tests := []struct {
+		mode     string
+		status   int
+		location string
+	}{
+		{string(middleware.NormalizeRedirect), fiber.StatusMovedPermanently, "/users/count?page=1"},
+		{string(middleware.NormalizeRewrite), fiber.StatusOK, ""},
+		{pathNormalizationDisabled, fiber.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			deps := newTestAppDeps()
+			deps.config.pathNormalization = tt.mode
+			app := newFiberApp(deps)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users/count/?page=1", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.location, resp.Header.Get(fiber.HeaderLocation))
+		})
+	}
+}
+
+func TestNewFiberApp_CompressionDisabled(t *testing.T) {
+	deps := newTestAppDeps()
+	deps.config.compressionLevel = compress.LevelDisabled
+	app := newFiberApp(deps)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/openapi.json", nil)
+	req.Header.Set(fiber.HeaderAcceptEncoding, "gzip")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Empty(t, resp.Header.Get(fiber.HeaderContentEncoding))
+}
+
+func TestReadiness_ReportsDownDependencies(t *testing.T) {
+	tests := []struct {
+		name   string
+		dbErr  error
+		mgoErr error
+		status int
+		checks map[string]interface{}
+	}{
+		{"all up", nil, nil, fiber.StatusOK, map[string]interface{}{"database": "up", "mongo": "up"}},
+		{"database down", errors.New("connection refused"), nil, fiber.StatusServiceUnavailable, map[string]interface{}{"database": "down", "mongo": "up"}},
+		{"mongo down", nil, errors.New("server selection timeout"), fiber.StatusServiceUnavailable, map[string]interface{}{"database": "up", "mongo": "down"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newTestAppDeps()
+			deps.dependencies = []dependency{
+				{name: "database", pinger: fakePinger{err: tt.dbErr}},
+				{name: "mongo", pinger: fakePinger{err: tt.mgoErr}},
+			}
+			app := newFiberApp(deps)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/readyz", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, tt.checks, body["checks"])
+
+			// Liveness and the legacy health endpoint ignore dependency outages
+			for _, path := range []string{"/livez", "/health"} {
+				resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, path, nil))
+				require.NoError(t, err)
+				assert.Equal(t, fiber.StatusOK, resp.StatusCode, path)
+			}
+		})
+	}
+}
+
+func TestDrainInFlight_LogsRequestsLeftAtTimeout(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	requests := middleware.NewRequestCounter()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	app := fiber.New()
+	app.Use(requests.Handler())
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/slow", nil), -1)
+		assert.NoError(t, err)
+	}()
+	<-started
+
+	drainInFlight(requests, 20*time.Millisecond)
+	assert.Contains(t, logs.String(), "with 1 request(s) still in flight")
+
+	close(release)
+	<-done
+	logs.Reset()
+	drainInFlight(requests, time.Second)
+	assert.Empty(t, logs.String())
+}
+
+func TestRegisteredRoutes_SortedAndDeduplicated(t *testing.T) {
+	app := fiber.New()
+	noop := func(c *fiber.Ctx) error { return nil }
+	app.Use(noop)
+	app.Post("/users", noop)
+	app.Get("/users/:id", noop)
+	app.Get("/health", noop)
+	app.Delete("/users/:id", noop)
+	app.Get("/users", noop)
+	app.Head("/files", noop)
+	users := app.Group("/users", noop)
+	users.Put("/:id", noop)
+
+	routes := registeredRoutes(app)
+
+	assert.Equal(t, []routeEntry{
+		{Method: fiber.MethodHead, Path: "/files"},
+		{Method: fiber.MethodGet, Path: "/health"},
+		{Method: fiber.MethodGet, Path: "/users"},
+		{Method: fiber.MethodPost, Path: "/users"},
+		{Method: fiber.MethodDelete, Path: "/users/:id"},
+		{Method: fiber.MethodGet, Path: "/users/:id"},
+		{Method: fiber.MethodPut, Path: "/users/:id"},
+	}, routes)
+}
+
+func TestPrintRoutes_JSON(t *testing.T) {
+	fiberApp := fiber.New()
+	noop := func(c *fiber.Ctx) error { return nil }
+	fiberApp.Post("/users", noop)
+	fiberApp.Get("/health", noop)
+	var buf bytes.Buffer
+	app := &App{fiberApp: fiberApp, logger: logging.New(&buf)}
+
+	app.printRoutes(true)
+
+	var entry struct {
+		Msg    string       `json:"msg"`
+		Count  int          `json:"count"`
+		Routes []routeEntry `json:"routes"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "registered routes", entry.Msg)
+	assert.Equal(t, 2, entry.Count)
+	assert.Equal(t, []routeEntry{{Method: fiber.MethodGet, Path: "/health"}, {Method: fiber.MethodPost, Path: "/users"}}, entry.Routes)
+}
+
+func TestPrintRoutes_Text(t *testing.T) {
+	fiberApp := fiber.New()
+	noop := func(c *fiber.Ctx) error { return nil }
+	fiberApp.Post("/users", noop)
+	fiberApp.Get("/health", noop)
+	var buf bytes.Buffer
+	app := &App{fiberApp: fiberApp, logger: logging.NewWithFormat(&buf, logging.FormatText)}
+
+	app.printRoutes(false)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `msg="registered route" method=GET path=/health`)
+	assert.Contains(t, lines[1], `msg="registered route" method=POST path=/users`)
+}
+
+func TestNewFiberApp_SecurityHeadersAllowDocsViewer(t *testing.T) {
+	deps := newTestAppDeps()
+	deps.config.securityHeaders = true
+	deps.config.contentSecurityPolicy = middleware.DefaultContentSecurityPolicy
+	deps.config.referrerPolicy = middleware.DefaultReferrerPolicy
+	app := newFiberApp(deps)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/openapi", nil))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, "nosniff", resp.Header.Get(fiber.HeaderXContentTypeOptions))
+	assert.Equal(t, "DENY", resp.Header.Get(fiber.HeaderXFrameOptions))
+	assert.Equal(t, "no-referrer", resp.Header.Get(fiber.HeaderReferrerPolicy))
+
+	policy := resp.Header.Get(fiber.HeaderContentSecurityPolicy)
+	matches := regexp.MustCompile(`'nonce-([^']+)'`).FindStringSubmatch(policy)
+	require.Len(t, matches, 2, "policy %q has no nonce", policy)
+	assert.Contains(t, string(body), `nonce="`+matches[1]+`"`)
+	assert.NotContains(t, string(body), "<style")
+	assert.NotContains(t, string(body), "<script>")
+}
+
+func TestOpenAPIDocs_ServesViewerAssets(t *testing.T) {
+	app := newTestFiberApp()
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/openapi", nil))
+	require.NoError(t, err)
+	page, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	tests := []struct {
+		path        string
+		tag         string
+		contentType string
+		contains    string
+	}{
+		{"/openapi/assets/openapi.css", `<link rel="stylesheet" href="/openapi/assets/openapi.css"`, "text/css", "--method-get"},
+		{"/openapi/assets/openapi.js", `<script src="/openapi/assets/openapi.js"`, "javascript", "dataset.specUrl"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Contains(t, string(page), tt.tag)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.path, nil))
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Contains(t, resp.Header.Get(fiber.HeaderContentType), tt.contentType)
+			assert.Contains(t, string(body), tt.contains)
+		})
+	}
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/openapi/assets/openapi.yaml", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestAPISchemas_MatchOpenAPISpec(t *testing.T) {
+	data, err := projectdocs.OpenAPIFS.ReadFile(openAPIJSONFile)
+	require.NoError(t, err)
+	var spec struct {
+		Components struct {
+			Schemas map[string]jsonschema.Schema `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(data, &spec))
+
+	for name, generated := range apiSchemas() {
+		t.Run(name, func(t *testing.T) {
+			documented, ok := spec.Components.Schemas[name]
+			require.True(t, ok, "missing from components.schemas")
+			assert.ElementsMatch(t, generated.Required, documented.Required)
+			require.Len(t, documented.Properties, len(generated.Properties))
+			for property, want := range generated.Properties {
+				got := documented.Properties[property]
+				require.NotNil(t, got, property)
+				assert.Equal(t, want.Type, got.Type, property)
+				if want.Format != "" {
+					assert.Equal(t, want.Format, got.Format, property)
+				}
+				assert.Equal(t, want.Enum, got.Enum, property)
+			}
+		})
+	}
+}
+
+func TestSchemas_ServesGeneratedSchemas(t *testing.T) {
+	app := newTestFiberApp()
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/schemas/UserRequest", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var schema jsonschema.Schema
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&schema))
+	assert.Equal(t, jsonschema.Draft, schema.Schema)
+	assert.Equal(t, []string{"email", "name", "password"}, schema.Required)
+	assert.Equal(t, "email", schema.Properties["email"].Format)
+	assert.NotContains(t, schema.Properties, "Verified")
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/schemas/UserResponse", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&schema))
+	assert.Equal(t, "email", schema.Properties["email"].Format)
+	assert.Equal(t, "date-time", schema.Properties["created_at"].Format)
+}
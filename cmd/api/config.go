@@ -1,10 +1,108 @@
 package main
 
-import "os"
+import (
+	"crypto/tls"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/example/go-clean-architecture/pkg/logging"
+	"github.com/example/go-clean-architecture/pkg/middleware"
+	"github.com/example/go-clean-architecture/pkg/monitoring"
+	"github.com/example/go-clean-architecture/pkg/secrets"
+	"github.com/example/go-clean-architecture/pkg/utils"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+)
 
 // Config holds application configuration.
 type Config struct {
-	port string
+	host                      string
+	port                      string
+	passwordPolicy            utils.PasswordPolicy
+	passwordHasher            utils.MigratingHasher
+	firstUserAdmin            bool
+	userRoles                 []string
+	defaultRole               string
+	maxUsers                  int
+	defaultPageSize           int
+	maxPageSize               int
+	requireIfMatch            bool
+	emailMXCheck              bool
+	emailMXTimeout            time.Duration
+	emailVerificationRequired bool
+	emailVerificationTTL      time.Duration
+	emailChangeKeepOld        bool
+	publicURL                 string
+	passwordResetURL          string
+	passwordResetTTL          time.Duration
+	passwordResetRateLimit    int
+	passwordResetRateWindow   time.Duration
+	databaseURL               secrets.Env
+	dbMaxOpenConns            int
+	dbMaxIdleConns            int
+	dbConnMaxLifetime         time.Duration
+	dbSlowQuery               time.Duration
+	dbStatementTimeout        time.Duration
+	dbReadOnlyRetries         int
+	dbReadOnlyRetryDelay      time.Duration
+	dbAutoMigrate             bool
+	dbReplicaURLs             []string
+	userCacheBackend          string
+	userCacheSize             int
+	userCacheTTL              time.Duration
+	redisURL                  string
+	trustedProxies            []string
+	memoryLogInterval         time.Duration
+	memoryLogBuffer           int
+	memoryLogBatchSize        int
+	memoryLogBatchWait        time.Duration
+	memoryLogFinalSample      bool
+	memoryLogFlushTimeout     time.Duration
+	memoryLogMonthlyBuckets   bool
+	mongoURL                  secrets.Env
+	mongoMode                 string
+	mongoReplicaSet           string
+	mongoReadPref             string
+	mongoWriteConcern         string
+	mongoSlowCommand          time.Duration
+	mongoOperationTimeout     time.Duration
+	mongoWriteRetries         int
+	reusePort                 bool
+	logRequestBodies          bool
+	logRedactFields           []string
+	alertWebhook              string
+	alertCooldown             time.Duration
+	alertThreshold            monitoring.AlertThreshold
+	heapProfileAlert          bool
+	heapProfileEvery          time.Duration
+	memorySampleRate          float64
+	memoryLegacyHeaders       bool
+	exposeMemoryHeaders       bool
+	compressionLevel          compress.Level
+	maxConcurrent             int
+	shutdownTimeout           time.Duration
+	printRoutesJSON           bool
+	tlsCert                   string
+	tlsKey                    string
+	tlsMinVersion             uint16
+	h2c                       bool
+	securityHeaders           bool
+	contentSecurityPolicy     string
+	referrerPolicy            string
+	corsAllowOrigins          []string
+	corsAllowCredentials      bool
+	corsExposeHeaders         []string
+	corsMaxAge                time.Duration
+	pathNormalization         string
+	jsonLargeUintsAsStrings   bool
+	appEnv                    string
+	logFormat                 logging.Format
 }
 
 // loadConfig loads configuration from environment variables.
@@ -13,6 +111,259 @@ func loadConfig() Config {
 	if port == "" {
 		port = "8080"
 	}
+	publicURL := strings.TrimSuffix(getEnv("PUBLIC_URL", "http://localhost:"+port), "/")
+	appEnv := getEnv("APP_ENV", appEnvProduction)
+
+	return Config{
+		host:                      getEnv("HOST", defaultHost),
+		port:                      port,
+		passwordPolicy:            utils.PasswordPolicyFromEnv(),
+		passwordHasher:            getEnvPasswordHasher(),
+		firstUserAdmin:            getEnvBool("FIRST_USER_ADMIN", true),
+		userRoles:                 getEnvList("USER_ROLES"),
+		defaultRole:               getEnv("DEFAULT_ROLE", entity.RoleUser),
+		maxUsers:                  getEnvInt("MAX_USERS", 0),
+		defaultPageSize:           getEnvInt("DEFAULT_PAGE_SIZE", 20),
+		maxPageSize:               getEnvInt("MAX_PAGE_SIZE", 100),
+		requireIfMatch:            getEnvBool("REQUIRE_IF_MATCH", true),
+		emailMXCheck:              getEnvBool("EMAIL_MX_CHECK", false),
+		emailMXTimeout:            getEnvDuration("EMAIL_MX_TIMEOUT", 2*time.Second),
+		emailVerificationRequired: getEnvBool("EMAIL_VERIFICATION_REQUIRED", false),
+		emailVerificationTTL:      getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		emailChangeKeepOld:        getEnvBool("EMAIL_CHANGE_KEEP_OLD", true),
+		publicURL:                 publicURL,
+		passwordResetURL:          getEnv("PASSWORD_RESET_URL", publicURL+"/password-reset"),
+		passwordResetTTL:          getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+		passwordResetRateLimit:    getEnvInt("PASSWORD_RESET_RATE_LIMIT", 5),
+		passwordResetRateWindow:   getEnvDuration("PASSWORD_RESET_RATE_WINDOW", 15*time.Minute),
+		databaseURL:               secrets.FromEnv("DATABASE_URL"),
+		dbMaxOpenConns:            getEnvInt("DB_MAX_OPEN_CONNS", 25),
+		dbMaxIdleConns:            getEnvInt("DB_MAX_IDLE_CONNS", 5),
+		dbConnMaxLifetime:         getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
+		dbSlowQuery:               getEnvDuration("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
+		dbStatementTimeout:        getEnvDuration("DB_STATEMENT_TIMEOUT", 30*time.Second),
+		dbReadOnlyRetries:         getEnvInt("DB_READ_ONLY_RETRIES", 0),
+		dbReadOnlyRetryDelay:      getEnvDuration("DB_READ_ONLY_RETRY_DELAY", 500*time.Millisecond),
+		dbAutoMigrate:             getEnvBool("DB_AUTO_MIGRATE", false),
+		dbReplicaURLs:             getEnvList("DATABASE_REPLICA_URLS"),
+		userCacheBackend:          getEnv("USER_CACHE_BACKEND", "memory"),
+		userCacheSize:             getEnvInt("USER_CACHE_SIZE", 1000),
+		userCacheTTL:              getEnvDuration("USER_CACHE_TTL", 5*time.Minute),
+		redisURL:                  getEnv("REDIS_URL", "redis://redis:6379/0"),
+		trustedProxies:            getEnvList("TRUSTED_PROXIES"),
+		memoryLogInterval:         getEnvDuration("MEMORY_LOG_INTERVAL", time.Minute),
+		memoryLogBuffer:           getEnvInt("MEMORY_LOG_BUFFER_SIZE", 1440),
+		memoryLogBatchSize:        getEnvInt("MEMORY_LOG_BATCH_SIZE", 1),
+		memoryLogBatchWait:        getEnvDuration("MEMORY_LOG_BATCH_INTERVAL", 5*time.Minute),
+		memoryLogFinalSample:      getEnvBool("MEMORY_LOG_FLUSH_ON_SHUTDOWN", true),
+		memoryLogFlushTimeout:     getEnvDuration("MEMORY_LOG_FLUSH_TIMEOUT", 5*time.Second),
+		memoryLogMonthlyBuckets:   getEnvBool("MEMORY_LOG_MONTHLY_BUCKETS", false),
+		mongoURL:                  secrets.FromEnv("MONGO_URL"),
+		mongoMode:                 getEnv("MONGO_MODE", mongoModeRequired),
+		mongoReplicaSet:           os.Getenv("MONGO_REPLICA_SET"),
+		mongoReadPref:             os.Getenv("MONGO_READ_PREFERENCE"),
+		mongoWriteConcern:         os.Getenv("MONGO_WRITE_CONCERN"),
+		mongoSlowCommand:          getEnvDuration("MONGO_SLOW_COMMAND_THRESHOLD", 0),
+		mongoOperationTimeout:     getEnvDuration("MONGO_OPERATION_TIMEOUT", repository.DefaultMongoOperationTimeout),
+		mongoWriteRetries:         getEnvInt("MONGO_WRITE_RETRIES", repository.DefaultMemoryLogWriteRetries),
+		reusePort:                 getEnvBool("SERVER_REUSE_PORT", false),
+		logRequestBodies:          getEnvBool("LOG_REQUEST_BODIES", false),
+		logRedactFields:           getEnvList("LOG_REDACT_FIELDS"),
+		alertWebhook:              os.Getenv("MEMORY_ALERT_WEBHOOK"),
+		alertCooldown:             getEnvDuration("MEMORY_ALERT_COOLDOWN", monitoring.DefaultAlertCooldown),
+		alertThreshold:            getEnvAlertThreshold("MEMORY_ALERT_MODE", "MEMORY_ALERT_THRESHOLD", monitoring.RatioThreshold(0.8)),
+		heapProfileAlert:          getEnvBool("HEAP_PROFILE_ON_ALERT", false),
+		heapProfileEvery:          getEnvDuration("HEAP_PROFILE_MIN_INTERVAL", time.Hour),
+		memorySampleRate:          getEnvFloat("MEMORY_MIDDLEWARE_SAMPLE_RATE", 1),
+		memoryLegacyHeaders:       getEnvBool("MEMORY_LEGACY_HEADERS", true),
+		exposeMemoryHeaders:       getEnvBool("EXPOSE_MEMORY_HEADERS", false),
+		compressionLevel:          getEnvCompressionLevel("COMPRESSION_LEVEL", compress.LevelDefault),
+		maxConcurrent:             getEnvInt("MAX_CONCURRENT_REQUESTS", 0),
+		shutdownTimeout:           getEnvDuration("SHUTDOWN_TIMEOUT", 10*time.Second),
+		printRoutesJSON:           getEnvBool("PRINT_ROUTES_JSON", false),
+		tlsCert:                   os.Getenv("TLS_CERT"),
+		tlsKey:                    os.Getenv("TLS_KEY"),
+		tlsMinVersion:             getEnvTLSVersion("TLS_MIN_VERSION", tls.VersionTLS12),
+		h2c:                       getEnvBool("SERVER_H2C", false),
+		securityHeaders:           getEnvBool("SECURITY_HEADERS", true),
+		contentSecurityPolicy:     getEnv("CONTENT_SECURITY_POLICY", middleware.DefaultContentSecurityPolicy),
+		referrerPolicy:            getEnv("REFERRER_POLICY", middleware.DefaultReferrerPolicy),
+		corsAllowOrigins:          getEnvList("CORS_ALLOW_ORIGINS"),
+		corsAllowCredentials:      getEnvBool("CORS_ALLOW_CREDENTIALS", false),
+		corsExposeHeaders:         getEnvList("CORS_EXPOSE_HEADERS"),
+		corsMaxAge:                getEnvDuration("CORS_MAX_AGE", 10*time.Minute),
+		pathNormalization:         getEnv("PATH_NORMALIZATION", string(middleware.NormalizeRewrite)),
+		jsonLargeUintsAsStrings:   getEnvBool("JSON_LARGE_UINTS_AS_STRINGS", false),
+		appEnv:                    appEnv,
+		logFormat:                 logging.Format(getEnv("LOG_FORMAT", string(defaultLogFormat(appEnv)))),
+	}
+}
+
+// APP_ENV values. Development selects defaults suited to a local machine.
+const (
+	appEnvProduction  = "production"
+	appEnvDevelopment = "development"
+)
+
+// defaultLogFormat returns the log format used when LOG_FORMAT is unset:
+// readable text in development and JSON for log collectors elsewhere.
+func defaultLogFormat(appEnv string) logging.Format {
+	if appEnv == appEnvDevelopment {
+		return logging.FormatText
+	}
+	return logging.FormatJSON
+}
+
+// roles returns the roles users may have: USER_ROLES, or user and admin when unset.
+func (c Config) roles() []string {
+	if len(c.userRoles) == 0 {
+		return usecase.DefaultRoles
+	}
+	return c.userRoles
+}
 
-	return Config{port: port}
+// pathNormalizationDisabled turns the path normalizer off, so only canonical
+// paths match a route.
+const pathNormalizationDisabled = "disabled"
+
+// pathNormalizerConfig returns the path normalization middleware settings.
+func (c Config) pathNormalizerConfig() middleware.PathNormalizerConfig {
+	return middleware.PathNormalizerConfig{
+		Mode:               middleware.NormalizeMode(c.pathNormalization),
+		TrailingSlashPaths: []string{monitoring.PprofIndexPath},
+	}
+}
+
+// corsConfig returns the CORS middleware settings. Without CORS_EXPOSE_HEADERS,
+// browser clients may read the request ID and measurement headers.
+func (c Config) corsConfig() middleware.CORSConfig {
+	exposeHeaders := c.corsExposeHeaders
+	if len(exposeHeaders) == 0 {
+		exposeHeaders = middleware.DefaultCORSExposeHeaders
+	}
+	return middleware.CORSConfig{
+		AllowOrigins:     c.corsAllowOrigins,
+		AllowCredentials: c.corsAllowCredentials,
+		ExposeHeaders:    exposeHeaders,
+		MaxAge:           c.corsMaxAge,
+	}
+}
+
+// getEnv reads a string environment variable, falling back to def when unset.
+func getEnv(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// getEnvList reads a comma-separated environment variable, dropping empty items.
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvInt reads an integer environment variable, falling back to def when unset or invalid.
+func getEnvInt(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// getEnvFloat reads a floating-point environment variable, falling back to def when unset or invalid.
+func getEnvFloat(key string, def float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// getEnvAlertThreshold reads a memory alert threshold from a mode and a value
+// environment variable. The default value only applies in the default mode:
+// in any other mode the value is NaN until set, which newApp rejects.
+func getEnvAlertThreshold(modeKey, valueKey string, def monitoring.AlertThreshold) monitoring.AlertThreshold {
+	mode := monitoring.ThresholdMode(getEnv(modeKey, string(def.Mode)))
+	if mode != def.Mode {
+		def.Value = math.NaN()
+	}
+	return monitoring.AlertThreshold{Mode: mode, Value: getEnvFloat(valueKey, def.Value)}
+}
+
+// getEnvBool reads a boolean environment variable, falling back to def when unset or invalid.
+func getEnvBool(key string, def bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// getEnvDuration reads a duration environment variable (e.g. "30s"), falling back to def when unset or invalid.
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// compressionLevels maps COMPRESSION_LEVEL values to response compression levels.
+var compressionLevels = map[string]compress.Level{
+	"disabled":         compress.LevelDisabled,
+	"default":          compress.LevelDefault,
+	"best-speed":       compress.LevelBestSpeed,
+	"best-compression": compress.LevelBestCompression,
+}
+
+// getEnvCompressionLevel reads a compression level name, falling back to def when unset or unknown.
+func getEnvCompressionLevel(key string, def compress.Level) compress.Level {
+	name := os.Getenv(key)
+	if name == "" {
+		return def
+	}
+	level, ok := compressionLevels[strings.ToLower(name)]
+	if !ok {
+		log.Printf("WARN: Unknown %s %q; expected disabled, default, best-speed or best-compression", key, name)
+		return def
+	}
+	return level
+}
+
+// tlsVersions maps TLS_MIN_VERSION values to TLS protocol versions.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// getEnvTLSVersion reads a TLS version such as "1.2", falling back to def when unset or unknown.
+func getEnvTLSVersion(key string, def uint16) uint16 {
+	name := os.Getenv(key)
+	if name == "" {
+		return def
+	}
+	version, ok := tlsVersions[name]
+	if !ok {
+		log.Printf("WARN: Unknown %s %q; expected 1.0, 1.1, 1.2 or 1.3", key, name)
+		return def
+	}
+	return version
+}
+
+// getEnvPasswordHasher reads the PASSWORD_HASHER hasher, warning when the name is unknown.
+func getEnvPasswordHasher() utils.MigratingHasher {
+	hasher, err := utils.PasswordHasherFromEnv()
+	if err != nil {
+		log.Printf("WARN: Invalid PASSWORD_HASHER: %v", err)
+	}
+	return hasher
 }
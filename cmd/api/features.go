@@ -0,0 +1,20 @@
+package main
+
+// Optional features, each switched on or off with a FEATURE_<NAME>
+// environment variable such as FEATURE_SCHEMAS=false. A disabled feature's
+// routes are not registered, so they answer 404. Ship a new endpoint dark by
+// adding a flag that defaults to false and registering its routes behind
+// deps.features.Enabled.
+const (
+	// featureSchemas serves the generated JSON Schemas at /schemas/:name.
+	featureSchemas = "schemas"
+	// featureTestRoute serves the GET /test smoke-test route.
+	featureTestRoute = "test_route"
+)
+
+// defaultFeatures lists every feature flag with its state when its
+// environment variable is not set.
+var defaultFeatures = map[string]bool{
+	featureSchemas:   true,
+	featureTestRoute: true,
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/example/go-clean-architecture/pkg/health"
+)
+
+// newHealthRegistry registers the health checks of the application's
+// subsystems: liveness, each dependency, memory sampling and memory logging.
+// Disabled dependencies and memory logging without MongoDB are left out.
+func newHealthRegistry(deps appDeps) *health.Registry {
+	registry := health.NewRegistry()
+	registry.Register("liveness", health.CheckFunc(func(context.Context) (health.Status, error) {
+		return health.StatusHealthy, nil
+	}))
+	for _, dep := range deps.dependencies {
+		if dep.pinger == nil {
+			continue
+		}
+		registry.RegisterWithTimeout(dep.name, readinessPingTimeout, health.PingCheck(dep.pinger))
+	}
+	registry.Register("memory", deps.memoryMonitor)
+	if deps.memoryLogging != nil {
+		registry.Register("memory_logging", deps.memoryLogging)
+	}
+	return registry
+}
+
+// runtimeInfo returns the goroutine count and uptime reported next to the health checks.
+func runtimeInfo(startTime time.Time) func() map[string]interface{} {
+	return func() map[string]interface{} {
+		uptime := time.Since(startTime)
+		return map[string]interface{}{
+			"goroutines":    runtime.NumGoroutine(),
+			"uptime":        uptime.Round(time.Second).String(),
+			"uptimeSeconds": int64(uptime.Seconds()),
+		}
+	}
+}
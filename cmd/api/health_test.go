@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/pkg/health"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFullHealth_AggregatesSubsystems(t *testing.T) {
+	tests := []struct {
+		name           string
+		dbErr          error
+		memoryLogsDown bool
+		status         int
+		overall        health.Status
+		checks         map[string]health.Status
+	}{
+		{
+			name: "all healthy", status: fiber.StatusOK, overall: health.StatusHealthy,
+			checks: map[string]health.Status{"liveness": health.StatusHealthy, "database": health.StatusHealthy, "mongo": health.StatusHealthy, "memory": health.StatusHealthy, "memory_logging": health.StatusHealthy},
+		},
+		{
+			name: "memory logs failing", memoryLogsDown: true, status: fiber.StatusOK, overall: health.StatusDegraded,
+			checks: map[string]health.Status{"liveness": health.StatusHealthy, "database": health.StatusHealthy, "mongo": health.StatusHealthy, "memory": health.StatusHealthy, "memory_logging": health.StatusDegraded},
+		},
+		{
+			name: "database down and memory logs failing", dbErr: errors.New("connection refused"), memoryLogsDown: true,
+			status: fiber.StatusServiceUnavailable, overall: health.StatusUnhealthy,
+			checks: map[string]health.Status{"liveness": health.StatusHealthy, "database": health.StatusUnhealthy, "mongo": health.StatusHealthy, "memory": health.StatusHealthy, "memory_logging": health.StatusDegraded},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newTestAppDeps()
+			deps.startTime = time.Now().Add(-time.Hour)
+			deps.dependencies = []dependency{
+				{name: "database", pinger: fakePinger{err: tt.dbErr}},
+				{name: "mongo", pinger: fakePinger{}},
+			}
+			if tt.memoryLogsDown {
+				logger := newMemoryLogger(&flakyMemoryLogStore{failures: memoryLogFailureThreshold}, time.Minute, 10)
+				for i := 0; i < memoryLogFailureThreshold; i++ {
+					logger.write(context.Background(), &entity.MemoryLog{})
+				}
+				deps.memoryLogging = logger
+			}
+			app := newFiberApp(deps)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/health/full", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+
+			var body struct {
+				Status        health.Status            `json:"status"`
+				Checks        map[string]health.Result `json:"checks"`
+				Goroutines    int                      `json:"goroutines"`
+				UptimeSeconds int64                    `json:"uptimeSeconds"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, tt.overall, body.Status)
+
+			statuses := make(map[string]health.Status)
+			for name, check := range body.Checks {
+				statuses[name] = check.Status
+			}
+			assert.Equal(t, tt.checks, statuses)
+			if tt.dbErr != nil {
+				assert.Equal(t, tt.dbErr.Error(), body.Checks["database"].Error)
+			}
+			if tt.memoryLogsDown {
+				assert.Contains(t, body.Checks["memory_logging"].Error, "consecutive memory log writes failed")
+			}
+			assert.Equal(t, int64(3600), body.UptimeSeconds)
+			assert.Positive(t, body.Goroutines)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/pkg/monitoring"
+)
+
+// maxHeapProfileSize keeps stored profiles below MongoDB's 16 MB document limit.
+const maxHeapProfileSize = 15 << 20
+
+// heapProfileStore persists captured heap profiles.
+type heapProfileStore interface {
+	Create(profile *entity.HeapProfile) error
+}
+
+// heapProfiler captures a heap profile when a memory alert fires, at most once
+// per interval, for post-mortem analysis.
+type heapProfiler struct {
+	store       heapProfileStore
+	minInterval time.Duration
+	capture     func() ([]byte, error)
+
+	mu          sync.Mutex
+	lastCapture time.Time
+}
+
+// newHeapProfiler creates a heap profiler storing at most one profile per minInterval.
+func newHeapProfiler(store heapProfileStore, minInterval time.Duration) *heapProfiler {
+	return &heapProfiler{
+		store:       store,
+		minInterval: minInterval,
+		capture:     monitoring.CaptureHeapProfile,
+	}
+}
+
+// onAlert captures and stores a heap profile in the background, unless one
+// was captured within the minimum interval.
+func (p *heapProfiler) onAlert(stats monitoring.MemoryStats) {
+	if !p.allow(time.Now()) {
+		return
+	}
+
+	go func() {
+		if err := p.record(stats); err != nil {
+			log.Printf("ERROR: Failed to capture heap profile: %v", err)
+		}
+	}()
+}
+
+// allow reports whether a profile may be captured now and records it if so.
+func (p *heapProfiler) allow(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.lastCapture.IsZero() && now.Sub(p.lastCapture) < p.minInterval {
+		return false
+	}
+	p.lastCapture = now
+	return true
+}
+
+// record captures a heap profile and stores it with the alerting stats.
+func (p *heapProfiler) record(stats monitoring.MemoryStats) error {
+	data, err := p.capture()
+	if err != nil {
+		return err
+	}
+	if len(data) > maxHeapProfileSize {
+		return fmt.Errorf("heap profile of %s exceeds the %s storage limit",
+			monitoring.FormatBytes(uint64(len(data))), monitoring.FormatBytes(maxHeapProfileSize))
+	}
+
+	if err := p.store.Create(&entity.HeapProfile{
+		Timestamp:    time.Now(),
+		Alloc:        stats.Alloc,
+		Sys:          stats.Sys,
+		NumGoroutine: stats.NumGoroutine,
+		Data:         data,
+	}); err != nil {
+		return err
+	}
+
+	log.Printf("INFO: Captured heap profile (%s) at Alloc: %s",
+		monitoring.FormatBytes(uint64(len(data))), monitoring.FormatBytes(stats.Alloc))
+	return nil
+}
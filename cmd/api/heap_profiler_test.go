@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/pkg/monitoring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHeapProfileStore records the profiles stored in it.
+type fakeHeapProfileStore struct {
+	mu       sync.Mutex
+	profiles []*entity.HeapProfile
+	err      error
+}
+
+func (s *fakeHeapProfileStore) Create(profile *entity.HeapProfile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.profiles = append(s.profiles, profile)
+	return nil
+}
+
+func (s *fakeHeapProfileStore) stored() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.profiles)
+}
+
+func TestHeapProfiler_CapturesOnAlertOncePerInterval(t *testing.T) {
+	store := &fakeHeapProfileStore{}
+	profiler := newHeapProfiler(store, time.Hour)
+
+	for i := 0; i < 5; i++ {
+		profiler.onAlert(monitoring.MemoryStats{Alloc: 900, Sys: 1000, NumGoroutine: 3})
+	}
+
+	require.Eventually(t, func() bool { return store.stored() == 1 }, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, store.stored())
+
+	profile := store.profiles[0]
+	assert.Equal(t, uint64(900), profile.Alloc)
+	assert.Equal(t, uint64(1000), profile.Sys)
+	assert.Equal(t, 3, profile.NumGoroutine)
+	assert.Equal(t, []byte{0x1f, 0x8b}, profile.Data[:2])
+}
+
+func TestHeapProfiler_AllowsCaptureAfterInterval(t *testing.T) {
+	profiler := newHeapProfiler(&fakeHeapProfileStore{}, time.Hour)
+	now := time.Now()
+
+	assert.True(t, profiler.allow(now))
+	assert.False(t, profiler.allow(now.Add(59*time.Minute)))
+	assert.True(t, profiler.allow(now.Add(time.Hour)))
+}
+
+func TestHeapProfiler_RecordRejectsOversizedProfiles(t *testing.T) {
+	store := &fakeHeapProfileStore{}
+	profiler := newHeapProfiler(store, time.Hour)
+	profiler.capture = func() ([]byte, error) { return make([]byte, maxHeapProfileSize+1), nil }
+
+	assert.Error(t, profiler.record(monitoring.MemoryStats{}))
+	assert.Zero(t, store.stored())
+}
+
+func TestHeapProfiler_RecordReturnsStoreErrors(t *testing.T) {
+	errStore := errors.New("mongo unavailable")
+	profiler := newHeapProfiler(&fakeHeapProfileStore{err: errStore}, time.Hour)
+
+	assert.ErrorIs(t, profiler.record(monitoring.MemoryStats{}), errStore)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/valyala/fasthttp/reuseport"
+)
+
+// defaultHost binds the server on all IPv4 interfaces.
+const defaultHost = "0.0.0.0"
+
+// listenAddress composes the network and address the server binds. A host
+// starting with a path ("/", "./" or "../") selects a Unix domain socket at
+// that path and ignores the port; IPv6 literals bind over IPv6.
+func listenAddress(host, port string) (network, addr string) {
+	if isSocketPath(host) {
+		return "unix", host
+	}
+	if host == "" {
+		host = defaultHost
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		return "tcp6", net.JoinHostPort(host, port)
+	}
+	return "tcp4", net.JoinHostPort(host, port)
+}
+
+// isSocketPath reports whether host names a filesystem path rather than a host.
+func isSocketPath(host string) bool {
+	return strings.HasPrefix(host, "/") || strings.HasPrefix(host, "./") || strings.HasPrefix(host, "../")
+}
+
+// newListener opens the listener for the server. With reusePort set, TCP
+// sockets are bound with SO_REUSEPORT, so a newly started process can bind the
+// same port while the old one drains its connections. Platforms without
+// SO_REUSEPORT fall back to a regular listener; on Windows SO_REUSEADDR is
+// used instead. Unix domain sockets ignore reusePort.
+func newListener(network, addr string, reusePort bool) (net.Listener, error) {
+	if network == "unix" {
+		if err := removeStaleSocket(addr); err != nil {
+			return nil, err
+		}
+		return net.Listen(network, addr)
+	}
+
+	if reusePort {
+		ln, err := reuseport.Listen(network, addr)
+		if err == nil {
+			return ln, nil
+		}
+
+		var noReusePort *reuseport.ErrNoReusePort
+		if !errors.As(err, &noReusePort) {
+			return nil, err
+		}
+		log.Printf("WARN: SO_REUSEPORT is not supported, falling back to a regular listener: %v", err)
+	}
+
+	return net.Listen(network, addr)
+}
+
+// removeStaleSocket deletes a socket file left behind by a process that no
+// longer accepts connections on it, so the path can be bound again. Live
+// sockets and other files are left alone.
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("socket %s is already in use", path)
+	}
+	return os.Remove(path)
+}
+
+// newTLSConfig loads the certificate and key for HTTPS termination. It returns
+// nil when neither file is configured, so the server falls back to plain
+// HTTP, and an error naming the problem when only one is set or a file is
+// missing or invalid.
+func newTLSConfig(certFile, keyFile string, minVersion uint16) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("TLS_CERT and TLS_KEY must be set together")
+	}
+	for _, file := range []struct{ env, path string }{{"TLS_CERT", certFile}, {"TLS_KEY", keyFile}} {
+		if _, err := os.Stat(file.path); err != nil {
+			return nil, fmt.Errorf("%s file %s is not readable: %w", file.env, file.path, err)
+		}
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   minVersion,
+	}, nil
+}
+
+// withTLS wraps ln to terminate TLS when config is set, and returns ln unchanged otherwise.
+func withTLS(ln net.Listener, config *tls.Config) net.Listener {
+	if config == nil {
+		return ln
+	}
+	return tls.NewListener(ln, config)
+}
+
+// newH2CServer serves fiberApp over HTTP/1.1 and cleartext HTTP/2 (h2c) with
+// prior knowledge. Fiber's own server only speaks HTTP/1.1, so requests go
+// through net/http and the Fiber adaptor instead. h2c is unencrypted and
+// meant for trusted networks, such as internal clients behind a proxy.
+func newH2CServer(fiberApp *fiber.App) *http.Server {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	config := fiberApp.Config()
+	bodyLimit := int64(config.BodyLimit)
+	handler := adaptor.FiberApp(fiberApp)
+
+	return &http.Server{
+		Protocols:    protocols,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+		IdleTimeout:  config.IdleTimeout,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > bodyLimit {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, bodyLimit)
+			// Unix socket peers have no IP address, which the adaptor requires;
+			// report them as 0.0.0.0 like fasthttp does.
+			if host, _, err := net.SplitHostPort(r.RemoteAddr); err != nil || net.ParseIP(host) == nil {
+				r.RemoteAddr = "0.0.0.0:0"
+			}
+			handler(w, r)
+		}),
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewListener_ReusePortAllowsSharedPort(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("SO_REUSEPORT port sharing is only asserted on Linux and macOS")
+	}
+
+	first, err := newListener("tcp4", "127.0.0.1:0", true)
+	require.NoError(t, err)
+	defer first.Close()
+
+	second, err := newListener("tcp4", first.Addr().String(), true)
+	require.NoError(t, err)
+	defer second.Close()
+
+	assert.Equal(t, first.Addr().String(), second.Addr().String())
+}
+
+func TestNewListener_WithoutReusePortRejectsSharedPort(t *testing.T) {
+	first, err := newListener("tcp4", "127.0.0.1:0", false)
+	require.NoError(t, err)
+	defer first.Close()
+
+	_, err = newListener("tcp4", first.Addr().String(), false)
+	assert.Error(t, err)
+}
+
+func TestListenAddress(t *testing.T) {
+	tests := []struct {
+		host    string
+		port    string
+		network string
+		addr    string
+	}{
+		{"", "8080", "tcp4", "0.0.0.0:8080"},
+		{"0.0.0.0", "8080", "tcp4", "0.0.0.0:8080"},
+		{"127.0.0.1", "9000", "tcp4", "127.0.0.1:9000"},
+		{"localhost", "8080", "tcp4", "localhost:8080"},
+		{"::1", "8080", "tcp6", "[::1]:8080"},
+		{"/run/api/api.sock", "8080", "unix", "/run/api/api.sock"},
+		{"./api.sock", "8080", "unix", "./api.sock"},
+		{"../api.sock", "8080", "unix", "../api.sock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			network, addr := listenAddress(tt.host, tt.port)
+			assert.Equal(t, tt.network, network)
+			assert.Equal(t, tt.addr, addr)
+		})
+	}
+}
+
+func TestNewListener_UnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix domain sockets are not asserted on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "api.sock")
+
+	ln, err := newListener("unix", path, false)
+	require.NoError(t, err)
+
+	// A second server must not steal a socket that is still accepting
+	_, err = newListener("unix", path, false)
+	assert.ErrorContains(t, err, "already in use")
+
+	// A socket left behind by a stopped server is replaced
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, ln.Close())
+	ln, err = newListener("unix", path, false)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	conn.Close()
+}
+
+func TestNewListener_UnixSocketRefusesRegularFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.sock")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o600))
+
+	_, err := newListener("unix", path, false)
+
+	assert.ErrorContains(t, err, "not a socket")
+}
+
+func TestNewH2CServer_NegotiatesHTTP2OverCleartext(t *testing.T) {
+	fiberApp := fiber.New(fiber.Config{DisableStartupMessage: true, BodyLimit: 16})
+	fiberApp.Post("/echo", func(c *fiber.Ctx) error {
+		return c.Send(c.Body())
+	})
+
+	ln, err := newListener("tcp4", "127.0.0.1:0", false)
+	require.NoError(t, err)
+	server := newH2CServer(fiberApp)
+	go func() { _ = server.Serve(ln) }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	url := "http://" + ln.Addr().String() + "/echo"
+
+	resp, err := client.Post(url, "text/plain", strings.NewReader("hello"))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "HTTP/2.0", resp.Proto)
+	assert.Equal(t, "hello", string(body))
+
+	resp, err = client.Post(url, "text/plain", strings.NewReader(strings.Repeat("x", 17)))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode, "the Fiber body limit still applies")
+
+	resp, err = http.Post(url, "text/plain", strings.NewReader("hello"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "HTTP/1.1", resp.Proto, "HTTP/1.1 clients are still served")
+}
@@ -5,20 +5,21 @@ import "log"
 func main() {
 	config := loadConfig()
 
-	app, err := newApp()
+	app, err := newApp(config)
 	if err != nil {
 		log.Fatal("Failed to initialize application:", err)
 	}
-	defer app.cleanup()
 
 	app.startMemoryMonitoring()
 	app.startMemoryLogging()
-	app.setupRoutes()
-	app.printRoutes()
+	app.printRoutes(config.printRoutesJSON)
 
-	if err := app.startServer(config.port); err != nil {
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- app.startServer(config.host, config.port, config.reusePort)
+	}()
+
+	if err := app.waitForShutdown(serverErr); err != nil {
 		log.Fatal("Failed to start server:", err)
 	}
-
-	app.waitForShutdown()
 }
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/pkg/health"
+)
+
+// memoryLogFailureThreshold is the number of consecutive failed writes after
+// which memory logging is reported as unhealthy.
+const memoryLogFailureThreshold = 3
+
+// memoryLogStore persists memory log samples. CreateMany treats samples that
+// an earlier, seemingly failed attempt already stored as stored.
+type memoryLogStore interface {
+	Create(ctx context.Context, memoryLog *entity.MemoryLog) error
+	CreateMany(ctx context.Context, memoryLogs []*entity.MemoryLog) error
+}
+
+// memoryLogger periodically stores memory samples and backs off while the
+// store is unavailable. Samples that fail to store are buffered and flushed
+// once writes succeed again. With batching, samples are buffered as they are
+// taken and stored a batch at a time.
+type memoryLogger struct {
+	store      memoryLogStore
+	interval   time.Duration
+	maxBackoff time.Duration
+	batchSize  int
+	batchWait  time.Duration
+
+	mu                  sync.RWMutex
+	consecutiveFailures int
+	lastErr             error
+	retryAt             time.Time
+	buffer              *memoryLogBuffer
+}
+
+// newMemoryLogger creates a memory logger writing one sample per interval and
+// buffering up to bufferSize failed samples.
+func newMemoryLogger(store memoryLogStore, interval time.Duration, bufferSize int) *memoryLogger {
+	return &memoryLogger{
+		store:      store,
+		interval:   interval,
+		maxBackoff: 16 * interval,
+		buffer:     newMemoryLogBuffer(bufferSize),
+	}
+}
+
+// setBatching makes the logger store samples in batches of size, or whatever
+// has been collected once maxWait has passed since the last batch, whichever
+// comes first. A zero maxWait only stores full batches. A size of 1 or less
+// stores every sample on its own.
+func (l *memoryLogger) setBatching(size int, maxWait time.Duration) {
+	l.batchSize = size
+	l.batchWait = maxWait
+}
+
+// run stores a sample every interval until the context is cancelled, which
+// also abandons a write in progress.
+func (l *memoryLogger) run(ctx context.Context, sample func() *entity.MemoryLog) {
+	if l.batchSize > 1 {
+		l.runBatched(ctx, sample)
+		return
+	}
+
+	timer := time.NewTimer(l.interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			timer.Reset(l.write(ctx, sample()))
+		}
+	}
+}
+
+// runBatched takes a sample every interval and stores the collected samples
+// when a batch is full or batchWait has passed, until the context is cancelled.
+func (l *memoryLogger) runBatched(ctx context.Context, sample func() *entity.MemoryLog) {
+	sampleTicker := time.NewTicker(l.interval)
+	defer sampleTicker.Stop()
+
+	var flushTimer *time.Timer
+	var flushC <-chan time.Time
+	if l.batchWait > 0 {
+		flushTimer = time.NewTimer(l.batchWait)
+		defer flushTimer.Stop()
+		flushC = flushTimer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-sampleTicker.C:
+			if l.collect(ctx, sample(), now) && flushTimer != nil {
+				flushTimer.Reset(l.batchWait)
+			}
+		case now := <-flushC:
+			l.flushBatch(ctx, now)
+			flushTimer.Reset(l.batchWait)
+		}
+	}
+}
+
+// collect buffers a sample and stores the batch once batchSize samples are
+// waiting. It reports whether the batch was stored or attempted.
+func (l *memoryLogger) collect(ctx context.Context, memoryLog *entity.MemoryLog, now time.Time) bool {
+	l.mu.Lock()
+	l.buffer.push(memoryLog)
+	full := l.buffer.len() >= l.batchSize
+	l.mu.Unlock()
+
+	if !full {
+		return false
+	}
+	return l.flushBatch(ctx, now)
+}
+
+// flushBatch stores every buffered sample with a single CreateMany and
+// reports whether it tried to. After a failure the samples stay buffered and
+// no batch is attempted until the backoff delay has passed.
+func (l *memoryLogger) flushBatch(ctx context.Context, now time.Time) bool {
+	l.mu.Lock()
+	if now.Before(l.retryAt) {
+		l.mu.Unlock()
+		return false
+	}
+	pending := l.buffer.items()
+	l.mu.Unlock()
+	if len(pending) == 0 {
+		return false
+	}
+
+	err := l.store.CreateMany(ctx, pending)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// IDs are assigned on the first attempt, and the store treats duplicates
+	// from writes that reached MongoDB despite reporting an error as stored
+	if err != nil {
+		l.consecutiveFailures++
+		l.lastErr = err
+		delay := l.backoff()
+		l.retryAt = now.Add(delay)
+
+		log.Printf("ERROR: Failed to store %d memory logs in MongoDB (attempt %d, retrying in %s): %v",
+			len(pending), l.consecutiveFailures, delay, err)
+		if l.consecutiveFailures == memoryLogFailureThreshold {
+			log.Printf("WARN: MongoDB marked unavailable for memory logging")
+		}
+		return true
+	}
+
+	if l.consecutiveFailures >= memoryLogFailureThreshold {
+		log.Printf("INFO: MongoDB memory logging recovered after %d failed attempts", l.consecutiveFailures)
+	}
+	l.consecutiveFailures = 0
+	l.lastErr = nil
+	l.retryAt = time.Time{}
+	l.buffer.discard(len(pending))
+	return true
+}
+
+// write stores a single sample and returns the delay before the next attempt.
+func (l *memoryLogger) write(ctx context.Context, memoryLog *entity.MemoryLog) time.Duration {
+	if err := l.store.Create(ctx, memoryLog); err != nil {
+		return l.recordFailure(memoryLog, err)
+	}
+
+	l.mu.Lock()
+	if l.consecutiveFailures >= memoryLogFailureThreshold {
+		log.Printf("INFO: MongoDB memory logging recovered after %d failed attempts", l.consecutiveFailures)
+	}
+	l.consecutiveFailures = 0
+	l.lastErr = nil
+	pending := l.buffer.items()
+	l.mu.Unlock()
+
+	l.flush(ctx, pending)
+	return l.interval
+}
+
+// recordFailure buffers a sample that could not be stored and returns the backoff delay.
+func (l *memoryLogger) recordFailure(memoryLog *entity.MemoryLog, err error) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.consecutiveFailures++
+	l.lastErr = err
+	l.buffer.push(memoryLog)
+	delay := l.backoff()
+
+	log.Printf("ERROR: Failed to store memory log in MongoDB (attempt %d, retrying in %s): %v",
+		l.consecutiveFailures, delay, err)
+	if l.consecutiveFailures == memoryLogFailureThreshold {
+		log.Printf("WARN: MongoDB marked unavailable for memory logging")
+	}
+
+	return delay
+}
+
+// flush stores buffered samples in a single batch and removes them from the
+// buffer. Samples stay buffered if the batch fails.
+func (l *memoryLogger) flush(ctx context.Context, pending []*entity.MemoryLog) {
+	if len(pending) == 0 {
+		return
+	}
+
+	if err := l.store.CreateMany(ctx, pending); err != nil {
+		log.Printf("ERROR: Failed to flush %d buffered memory logs to MongoDB: %v", len(pending), err)
+		return
+	}
+
+	l.mu.Lock()
+	l.buffer.discard(len(pending))
+	l.mu.Unlock()
+	log.Printf("INFO: Flushed %d buffered memory logs to MongoDB", len(pending))
+}
+
+// flushFinal stores the samples still buffered after failed writes followed
+// by final, unless it is nil, in a single batch. It runs on shutdown, once
+// the logging loop has stopped, and gives up when ctx is done.
+func (l *memoryLogger) flushFinal(ctx context.Context, final *entity.MemoryLog) error {
+	l.mu.RLock()
+	pending := l.buffer.items()
+	l.mu.RUnlock()
+	buffered := len(pending)
+	if final != nil {
+		pending = append(pending, final)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := l.store.CreateMany(ctx, pending); err != nil {
+		return fmt.Errorf("failed to flush %d memory logs to MongoDB: %w", len(pending), err)
+	}
+	l.mu.Lock()
+	l.buffer.discard(buffered)
+	l.mu.Unlock()
+	log.Printf("INFO: Flushed %d memory logs to MongoDB on shutdown", len(pending))
+	return nil
+}
+
+// backoff doubles the interval for every consecutive failure, up to maxBackoff.
+func (l *memoryLogger) backoff() time.Duration {
+	delay := l.interval
+	for i := 0; i < l.consecutiveFailures && delay < l.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > l.maxBackoff {
+		delay = l.maxBackoff
+	}
+	return delay
+}
+
+// Healthy reports whether recent memory log writes have succeeded.
+func (l *memoryLogger) Healthy() bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.consecutiveFailures < memoryLogFailureThreshold
+}
+
+// Check implements health.Checker, reporting degraded while memory logs cannot be stored.
+func (l *memoryLogger) Check(ctx context.Context) (health.Status, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.consecutiveFailures >= memoryLogFailureThreshold {
+		return health.StatusDegraded, fmt.Errorf("%d consecutive memory log writes failed", l.consecutiveFailures)
+	}
+	return health.StatusHealthy, nil
+}
+
+// Buffered returns the number of samples waiting to be flushed.
+func (l *memoryLogger) Buffered() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.buffer.len()
+}
+
+// Dropped returns the number of samples discarded because the buffer was full.
+func (l *memoryLogger) Dropped() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.buffer.dropped
+}
+
+// ConsecutiveFailures returns the number of failed writes since the last success.
+func (l *memoryLogger) ConsecutiveFailures() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.consecutiveFailures
+}
+
+// memoryLogBuffer is a bounded FIFO ring buffer of memory logs that drops the
+// oldest entry when full.
+type memoryLogBuffer struct {
+	entries []*entity.MemoryLog
+	start   int
+	size    int
+	dropped uint64
+}
+
+// newMemoryLogBuffer creates a buffer holding at most capacity entries.
+func newMemoryLogBuffer(capacity int) *memoryLogBuffer {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &memoryLogBuffer{entries: make([]*entity.MemoryLog, capacity)}
+}
+
+// push appends an entry, overwriting the oldest one when the buffer is full.
+func (b *memoryLogBuffer) push(memoryLog *entity.MemoryLog) {
+	if len(b.entries) == 0 {
+		b.dropped++
+		return
+	}
+
+	if b.size == len(b.entries) {
+		b.entries[b.start] = memoryLog
+		b.start = (b.start + 1) % len(b.entries)
+		b.dropped++
+		return
+	}
+
+	b.entries[(b.start+b.size)%len(b.entries)] = memoryLog
+	b.size++
+}
+
+// items returns the buffered entries, oldest first.
+func (b *memoryLogBuffer) items() []*entity.MemoryLog {
+	items := make([]*entity.MemoryLog, b.size)
+	for i := range items {
+		items[i] = b.entries[(b.start+i)%len(b.entries)]
+	}
+	return items
+}
+
+// discard removes the n oldest entries.
+func (b *memoryLogBuffer) discard(n int) {
+	if n > b.size {
+		n = b.size
+	}
+	for i := 0; i < n; i++ {
+		b.entries[(b.start+i)%len(b.entries)] = nil
+	}
+	if len(b.entries) > 0 {
+		b.start = (b.start + n) % len(b.entries)
+	}
+	b.size -= n
+}
+
+// len returns the number of buffered entries.
+func (b *memoryLogBuffer) len() int {
+	return b.size
+}
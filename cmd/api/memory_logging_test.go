@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyMemoryLogStore fails the first failures writes and succeeds afterwards.
+type flakyMemoryLogStore struct {
+	failures int
+	calls    int
+	stored   []*entity.MemoryLog
+	batches  [][]*entity.MemoryLog
+}
+
+func (s *flakyMemoryLogStore) Create(ctx context.Context, memoryLog *entity.MemoryLog) error {
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("server selection timeout")
+	}
+	s.stored = append(s.stored, memoryLog)
+	return nil
+}
+
+func (s *flakyMemoryLogStore) CreateMany(ctx context.Context, memoryLogs []*entity.MemoryLog) error {
+	s.batches = append(s.batches, memoryLogs)
+	s.stored = append(s.stored, memoryLogs...)
+	return nil
+}
+
+func TestMemoryLogger_BacksOffAndRecovers(t *testing.T) {
+	store := &flakyMemoryLogStore{failures: 5}
+	logger := newMemoryLogger(store, time.Minute, 10)
+
+	expected := []time.Duration{2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 16 * time.Minute, 16 * time.Minute}
+	for i, want := range expected {
+		assert.Equal(t, want, logger.write(context.Background(), &entity.MemoryLog{}), "attempt %d", i+1)
+		assert.Equal(t, i+1, logger.ConsecutiveFailures())
+		assert.Equal(t, i+1 < memoryLogFailureThreshold, logger.Healthy(), "attempt %d", i+1)
+	}
+
+	assert.Equal(t, time.Minute, logger.write(context.Background(), &entity.MemoryLog{}))
+	assert.True(t, logger.Healthy())
+	assert.Zero(t, logger.ConsecutiveFailures())
+	assert.Len(t, store.stored, 6)
+}
+
+func TestMemoryLogger_SingleFailureStaysHealthy(t *testing.T) {
+	logger := newMemoryLogger(&flakyMemoryLogStore{failures: 1}, time.Minute, 10)
+
+	logger.write(context.Background(), &entity.MemoryLog{})
+
+	assert.True(t, logger.Healthy())
+	assert.Equal(t, 1, logger.ConsecutiveFailures())
+}
+
+func TestMemoryLogger_BuffersFailedWritesAndFlushesOnRecovery(t *testing.T) {
+	store := &flakyMemoryLogStore{failures: 3}
+	logger := newMemoryLogger(store, time.Minute, 10)
+
+	for i := 1; i <= 3; i++ {
+		logger.write(context.Background(), &entity.MemoryLog{NumGoroutine: i})
+	}
+	assert.Equal(t, 3, logger.Buffered())
+	assert.Empty(t, store.stored)
+
+	logger.write(context.Background(), &entity.MemoryLog{NumGoroutine: 4})
+
+	require.Len(t, store.batches, 1)
+	assert.Equal(t, []int{1, 2, 3}, goroutineCounts(store.batches[0]))
+	assert.Zero(t, logger.Buffered())
+	assert.Zero(t, logger.Dropped())
+}
+
+func TestMemoryLogger_DropsOldestWhenBufferFull(t *testing.T) {
+	store := &flakyMemoryLogStore{failures: 5}
+	logger := newMemoryLogger(store, time.Minute, 2)
+
+	for i := 1; i <= 5; i++ {
+		logger.write(context.Background(), &entity.MemoryLog{NumGoroutine: i})
+	}
+	assert.Equal(t, 2, logger.Buffered())
+	assert.Equal(t, uint64(3), logger.Dropped())
+
+	logger.write(context.Background(), &entity.MemoryLog{NumGoroutine: 6})
+
+	require.Len(t, store.batches, 1)
+	assert.Equal(t, []int{4, 5}, goroutineCounts(store.batches[0]))
+}
+
+func TestMemoryLogger_BatchFlushesWhenFull(t *testing.T) {
+	store := &flakyMemoryLogStore{}
+	logger := newMemoryLogger(store, time.Second, 10)
+	logger.setBatching(3, 0)
+	now := time.Now()
+
+	assert.False(t, logger.collect(context.Background(), &entity.MemoryLog{NumGoroutine: 1}, now))
+	assert.False(t, logger.collect(context.Background(), &entity.MemoryLog{NumGoroutine: 2}, now))
+	assert.Empty(t, store.batches)
+	assert.Equal(t, 2, logger.Buffered())
+
+	assert.True(t, logger.collect(context.Background(), &entity.MemoryLog{NumGoroutine: 3}, now))
+
+	require.Len(t, store.batches, 1)
+	assert.Equal(t, []int{1, 2, 3}, goroutineCounts(store.batches[0]))
+	assert.Zero(t, store.calls, "batches never write samples one at a time")
+	assert.Zero(t, logger.Buffered())
+}
+
+func TestMemoryLogger_BatchFlushesAfterMaxWait(t *testing.T) {
+	store := &flakyMemoryLogStore{}
+	logger := newMemoryLogger(store, 5*time.Millisecond, 100)
+	logger.setBatching(100, 30*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	var samples int
+	logger.run(ctx, func() *entity.MemoryLog {
+		samples++
+		return &entity.MemoryLog{NumGoroutine: samples}
+	})
+
+	require.NotEmpty(t, store.batches, "partial batches are stored once the wait has passed")
+	var stored []int
+	for _, batch := range store.batches {
+		assert.Less(t, len(batch), 100)
+		stored = append(stored, goroutineCounts(batch)...)
+	}
+	assert.Equal(t, samples, len(stored)+logger.Buffered(), "every sample is stored or still buffered")
+	for i, count := range stored {
+		assert.Equal(t, i+1, count, "samples are stored in order")
+	}
+}
+
+func TestMemoryLogger_BatchBacksOffAfterFailure(t *testing.T) {
+	logger := newMemoryLogger(&failingMemoryLogStore{}, time.Minute, 10)
+	logger.setBatching(2, 0)
+	now := time.Now()
+
+	logger.collect(context.Background(), &entity.MemoryLog{NumGoroutine: 1}, now)
+	assert.True(t, logger.collect(context.Background(), &entity.MemoryLog{NumGoroutine: 2}, now))
+	assert.Equal(t, 1, logger.ConsecutiveFailures())
+	assert.Equal(t, 2, logger.Buffered())
+
+	// Full batches wait for the backoff to pass before another attempt
+	assert.False(t, logger.collect(context.Background(), &entity.MemoryLog{NumGoroutine: 3}, now.Add(time.Minute)))
+	assert.False(t, logger.flushBatch(context.Background(), now.Add(time.Minute)))
+	assert.Equal(t, 1, logger.ConsecutiveFailures())
+
+	store := &flakyMemoryLogStore{}
+	logger.store = store
+	assert.True(t, logger.flushBatch(context.Background(), now.Add(2*time.Minute)))
+
+	require.Len(t, store.batches, 1)
+	assert.Equal(t, []int{1, 2, 3}, goroutineCounts(store.batches[0]))
+	assert.Zero(t, logger.ConsecutiveFailures())
+	assert.Zero(t, logger.Buffered())
+}
+
+func goroutineCounts(memoryLogs []*entity.MemoryLog) []int {
+	counts := make([]int, len(memoryLogs))
+	for i, memoryLog := range memoryLogs {
+		counts[i] = memoryLog.NumGoroutine
+	}
+	return counts
+}
+
+func TestMemoryLogger_FlushFinal(t *testing.T) {
+	t.Run("without a final sample", func(t *testing.T) {
+		store := &flakyMemoryLogStore{}
+		require.NoError(t, newMemoryLogger(store, time.Minute, 10).flushFinal(context.Background(), nil))
+		assert.Empty(t, store.batches)
+	})
+
+	t.Run("keeps the buffer when the store fails", func(t *testing.T) {
+		memLogger := newMemoryLogger(&failingMemoryLogStore{}, time.Minute, 10)
+		memLogger.write(context.Background(), &entity.MemoryLog{})
+
+		require.Error(t, memLogger.flushFinal(context.Background(), &entity.MemoryLog{}))
+		assert.Equal(t, 1, memLogger.Buffered())
+	})
+}
+
+// failingMemoryLogStore rejects every write.
+type failingMemoryLogStore struct{}
+
+func (failingMemoryLogStore) Create(context.Context, *entity.MemoryLog) error {
+	return errors.New("server selection timeout")
+}
+
+func (failingMemoryLogStore) CreateMany(context.Context, []*entity.MemoryLog) error {
+	return errors.New("server selection timeout")
+}
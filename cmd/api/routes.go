@@ -1,46 +1,232 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
 	"github.com/example/go-clean-architecture/internal/handler"
+	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/example/go-clean-architecture/pkg/feature"
+	"github.com/example/go-clean-architecture/pkg/health"
+	"github.com/example/go-clean-architecture/pkg/jsonschema"
 	"github.com/example/go-clean-architecture/pkg/monitoring"
+	"github.com/example/go-clean-architecture/pkg/static"
 	"github.com/gofiber/fiber/v2"
 )
 
 // setupRoutes wires all application routes.
-func (app *App) setupRoutes() {
-	app.fiberApp.Get("/health", HealthCheckHandler())
-	app.fiberApp.Get("/health/memory", monitoring.MemoryHealthCheckHandler(app.memoryMonitor))
+func setupRoutes(router *fiber.App, deps appDeps) {
+	router.Get("/livez", LivenessHandler())
+	router.Get("/readyz", ReadinessHandler(deps.dependencies))
+	router.Get("/health", HealthCheckHandler(deps.db, deps.memoryLogging, deps.startTime, deps.requests))
+	router.Get("/health/memory", monitoring.MemoryHealthCheckHandler(deps.memoryMonitor))
+	router.Get("/health/full", health.Handler(newHealthRegistry(deps), health.WithInfo(runtimeInfo(deps.startTime))))
+	router.Get("/debug/runtime", monitoring.RuntimeInfoHandler(deps.memoryMonitor, deps.startTime))
+
+	// Profiling and GC control are restricted to admins.
+	auth := handler.AuthMiddleware(deps.userUsecase)
+	adminOnly := handler.RequireRole(entity.RoleAdmin)
+	monitoring.RegisterPprofRoutes(router, auth, adminOnly)
+	router.Post("/debug/gc", auth, adminOnly, monitoring.GCHandler(deps.memoryMonitor))
+	router.Get("/debug/stacks", auth, adminOnly, monitoring.StacksHandler())
+	router.Get("/debug/usecase-metrics", auth, adminOnly, UsecaseMetricsHandler(deps.usecaseMetrics))
+
+	// Optional routes are only registered while their feature flag is on.
+	if deps.features.Enabled(featureTestRoute) {
+		router.Get("/test", func(c *fiber.Ctx) error {
+			return c.SendString("Test route working")
+		})
+	}
 
-	app.fiberApp.Get("/openapi", OpenAPIDocsHandler("/openapi.json"))
-	app.fiberApp.Get("/openapi.json", OpenAPISpecHandler(openAPIJSONFile, "json"))
-	app.fiberApp.Get("/openapi.yaml", OpenAPISpecHandler(openAPIYAMLFile, "yaml"))
+	router.Get("/openapi", OpenAPIDocsHandler("/openapi.json", "/openapi/assets"))
+	static.Register(router, "/openapi/assets", openAPIViewerAssets())
+	router.Get("/openapi.json", OpenAPISpecHandler(openAPIJSONFile, "json"))
+	router.Get("/openapi.yaml", OpenAPISpecHandler(openAPIYAMLFile, "yaml"))
+	if deps.features.Enabled(featureSchemas) {
+		router.Get("/schemas/:name", jsonschema.Handler(apiSchemas()))
+	}
+	router.Get("/features", auth, adminOnly, feature.Handler(deps.features))
+
+	// Routes backed by MongoDB are only registered when it is available.
+	if deps.memoryLogs != nil {
+		memoryLogHandler := handler.NewMemoryLogHandler(deps.memoryLogs)
+		router.Get("/memory-logs/prometheus", auth, adminOnly, memoryLogHandler.PrometheusHandler)
+		router.Delete("/memory-logs", auth, adminOnly, memoryLogHandler.DeleteHandler)
+		router.Get("/memory-logs/:id", memoryLogHandler.GetByIDHandler)
+	}
+	if deps.auditLogs != nil {
+		auditLogHandler := handler.NewAuditLogHandler(deps.auditLogs, handler.WithAuditLogPagination(deps.config.pagination()))
+		router.Get("/audit-logs", auth, adminOnly, auditLogHandler.ListHandler)
+	}
+
+	userHandler := handler.NewUserHandler(deps.userUsecase,
+		handler.WithIfMatchRequired(deps.config.requireIfMatch),
+		handler.WithPagination(deps.config.pagination()))
+	var verificationHandler *handler.VerificationHandler
+	if deps.emailVerifier != nil {
+		verificationHandler = handler.NewVerificationHandler(deps.emailVerifier)
+	}
+	setupUserRoutes(router, userHandler, verificationHandler, auth, handler.OptionalAuthMiddleware(deps.userUsecase))
 
-	setupUserRoutes(app.fiberApp, app.userHandler)
+	// The /users group's Content-Type check also covers these routes. Reset
+	// requests are rate limited, since each one sends a message.
+	if deps.passwordResetter != nil {
+		passwordResetHandler := handler.NewPasswordResetHandler(deps.passwordResetter)
+		resetRateLimit := handler.PasswordResetRateLimit(deps.config.passwordResetRateLimit, deps.config.passwordResetRateWindow)
+		router.Post("/users/password-reset/request", resetRateLimit, passwordResetHandler.RequestHandler)
+		router.Post("/users/password-reset/confirm", passwordResetHandler.ConfirmHandler)
+	}
 }
 
-// setupUserRoutes sets up user-related routes.
-func setupUserRoutes(router *fiber.App, userHandler *handler.UserHandler) {
-	router.Get("/test", func(c *fiber.Ctx) error {
-		return c.SendString("Test route working")
-	})
+// pagination returns the page sizes of the list endpoints.
+func (c Config) pagination() handler.Pagination {
+	return handler.Pagination{DefaultLimit: c.defaultPageSize, MaxLimit: c.maxPageSize}
+}
 
-	users := router.Group("/users")
+// setupUserRoutes sets up user-related routes. The verification route is
+// skipped when verificationHandler is nil. Creating users stays open to
+// anonymous callers; a user is updated, or their password or email changed,
+// only by themself or an admin, and only authenticated admins may set roles.
+func setupUserRoutes(router *fiber.App, userHandler *handler.UserHandler, verificationHandler *handler.VerificationHandler, auth, optionalAuth fiber.Handler) {
+	// Reject write bodies the handlers would otherwise have to guess at
+	users := router.Group("/users", handler.RequireBodyContentType())
 	{
-		users.Post("/", userHandler.CreateHandler)
+		users.Post("", optionalAuth, userHandler.CreateHandler)
+		users.Get("/count", userHandler.CountHandler)
+		if verificationHandler != nil {
+			users.Get("/verify", verificationHandler.VerifyHandler)
+		}
+		users.Get("/all", auth, handler.RequireRole(entity.RoleAdmin), userHandler.GetAllHandler)
 		users.Get("/:id", userHandler.GetByIDHandler)
-		users.Get("/", userHandler.GetByEmailHandler)
-		users.Get("/all", userHandler.GetAllHandler)
-		users.Put("/:id", userHandler.UpdateHandler)
-		users.Delete("/:id", userHandler.DeleteHandler)
+		users.Get("", auth, handler.RequireRole(entity.RoleAdmin), userHandler.ListHandler)
+		users.Put("/:id", auth, handler.RequireSelfOrRole(entity.RoleAdmin), userHandler.UpdateHandler)
+		users.Post("/:id/password", auth, handler.RequireSelfOrRole(entity.RoleAdmin), userHandler.ChangePasswordHandler)
+		users.Post("/:id/email", auth, handler.RequireSelfOrRole(entity.RoleAdmin), userHandler.ChangeEmailHandler)
+		users.Delete("/:id", auth, handler.RequireRole(entity.RoleAdmin), userHandler.DeleteHandler)
+	}
+}
+
+// dbStatsProvider exposes database connection pool statistics.
+type dbStatsProvider interface {
+	Stats() sql.DBStats
+}
+
+// memoryLoggingHealth reports whether memory samples are being persisted.
+type memoryLoggingHealth interface {
+	health.Checker
+	Healthy() bool
+	ConsecutiveFailures() int
+	Buffered() int
+	Dropped() uint64
+}
+
+// requestCountProvider reports the number of requests served.
+type requestCountProvider interface {
+	Total() uint64
+}
+
+// pinger is a dependency that can report whether it is reachable.
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// dependency is a named external service the application needs to serve
+// requests. A nil pinger marks a dependency that is disabled.
+type dependency struct {
+	name   string
+	pinger pinger
+}
+
+// readinessPingTimeout bounds how long a readiness probe waits for each dependency.
+const readinessPingTimeout = 2 * time.Second
+
+// UsecaseMetricsHandler reports call counts, failures and latency per user usecase method.
+func UsecaseMetricsHandler(metrics *usecase.UsecaseMetrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(metrics.Snapshot())
+	}
+}
+
+// LivenessHandler reports that the process is up and able to serve requests.
+// It checks no dependencies, so an outage elsewhere never gets the process restarted.
+func LivenessHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"status": "alive"})
+	}
+}
+
+// ReadinessHandler reports whether every dependency is reachable, responding
+// 503 while any of them is down so traffic is routed elsewhere. Disabled
+// dependencies are reported as such and never fail the check.
+func ReadinessHandler(dependencies []dependency) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		status, ready := fiber.StatusOK, "ready"
+		checks := fiber.Map{}
+
+		for _, dep := range dependencies {
+			if dep.pinger == nil {
+				checks[dep.name] = "disabled"
+				continue
+			}
+			ctx, cancel := context.WithTimeout(c.UserContext(), readinessPingTimeout)
+			err := dep.pinger.Ping(ctx)
+			cancel()
+
+			if err != nil {
+				log.Printf("WARN: Readiness check failed for %s: %v", dep.name, err)
+				checks[dep.name] = "down"
+				status, ready = fiber.StatusServiceUnavailable, "not ready"
+				continue
+			}
+			checks[dep.name] = "up"
+		}
+
+		return c.Status(status).JSON(fiber.Map{
+			"status": ready,
+			"checks": checks,
+		})
 	}
 }
 
 // HealthCheckHandler handles health check requests.
-func HealthCheckHandler() fiber.Handler {
+func HealthCheckHandler(db dbStatsProvider, memoryLogging memoryLoggingHealth, startTime time.Time, requests requestCountProvider) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		stats := db.Stats()
+		uptime := time.Since(startTime)
+
+		// Memory logging is reported as disabled when it runs without MongoDB.
+		status := "healthy"
+		memoryLoggingStatus := fiber.Map{"disabled": true}
+		if memoryLogging != nil {
+			if !memoryLogging.Healthy() {
+				status = "degraded"
+			}
+			memoryLoggingStatus = fiber.Map{
+				"healthy":             memoryLogging.Healthy(),
+				"consecutiveFailures": memoryLogging.ConsecutiveFailures(),
+				"buffered":            memoryLogging.Buffered(),
+				"dropped":             memoryLogging.Dropped(),
+			}
+		}
+
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"status":  "healthy",
-			"message": "Service is running",
+			"status":         status,
+			"message":        "Service is running",
+			"uptime":         uptime.Round(time.Second).String(),
+			"uptimeSeconds":  int64(uptime.Seconds()),
+			"requestsServed": requests.Total(),
+			"memoryLogging":  memoryLoggingStatus,
+			"database": fiber.Map{
+				"maxOpenConnections": stats.MaxOpenConnections,
+				"openConnections":    stats.OpenConnections,
+				"inUse":              stats.InUse,
+				"idle":               stats.Idle,
+				"waitCount":          stats.WaitCount,
+				"waitDuration":       stats.WaitDuration.String(),
+			},
 		})
 	}
 }
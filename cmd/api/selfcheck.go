@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/example/go-clean-architecture/pkg/logging"
+)
+
+// selfCheckTimeout bounds how long the startup self-check waits for each dependency.
+const selfCheckTimeout = 5 * time.Second
+
+// dependencyCheck is the outcome of pinging one dependency.
+type dependencyCheck struct {
+	name     string
+	disabled bool
+	latency  time.Duration
+	err      error
+}
+
+// LogValue reports the check as reachable or not, with its latency.
+func (d dependencyCheck) LogValue() slog.Value {
+	if d.disabled {
+		return slog.GroupValue(slog.Bool("disabled", true))
+	}
+	attrs := []slog.Attr{
+		slog.Bool("reachable", d.err == nil),
+		slog.Float64("latency_ms", float64(d.latency.Microseconds())/1000),
+	}
+	if d.err != nil {
+		attrs = append(attrs, slog.String("error", d.err.Error()))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// checkDependencies pings each dependency in turn and records the outcome.
+// Disabled dependencies are recorded without being pinged.
+func checkDependencies(ctx context.Context, deps []dependency) []dependencyCheck {
+	checks := make([]dependencyCheck, 0, len(deps))
+	for _, dep := range deps {
+		if dep.pinger == nil {
+			checks = append(checks, dependencyCheck{name: dep.name, disabled: true})
+			continue
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, selfCheckTimeout)
+		start := time.Now()
+		err := dep.pinger.Ping(pingCtx)
+		cancel()
+		checks = append(checks, dependencyCheck{name: dep.name, latency: time.Since(start), err: err})
+	}
+	return checks
+}
+
+// runStartupSelfCheck pings the critical dependencies and logs a single
+// summary of the startup state: dependency reachability, applied migrations,
+// the number of registered routes and the effective configuration. It returns
+// an error naming the first unreachable dependency.
+func runStartupSelfCheck(ctx context.Context, logger *slog.Logger, deps []dependency, migrations []string, routes int, config Config) error {
+	checks := checkDependencies(ctx, deps)
+
+	depAttrs := make([]any, 0, len(checks))
+	var failed *dependencyCheck
+	for i, check := range checks {
+		depAttrs = append(depAttrs, slog.Any(check.name, check))
+		if check.err != nil && failed == nil {
+			failed = &checks[i]
+		}
+	}
+	attrs := []any{
+		slog.Group("dependencies", depAttrs...),
+		slog.Any("migrations", migrations),
+		slog.Int("routes", routes),
+		slog.Any("config", config),
+	}
+
+	if failed != nil {
+		logger.Error("startup self-check failed", attrs...)
+		return fmt.Errorf("startup self-check failed: %s is unreachable: %w", failed.name, failed.err)
+	}
+	logger.Info("startup self-check passed", attrs...)
+	return nil
+}
+
+// LogValue renders the configuration for logs, redacting credentials and secret URLs.
+func (c Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("app_env", c.appEnv),
+		slog.String("log_format", string(c.logFormat)),
+		slog.String("host", c.host),
+		slog.String("port", c.port),
+		slog.Int("password_min_length", c.passwordPolicy.MinLength),
+		slog.Bool("password_require_upper", c.passwordPolicy.RequireUpper),
+		slog.Bool("password_require_lower", c.passwordPolicy.RequireLower),
+		slog.Bool("password_require_digit", c.passwordPolicy.RequireDigit),
+		slog.Bool("password_require_symbol", c.passwordPolicy.RequireSymbol),
+		slog.Int("password_blocklist_size", len(c.passwordPolicy.Blocklist)),
+		slog.String("password_hasher", fmt.Sprintf("%T", c.passwordHasher.Hasher)),
+		slog.Bool("first_user_admin", c.firstUserAdmin),
+		slog.Any("user_roles", c.roles()),
+		slog.String("default_role", c.defaultRole),
+		slog.Int("max_users", c.maxUsers),
+		slog.Int("default_page_size", c.defaultPageSize),
+		slog.Int("max_page_size", c.maxPageSize),
+		slog.Bool("require_if_match", c.requireIfMatch),
+		slog.Bool("email_mx_check", c.emailMXCheck),
+		slog.Duration("email_mx_timeout", c.emailMXTimeout),
+		slog.Bool("email_verification_required", c.emailVerificationRequired),
+		slog.Duration("email_verification_ttl", c.emailVerificationTTL),
+		slog.Bool("email_change_keep_old", c.emailChangeKeepOld),
+		slog.String("public_url", c.publicURL),
+		slog.String("password_reset_url", c.passwordResetURL),
+		slog.Duration("password_reset_ttl", c.passwordResetTTL),
+		slog.Int("password_reset_rate_limit", c.passwordResetRateLimit),
+		slog.Duration("password_reset_rate_window", c.passwordResetRateWindow),
+		slog.Any("database_url", c.databaseURL),
+		slog.Int("db_max_open_conns", c.dbMaxOpenConns),
+		slog.Int("db_max_idle_conns", c.dbMaxIdleConns),
+		slog.Duration("db_conn_max_lifetime", c.dbConnMaxLifetime),
+		slog.Duration("db_slow_query_threshold", c.dbSlowQuery),
+		slog.Duration("db_statement_timeout", c.dbStatementTimeout),
+		slog.Int("db_read_only_retries", c.dbReadOnlyRetries),
+		slog.Duration("db_read_only_retry_delay", c.dbReadOnlyRetryDelay),
+		slog.Bool("db_auto_migrate", c.dbAutoMigrate),
+		slog.Int("db_replicas", len(c.dbReplicaURLs)),
+		slog.String("user_cache_backend", c.userCacheBackend),
+		slog.Int("user_cache_size", c.userCacheSize),
+		slog.Duration("user_cache_ttl", c.userCacheTTL),
+		slog.String("redis_url", redactURLCredentials(c.redisURL)),
+		slog.Any("trusted_proxies", c.trustedProxies),
+		slog.Duration("memory_log_interval", c.memoryLogInterval),
+		slog.Int("memory_log_buffer", c.memoryLogBuffer),
+		slog.Int("memory_log_batch_size", c.memoryLogBatchSize),
+		slog.Duration("memory_log_batch_interval", c.memoryLogBatchWait),
+		slog.Bool("memory_log_flush_on_shutdown", c.memoryLogFinalSample),
+		slog.Duration("memory_log_flush_timeout", c.memoryLogFlushTimeout),
+		slog.Bool("memory_log_monthly_buckets", c.memoryLogMonthlyBuckets),
+		slog.Any("mongo_url", c.mongoURL),
+		slog.String("mongo_mode", c.mongoMode),
+		slog.String("mongo_replica_set", c.mongoReplicaSet),
+		slog.String("mongo_read_preference", c.mongoReadPref),
+		slog.String("mongo_write_concern", c.mongoWriteConcern),
+		slog.Duration("mongo_slow_command_threshold", c.mongoSlowCommand),
+		slog.Duration("mongo_operation_timeout", c.mongoOperationTimeout),
+		slog.Int("mongo_write_retries", c.mongoWriteRetries),
+		slog.Bool("reuse_port", c.reusePort),
+		slog.Bool("log_request_bodies", c.logRequestBodies),
+		slog.Any("log_redact_fields", c.logRedactFields),
+		slog.String("alert_webhook", redactURLPath(c.alertWebhook)),
+		slog.Duration("alert_cooldown", c.alertCooldown),
+		slog.String("alert_threshold", c.alertThreshold.String()),
+		slog.Bool("heap_profile_on_alert", c.heapProfileAlert),
+		slog.Duration("heap_profile_min_interval", c.heapProfileEvery),
+		slog.Float64("memory_sample_rate", c.memorySampleRate),
+		slog.Bool("memory_legacy_headers", c.memoryLegacyHeaders),
+		slog.Bool("expose_memory_headers", c.exposeMemoryHeaders),
+		slog.Int("compression_level", int(c.compressionLevel)),
+		slog.Int("max_concurrent_requests", c.maxConcurrent),
+		slog.Duration("shutdown_timeout", c.shutdownTimeout),
+		slog.Bool("print_routes_json", c.printRoutesJSON),
+		slog.String("tls_cert", c.tlsCert),
+		slog.String("tls_key", c.tlsKey),
+		slog.String("tls_min_version", tls.VersionName(c.tlsMinVersion)),
+		slog.Bool("h2c", c.h2c),
+		slog.Bool("security_headers", c.securityHeaders),
+		slog.String("content_security_policy", c.contentSecurityPolicy),
+		slog.String("referrer_policy", c.referrerPolicy),
+		slog.Any("cors_allow_origins", c.corsAllowOrigins),
+		slog.Bool("cors_allow_credentials", c.corsAllowCredentials),
+		slog.Any("cors_expose_headers", c.corsExposeHeaders),
+		slog.Duration("cors_max_age", c.corsMaxAge),
+		slog.String("path_normalization", c.pathNormalization),
+		slog.Bool("json_large_uints_as_strings", c.jsonLargeUintsAsStrings),
+	)
+}
+
+// redactURLCredentials masks the password in a URL's user info.
+// Unparseable values are redacted entirely, since they may still hold a secret.
+func redactURLCredentials(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return logging.Redacted
+	}
+	return u.Redacted()
+}
+
+// redactURLPath keeps only the scheme and host of a URL whose path or query
+// may carry a secret, as webhook URLs usually do.
+func redactURLPath(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return logging.Redacted
+	}
+	return u.Scheme + "://" + u.Host + "/" + logging.Redacted
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// shutdownStepTimeout bounds the teardown steps that have no configured timeout.
+const shutdownStepTimeout = 5 * time.Second
+
+// shutdownStep is a named teardown action bounded by its own timeout.
+type shutdownStep struct {
+	name    string
+	timeout time.Duration
+	close   func(ctx context.Context) error
+}
+
+// ShutdownManager tears the application down in ordered steps. Steps run in
+// reverse registration order, so registering components as they are started
+// closes each one before the components it depends on.
+type ShutdownManager struct {
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	steps []shutdownStep
+}
+
+// NewShutdownManager creates a shutdown manager logging each step to logger.
+func NewShutdownManager(logger *slog.Logger) *ShutdownManager {
+	return &ShutdownManager{logger: logger}
+}
+
+// Register adds a teardown step that is given up to timeout to finish.
+func (m *ShutdownManager) Register(name string, timeout time.Duration, close func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.steps = append(m.steps, shutdownStep{name: name, timeout: timeout, close: close})
+}
+
+// Steps returns the names of the registered steps in the order they run.
+func (m *ShutdownManager) Steps() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.steps))
+	for i := len(m.steps) - 1; i >= 0; i-- {
+		names = append(names, m.steps[i].name)
+	}
+	return names
+}
+
+// Shutdown runs the registered steps, last registered first, and forgets
+// them so a second call does nothing. A step that fails or times out is
+// logged and the remaining steps still run; their errors are joined.
+func (m *ShutdownManager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	steps := m.steps
+	m.steps = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(steps) - 1; i >= 0; i-- {
+		if err := m.run(ctx, steps[i]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", steps[i].name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// run executes a single step, abandoning it once its timeout expires.
+func (m *ShutdownManager) run(ctx context.Context, step shutdownStep) error {
+	stepCtx, cancel := context.WithTimeout(ctx, step.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- step.close(stepCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-stepCtx.Done():
+		err = fmt.Errorf("gave up after %s: %w", step.timeout, stepCtx.Err())
+	}
+
+	if err != nil {
+		m.logger.Error("shutdown step failed", "step", step.name, "duration", time.Since(start), "error", err)
+		return err
+	}
+	m.logger.Info("shutdown step finished", "step", step.name, "duration", time.Since(start))
+	return nil
+}
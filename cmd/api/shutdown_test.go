@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/go-clean-architecture/internal/driver"
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/pkg/logging"
+	"github.com/example/go-clean-architecture/pkg/middleware"
+	"github.com/example/go-clean-architecture/pkg/monitoring"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// loggedShutdownSteps returns the steps named in the shutdown log entries, in order.
+func loggedShutdownSteps(t *testing.T, buf *bytes.Buffer) []string {
+	t.Helper()
+
+	var steps []string
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var entry struct {
+			Step string `json:"step"`
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		if entry.Step != "" {
+			steps = append(steps, entry.Step)
+		}
+	}
+	return steps
+}
+
+func TestShutdownManager_RunsStepsInReverseOrder(t *testing.T) {
+	var buf bytes.Buffer
+	manager := NewShutdownManager(logging.New(&buf))
+	var ran []string
+	for _, name := range []string{"first", "second", "third"} {
+		manager.Register(name, time.Second, func(context.Context) error {
+			ran = append(ran, name)
+			return nil
+		})
+	}
+
+	assert.Equal(t, []string{"third", "second", "first"}, manager.Steps())
+	require.NoError(t, manager.Shutdown(context.Background()))
+	assert.Equal(t, []string{"third", "second", "first"}, ran)
+	assert.Equal(t, []string{"third", "second", "first"}, loggedShutdownSteps(t, &buf))
+
+	// Steps run only once
+	require.NoError(t, manager.Shutdown(context.Background()))
+	assert.Len(t, ran, 3)
+}
+
+func TestShutdownManager_ContinuesPastFailedAndTimedOutSteps(t *testing.T) {
+	var buf bytes.Buffer
+	manager := NewShutdownManager(logging.New(&buf))
+	errClose := errors.New("connection reset")
+	var closed bool
+	manager.Register("last", time.Second, func(context.Context) error {
+		closed = true
+		return nil
+	})
+	manager.Register("failing", time.Second, func(context.Context) error {
+		return errClose
+	})
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	manager.Register("hanging", 10*time.Millisecond, func(context.Context) error {
+		<-release
+		return nil
+	})
+
+	err := manager.Shutdown(context.Background())
+
+	require.ErrorIs(t, err, errClose)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "hanging: gave up after 10ms")
+	assert.Contains(t, err.Error(), "failing: connection reset")
+	assert.True(t, closed)
+	assert.Contains(t, buf.String(), `"msg":"shutdown step failed"`)
+}
+
+func TestApp_ShutdownOrder(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectClose()
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	// Leave a sample buffered after a failed write
+	store := &flakyMemoryLogStore{failures: 1}
+	memLogger := newMemoryLogger(store, time.Minute, 10)
+	memLogger.write(context.Background(), &entity.MemoryLog{NumGoroutine: 1})
+	require.Equal(t, 1, memLogger.Buffered())
+
+	var buf bytes.Buffer
+	ctx, cancel := context.WithCancel(context.Background())
+	app := &App{
+		fiberApp:     fiber.New(),
+		db:           driver.NewDB(gormDB),
+		memoryLogger: memLogger,
+		requests:     middleware.NewRequestCounter(),
+		ctx:          ctx,
+		cancel:       cancel,
+		shutdown:     NewShutdownManager(logging.New(&buf)),
+	}
+	stopped := make(chan struct{})
+	app.goBackground(func() {
+		<-app.ctx.Done()
+		close(stopped)
+	})
+	app.registerShutdownSteps(Config{shutdownTimeout: time.Second, memoryLogFlushTimeout: time.Second})
+
+	want := []string{"http server", "in-flight requests", "background tasks", "memory log flush", "database"}
+	assert.Equal(t, want, app.shutdown.Steps())
+
+	require.NoError(t, app.shutdown.Shutdown(context.Background()))
+	assert.Equal(t, want, loggedShutdownSteps(t, &buf))
+	assert.Equal(t, 0, memLogger.Buffered())
+	assert.Len(t, store.stored, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("background goroutine still running after shutdown")
+	}
+}
+
+func TestApp_ShutdownWritesFinalMemoryLog(t *testing.T) {
+	store := &flakyMemoryLogStore{failures: 1}
+	memLogger := newMemoryLogger(store, time.Minute, 10)
+	memLogger.write(context.Background(), &entity.MemoryLog{NumGoroutine: -1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	app := &App{
+		memoryMonitor: monitoring.NewMemoryMonitor(0),
+		memoryLogger:  memLogger,
+		ctx:           ctx,
+		cancel:        cancel,
+		shutdown:      NewShutdownManager(logging.New(io.Discard)),
+	}
+	app.registerShutdownSteps(Config{memoryLogFinalSample: true, memoryLogFlushTimeout: time.Second})
+
+	// Run only the flush; the other steps need a server and a database
+	flush := NewShutdownManager(logging.New(io.Discard))
+	for _, step := range app.shutdown.steps {
+		if step.name == "memory log flush" {
+			flush.Register(step.name, step.timeout, step.close)
+		}
+	}
+	start := time.Now()
+	require.NoError(t, flush.Shutdown(context.Background()))
+
+	// The buffered sample and a final snapshot are stored in one batch
+	require.Len(t, store.batches, 1)
+	batch := store.batches[0]
+	require.Len(t, batch, 2)
+	assert.Equal(t, -1, batch[0].NumGoroutine)
+	assert.NotZero(t, batch[1].Sys)
+	assert.False(t, batch[1].Timestamp.Before(start))
+	assert.Zero(t, memLogger.Buffered())
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertificate writes a self-signed certificate for 127.0.0.1 and its key to dir.
+func writeTestCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir)
+
+	config, err := newTLSConfig("", "", tls.VersionTLS12)
+	require.NoError(t, err)
+	assert.Nil(t, config, "plain HTTP without certificates")
+
+	config, err = newTLSConfig(certFile, keyFile, tls.VersionTLS13)
+	require.NoError(t, err)
+	require.NotNil(t, config)
+	assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+	assert.Len(t, config.Certificates, 1)
+
+	_, err = newTLSConfig(certFile, "", tls.VersionTLS12)
+	assert.ErrorContains(t, err, "must be set together")
+
+	_, err = newTLSConfig(filepath.Join(dir, "missing.pem"), keyFile, tls.VersionTLS12)
+	assert.ErrorContains(t, err, "TLS_CERT file")
+
+	_, err = newTLSConfig(certFile, certFile, tls.VersionTLS12)
+	assert.ErrorContains(t, err, "failed to load TLS certificate")
+}
+
+func TestWithTLS_ServesHTTPSWhenConfigured(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir())
+	config, err := newTLSConfig(certFile, keyFile, tls.VersionTLS12)
+	require.NoError(t, err)
+
+	plain, err := newListener("tcp4", "127.0.0.1:0", false)
+	require.NoError(t, err)
+	assert.Same(t, plain, withTLS(plain, nil), "plain HTTP keeps the listener unchanged")
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendString("ok") })
+	go func() { _ = app.Listener(withTLS(plain, config)) }()
+	t.Cleanup(func() { _ = app.Shutdown() })
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get("https://" + plain.Addr().String() + "/")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotNil(t, resp.TLS)
+	assert.GreaterOrEqual(t, resp.TLS.Version, uint16(tls.VersionTLS12))
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/example/go-clean-architecture/internal/driver"
+	"github.com/example/go-clean-architecture/pkg/migrate"
+	"github.com/example/go-clean-architecture/pkg/secrets"
+)
+
+// usage describes the command line.
+const usage = `usage: migrate [--force] <command>
+
+commands:
+  up         apply every pending migration
+  down N     roll back the last N migrations; requires --force
+  version    print the current schema version`
+
+// migrator is the subset of *migrate.Migrator used by the commands.
+type migrator interface {
+	Up(ctx context.Context) ([]migrate.Migration, error)
+	Down(ctx context.Context, n int) ([]migrate.Migration, error)
+	Version(ctx context.Context) (uint64, error)
+	Applied(ctx context.Context) ([]migrate.Migration, error)
+}
+
+// errUsage is returned for command lines that do not match usage.
+var errUsage = errors.New(usage)
+
+// main applies or rolls back the versioned migrations on the database at
+// DATABASE_URL, read from DATABASE_URL_FILE when set as the API does.
+func main() {
+	databaseURL := secrets.FromEnv("DATABASE_URL")
+	if err := databaseURL.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	db, err := driver.NewDatabase(driver.DatabaseConfig{URL: databaseURL.Value})
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	migrator, err := db.Migrator()
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+
+	if err := run(context.Background(), migrator, os.Args[1:], os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run executes the command in args and prints what it did and the resulting
+// schema version to out. Rolling back drops schema and data, so down only
+// lists the migrations it would roll back unless --force is given.
+func run(ctx context.Context, m migrator, args []string, out io.Writer) error {
+	// --force may come before or after the command, e.g. "down 1 --force".
+	force := false
+	var positional []string
+	for _, arg := range args {
+		switch {
+		case arg == "--force" || arg == "-force":
+			force = true
+		case strings.HasPrefix(arg, "-"):
+			return errUsage
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	args = positional
+
+	switch {
+	case len(args) == 1 && args[0] == "up":
+		applied, err := m.Up(ctx)
+		for _, migration := range applied {
+			fmt.Fprintf(out, "applied %s\n", migration)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "no pending migrations")
+		}
+
+	case len(args) == 2 && args[0] == "down":
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return fmt.Errorf("down needs a positive number of migrations, got %q", args[1])
+		}
+		if !force {
+			return refuseDown(ctx, m, n, out)
+		}
+		rolledBack, err := m.Down(ctx, n)
+		for _, migration := range rolledBack {
+			fmt.Fprintf(out, "rolled back %s\n", migration)
+		}
+		if err != nil {
+			return err
+		}
+
+	case len(args) == 1 && args[0] == "version":
+
+	default:
+		return errUsage
+	}
+
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "schema version: %d\n", version)
+	return nil
+}
+
+// refuseDown lists the migrations a down of n would roll back and returns an
+// error asking for --force.
+func refuseDown(ctx context.Context, m migrator, n int, out io.Writer) error {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return err
+	}
+	for i := len(applied) - 1; i >= 0 && i >= len(applied)-n; i-- {
+		fmt.Fprintf(out, "would roll back %s\n", applied[i])
+	}
+	return errors.New("rolling back migrations drops schema and data; rerun with --force")
+}
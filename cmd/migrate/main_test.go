@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/example/go-clean-architecture/migrations"
+	"github.com/example/go-clean-architecture/pkg/migrate"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMigrator tracks the schema version in memory
+type fakeMigrator struct {
+	migrations []migrate.Migration
+	applied    int
+}
+
+func newFakeMigrator() *fakeMigrator {
+	return &fakeMigrator{migrations: []migrate.Migration{
+		{Version: 1, Name: "create_users"},
+		{Version: 2, Name: "add_user_phone"},
+	}}
+}
+
+func (f *fakeMigrator) Up(ctx context.Context) ([]migrate.Migration, error) {
+	pending := f.migrations[f.applied:]
+	f.applied = len(f.migrations)
+	return pending, nil
+}
+
+func (f *fakeMigrator) Down(ctx context.Context, n int) ([]migrate.Migration, error) {
+	var rolledBack []migrate.Migration
+	for ; n > 0 && f.applied > 0; n-- {
+		f.applied--
+		rolledBack = append(rolledBack, f.migrations[f.applied])
+	}
+	return rolledBack, nil
+}
+
+func (f *fakeMigrator) Version(ctx context.Context) (uint64, error) {
+	if f.applied == 0 {
+		return 0, nil
+	}
+	return f.migrations[f.applied-1].Version, nil
+}
+
+func (f *fakeMigrator) Applied(ctx context.Context) ([]migrate.Migration, error) {
+	return f.migrations[:f.applied], nil
+}
+
+func runCommand(t *testing.T, m migrator, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := run(context.Background(), m, args, &out)
+	return out.String(), err
+}
+
+func TestRun_UpThenDown(t *testing.T) {
+	m := newFakeMigrator()
+
+	out, err := runCommand(t, m, "up")
+	require.NoError(t, err)
+	assert.Equal(t, "applied 000001_create_users\napplied 000002_add_user_phone\nschema version: 2\n", out)
+
+	out, err = runCommand(t, m, "up")
+	require.NoError(t, err)
+	assert.Equal(t, "no pending migrations\nschema version: 2\n", out)
+
+	out, err = runCommand(t, m, "--force", "down", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "rolled back 000002_add_user_phone\nschema version: 1\n", out)
+
+	out, err = runCommand(t, m, "version")
+	require.NoError(t, err)
+	assert.Equal(t, "schema version: 1\n", out)
+}
+
+func TestRun_DownRequiresForce(t *testing.T) {
+	m := newFakeMigrator()
+	_, err := runCommand(t, m, "up")
+	require.NoError(t, err)
+
+	out, err := runCommand(t, m, "down", "2")
+	assert.ErrorContains(t, err, "--force")
+	assert.Equal(t, "would roll back 000002_add_user_phone\nwould roll back 000001_create_users\n", out)
+	assert.Equal(t, 2, m.applied, "nothing is rolled back without --force")
+
+	out, err = runCommand(t, m, "down", "2", "--force")
+	require.NoError(t, err)
+	assert.Contains(t, out, "schema version: 0\n")
+	assert.Zero(t, m.applied)
+}
+
+func TestRun_RejectsInvalidArguments(t *testing.T) {
+	tests := map[string][]string{
+		"no command":      nil,
+		"unknown command": {"redo"},
+		"down without n":  {"down", "--force"},
+		"down zero":       {"down", "0", "--force"},
+		"down not number": {"down", "all", "--force"},
+		"unknown flag":    {"--yes", "down", "1"},
+		"extra argument":  {"up", "1"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := newFakeMigrator()
+			_, err := runCommand(t, m, args...)
+			assert.Error(t, err)
+			assert.Zero(t, m.applied)
+		})
+	}
+}
+
+// TestRun_PostgreSQL drives the commands against the PostgreSQL database at
+// DATABASE_TEST_URL, skipping the test when it is unset. The database should
+// be empty, since the test rolls back everything it applies.
+func TestRun_PostgreSQL(t *testing.T) {
+	url := os.Getenv("DATABASE_TEST_URL")
+	if url == "" {
+		t.Skip("DATABASE_TEST_URL not set")
+	}
+
+	db, err := sql.Open("pgx", url)
+	require.NoError(t, err)
+	defer db.Close()
+
+	loaded, err := migrate.Load(migrations.FS)
+	require.NoError(t, err)
+	m := migrate.New(db, loaded)
+	latest := loaded[len(loaded)-1]
+
+	out, err := runCommand(t, m, "up")
+	require.NoError(t, err)
+	assert.Contains(t, out, "applied "+latest.String()+"\n")
+	version, err := m.Version(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, latest.Version, version)
+
+	_, err = runCommand(t, m, "down", "1")
+	assert.Error(t, err)
+	version, err = m.Version(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, latest.Version, version)
+
+	out, err = runCommand(t, m, "down", strconv.Itoa(len(loaded)), "--force")
+	require.NoError(t, err)
+	assert.Contains(t, out, "schema version: 0\n")
+	version, err = m.Version(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, version)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/example/go-clean-architecture/internal/driver"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/example/go-clean-architecture/pkg/secrets"
+	"github.com/example/go-clean-architecture/pkg/utils"
+)
+
+// main creates the initial admin user from SEED_ADMIN_* environment variables.
+func main() {
+	email := os.Getenv("SEED_ADMIN_EMAIL")
+	password := os.Getenv("SEED_ADMIN_PASSWORD")
+	if email == "" || password == "" {
+		log.Fatal("SEED_ADMIN_EMAIL and SEED_ADMIN_PASSWORD must be set")
+	}
+
+	name := os.Getenv("SEED_ADMIN_NAME")
+	if name == "" {
+		name = "Administrator"
+	}
+
+	// Connect like the API, reading DATABASE_URL_FILE when set.
+	databaseURL := secrets.FromEnv("DATABASE_URL")
+	if err := databaseURL.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	db, err := driver.NewDatabase(driver.DatabaseConfig{URL: databaseURL.Value})
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	migrator, err := db.Migrator()
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+	ctx := context.Background()
+	if _, err := migrator.Up(ctx); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	// Hash with the same hasher the API hashes new passwords with.
+	hasher, err := utils.PasswordHasherFromEnv()
+	if err != nil {
+		log.Printf("WARN: Invalid PASSWORD_HASHER: %v", err)
+	}
+	// Hold the admin password to the same PASSWORD_* policy as the API.
+	userUsecase := usecase.NewUserUsecase(repository.NewUserRepository(db),
+		usecase.WithHasher(hasher),
+		usecase.WithPasswordPolicy(utils.PasswordPolicyFromEnv()))
+
+	created, err := usecase.SeedAdmin(ctx, userUsecase, name, email, password)
+	if err != nil {
+		log.Fatal("Failed to seed admin user:", err)
+	}
+
+	if created {
+		log.Printf("Created admin user %s", email)
+	} else {
+		log.Printf("Skipped seeding admin user %s: users already exist", email)
+	}
+}
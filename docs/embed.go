@@ -4,3 +4,8 @@ import "embed"
 
 //go:embed openapi.yaml openapi.json
 var OpenAPIFS embed.FS
+
+// ViewerFS holds the stylesheet and script of the OpenAPI HTML viewer
+//
+//go:embed viewer
+var ViewerFS embed.FS
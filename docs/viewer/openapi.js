@@ -0,0 +1,227 @@
+// The spec URL is passed by the page, so the script works for any mount point.
+const SPEC_URL = document.currentScript.dataset.specUrl;
+
+const METHOD_COLORS = {
+  get: 'get',
+  post: 'post',
+  put: 'put',
+  delete: 'delete',
+  patch: 'post',
+  options: 'get',
+  head: 'get'
+};
+
+function createTag(method) {
+  const tag = document.createElement('span');
+  tag.className = 'tag ' + (METHOD_COLORS[method] || 'get');
+  tag.textContent = method.toUpperCase();
+  return tag;
+}
+
+function createSummary(method, path, summary) {
+  const summaryEl = document.createElement('summary');
+  summaryEl.appendChild(createTag(method));
+  const pathEl = document.createElement('span');
+  pathEl.className = 'path';
+  pathEl.textContent = path;
+  summaryEl.appendChild(pathEl);
+  if (summary) {
+    const summaryText = document.createElement('span');
+    summaryText.textContent = summary;
+    summaryEl.appendChild(summaryText);
+  }
+  return summaryEl;
+}
+
+function renderParameters(parameters) {
+  if (!parameters || parameters.length === 0) return null;
+  const section = document.createElement('div');
+  section.className = 'section';
+  const title = document.createElement('h4');
+  title.textContent = 'Parameters';
+  section.appendChild(title);
+  const table = document.createElement('table');
+  table.innerHTML = '<thead><tr><th>Name</th><th>In</th><th>Type</th><th>Required</th><th>Description</th></tr></thead>';
+  const tbody = document.createElement('tbody');
+  parameters.forEach(param => {
+    const row = document.createElement('tr');
+    row.innerHTML = '<td>' + param.name + '</td>' +
+      '<td>' + param.in + '</td>' +
+      '<td>' + (param.schema ? param.schema.type || '' : '') + '</td>' +
+      '<td>' + (param.required ? 'Yes' : 'No') + '</td>' +
+      '<td>' + (param.description || '') + '</td>';
+    tbody.appendChild(row);
+  });
+  table.appendChild(tbody);
+  section.appendChild(table);
+  return section;
+}
+
+function renderRequestBody(body) {
+  if (!body) return null;
+  const section = document.createElement('div');
+  section.className = 'section';
+  const title = document.createElement('h4');
+  title.textContent = 'Request Body';
+  section.appendChild(title);
+
+  if (body.description) {
+    const description = document.createElement('p');
+    description.className = 'description';
+    description.textContent = body.description;
+    section.appendChild(description);
+  }
+
+  const content = body.content || {};
+  Object.entries(content).forEach(([mime, cfg]) => {
+    const mimeTitle = document.createElement('strong');
+    mimeTitle.textContent = mime;
+    section.appendChild(mimeTitle);
+    if (cfg.schema) {
+      const pre = document.createElement('pre');
+      pre.textContent = JSON.stringify(cfg.schema, null, 2);
+      section.appendChild(pre);
+    }
+  });
+  return section;
+}
+
+function renderResponses(responses) {
+  if (!responses) return null;
+  const section = document.createElement('div');
+  section.className = 'section';
+  const title = document.createElement('h4');
+  title.textContent = 'Responses';
+  section.appendChild(title);
+
+  Object.entries(responses).forEach(([status, resp]) => {
+    const wrapper = document.createElement('div');
+    const heading = document.createElement('strong');
+    heading.textContent = status + ' · ' + (resp.description || 'Response');
+    wrapper.appendChild(heading);
+    if (resp.content) {
+      Object.entries(resp.content).forEach(([mime, cfg]) => {
+        const mimeLabel = document.createElement('div');
+        mimeLabel.className = 'mime';
+        mimeLabel.textContent = mime;
+        wrapper.appendChild(mimeLabel);
+        if (cfg.schema) {
+          const pre = document.createElement('pre');
+          pre.textContent = JSON.stringify(cfg.schema, null, 2);
+          wrapper.appendChild(pre);
+        }
+      });
+    }
+    section.appendChild(wrapper);
+  });
+
+  return section;
+}
+
+function renderOperation(method, path, operation) {
+  const details = document.createElement('details');
+  details.appendChild(createSummary(method, path, operation.summary || ''));
+
+  const body = document.createElement('div');
+  body.className = 'section';
+  if (operation.description) {
+    const desc = document.createElement('p');
+    desc.className = 'description';
+    desc.textContent = operation.description;
+    body.appendChild(desc);
+  }
+  details.appendChild(body);
+
+  const params = renderParameters(operation.parameters);
+  if (params) details.appendChild(params);
+
+  const request = renderRequestBody(operation.requestBody);
+  if (request) details.appendChild(request);
+
+  const responses = renderResponses(operation.responses);
+  if (responses) details.appendChild(responses);
+
+  return details;
+}
+
+function renderPaths(paths) {
+  const container = document.getElementById('paths');
+  container.innerHTML = '';
+  if (!paths || Object.keys(paths).length === 0) {
+    container.innerHTML = '<div class="card">No paths defined.</div>';
+    return;
+  }
+
+  Object.keys(paths).sort().forEach(path => {
+    const operations = paths[path];
+    Object.keys(operations).forEach(method => {
+      const op = operations[method];
+      container.appendChild(renderOperation(method, path, op));
+    });
+  });
+}
+
+function renderSchemas(schemas) {
+  const container = document.getElementById('schemas');
+  container.innerHTML = '';
+  if (!schemas) {
+    container.hidden = true;
+    return;
+  }
+  container.hidden = false;
+  const title = document.createElement('h2');
+  title.textContent = 'Schemas';
+  container.appendChild(title);
+
+  Object.entries(schemas).forEach(([name, schema]) => {
+    const section = document.createElement('section');
+    const heading = document.createElement('h3');
+    heading.textContent = name;
+    heading.className = 'schema-name';
+    section.appendChild(heading);
+    const pre = document.createElement('pre');
+    pre.textContent = JSON.stringify(schema, null, 2);
+    section.appendChild(pre);
+    container.appendChild(section);
+  });
+}
+
+function renderInfo(info, servers) {
+  document.getElementById('api-title').textContent = info.title || 'API Documentation';
+  document.getElementById('api-description').textContent = info.description || '';
+  const card = document.getElementById('info-card');
+  card.innerHTML = '';
+  const version = document.createElement('p');
+  version.innerHTML = '<strong>Version:</strong> ' + (info.version || 'N/A');
+  card.appendChild(version);
+  if (servers && servers.length) {
+    const list = document.createElement('ul');
+    list.className = 'servers';
+    servers.forEach(server => {
+      const li = document.createElement('li');
+      li.textContent = server.url;
+      list.appendChild(li);
+    });
+    const serversTitle = document.createElement('strong');
+    serversTitle.textContent = 'Servers:';
+    card.appendChild(serversTitle);
+    card.appendChild(list);
+  }
+}
+
+async function init() {
+  const container = document.getElementById('paths');
+  container.innerHTML = '<div class="card loading">Loading specification...</div>';
+  try {
+    const response = await fetch(SPEC_URL);
+    if (!response.ok) throw new Error('Unable to load specification');
+    const spec = await response.json();
+    renderInfo(spec.info || {}, spec.servers || []);
+    renderPaths(spec.paths || {});
+    renderSchemas(spec.components ? spec.components.schemas : null);
+  } catch (error) {
+    container.innerHTML = '<div class="card error">Failed to load API specification. ' + error.message + '</div>';
+  }
+}
+
+init();
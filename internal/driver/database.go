@@ -1,41 +1,136 @@
 package driver
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// DB represents the database connection
+// DB represents the database connection. The embedded connection is the
+// primary, which takes every write; reads that tolerate replication lag can
+// go to a replica through Reader.
 type DB struct {
 	*gorm.DB
+	replicas []*gorm.DB
+	next     *atomic.Uint64
+}
+
+// NewDB wraps a primary connection and optional read replicas
+func NewDB(primary *gorm.DB, replicas ...*gorm.DB) *DB {
+	return &DB{DB: primary, replicas: replicas, next: new(atomic.Uint64)}
+}
+
+// Reader returns a replica connection, taking turns between replicas, or the
+// primary when there are none
+func (d *DB) Reader() *DB {
+	if len(d.replicas) == 0 {
+		return d
+	}
+	replica := d.replicas[(d.next.Add(1)-1)%uint64(len(d.replicas))]
+	return &DB{DB: replica}
+}
+
+// WithContext returns the connection, and its replicas, running queries with
+// ctx, so they are cancelled with it and logged with its request ID
+func (d *DB) WithContext(ctx context.Context) *DB {
+	replicas := make([]*gorm.DB, len(d.replicas))
+	for i, replica := range d.replicas {
+		replicas[i] = replica.WithContext(ctx)
+	}
+	return &DB{DB: d.DB.WithContext(ctx), replicas: replicas, next: d.next}
+}
+
+// Replicas returns a connection for each read replica
+func (d *DB) Replicas() []*DB {
+	replicas := make([]*DB, len(d.replicas))
+	for i, replica := range d.replicas {
+		replicas[i] = &DB{DB: replica}
+	}
+	return replicas
+}
+
+// DatabaseConfig holds connection pool settings; zero values keep the driver defaults
+type DatabaseConfig struct {
+	URL              string // connection string; empty reads DATABASE_URL
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	Logger           *slog.Logger  // query logger; nil keeps GORM's default logger
+	SlowQuery        time.Duration // queries slower than this are logged as warnings; zero disables
+	ReplicaURLs      []string      // read replicas; reads go to the primary when empty
+	StatementTimeout time.Duration // the server cancels statements running longer, unless the URL sets statement_timeout; zero disables
+}
+
+// poolConfigurer is the subset of *sql.DB used to apply pool settings
+type poolConfigurer interface {
+	SetMaxOpenConns(n int)
+	SetMaxIdleConns(n int)
+	SetConnMaxLifetime(d time.Duration)
 }
 
 // NewDatabase creates a new database connection with retry mechanism
-func NewDatabase() (*DB, error) {
-	// Get database URL from environment variable or use default
-	dbURL := os.Getenv("DATABASE_URL")
+func NewDatabase(config DatabaseConfig) (*DB, error) {
+	// Get database URL from the config, the environment or use default
+	dbURL := config.URL
+	if dbURL == "" {
+		dbURL = os.Getenv("DATABASE_URL")
+	}
 	if dbURL == "" {
 		// Default PostgreSQL connection string
 		dbURL = "host=db user=user password=password dbname=go_clean_arch port=5432 sslmode=disable"
 	}
 
+	gormConfig := &gorm.Config{}
+	if config.Logger != nil {
+		gormConfig.Logger = NewGormLogger(config.Logger, config.SlowQuery)
+	}
+
+	primary, err := open("database", dbURL, gormConfig, config)
+	if err != nil {
+		return nil, err
+	}
+	replicas := make([]*gorm.DB, 0, len(config.ReplicaURLs))
+	for i, replicaURL := range config.ReplicaURLs {
+		replica, err := open(fmt.Sprintf("database replica %d", i+1), replicaURL, gormConfig, config)
+		if err != nil {
+			return nil, err
+		}
+		replicas = append(replicas, replica)
+	}
+
+	return NewDB(primary, replicas...), nil
+}
+
+// open connects to the database at dbURL with retry mechanism and applies the pool settings
+func open(name, dbURL string, gormConfig *gorm.Config, config DatabaseConfig) (*gorm.DB, error) {
+	dbURL, err := withStatementTimeout(dbURL, config.StatementTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s connection string: %w", name, err)
+	}
+
 	// Retry mechanism
 	var db *gorm.DB
-	var err error
 
 	// Try to connect with exponential backoff
 	for i := 0; i < 5; i++ {
-		db, err = gorm.Open(postgres.Open(dbURL), &gorm.Config{})
+		db, err = gorm.Open(postgres.Open(dbURL), gormConfig)
 		if err == nil {
 			break
 		}
 
-		log.Printf("Failed to connect to database (attempt %d): %v", i+1, err)
+		log.Printf("Failed to connect to %s (attempt %d): %v", name, i+1, err)
 		if i < 4 {
 			// Exponential backoff: 1s, 2s, 4s, 8s
 			time.Sleep(time.Duration(1<<i) * time.Second)
@@ -43,12 +138,94 @@ func NewDatabase() (*DB, error) {
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database after 5 attempts: %w", err)
+		return nil, fmt.Errorf("failed to connect to %s after 5 attempts: %w", name, err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to access %s pool: %w", name, err)
+	}
+	applyPoolConfig(sqlDB, config)
+
+	log.Printf("Connection to %s established", name)
+
+	return db, nil
+}
+
+// withStatementTimeout adds a statement_timeout runtime parameter, in
+// milliseconds, to a URL or key=value connection string. Connection strings
+// that already set one keep it.
+func withStatementTimeout(dsn string, timeout time.Duration) (string, error) {
+	if timeout <= 0 {
+		return dsn, nil
+	}
+	// Round up, since 0 would disable the timeout
+	millis := strconv.FormatInt(max(timeout.Milliseconds(), 1), 10)
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		parsed, err := url.Parse(dsn)
+		if err != nil {
+			return "", err
+		}
+		query := parsed.Query()
+		if query.Has("statement_timeout") {
+			return dsn, nil
+		}
+		query.Set("statement_timeout", millis)
+		parsed.RawQuery = query.Encode()
+		return parsed.String(), nil
+	}
+
+	for _, field := range strings.Fields(dsn) {
+		if strings.HasPrefix(field, "statement_timeout=") {
+			return dsn, nil
+		}
+	}
+	return strings.TrimSpace(dsn + " statement_timeout=" + millis), nil
+}
+
+// applyPoolConfig applies the configured pool settings to the connection pool
+func applyPoolConfig(pool poolConfigurer, config DatabaseConfig) {
+	if config.MaxOpenConns > 0 {
+		pool.SetMaxOpenConns(config.MaxOpenConns)
+	}
+	if config.MaxIdleConns > 0 {
+		pool.SetMaxIdleConns(config.MaxIdleConns)
+	}
+	if config.ConnMaxLifetime > 0 {
+		pool.SetConnMaxLifetime(config.ConnMaxLifetime)
+	}
+}
+
+// Stats returns the connection pool statistics
+func (d *DB) Stats() sql.DBStats {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return sql.DBStats{}
 	}
+	return sqlDB.Stats()
+}
 
-	log.Println("Database connection established")
+// Close closes the connection pools of the primary and every replica
+func (d *DB) Close() error {
+	var errs []error
+	for _, conn := range append([]*gorm.DB{d.DB}, d.replicas...) {
+		sqlDB, err := conn.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
 
-	return &DB{db}, nil
+// Ping verifies that the database is reachable
+func (d *DB) Ping(ctx context.Context) error {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
 }
 
 // Create implements the Database interface
@@ -75,8 +252,44 @@ func (d *DB) Save(value interface{}) error {
 	return result.Error
 }
 
+// SaveIf implements the Database interface, updating every field of value
+// only in the rows that also match the conditions
+func (d *DB) SaveIf(value interface{}, query interface{}, args ...interface{}) (int64, error) {
+	result := d.DB.Model(value).Where(query, args...).Select("*").Updates(value)
+	return result.RowsAffected, result.Error
+}
+
 // Delete implements the Database interface
 func (d *DB) Delete(value interface{}, conditions ...interface{}) error {
 	result := d.DB.Delete(value, conditions...)
 	return result.Error
 }
+
+// Count implements the Database interface
+func (d *DB) Count(ctx context.Context, model interface{}, count *int64) error {
+	result := d.DB.WithContext(ctx).Model(model).Count(count)
+	return result.Error
+}
+
+// FindPage implements the Database interface; a non-positive limit disables paging
+func (d *DB) FindPage(dest interface{}, order string, offset, limit int, conditions ...interface{}) error {
+	query := d.DB.Order(order)
+	if limit > 0 {
+		query = query.Offset(offset).Limit(limit)
+	}
+	result := query.Find(dest, conditions...)
+	return result.Error
+}
+
+// Exec implements the Database interface
+func (d *DB) Exec(sql string, values ...interface{}) error {
+	result := d.DB.Exec(sql, values...)
+	return result.Error
+}
+
+// Transaction implements the Database interface, committing when fn returns nil
+func (d *DB) Transaction(ctx context.Context, fn func(tx *DB) error) error {
+	return d.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&DB{DB: tx})
+	})
+}
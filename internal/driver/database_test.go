@@ -0,0 +1,158 @@
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// recordingPool captures the pool settings applied to it
+type recordingPool struct {
+	maxOpen     int
+	maxIdle     int
+	maxLifetime time.Duration
+}
+
+func (p *recordingPool) SetMaxOpenConns(n int)              { p.maxOpen = n }
+func (p *recordingPool) SetMaxIdleConns(n int)              { p.maxIdle = n }
+func (p *recordingPool) SetConnMaxLifetime(d time.Duration) { p.maxLifetime = d }
+
+func TestApplyPoolConfig(t *testing.T) {
+	pool := &recordingPool{}
+
+	applyPoolConfig(pool, DatabaseConfig{
+		MaxOpenConns:    20,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 30 * time.Minute,
+	})
+
+	assert.Equal(t, 20, pool.maxOpen)
+	assert.Equal(t, 5, pool.maxIdle)
+	assert.Equal(t, 30*time.Minute, pool.maxLifetime)
+}
+
+func TestApplyPoolConfig_ZeroValuesKeepDefaults(t *testing.T) {
+	pool := &recordingPool{maxOpen: -1, maxIdle: -1, maxLifetime: -1}
+
+	applyPoolConfig(pool, DatabaseConfig{})
+
+	assert.Equal(t, -1, pool.maxOpen)
+	assert.Equal(t, -1, pool.maxIdle)
+	assert.Equal(t, time.Duration(-1), pool.maxLifetime)
+}
+
+func TestApplyPoolConfig_SQLDB(t *testing.T) {
+	// Opening a pgx pool is lazy, so no server is needed to inspect its stats
+	sqlDB, err := sql.Open("pgx", "host=localhost")
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	applyPoolConfig(sqlDB, DatabaseConfig{MaxOpenConns: 7})
+
+	assert.Equal(t, 7, sqlDB.Stats().MaxOpenConnections)
+}
+
+func TestDB_Reader(t *testing.T) {
+	primary, first, second := &gorm.DB{}, &gorm.DB{}, &gorm.DB{}
+
+	db := NewDB(primary)
+	assert.Same(t, db, db.Reader(), "without replicas reads use the primary")
+	assert.Empty(t, db.Replicas())
+
+	db = NewDB(primary, first, second)
+	assert.Same(t, primary, db.DB, "writes use the primary")
+	assert.Same(t, first, db.Reader().DB)
+	assert.Same(t, second, db.Reader().DB)
+	assert.Same(t, first, db.Reader().DB)
+	require.Len(t, db.Replicas(), 2)
+	assert.Same(t, second, db.Replicas()[1].DB)
+
+	// Transactions and replica connections read from themselves
+	tx := &DB{DB: primary}
+	assert.Same(t, tx, tx.Reader())
+}
+
+func TestDB_WithContext(t *testing.T) {
+	var conns []*gorm.DB
+	for range 3 {
+		sqlDB, _, err := sqlmock.New()
+		require.NoError(t, err)
+		t.Cleanup(func() { sqlDB.Close() })
+		conn, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+		require.NoError(t, err)
+		conns = append(conns, conn)
+	}
+	db := NewDB(conns[0], conns[1], conns[2])
+	ctx := context.WithValue(context.Background(), contextKey{}, "request")
+
+	scoped := db.WithContext(ctx)
+
+	assert.Equal(t, ctx, scoped.Statement.Context, "writes use ctx")
+	assert.Equal(t, ctx, scoped.Reader().Statement.Context, "reads use ctx")
+	assert.Equal(t, ctx, scoped.Reader().Statement.Context)
+	// The scoped connection shares the turns of the original one
+	assert.Same(t, conns[1], db.Reader().DB)
+}
+
+// contextKey marks the context passed to WithContext
+type contextKey struct{}
+
+func TestDB_CloseClosesReplicas(t *testing.T) {
+	var mocks []sqlmock.Sqlmock
+	var conns []*gorm.DB
+	for range 2 {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		mock.ExpectClose()
+		conn, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+		require.NoError(t, err)
+		mocks, conns = append(mocks, mock), append(conns, conn)
+	}
+
+	require.NoError(t, NewDB(conns[0], conns[1]).Close())
+	for _, mock := range mocks {
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestWithStatementTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		dsn     string
+		timeout time.Duration
+		want    string
+	}{
+		{"key value", "host=db dbname=app", 30 * time.Second, "host=db dbname=app statement_timeout=30000"},
+		{"url", "postgres://user:pass@db:5432/app?sslmode=disable", 5 * time.Second, "postgres://user:pass@db:5432/app?sslmode=disable&statement_timeout=5000"},
+		{"postgresql url", "postgresql://db/app", 1500 * time.Millisecond, "postgresql://db/app?statement_timeout=1500"},
+		{"sub-millisecond rounds up", "host=db", time.Microsecond, "host=db statement_timeout=1"},
+		{"disabled", "host=db", 0, "host=db"},
+		{"key value keeps explicit", "host=db statement_timeout=100", time.Second, "host=db statement_timeout=100"},
+		{"url keeps explicit", "postgres://db/app?statement_timeout=100", time.Second, "postgres://db/app?statement_timeout=100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := withStatementTimeout(tt.dsn, tt.timeout)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWithStatementTimeout_ParsedByPgx(t *testing.T) {
+	dsn, err := withStatementTimeout("postgres://user@db:5432/app", 2*time.Second)
+	require.NoError(t, err)
+
+	config, err := pgx.ParseConfig(dsn)
+	require.NoError(t, err)
+	assert.Equal(t, "2000", config.RuntimeParams["statement_timeout"])
+}
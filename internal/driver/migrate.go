@@ -0,0 +1,19 @@
+package driver
+
+import (
+	"github.com/example/go-clean-architecture/migrations"
+	"github.com/example/go-clean-architecture/pkg/migrate"
+)
+
+// Migrator returns a migrator for the project's versioned migrations
+func (d *DB) Migrator() (*migrate.Migrator, error) {
+	sqlDB, err := d.DB.DB()
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := migrate.Load(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(sqlDB, loaded), nil
+}
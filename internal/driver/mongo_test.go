@@ -0,0 +1,47 @@
+package driver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestMongoClientOptions_AppliesConfig(t *testing.T) {
+	clientOptions, err := mongoClientOptions("mongodb://localhost:27017", MongoConfig{
+		ReplicaSet:     "rs0",
+		ReadPreference: "secondaryPreferred",
+		WriteConcern:   "majority",
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, clientOptions.ReplicaSet)
+	assert.Equal(t, "rs0", *clientOptions.ReplicaSet)
+	assert.Equal(t, readpref.SecondaryPreferredMode, clientOptions.ReadPreference.Mode())
+	assert.Equal(t, "majority", clientOptions.WriteConcern.W)
+}
+
+func TestMongoClientOptions_NumericWriteConcern(t *testing.T) {
+	clientOptions, err := mongoClientOptions("mongodb://localhost:27017", MongoConfig{WriteConcern: "2"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, clientOptions.WriteConcern.W)
+}
+
+func TestMongoClientOptions_EmptyConfigKeepsURI(t *testing.T) {
+	clientOptions, err := mongoClientOptions("mongodb://localhost:27017/?replicaSet=rs1&readPreference=nearest", MongoConfig{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "rs1", *clientOptions.ReplicaSet)
+	assert.Equal(t, readpref.NearestMode, clientOptions.ReadPreference.Mode())
+	assert.Nil(t, clientOptions.WriteConcern)
+}
+
+func TestMongoClientOptions_InvalidValues(t *testing.T) {
+	_, err := mongoClientOptions("mongodb://localhost:27017", MongoConfig{ReadPreference: "fastest"})
+	assert.ErrorContains(t, err, "read preference")
+
+	_, err = mongoClientOptions("mongodb://localhost:27017", MongoConfig{WriteConcern: "all"})
+	assert.ErrorContains(t, err, "write concern")
+}
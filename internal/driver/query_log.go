@@ -0,0 +1,129 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// gormLogger writes GORM query logs through a structured logger. Logs are
+// written with the query's context, so a request ID stored in it by the
+// request ID middleware ends up in the log line.
+type gormLogger struct {
+	logger        *slog.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewGormLogger creates a GORM logger that reports failed queries as errors,
+// queries slower than slowThreshold as warnings and every other query at debug
+// level. A zero slowThreshold disables slow-query warnings.
+func NewGormLogger(logger *slog.Logger, slowThreshold time.Duration) gormlogger.Interface {
+	return &gormLogger{logger: logger, level: gormlogger.Warn, slowThreshold: slowThreshold}
+}
+
+// LogMode returns a copy of the logger at the given level
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// Info logs a GORM informational message
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Warn logs a GORM warning
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Error logs a GORM error
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// ParamsFilter drops the bound parameters from logged queries, so the SQL is
+// logged with placeholders and never carries emails or password hashes
+func (l *gormLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+// Trace logs a finished query with its SQL, affected rows and duration. Slow
+// queries often point at N+1 access patterns or missing indexes.
+// Record-not-found errors are expected lookups and are not reported as failures.
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+	if !failed && !slow && !l.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []any{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Float64("duration_ms", durationMillis(elapsed)),
+	}
+	if failed && l.level >= gormlogger.Error {
+		l.logger.ErrorContext(ctx, "database query failed", append(attrs, slog.String("error", err.Error()))...)
+		return
+	}
+	if slow && l.level >= gormlogger.Warn {
+		l.logger.WarnContext(ctx, "slow database query", append(attrs, slog.Float64("threshold_ms", durationMillis(l.slowThreshold)))...)
+		return
+	}
+	l.logger.DebugContext(ctx, "database query", attrs...)
+}
+
+// NewMongoCommandMonitor creates a Mongo command monitor that reports failed
+// commands as errors, commands slower than slowThreshold as warnings and every
+// other command at debug level. A zero slowThreshold disables slow-command
+// warnings. Events carry the operation's context, so request IDs end up in
+// the log line.
+func NewMongoCommandMonitor(logger *slog.Logger, slowThreshold time.Duration) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			attrs := []any{
+				slog.String("command", evt.CommandName),
+				slog.String("database", evt.DatabaseName),
+				slog.Float64("duration_ms", durationMillis(evt.Duration)),
+			}
+			if slowThreshold > 0 && evt.Duration > slowThreshold {
+				logger.WarnContext(ctx, "slow mongo command", append(attrs, slog.Float64("threshold_ms", durationMillis(slowThreshold)))...)
+				return
+			}
+			logger.DebugContext(ctx, "mongo command", attrs...)
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			logger.ErrorContext(ctx, "mongo command failed",
+				slog.String("command", evt.CommandName),
+				slog.String("database", evt.DatabaseName),
+				slog.Float64("duration_ms", durationMillis(evt.Duration)),
+				slog.String("error", evt.Failure))
+		},
+	}
+}
+
+// durationMillis converts d to fractional milliseconds for logging
+func durationMillis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,158 @@
+package driver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/go-clean-architecture/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/event"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type queryLogUser struct {
+	ID   uint
+	Name string
+}
+
+// newLoggedGormDB opens GORM on a SQL mock, logging queries to logs
+func newLoggedGormDB(t *testing.T, logs *bytes.Buffer) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: NewGormLogger(logging.New(logs), 20*time.Millisecond),
+	})
+	require.NoError(t, err)
+	return gormDB, sqlMock
+}
+
+func TestGormLogger_FailedQueryCarriesRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	db, sqlMock := newLoggedGormDB(t, &logs)
+	sqlMock.ExpectQuery(`SELECT \* FROM "query_log_users"`).WillReturnError(errors.New("connection reset"))
+
+	ctx := logging.ContextWithRequestID(context.Background(), "req-42")
+	var users []queryLogUser
+	err := db.WithContext(ctx).Find(&users).Error
+	require.Error(t, err)
+
+	assert.Contains(t, logs.String(), `"msg":"database query failed"`)
+	assert.Contains(t, logs.String(), `"requestId":"req-42"`)
+	assert.Contains(t, logs.String(), `"sql":"SELECT * FROM \"query_log_users\""`)
+	assert.Contains(t, logs.String(), `"error":"connection reset"`)
+}
+
+func TestGormLogger_RecordNotFoundIsNotAFailure(t *testing.T) {
+	var logs bytes.Buffer
+	db, sqlMock := newLoggedGormDB(t, &logs)
+	sqlMock.ExpectQuery(`SELECT \* FROM "query_log_users"`).WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+
+	var user queryLogUser
+	err := db.First(&user).Error
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	assert.Empty(t, logs.String())
+}
+
+func TestMongoCommandMonitor_FailedCommandCarriesRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	monitor := NewMongoCommandMonitor(logging.New(&logs), 0)
+
+	ctx := logging.ContextWithRequestID(context.Background(), "req-42")
+	monitor.Failed(ctx, &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{
+			CommandName:  "aggregate",
+			DatabaseName: "go_clean_arch",
+			Duration:     1500 * time.Microsecond,
+		},
+		Failure: "operation exceeded time limit",
+	})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find"},
+	})
+
+	assert.Contains(t, logs.String(), `"msg":"mongo command failed"`)
+	assert.Contains(t, logs.String(), `"requestId":"req-42"`)
+	assert.Contains(t, logs.String(), `"command":"aggregate"`)
+	assert.Contains(t, logs.String(), `"duration_ms":1.5`)
+	assert.NotContains(t, logs.String(), `"command":"find"`, "successful commands are logged at debug level")
+}
+
+func TestGormLogger_WarnsAboutSlowQueries(t *testing.T) {
+	var logs bytes.Buffer
+	db, sqlMock := newLoggedGormDB(t, &logs)
+	sqlMock.ExpectQuery(`SELECT \* FROM "query_log_users"`).
+		WillDelayFor(50 * time.Millisecond).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "John Doe"))
+	sqlMock.ExpectQuery(`SELECT \* FROM "query_log_users"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "John Doe"))
+
+	var users []queryLogUser
+	require.NoError(t, db.Find(&users).Error)
+	require.NoError(t, db.Find(&users).Error)
+
+	lines := bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n"))
+	require.Len(t, lines, 1, "only the slow query is logged")
+	assert.Contains(t, string(lines[0]), `"level":"WARN"`)
+	assert.Contains(t, string(lines[0]), `"msg":"slow database query"`)
+	assert.Contains(t, string(lines[0]), `"sql":"SELECT * FROM \"query_log_users\""`)
+	assert.Contains(t, string(lines[0]), `"threshold_ms":20`)
+}
+
+func TestGormLogger_ZeroThresholdDisablesSlowQueryWarnings(t *testing.T) {
+	var logs bytes.Buffer
+	l := NewGormLogger(logging.New(&logs), 0)
+
+	l.Trace(context.Background(), time.Now().Add(-time.Hour), func() (string, int64) {
+		return "SELECT 1", 1
+	}, nil)
+
+	assert.Empty(t, logs.String())
+}
+
+func TestMongoCommandMonitor_WarnsAboutSlowCommands(t *testing.T) {
+	var logs bytes.Buffer
+	monitor := NewMongoCommandMonitor(logging.New(&logs), 100*time.Millisecond)
+
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{
+			CommandName:  "aggregate",
+			DatabaseName: "go_clean_arch",
+			Duration:     250 * time.Millisecond,
+		},
+	})
+	monitor.Succeeded(context.Background(), &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", Duration: 5 * time.Millisecond},
+	})
+
+	lines := bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n"))
+	require.Len(t, lines, 1, "only the slow command is logged")
+	assert.Contains(t, string(lines[0]), `"level":"WARN"`)
+	assert.Contains(t, string(lines[0]), `"msg":"slow mongo command"`)
+	assert.Contains(t, string(lines[0]), `"command":"aggregate"`)
+	assert.Contains(t, string(lines[0]), `"duration_ms":250`)
+	assert.Contains(t, string(lines[0]), `"threshold_ms":100`)
+}
+
+func TestGormLogger_LogsParameterizedSQL(t *testing.T) {
+	var logs bytes.Buffer
+	db, sqlMock := newLoggedGormDB(t, &logs)
+	sqlMock.ExpectQuery(`SELECT \* FROM "query_log_users"`).WillReturnError(errors.New("connection reset"))
+
+	var users []queryLogUser
+	err := db.Where("name = ?", "jane@example.com").Find(&users).Error
+	require.Error(t, err)
+
+	assert.Contains(t, logs.String(), `WHERE name = $1`)
+	assert.NotContains(t, logs.String(), "jane@example.com")
+}
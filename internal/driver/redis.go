@@ -0,0 +1,31 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedis creates a Redis client from a redis:// URL. An unreachable server
+// is logged rather than treated as fatal, since callers degrade without it.
+func NewRedis(redisURL string) (*redis.Client, error) {
+	options, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+
+	client := redis.NewClient(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Printf("WARN: Redis is not reachable yet: %v", err)
+	} else {
+		log.Println("Redis connection established")
+	}
+
+	return client, nil
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"time"
+)
+
+// Audited user actions
+const (
+	AuditActionCreate         = "create"
+	AuditActionUpdate         = "update"
+	AuditActionDelete         = "delete"
+	AuditActionChangePassword = "change_password"
+	AuditActionChangeEmail    = "change_email"
+	AuditActionResetPassword  = "reset_password"
+)
+
+// AuditActorAnonymous is the actor recorded for unauthenticated requests
+const AuditActorAnonymous = "anonymous"
+
+// AuditChange holds the value of a field before and after a mutation
+type AuditChange struct {
+	Before interface{} `json:"before,omitempty" bson:"before,omitempty"`
+	After  interface{} `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+// AuditLog records who changed which user, how, and when
+type AuditLog struct {
+	ID        string                 `json:"id" bson:"_id,omitempty"`
+	Actor     string                 `json:"actor" bson:"actor"`
+	Action    string                 `json:"action" bson:"action"`
+	TargetID  uint                   `json:"targetId" bson:"targetId"`
+	Changes   map[string]AuditChange `json:"changes,omitempty" bson:"changes,omitempty"`
+	Timestamp time.Time              `json:"timestamp" bson:"timestamp"`
+}
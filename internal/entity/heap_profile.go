@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/example/go-clean-architecture/pkg/jsonnum"
+)
+
+// HeapProfile represents a heap profile captured when a memory alert fired
+type HeapProfile struct {
+	ID           string    `json:"id" bson:"_id,omitempty"`
+	Timestamp    time.Time `json:"timestamp" bson:"timestamp"`
+	Alloc        uint64    `json:"alloc" bson:"alloc"`
+	Sys          uint64    `json:"sys" bson:"sys"`
+	NumGoroutine int       `json:"numGoroutine" bson:"numGoroutine"`
+	Size         int       `json:"size" bson:"size"`
+	Data         []byte    `json:"-" bson:"data,omitempty"` // gzipped pprof profile
+}
+
+// heapProfileJSON is HeapProfile with its byte counts encoded by jsonnum
+type heapProfileJSON struct {
+	plainHeapProfile
+	Alloc jsonnum.Uint64 `json:"alloc"`
+	Sys   jsonnum.Uint64 `json:"sys"`
+}
+
+// plainHeapProfile has HeapProfile's fields without its JSON methods
+type plainHeapProfile HeapProfile
+
+// MarshalJSON writes the byte counts with jsonnum
+func (p HeapProfile) MarshalJSON() ([]byte, error) {
+	return json.Marshal(heapProfileJSON{
+		plainHeapProfile: plainHeapProfile(p),
+		Alloc:            jsonnum.Uint64(p.Alloc),
+		Sys:              jsonnum.Uint64(p.Sys),
+	})
+}
+
+// UnmarshalJSON reads byte counts written as numbers or strings
+func (p *HeapProfile) UnmarshalJSON(data []byte) error {
+	var v heapProfileJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = HeapProfile(v.plainHeapProfile)
+	p.Alloc, p.Sys = uint64(v.Alloc), uint64(v.Sys)
+	return nil
+}
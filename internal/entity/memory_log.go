@@ -1,7 +1,10 @@
 package entity
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/example/go-clean-architecture/pkg/jsonnum"
 )
 
 // MemoryLog represents a memory log entity for MongoDB storage
@@ -15,3 +18,36 @@ type MemoryLog struct {
 	GCCPUFraction float64   `json:"gcCPUFraction" bson:"gcCPUFraction"`
 	NumGoroutine  int       `json:"numGoroutine" bson:"numGoroutine"`
 }
+
+// memoryLogJSON is MemoryLog with its byte counts encoded by jsonnum
+type memoryLogJSON struct {
+	plainMemoryLog
+	Alloc      jsonnum.Uint64 `json:"alloc"`
+	TotalAlloc jsonnum.Uint64 `json:"totalAlloc"`
+	Sys        jsonnum.Uint64 `json:"sys"`
+}
+
+// plainMemoryLog has MemoryLog's fields without its JSON methods
+type plainMemoryLog MemoryLog
+
+// MarshalJSON writes the byte counts with jsonnum, so clients parsing them
+// as JavaScript numbers can keep them exact
+func (l MemoryLog) MarshalJSON() ([]byte, error) {
+	return json.Marshal(memoryLogJSON{
+		plainMemoryLog: plainMemoryLog(l),
+		Alloc:          jsonnum.Uint64(l.Alloc),
+		TotalAlloc:     jsonnum.Uint64(l.TotalAlloc),
+		Sys:            jsonnum.Uint64(l.Sys),
+	})
+}
+
+// UnmarshalJSON reads byte counts written as numbers or strings
+func (l *MemoryLog) UnmarshalJSON(data []byte) error {
+	var v memoryLogJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*l = MemoryLog(v.plainMemoryLog)
+	l.Alloc, l.TotalAlloc, l.Sys = uint64(v.Alloc), uint64(v.TotalAlloc), uint64(v.Sys)
+	return nil
+}
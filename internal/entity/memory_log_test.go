@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/pkg/jsonnum"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLog_JSONLargeUintsAsStrings(t *testing.T) {
+	log := MemoryLog{
+		ID:         "65f1a2b3c4d5e6f708192a3b",
+		Timestamp:  time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Alloc:      2048,
+		TotalAlloc: 1<<53 + 1,
+		Sys:        1 << 62,
+		NumGC:      3,
+	}
+
+	tests := []struct {
+		strings    bool
+		totalAlloc interface{}
+	}{
+		{false, float64(1<<53 + 1)},
+		{true, "9007199254740993"},
+	}
+	for _, tt := range tests {
+		previous := jsonnum.LargeUintsAsStrings
+		jsonnum.LargeUintsAsStrings = tt.strings
+		data, err := json.Marshal(log)
+		jsonnum.LargeUintsAsStrings = previous
+		require.NoError(t, err)
+
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &fields))
+		assert.Equal(t, tt.totalAlloc, fields["totalAlloc"])
+		assert.Equal(t, float64(2048), fields["alloc"])
+		assert.Equal(t, "65f1a2b3c4d5e6f708192a3b", fields["id"])
+		assert.Equal(t, "2024-03-01T12:00:00Z", fields["timestamp"])
+
+		var decoded MemoryLog
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, log, decoded, "both encodings decode to the exact value")
+	}
+}
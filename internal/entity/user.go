@@ -4,12 +4,20 @@ import (
 	"time"
 )
 
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // User represents a user entity
 type User struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	Name      string    `json:"name" gorm:"not null"`
 	Email     string    `json:"email" gorm:"uniqueIndex;not null"`
 	Password  string    `json:"-" gorm:"not null"`
+	Role      string    `json:"role" gorm:"not null;default:user"`
+	Verified  bool      `json:"verified" gorm:"not null;default:false"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -23,14 +31,59 @@ func (User) TableName() string {
 type UserResponse struct {
 	ID        uint      `json:"id"`
 	Name      string    `json:"name"`
-	Email     string    `json:"email"`
+	Email     string    `json:"email" jsonschema:"format=email"`
+	Role      string    `json:"role"`
+	Verified  bool      `json:"verified"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// UserListResponse represents a page of users. Page is omitted for cursor
+// listings; NextCursor is omitted once the listing is exhausted.
+type UserListResponse struct {
+	Users      []UserResponse `json:"users"`
+	Page       int            `json:"page,omitempty"`
+	Limit      int            `json:"limit"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
 // UserRequest represents the user request structure
 type UserRequest struct {
-	Name     string `json:"name" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Name     string `json:"name" yaml:"name" binding:"required"`
+	Email    string `json:"email" yaml:"email" binding:"required,email"`
+	Password string `json:"password" yaml:"password" binding:"required,min=6" jsonschema:"format=password"`
+	Role     string `json:"role,omitempty" yaml:"role,omitempty"`
+	// Verified marks the email as already verified; only trusted callers such as seeding set it
+	Verified bool `json:"-" yaml:"-"`
+}
+
+// UpdateUserRequest represents the user update request structure. It has no
+// password, which only changes through the change password and reset flows.
+type UpdateUserRequest struct {
+	Name  string `json:"name" yaml:"name" binding:"required"`
+	Email string `json:"email" yaml:"email" binding:"required,email"`
+	Role  string `json:"role,omitempty" yaml:"role,omitempty"`
+}
+
+// ChangePasswordRequest represents the change password request structure
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required"`
+}
+
+// ChangeEmailRequest represents the change email request structure
+type ChangeEmailRequest struct {
+	CurrentPassword string `json:"currentPassword" yaml:"currentPassword"`
+	Email           string `json:"email" yaml:"email" jsonschema:"format=email"`
+}
+
+// PasswordResetRequest represents a request for a password reset link
+type PasswordResetRequest struct {
+	Email string `json:"email" yaml:"email"`
+}
+
+// PasswordResetConfirmRequest represents the confirmation of a password reset
+type PasswordResetConfirmRequest struct {
+	Token       string `json:"token" yaml:"token"`
+	NewPassword string `json:"newPassword" yaml:"newPassword"`
 }
@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUser_MarshalOmitsPassword(t *testing.T) {
+	user := User{ID: 1, Name: "John Doe", Email: "john.doe@example.com", Password: "$2a$10$hashedpassword"}
+
+	data, err := json.Marshal(user)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.NotContains(t, fields, "password")
+	assert.NotContains(t, fields, "Password")
+	assert.NotContains(t, string(data), user.Password)
+}
+
+func TestUser_PasswordFieldIsNotSerialized(t *testing.T) {
+	field, ok := reflect.TypeOf(User{}).FieldByName("Password")
+	require.True(t, ok)
+
+	assert.Equal(t, "-", field.Tag.Get("json"))
+}
+
+func TestUserResponse_OmitsPassword(t *testing.T) {
+	data, err := json.Marshal(UserResponse{ID: 1, Name: "John Doe", Email: "john.doe@example.com"})
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.NotContains(t, fields, "password")
+}
+
+func TestUserResponse_HasNoPasswordField(t *testing.T) {
+	responseType := reflect.TypeOf(UserResponse{})
+
+	for i := 0; i < responseType.NumField(); i++ {
+		field := responseType.Field(i)
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		for _, name := range []string{field.Name, jsonName} {
+			assert.NotContains(t, strings.ToLower(name), "password",
+				"UserResponse must not expose a password field, found %s", field.Name)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+)
+
+// User token purposes
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailChange       = "email_change"
+)
+
+// UserToken is a single-use token sent to a user, such as an email
+// verification link. ID holds the SHA-256 hash of the token rather than the
+// token itself, so stored tokens cannot be replayed. Email is the address
+// the token was sent to, which for an email change is the new address.
+type UserToken struct {
+	ID        string    `bson:"_id"`
+	Purpose   string    `bson:"purpose"`
+	UserID    uint      `bson:"userId"`
+	Email     string    `bson:"email,omitempty"`
+	ExpiresAt time.Time `bson:"expiresAt"`
+	CreatedAt time.Time `bson:"createdAt"`
+}
+
+// Expired reports whether the token has expired at the given time
+func (t *UserToken) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
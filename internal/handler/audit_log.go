@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// auditActions are the actions accepted by the action filter
+var auditActions = map[string]bool{
+	entity.AuditActionCreate:         true,
+	entity.AuditActionUpdate:         true,
+	entity.AuditActionDelete:         true,
+	entity.AuditActionChangePassword: true,
+	entity.AuditActionChangeEmail:    true,
+	entity.AuditActionResetPassword:  true,
+}
+
+// AuditLogReader looks up stored audit entries
+type AuditLogReader interface {
+	Find(filter repository.AuditLogFilter, limit int) ([]*entity.AuditLog, error)
+}
+
+// AuditLogHandler represents the HTTP handler for audit logs
+type AuditLogHandler struct {
+	auditLogs  AuditLogReader
+	pagination Pagination
+}
+
+// AuditLogHandlerOption configures optional audit log handler behavior
+type AuditLogHandlerOption func(*AuditLogHandler)
+
+// WithAuditLogPagination sets the page sizes of the audit log listing
+func WithAuditLogPagination(pagination Pagination) AuditLogHandlerOption {
+	return func(h *AuditLogHandler) {
+		h.pagination = pagination
+	}
+}
+
+// NewAuditLogHandler creates a new audit log handler
+func NewAuditLogHandler(auditLogs AuditLogReader, opts ...AuditLogHandlerOption) *AuditLogHandler {
+	h := &AuditLogHandler{
+		auditLogs:  auditLogs,
+		pagination: defaultPagination,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// ListHandler handles listing a page of audit entries, newest first,
+// optionally filtered by target user ID and action
+func (h *AuditLogHandler) ListHandler(c *fiber.Ctx) error {
+	var filter repository.AuditLogFilter
+	if target := c.Query("target"); target != "" {
+		id, err := strconv.ParseUint(target, 10, 64)
+		if err != nil || id == 0 {
+			return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, "target must be a user ID")
+		}
+		filter.TargetID = uint(id)
+	}
+	if action := c.Query("action"); action != "" {
+		if !auditActions[action] {
+			return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, "action must be create, update, delete, change_password, change_email or reset_password")
+		}
+		filter.Action = action
+	}
+	offset, limit, err := h.pagination.Parse(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, err.Error())
+	}
+	filter.Offset = offset
+
+	entries, err := h.auditLogs.Find(filter, limit)
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, CodeInternal, "Failed to load audit logs")
+	}
+
+	return respond(c, fiber.StatusOK, entries)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/example/go-clean-architecture/internal/usecase/mocks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuditLogReader records the filter it was asked for
+type fakeAuditLogReader struct {
+	filter repository.AuditLogFilter
+	limit  int
+	err    error
+}
+
+func (f *fakeAuditLogReader) Find(filter repository.AuditLogFilter, limit int) ([]*entity.AuditLog, error) {
+	f.filter, f.limit = filter, limit
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []*entity.AuditLog{{ID: "a1", Actor: "admin@example.com", Action: entity.AuditActionDelete, TargetID: 7}}, nil
+}
+
+func TestAuditLogHandler_List(t *testing.T) {
+	reader := &fakeAuditLogReader{}
+	app := fiber.New()
+	app.Get("/audit-logs", NewAuditLogHandler(reader).ListHandler)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/audit-logs?target=7&action=delete", nil))
+	require.NoError(t, err)
+
+	var entries []entity.AuditLog
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&entries))
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "admin@example.com", entries[0].Actor)
+	assert.Equal(t, repository.AuditLogFilter{TargetID: 7, Action: entity.AuditActionDelete}, reader.filter)
+	assert.Equal(t, defaultPagination.DefaultLimit, reader.limit)
+}
+
+func TestAuditLogHandler_ListErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		reader *fakeAuditLogReader
+		status int
+		code   string
+	}{
+		{"invalid target", "?target=abc", &fakeAuditLogReader{}, fiber.StatusBadRequest, CodeInvalidQuery},
+		{"unknown action", "?action=drop", &fakeAuditLogReader{}, fiber.StatusBadRequest, CodeInvalidQuery},
+		{"store failure", "", &fakeAuditLogReader{err: errors.New("mongo unavailable")}, fiber.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/audit-logs", NewAuditLogHandler(tt.reader).ListHandler)
+
+			resp, body := doJSON(t, app, fiber.MethodGet, "/audit-logs"+tt.query, "")
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.code, body["code"])
+		})
+	}
+}
+
+// recordingAuditWriter keeps the audit entries written through it
+type recordingAuditWriter struct {
+	entries []*entity.AuditLog
+}
+
+func (w *recordingAuditWriter) Create(entry *entity.AuditLog) error {
+	w.entries = append(w.entries, entry)
+	return nil
+}
+
+func TestUserHandler_AttributesMutationsToAuthenticatedUser(t *testing.T) {
+	uc := mocks.NewMockUserUsecase(t)
+	uc.On("GetUserByID", mock.Anything, uint(7)).Return(&entity.UserResponse{ID: 7, Name: "Jane"}, nil)
+	uc.On("DeleteUser", mock.Anything, uint(7)).Return(nil)
+	writer := &recordingAuditWriter{}
+	userHandler := NewUserHandler(usecase.NewAuditUserUsecase(uc, writer))
+
+	app := fiber.New()
+	app.Delete("/users/:id", func(c *fiber.Ctx) error {
+		c.Locals(currentUserKey, &entity.UserResponse{ID: 1, Email: "admin@example.com", Role: entity.RoleAdmin})
+		return c.Next()
+	}, userHandler.DeleteHandler)
+
+	resp, _ := doJSON(t, app, fiber.MethodDelete, "/users/7", "")
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.Len(t, writer.entries, 1)
+	assert.Equal(t, "admin@example.com", writer.entries[0].Actor)
+	assert.Equal(t, entity.AuditActionDelete, writer.entries[0].Action)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+)
+
+// currentUserKey is the context locals key holding the authenticated user
+const currentUserKey = "currentUser"
+
+// AuthMiddleware authenticates requests using HTTP Basic credentials and
+// stores the authenticated user in the request context
+func AuthMiddleware(userUsecase usecase.UserUsecase) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		email, password, ok := parseBasicAuth(c.Get(fiber.HeaderAuthorization))
+		if !ok {
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="api"`)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Authentication required"})
+		}
+
+		user, err := userUsecase.Authenticate(c.UserContext(), email, password)
+		if errors.Is(err, usecase.ErrEmailNotVerified) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
+		if err != nil {
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="api"`)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		c.Locals(currentUserKey, user)
+		return c.Next()
+	}
+}
+
+// OptionalAuthMiddleware authenticates requests carrying an Authorization
+// header like AuthMiddleware and lets requests without one through anonymously
+func OptionalAuthMiddleware(userUsecase usecase.UserUsecase) fiber.Handler {
+	authenticate := AuthMiddleware(userUsecase)
+	return func(c *fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) == "" {
+			return c.Next()
+		}
+		return authenticate(c)
+	}
+}
+
+// RequireRole allows the request only when the authenticated user has the given role
+func RequireRole(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user := CurrentUser(c)
+		if user == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Authentication required"})
+		}
+		if user.Role != role {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Insufficient permissions"})
+		}
+		return c.Next()
+	}
+}
+
+// RequireSelfOrRole allows the request only when the authenticated user is the
+// one named by the :id route parameter or has the given role
+func RequireSelfOrRole(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user := CurrentUser(c)
+		if user == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Authentication required"})
+		}
+		if c.Params("id") != strconv.FormatUint(uint64(user.ID), 10) && user.Role != role {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Insufficient permissions"})
+		}
+		return c.Next()
+	}
+}
+
+// CurrentUser returns the authenticated user for the request, if any
+func CurrentUser(c *fiber.Ctx) *entity.UserResponse {
+	user, _ := c.Locals(currentUserKey).(*entity.UserResponse)
+	return user
+}
+
+// parseBasicAuth extracts the credentials from a Basic Authorization header
+func parseBasicAuth(header string) (username, password string, ok bool) {
+	const prefix = "Basic "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", "", false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(header[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+
+	username, password, ok = strings.Cut(string(decoded), ":")
+	return username, password, ok
+}
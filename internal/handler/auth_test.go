@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAuthTestApp(uc usecase.UserUsecase) *fiber.App {
+	app := fiber.New()
+	h := NewUserHandler(uc)
+	app.Delete("/users/:id", AuthMiddleware(uc), RequireRole(entity.RoleAdmin), h.DeleteHandler)
+	return app
+}
+
+func basicAuth(email, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(email+":"+password))
+}
+
+func newRoleUsecase(role string) *stubUserUsecase {
+	return &stubUserUsecase{
+		authenticate: func(email, password string) (*entity.UserResponse, error) {
+			return &entity.UserResponse{ID: 1, Email: email, Role: role}, nil
+		},
+		deleteUser: func(id uint) error { return nil },
+	}
+}
+
+func TestRequireRole_NonAdminForbiddenOnDelete(t *testing.T) {
+	app := newAuthTestApp(newRoleUsecase(entity.RoleUser))
+
+	req := httptest.NewRequest(fiber.MethodDelete, "/users/2", nil)
+	req.Header.Set(fiber.HeaderAuthorization, basicAuth("user@example.com", "S3curePassword"))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
+
+func TestRequireRole_AdminAllowedToDelete(t *testing.T) {
+	app := newAuthTestApp(newRoleUsecase(entity.RoleAdmin))
+
+	req := httptest.NewRequest(fiber.MethodDelete, "/users/2", nil)
+	req.Header.Set(fiber.HeaderAuthorization, basicAuth("admin@example.com", "S3curePassword"))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestAuthMiddleware_RejectsMissingAndInvalidCredentials(t *testing.T) {
+	uc := newRoleUsecase(entity.RoleAdmin)
+	uc.authenticate = func(email, password string) (*entity.UserResponse, error) {
+		return nil, usecase.ErrInvalidCredentials
+	}
+	app := newAuthTestApp(uc)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodDelete, "/users/2", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, `Basic realm="api"`, resp.Header.Get(fiber.HeaderWWWAuthenticate))
+
+	req := httptest.NewRequest(fiber.MethodDelete, "/users/2", nil)
+	req.Header.Set(fiber.HeaderAuthorization, basicAuth("admin@example.com", "wrong"))
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestRequireSelfOrRole(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   string
+		path   string
+		status int
+	}{
+		{"self", entity.RoleUser, "/users/1", fiber.StatusOK},
+		{"other user", entity.RoleUser, "/users/2", fiber.StatusForbidden},
+		{"admin", entity.RoleAdmin, "/users/2", fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/users/:id", AuthMiddleware(newRoleUsecase(tt.role)), RequireSelfOrRole(entity.RoleAdmin), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest(fiber.MethodGet, tt.path, nil)
+			req.Header.Set(fiber.HeaderAuthorization, basicAuth("user@example.com", "S3curePassword"))
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestOptionalAuthMiddleware(t *testing.T) {
+	uc := newRoleUsecase(entity.RoleAdmin)
+	app := fiber.New()
+	app.Get("/", OptionalAuthMiddleware(uc), func(c *fiber.Ctx) error {
+		if user := CurrentUser(c); user != nil {
+			return c.SendString(user.Role)
+		}
+		return c.SendString("anonymous")
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "anonymous", string(body))
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderAuthorization, basicAuth("admin@example.com", "S3curePassword"))
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, entity.RoleAdmin, string(body))
+
+	uc.authenticate = func(email, password string) (*entity.UserResponse, error) {
+		return nil, usecase.ErrInvalidCredentials
+	}
+	req = httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderAuthorization, basicAuth("admin@example.com", "wrong"))
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode, "bad credentials are not ignored")
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	errUnsupportedContentType = errors.New("unsupported content type")
+	errMalformedYAML          = errors.New("malformed YAML body")
+	errMalformedJSON          = errors.New("malformed JSON body")
+)
+
+// unknownFieldError reports a JSON object key that the request type has no
+// field for, typically a client typo
+type unknownFieldError struct {
+	field string
+}
+
+func (e *unknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q", e.field)
+}
+
+// yamlContentTypes are the accepted media types for YAML request bodies
+var yamlContentTypes = map[string]bool{
+	"application/yaml":   true,
+	"application/x-yaml": true,
+	"text/yaml":          true,
+}
+
+// parseBody decodes the request body into out as YAML when the Content-Type is
+// a YAML media type and as JSON when it is JSON or missing
+func parseBody(c *fiber.Ctx, out interface{}) error {
+	contentType := mediaType(c.Get(fiber.HeaderContentType))
+
+	switch {
+	case yamlContentTypes[contentType]:
+		if len(c.Body()) == 0 {
+			return errMalformedYAML
+		}
+		if err := yaml.Unmarshal(c.Body(), out); err != nil {
+			return fmt.Errorf("%w: %v", errMalformedYAML, err)
+		}
+		return nil
+	case contentType == "" || contentType == fiber.MIMEApplicationJSON:
+		return c.App().Config().JSONDecoder(c.Body(), out)
+	default:
+		return errUnsupportedContentType
+	}
+}
+
+// parseStrictBody decodes the request body like parseBody, except that JSON
+// bodies are decoded with encoding/json rejecting unknown fields, so a typo
+// such as "emaill" fails instead of leaving the field empty. YAML bodies are
+// decoded as before.
+func parseStrictBody(c *fiber.Ctx, out interface{}) error {
+	contentType := mediaType(c.Get(fiber.HeaderContentType))
+	if contentType != "" && contentType != fiber.MIMEApplicationJSON {
+		return parseBody(c, out)
+	}
+	return decodeStrictJSON(c.Body(), out)
+}
+
+// decodeStrictJSON decodes a single JSON value into out, failing with an
+// *unknownFieldError for object keys out has no field for
+func decodeStrictJSON(body []byte, out interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		// encoding/json reports unknown fields with a plain error
+		if quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			if field, unquoteErr := strconv.Unquote(quoted); unquoteErr == nil {
+				return &unknownFieldError{field: field}
+			}
+		}
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("%w: unexpected data after the JSON value", errMalformedJSON)
+	}
+	return nil
+}
+
+// mediaType returns the lower-cased media type of a Content-Type header without parameters
+func mediaType(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
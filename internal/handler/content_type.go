@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireBodyContentType rejects POST, PUT and PATCH requests whose
+// Content-Type is not one parseBody decodes with a 415 APIError, instead of
+// letting a parser guess at the body. Requests without a Content-Type are
+// still decoded as JSON.
+func RequireBodyContentType() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch:
+		default:
+			return c.Next()
+		}
+
+		contentType := mediaType(c.Get(fiber.HeaderContentType))
+		if contentType != "" && contentType != fiber.MIMEApplicationJSON && !yamlContentTypes[contentType] {
+			return errorResponse(c, fiber.StatusUnsupportedMediaType, CodeUnsupportedContentType,
+				"Content-Type must be application/json or application/yaml")
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireBodyContentType_RejectsTextPlainOnCreate(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+
+	req := httptest.NewRequest(fiber.MethodPost, "/users", strings.NewReader(testUserBody))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMETextPlain)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var body APIError
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, fiber.StatusUnsupportedMediaType, resp.StatusCode)
+	assert.Equal(t, CodeUnsupportedContentType, body.Code)
+	uc.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+}
+
+func TestRequireBodyContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		status      int
+	}{
+		{"json", fiber.MethodPost, fiber.MIMEApplicationJSON, fiber.StatusOK},
+		{"json with charset", fiber.MethodPut, "application/json; charset=utf-8", fiber.StatusOK},
+		{"yaml", fiber.MethodPatch, "application/yaml", fiber.StatusOK},
+		{"no content type", fiber.MethodPost, "", fiber.StatusOK},
+		{"form", fiber.MethodPost, fiber.MIMEApplicationForm, fiber.StatusUnsupportedMediaType},
+		{"xml", fiber.MethodPut, fiber.MIMEApplicationXML, fiber.StatusUnsupportedMediaType},
+		{"text on patch", fiber.MethodPatch, fiber.MIMETextPlain, fiber.StatusUnsupportedMediaType},
+		{"reads are not checked", fiber.MethodGet, fiber.MIMETextPlain, fiber.StatusOK},
+		{"deletes are not checked", fiber.MethodDelete, fiber.MIMETextPlain, fiber.StatusOK},
+	}
+
+	app := fiber.New()
+	app.Use(RequireBodyContentType())
+	app.All("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader("{}"))
+			if tt.contentType != "" {
+				req.Header.Set(fiber.HeaderContentType, tt.contentType)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/example/go-clean-architecture/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Stable error codes returned in the APIError envelope
+const (
+	CodeEmptyBody              = "empty_body"
+	CodeMalformedJSON          = "malformed_json"
+	CodeMalformedYAML          = "malformed_yaml"
+	CodeInvalidFieldType       = "invalid_field_type"
+	CodeUnknownField           = "unknown_field"
+	CodeUnsupportedContentType = "unsupported_content_type"
+	CodeInvalidBody            = "invalid_body"
+	CodeInvalidID              = "invalid_id"
+	CodeInvalidQuery           = "invalid_query"
+	CodeInvalidToken           = "invalid_token"
+	CodeTokenExpired           = "token_expired"
+	CodeEmailTaken             = "email_taken"
+	CodePreconditionFailed     = "precondition_failed"
+	CodePreconditionRequired   = "precondition_required"
+	CodeWeakPassword           = "weak_password"
+	CodeRateLimited            = "rate_limited"
+	CodeNotFound               = "not_found"
+	CodeServiceUnavailable     = "service_unavailable"
+	CodeInternal               = "internal_error"
+)
+
+// APIError is the standard error envelope returned by the API
+type APIError struct {
+	Error     string `json:"error"`
+	Code      string `json:"code,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// errorResponse writes an APIError with the given status
+func errorResponse(c *fiber.Ctx, status int, code, message string) error {
+	return c.Status(status).JSON(APIError{
+		Error:     message,
+		Code:      code,
+		RequestID: middleware.RequestID(c),
+	})
+}
+
+// readOnlyRetryAfter is the Retry-After, in seconds, sent with writes
+// rejected while the database is read-only
+const readOnlyRetryAfter = "5"
+
+// serviceUnavailable responds 503 to a write the database could not take
+// because it is read-only, as during a failover, so clients retry it shortly
+func serviceUnavailable(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderRetryAfter, readOnlyRetryAfter)
+	return errorResponse(c, fiber.StatusServiceUnavailable, CodeServiceUnavailable,
+		"The database is temporarily read-only; try again shortly")
+}
+
+// bodyParseError responds with a classified APIError for a failed body parse:
+// 415 for unsupported content types and 400 otherwise
+func bodyParseError(c *fiber.Ctx, err error) error {
+	code, message := classifyBodyError(c, err)
+	status := fiber.StatusBadRequest
+	if code == CodeUnsupportedContentType {
+		status = fiber.StatusUnsupportedMediaType
+	}
+	return errorResponse(c, status, code, message)
+}
+
+// classifyBodyError maps a body parsing failure to a stable code and a message
+// that does not expose parser internals
+func classifyBodyError(c *fiber.Ctx, err error) (code, message string) {
+	if errors.Is(err, errUnsupportedContentType) || errors.Is(err, fiber.ErrUnprocessableEntity) {
+		return CodeUnsupportedContentType, "Content-Type must be application/json or application/yaml"
+	}
+	if len(c.Body()) == 0 {
+		return CodeEmptyBody, "Request body is empty"
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var unknownErr *unknownFieldError
+	switch {
+	case errors.Is(err, errMalformedYAML):
+		return CodeMalformedYAML, "Request body is not valid YAML"
+	case errors.As(err, &unknownErr):
+		return CodeUnknownField, fmt.Sprintf("Unknown field %q", unknownErr.field)
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, errMalformedJSON):
+		return CodeMalformedJSON, "Request body is not valid JSON"
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return CodeInvalidFieldType, fmt.Sprintf("Field %q must be of type %s", typeErr.Field, typeErr.Type)
+	case errors.As(err, &typeErr):
+		return CodeInvalidFieldType, "Request body must be a JSON object"
+	default:
+		return CodeInvalidBody, "Request body could not be parsed"
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyParseError(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		code        string
+		message     string
+	}{
+		{"malformed json", fiber.MIMEApplicationJSON, `{"name":`, fiber.StatusBadRequest, CodeMalformedJSON, "Request body is not valid JSON"},
+		{"invalid syntax", fiber.MIMEApplicationJSON, `{name: "John"}`, fiber.StatusBadRequest, CodeMalformedJSON, "Request body is not valid JSON"},
+		{"empty body", fiber.MIMEApplicationJSON, "", fiber.StatusBadRequest, CodeEmptyBody, "Request body is empty"},
+		{"non-json content type", fiber.MIMETextPlain, `{"name":"John"}`, fiber.StatusUnsupportedMediaType, CodeUnsupportedContentType, "Content-Type must be application/json or application/yaml"},
+		{"wrong field type", fiber.MIMEApplicationJSON, `{"name":42}`, fiber.StatusBadRequest, CodeInvalidFieldType, `Field "name" must be of type string`},
+		{"not an object", fiber.MIMEApplicationJSON, `["John"]`, fiber.StatusBadRequest, CodeInvalidFieldType, "Request body must be a JSON object"},
+		{"trailing data", fiber.MIMEApplicationJSON, `{"name":"John"} {}`, fiber.StatusBadRequest, CodeMalformedJSON, "Request body is not valid JSON"},
+		{"extra field", fiber.MIMEApplicationJSON, `{"name":"John","email":"john@example.com","password":"S3curePassword","nickname":"JJ"}`, fiber.StatusBadRequest, CodeUnknownField, `Unknown field "nickname"`},
+		{"typo in field name", fiber.MIMEApplicationJSON, `{"name":"John","emaill":"john@example.com","password":"S3curePassword"}`, fiber.StatusBadRequest, CodeUnknownField, `Unknown field "emaill"`},
+		{"malformed yaml", "application/yaml", "name: [John", fiber.StatusBadRequest, CodeMalformedYAML, "Request body is not valid YAML"},
+		{"empty yaml body", "application/yaml", "", fiber.StatusBadRequest, CodeEmptyBody, "Request body is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, uc := newUserRoutesTestApp(t)
+
+			req := httptest.NewRequest(fiber.MethodPost, "/users", strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, tt.contentType)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			body := decodeAPIError(t, resp.Body)
+			assert.Equal(t, tt.code, body.Code)
+			assert.Equal(t, tt.message, body.Error)
+			uc.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// decodeAPIError decodes an APIError response body
+func decodeAPIError(t *testing.T, body io.Reader) APIError {
+	t.Helper()
+
+	var apiErr APIError
+	require.NoError(t, json.NewDecoder(body).Decode(&apiErr))
+	return apiErr
+}
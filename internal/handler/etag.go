@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+)
+
+// userETag derives a strong ETag from the user's identity and last update time
+func userETag(user *entity.UserResponse) string {
+	sum := sha256.Sum256([]byte(strconv.FormatUint(uint64(user.ID), 10) + ":" +
+		strconv.FormatInt(user.UpdatedAt.UnixNano(), 10)))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// ifMatch reports whether an If-Match header matches etag. If-Match uses
+// strong comparison, so weak tags never match.
+func ifMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// etagMatches reports whether an If-None-Match style header matches etag,
+// using weak comparison as required for conditional GETs
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
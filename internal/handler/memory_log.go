@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/pkg/promtext"
+	"github.com/gofiber/fiber/v2"
+)
+
+// MemoryLogReader looks up stored memory logs
+type MemoryLogReader interface {
+	FindByID(ctx context.Context, id string) (*entity.MemoryLog, error)
+	FindByTimeRange(ctx context.Context, start, end time.Time) ([]*entity.MemoryLog, error)
+}
+
+// MemoryLogStore looks up and deletes stored memory logs
+type MemoryLogStore interface {
+	MemoryLogReader
+	DeleteOlderThan(ctx context.Context, olderThan time.Time) (int64, error)
+}
+
+// endOfTime is a cutoff after every memory log, so deleting the logs older
+// than it deletes them all
+var endOfTime = time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)
+
+// defaultPrometheusRange is how far back from its end the Prometheus export
+// reaches when the request has no from
+const defaultPrometheusRange = time.Hour
+
+// maxPrometheusRange caps the span of a Prometheus export, since every log in
+// it is loaded and rendered at once
+const maxPrometheusRange = 24 * time.Hour
+
+// MemoryLogHandler represents the HTTP handler for memory logs
+type MemoryLogHandler struct {
+	memoryLogs MemoryLogStore
+}
+
+// NewMemoryLogHandler creates a new memory log handler
+func NewMemoryLogHandler(memoryLogs MemoryLogStore) *MemoryLogHandler {
+	return &MemoryLogHandler{memoryLogs: memoryLogs}
+}
+
+// GetByIDHandler handles retrieving a memory log by its hex ObjectID
+func (h *MemoryLogHandler) GetByIDHandler(c *fiber.Ctx) error {
+	memoryLog, err := h.memoryLogs.FindByID(c.UserContext(), c.Params("id"))
+	switch {
+	case errors.Is(err, repository.ErrInvalidMemoryLogID):
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidID, "Memory log ID must be a 24-character hex ObjectID")
+	case errors.Is(err, repository.ErrMemoryLogNotFound):
+		return errorResponse(c, fiber.StatusNotFound, CodeNotFound, "Memory log not found")
+	case err != nil:
+		return errorResponse(c, fiber.StatusInternalServerError, CodeInternal, "Failed to load memory log")
+	}
+
+	return respond(c, fiber.StatusOK, memoryLog)
+}
+
+// PrometheusHandler handles exporting the memory logs between the from and to
+// query timestamps as Prometheus gauges, one timestamped sample per log, so
+// dashboards can be backfilled from stored samples. to defaults to now and
+// from to an hour before to; ranges longer than a day are rejected.
+func (h *MemoryLogHandler) PrometheusHandler(c *fiber.Ctx) error {
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, err.Error())
+	}
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, err.Error())
+	}
+
+	end := time.Now()
+	if to != nil {
+		end = *to
+	}
+	start := end.Add(-defaultPrometheusRange)
+	if from != nil {
+		start = *from
+	}
+	if start.After(end) {
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, "from must not be after to")
+	}
+	if end.Sub(start) > maxPrometheusRange {
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery,
+			"from and to must be at most "+maxPrometheusRange.String()+" apart")
+	}
+
+	memoryLogs, err := h.memoryLogs.FindByTimeRange(c.UserContext(), start, end)
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, CodeInternal, "Failed to load memory logs")
+	}
+
+	c.Set(fiber.HeaderContentType, promtext.ContentType)
+	return promtext.Write(c, memoryLogGauges(memoryLogs))
+}
+
+// DeleteHandler handles deleting the memory logs recorded before the before
+// query timestamp and responds with how many were deleted. Deleting every log
+// takes an explicit all=true instead, so a missing parameter cannot wipe them
+// by accident.
+func (h *MemoryLogHandler) DeleteHandler(c *fiber.Ctx) error {
+	before, err := parseTimeQuery(c, "before")
+	if err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, err.Error())
+	}
+	all := c.Query("all")
+
+	var cutoff time.Time
+	switch {
+	case before != nil && all != "":
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, "before and all must not be combined")
+	case before != nil:
+		cutoff = *before
+	case all == "true":
+		cutoff = endOfTime
+	default:
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, "before is required; pass all=true to delete every memory log")
+	}
+
+	deleted, err := h.memoryLogs.DeleteOlderThan(c.UserContext(), cutoff)
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, CodeInternal, "Failed to delete memory logs")
+	}
+
+	return respond(c, fiber.StatusOK, fiber.Map{"deleted": deleted})
+}
+
+// memoryLogMetrics are the measurements exported from each memory log
+var memoryLogMetrics = []struct {
+	name, help string
+	value      func(*entity.MemoryLog) float64
+}{
+	{"memory_log_alloc_bytes", "Bytes of allocated heap objects.",
+		func(l *entity.MemoryLog) float64 { return float64(l.Alloc) }},
+	{"memory_log_total_alloc_bytes", "Cumulative bytes allocated for heap objects.",
+		func(l *entity.MemoryLog) float64 { return float64(l.TotalAlloc) }},
+	{"memory_log_sys_bytes", "Bytes of memory obtained from the OS.",
+		func(l *entity.MemoryLog) float64 { return float64(l.Sys) }},
+	{"memory_log_gc_cycles", "Number of completed GC cycles.",
+		func(l *entity.MemoryLog) float64 { return float64(l.NumGC) }},
+	{"memory_log_gc_cpu_fraction", "Fraction of CPU time used by the GC since the program started.",
+		func(l *entity.MemoryLog) float64 { return l.GCCPUFraction }},
+	{"memory_log_goroutines", "Number of goroutines.",
+		func(l *entity.MemoryLog) float64 { return float64(l.NumGoroutine) }},
+}
+
+// memoryLogGauges returns a gauge for each measurement in the memory logs
+func memoryLogGauges(memoryLogs []*entity.MemoryLog) []promtext.Gauge {
+	gauges := make([]promtext.Gauge, len(memoryLogMetrics))
+	for i, metric := range memoryLogMetrics {
+		samples := make([]promtext.Sample, len(memoryLogs))
+		for j, memoryLog := range memoryLogs {
+			samples[j] = promtext.Sample{Value: metric.value(memoryLog), Timestamp: memoryLog.Timestamp}
+		}
+		gauges[i] = promtext.Gauge{Name: metric.name, Help: metric.help, Samples: samples}
+	}
+	return gauges
+}
@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/pkg/promtext"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMemoryLogID = "65f1a2b3c4d5e6f708192a3b"
+
+// fakeMemoryLogStore serves a single memory log by ID, mimicking the
+// repository's ID validation, and finds and counts deletions of logs by time
+type fakeMemoryLogStore struct {
+	err  error
+	logs []*entity.MemoryLog
+}
+
+func (f fakeMemoryLogStore) FindByID(ctx context.Context, id string) (*entity.MemoryLog, error) {
+	if len(id) != 24 {
+		return nil, repository.ErrInvalidMemoryLogID
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	if id != testMemoryLogID {
+		return nil, repository.ErrMemoryLogNotFound
+	}
+	return &entity.MemoryLog{ID: id, Alloc: 42}, nil
+}
+
+func (f fakeMemoryLogStore) FindByTimeRange(ctx context.Context, start, end time.Time) ([]*entity.MemoryLog, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var found []*entity.MemoryLog
+	for _, memoryLog := range f.logs {
+		if !memoryLog.Timestamp.Before(start) && !memoryLog.Timestamp.After(end) {
+			found = append(found, memoryLog)
+		}
+	}
+	return found, nil
+}
+
+func (f fakeMemoryLogStore) DeleteOlderThan(ctx context.Context, olderThan time.Time) (int64, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	var deleted int64
+	for _, memoryLog := range f.logs {
+		if memoryLog.Timestamp.Before(olderThan) {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func newMemoryLogTestApp(store MemoryLogStore) *fiber.App {
+	app := fiber.New()
+	memoryLogHandler := NewMemoryLogHandler(store)
+	app.Delete("/memory-logs", memoryLogHandler.DeleteHandler)
+	app.Get("/memory-logs/prometheus", memoryLogHandler.PrometheusHandler)
+	app.Get("/memory-logs/:id", memoryLogHandler.GetByIDHandler)
+	return app
+}
+
+func TestMemoryLogHandler_GetByID(t *testing.T) {
+	app := newMemoryLogTestApp(fakeMemoryLogStore{})
+
+	resp, body := doJSON(t, app, fiber.MethodGet, "/memory-logs/"+testMemoryLogID, "")
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, testMemoryLogID, body["id"])
+	assert.Equal(t, float64(42), body["alloc"])
+}
+
+func TestMemoryLogHandler_GetByIDErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		reader fakeMemoryLogStore
+		id     string
+		status int
+		code   string
+	}{
+		{"not found", fakeMemoryLogStore{}, "65f1a2b3c4d5e6f708192a3c", fiber.StatusNotFound, CodeNotFound},
+		{"malformed id", fakeMemoryLogStore{}, "not-an-id", fiber.StatusBadRequest, CodeInvalidID},
+		{"store failure", fakeMemoryLogStore{err: errors.New("mongo unavailable")}, testMemoryLogID, fiber.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newMemoryLogTestApp(tt.reader)
+
+			resp, body := doJSON(t, app, fiber.MethodGet, "/memory-logs/"+tt.id, "")
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.code, body["code"])
+			assert.NotContains(t, body["error"], "mongo")
+		})
+	}
+}
+
+func TestMemoryLogHandler_Prometheus(t *testing.T) {
+	base := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	app := newMemoryLogTestApp(fakeMemoryLogStore{logs: []*entity.MemoryLog{
+		{Timestamp: base.Add(-time.Hour), Alloc: 1},
+		{Timestamp: base, Alloc: 1048576, TotalAlloc: 4194304, Sys: 8388608, NumGC: 3, GCCPUFraction: 0.25, NumGoroutine: 12},
+		{Timestamp: base.Add(time.Minute), Alloc: 2097152, TotalAlloc: 6291456, Sys: 8388608, NumGC: 4, GCCPUFraction: 0.5, NumGoroutine: 10},
+	}})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet,
+		"/memory-logs/prometheus?from=2024-03-15T12:00:00Z&to=2024-03-15T12:30:00Z", nil))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, promtext.ContentType, resp.Header.Get(fiber.HeaderContentType))
+	assert.Equal(t, `# HELP memory_log_alloc_bytes Bytes of allocated heap objects.
+# TYPE memory_log_alloc_bytes gauge
+memory_log_alloc_bytes 1.048576e+06 1710504000000
+memory_log_alloc_bytes 2.097152e+06 1710504060000
+# HELP memory_log_total_alloc_bytes Cumulative bytes allocated for heap objects.
+# TYPE memory_log_total_alloc_bytes gauge
+memory_log_total_alloc_bytes 4.194304e+06 1710504000000
+memory_log_total_alloc_bytes 6.291456e+06 1710504060000
+# HELP memory_log_sys_bytes Bytes of memory obtained from the OS.
+# TYPE memory_log_sys_bytes gauge
+memory_log_sys_bytes 8.388608e+06 1710504000000
+memory_log_sys_bytes 8.388608e+06 1710504060000
+# HELP memory_log_gc_cycles Number of completed GC cycles.
+# TYPE memory_log_gc_cycles gauge
+memory_log_gc_cycles 3 1710504000000
+memory_log_gc_cycles 4 1710504060000
+# HELP memory_log_gc_cpu_fraction Fraction of CPU time used by the GC since the program started.
+# TYPE memory_log_gc_cpu_fraction gauge
+memory_log_gc_cpu_fraction 0.25 1710504000000
+memory_log_gc_cpu_fraction 0.5 1710504060000
+# HELP memory_log_goroutines Number of goroutines.
+# TYPE memory_log_goroutines gauge
+memory_log_goroutines 12 1710504000000
+memory_log_goroutines 10 1710504060000
+`, string(body))
+}
+
+func TestMemoryLogHandler_PrometheusEmptyRange(t *testing.T) {
+	app := newMemoryLogTestApp(fakeMemoryLogStore{})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/memory-logs/prometheus", nil))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Empty(t, body)
+}
+
+func TestMemoryLogHandler_PrometheusErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		reader fakeMemoryLogStore
+		query  string
+		status int
+		code   string
+	}{
+		{"malformed from", fakeMemoryLogStore{}, "?from=yesterday", fiber.StatusBadRequest, CodeInvalidQuery},
+		{"malformed to", fakeMemoryLogStore{}, "?to=1710504000", fiber.StatusBadRequest, CodeInvalidQuery},
+		{"inverted range", fakeMemoryLogStore{}, "?from=2024-03-15T13:00:00Z&to=2024-03-15T12:00:00Z", fiber.StatusBadRequest, CodeInvalidQuery},
+		{"range over a day", fakeMemoryLogStore{}, "?from=2024-03-14T11:59:59Z&to=2024-03-15T12:00:00Z", fiber.StatusBadRequest, CodeInvalidQuery},
+		{"from without to reaching too far back", fakeMemoryLogStore{}, "?from=2000-01-01T00:00:00Z", fiber.StatusBadRequest, CodeInvalidQuery},
+		{"store failure", fakeMemoryLogStore{err: errors.New("mongo unavailable")}, "", fiber.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newMemoryLogTestApp(tt.reader)
+
+			resp, body := doJSON(t, app, fiber.MethodGet, "/memory-logs/prometheus"+tt.query, "")
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.code, body["code"])
+		})
+	}
+}
+
+func TestMemoryLogHandler_Delete(t *testing.T) {
+	base := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	store := fakeMemoryLogStore{logs: []*entity.MemoryLog{
+		{Timestamp: base.AddDate(-1, 0, 0)},
+		{Timestamp: base.Add(-time.Hour)},
+		{Timestamp: base},
+		{Timestamp: base.Add(time.Hour)},
+	}}
+
+	tests := []struct {
+		name    string
+		query   string
+		deleted float64
+	}{
+		{"before", "?before=2024-03-15T12:00:00Z", 2},
+		{"before with offset", "?before=2024-03-15T14:30:00%2B01:00", 4},
+		{"before everything", "?before=2020-01-01T00:00:00Z", 0},
+		{"all", "?all=true", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newMemoryLogTestApp(store)
+
+			resp, body := doJSON(t, app, fiber.MethodDelete, "/memory-logs"+tt.query, "")
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, tt.deleted, body["deleted"])
+		})
+	}
+}
+
+func TestMemoryLogHandler_DeleteErrors(t *testing.T) {
+	logs := []*entity.MemoryLog{{Timestamp: time.Now().Add(-time.Hour)}}
+	tests := []struct {
+		name   string
+		store  fakeMemoryLogStore
+		query  string
+		status int
+		code   string
+	}{
+		{"no filter", fakeMemoryLogStore{logs: logs}, "", fiber.StatusBadRequest, CodeInvalidQuery},
+		{"all not true", fakeMemoryLogStore{logs: logs}, "?all=1", fiber.StatusBadRequest, CodeInvalidQuery},
+		{"all false", fakeMemoryLogStore{logs: logs}, "?all=false", fiber.StatusBadRequest, CodeInvalidQuery},
+		{"before and all", fakeMemoryLogStore{logs: logs}, "?before=2024-03-15T12:00:00Z&all=true", fiber.StatusBadRequest, CodeInvalidQuery},
+		{"malformed before", fakeMemoryLogStore{logs: logs}, "?before=yesterday", fiber.StatusBadRequest, CodeInvalidQuery},
+		{"store failure", fakeMemoryLogStore{err: errors.New("mongo unavailable")}, "?all=true", fiber.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newMemoryLogTestApp(tt.store)
+
+			resp, body := doJSON(t, app, fiber.MethodDelete, "/memory-logs"+tt.query, "")
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.code, body["code"])
+			assert.NotContains(t, body, "deleted")
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HeaderLimitClamped is set to the maximum page size on responses whose
+// requested limit was above it
+const HeaderLimitClamped = "X-Limit-Clamped"
+
+// Pagination holds the page sizes of a list endpoint
+type Pagination struct {
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// defaultPagination is the page sizing of list endpoints whose handler is
+// not given one
+var defaultPagination = Pagination{
+	DefaultLimit: 20,
+	MaxLimit:     100,
+}
+
+// Validate checks that both sizes are positive and the default fits under the maximum
+func (p Pagination) Validate() error {
+	if p.DefaultLimit < 1 || p.MaxLimit < 1 {
+		return errors.New("page sizes must be positive")
+	}
+	if p.DefaultLimit > p.MaxLimit {
+		return fmt.Errorf("default page size %d is greater than the maximum page size %d", p.DefaultLimit, p.MaxLimit)
+	}
+	return nil
+}
+
+// Parse reads the 1-based page and limit query parameters. A missing limit
+// takes the default, and a limit above the maximum is clamped to it rather
+// than rejected, with HeaderLimitClamped telling the client.
+func (p Pagination) Parse(c *fiber.Ctx) (offset, limit int, err error) {
+	page, err := parsePositiveQuery(c, "page", 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	limit, err = parsePositiveQuery(c, "limit", p.DefaultLimit)
+	if err != nil {
+		return 0, 0, err
+	}
+	if limit > p.MaxLimit {
+		limit = p.MaxLimit
+		c.Set(HeaderLimitClamped, strconv.Itoa(p.MaxLimit))
+	}
+	if page-1 > math.MaxInt/limit {
+		return 0, 0, errors.New("page is too large")
+	}
+	return (page - 1) * limit, limit, nil
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagination_Parse(t *testing.T) {
+	pagination := Pagination{DefaultLimit: 10, MaxLimit: 50}
+
+	tests := []struct {
+		name    string
+		query   string
+		offset  int
+		limit   int
+		clamped string
+	}{
+		{"defaults", "", 0, 10, ""},
+		{"explicit limit", "?limit=25", 0, 25, ""},
+		{"page offsets by limit", "?page=3&limit=25", 50, 25, ""},
+		{"page with default limit", "?page=2", 10, 10, ""},
+		{"limit at maximum", "?limit=50", 0, 50, ""},
+		{"limit over maximum is clamped", "?limit=500", 0, 50, "50"},
+		{"clamped limit drives the offset", "?page=2&limit=500", 50, 50, "50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var offset, limit int
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				var err error
+				offset, limit, err = pagination.Parse(c)
+				require.NoError(t, err)
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/"+tt.query, nil))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.offset, offset)
+			assert.Equal(t, tt.limit, limit)
+			assert.Equal(t, tt.clamped, resp.Header.Get(HeaderLimitClamped))
+		})
+	}
+}
+
+func TestPagination_ParseRejectsInvalidQueries(t *testing.T) {
+	for _, query := range []string{"?page=0", "?page=-1", "?limit=0", "?limit=abc", "?page=9223372036854775807&limit=2"} {
+		t.Run(query, func(t *testing.T) {
+			var err error
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				_, _, err = defaultPagination.Parse(c)
+				return nil
+			})
+
+			_, testErr := app.Test(httptest.NewRequest(fiber.MethodGet, "/"+query, nil))
+			require.NoError(t, testErr)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPagination_Validate(t *testing.T) {
+	assert.NoError(t, defaultPagination.Validate())
+	assert.NoError(t, Pagination{DefaultLimit: 100, MaxLimit: 100}.Validate())
+	assert.ErrorContains(t, Pagination{DefaultLimit: 200, MaxLimit: 100}.Validate(), "greater than the maximum")
+	assert.Error(t, Pagination{DefaultLimit: 0, MaxLimit: 100}.Validate())
+	assert.Error(t, Pagination{DefaultLimit: 10, MaxLimit: 0}.Validate())
+}
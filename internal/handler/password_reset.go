@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/example/go-clean-architecture/pkg/middleware"
+	"github.com/example/go-clean-architecture/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// passwordResetRequestedMessage is returned for every reset request, whether
+// or not the email is registered
+const passwordResetRequestedMessage = "If the email is registered, a password reset link has been sent"
+
+// PasswordResetter sends password reset links and applies resets
+type PasswordResetter interface {
+	RequestReset(ctx context.Context, email string) error
+	ConfirmReset(ctx context.Context, token, newPassword string) error
+}
+
+// PasswordResetHandler represents the HTTP handler for password resets
+type PasswordResetHandler struct {
+	resetter PasswordResetter
+}
+
+// NewPasswordResetHandler creates a new password reset handler
+func NewPasswordResetHandler(resetter PasswordResetter) *PasswordResetHandler {
+	return &PasswordResetHandler{resetter: resetter}
+}
+
+// PasswordResetRateLimit allows each client IP at most max reset requests per
+// window, answering 429 beyond that
+func PasswordResetRateLimit(max int, window time.Duration) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:          max,
+		Expiration:   window,
+		KeyGenerator: middleware.ClientIP,
+		LimitReached: func(c *fiber.Ctx) error {
+			return errorResponse(c, fiber.StatusTooManyRequests, CodeRateLimited, "Too many password reset requests, try again later")
+		},
+	})
+}
+
+// RequestHandler handles requests for a password reset link. It answers 200
+// for unknown emails and delivery failures alike, so responses never reveal
+// which emails are registered.
+func (h *PasswordResetHandler) RequestHandler(c *fiber.Ctx) error {
+	var req entity.PasswordResetRequest
+	if err := parseBody(c, &req); err != nil {
+		return bodyParseError(c, err)
+	}
+	if strings.TrimSpace(req.Email) == "" {
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidBody, "email is required")
+	}
+
+	if err := h.resetter.RequestReset(c.UserContext(), req.Email); err != nil {
+		log.Printf("ERROR: Failed to send password reset link: %v", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": passwordResetRequestedMessage})
+}
+
+// ConfirmHandler handles setting a new password with a reset token
+func (h *PasswordResetHandler) ConfirmHandler(c *fiber.Ctx) error {
+	var req entity.PasswordResetConfirmRequest
+	if err := parseBody(c, &req); err != nil {
+		return bodyParseError(c, err)
+	}
+	if req.Token == "" {
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidBody, "token is required")
+	}
+
+	err := h.resetter.ConfirmReset(c.UserContext(), req.Token, req.NewPassword)
+	var strengthErr *utils.PasswordStrengthError
+	switch {
+	case errors.As(err, &strengthErr):
+		return errorResponse(c, fiber.StatusBadRequest, CodeWeakPassword, err.Error())
+	case errors.Is(err, usecase.ErrInvalidToken):
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidToken, err.Error())
+	case errors.Is(err, usecase.ErrTokenExpired):
+		return errorResponse(c, fiber.StatusBadRequest, CodeTokenExpired, err.Error())
+	case errors.Is(err, repository.ErrServiceUnavailable):
+		return serviceUnavailable(c)
+	case err != nil:
+		return errorResponse(c, fiber.StatusInternalServerError, CodeInternal, "Failed to reset password")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Password reset successfully"})
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/example/go-clean-architecture/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePasswordResetter records reset requests and fails confirmations with confirmErr
+type fakePasswordResetter struct {
+	requested  []string
+	requestErr error
+	confirmErr error
+}
+
+func (f *fakePasswordResetter) RequestReset(_ context.Context, email string) error {
+	f.requested = append(f.requested, email)
+	return f.requestErr
+}
+
+func (f *fakePasswordResetter) ConfirmReset(_ context.Context, token, newPassword string) error {
+	return f.confirmErr
+}
+
+// newPasswordResetApp returns an app serving the reset routes with the given rate limit
+func newPasswordResetApp(resetter PasswordResetter, limit int) *fiber.App {
+	h := NewPasswordResetHandler(resetter)
+	app := fiber.New()
+	app.Post("/users/password-reset/request", PasswordResetRateLimit(limit, time.Minute), h.RequestHandler)
+	app.Post("/users/password-reset/confirm", h.ConfirmHandler)
+	return app
+}
+
+func postJSON(t *testing.T, app *fiber.App, path, body string) (int, APIError) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var apiErr APIError
+	_ = json.NewDecoder(resp.Body).Decode(&apiErr)
+	return resp.StatusCode, apiErr
+}
+
+func TestPasswordResetHandler_RequestAlwaysSucceeds(t *testing.T) {
+	tests := []struct {
+		name     string
+		resetter *fakePasswordResetter
+	}{
+		{"registered email", &fakePasswordResetter{}},
+		{"delivery failure", &fakePasswordResetter{requestErr: errors.New("smtp unavailable")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newPasswordResetApp(tt.resetter, 10)
+
+			status, _ := postJSON(t, app, "/users/password-reset/request", `{"email":"john@example.com"}`)
+
+			assert.Equal(t, fiber.StatusOK, status)
+			assert.Equal(t, []string{"john@example.com"}, tt.resetter.requested)
+		})
+	}
+}
+
+func TestPasswordResetHandler_RequestRequiresEmail(t *testing.T) {
+	app := newPasswordResetApp(&fakePasswordResetter{}, 10)
+
+	status, body := postJSON(t, app, "/users/password-reset/request", `{"email":"  "}`)
+
+	assert.Equal(t, fiber.StatusBadRequest, status)
+	assert.Equal(t, CodeInvalidBody, body.Code)
+}
+
+func TestPasswordResetHandler_RequestIsRateLimited(t *testing.T) {
+	resetter := &fakePasswordResetter{}
+	app := newPasswordResetApp(resetter, 2)
+
+	for i := 0; i < 2; i++ {
+		status, _ := postJSON(t, app, "/users/password-reset/request", `{"email":"john@example.com"}`)
+		require.Equal(t, fiber.StatusOK, status)
+	}
+	status, body := postJSON(t, app, "/users/password-reset/request", `{"email":"john@example.com"}`)
+
+	assert.Equal(t, fiber.StatusTooManyRequests, status)
+	assert.Equal(t, CodeRateLimited, body.Code)
+	assert.Len(t, resetter.requested, 2)
+}
+
+func TestPasswordResetHandler_Confirm(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		err    error
+		status int
+		code   string
+	}{
+		{"success", `{"token":"t","newPassword":"N3wPassword!"}`, nil, fiber.StatusOK, ""},
+		{"missing token", `{"newPassword":"N3wPassword!"}`, nil, fiber.StatusBadRequest, CodeInvalidBody},
+		{"weak password", `{"token":"t","newPassword":"weak"}`, &utils.PasswordStrengthError{}, fiber.StatusBadRequest, CodeWeakPassword},
+		{"unknown or reused token", `{"token":"t","newPassword":"N3wPassword!"}`, usecase.ErrInvalidToken, fiber.StatusBadRequest, CodeInvalidToken},
+		{"expired token", `{"token":"t","newPassword":"N3wPassword!"}`, usecase.ErrTokenExpired, fiber.StatusBadRequest, CodeTokenExpired},
+		{"store failure", `{"token":"t","newPassword":"N3wPassword!"}`, errors.New("mongo unavailable"), fiber.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newPasswordResetApp(&fakePasswordResetter{confirmErr: tt.err}, 10)
+
+			status, body := postJSON(t, app, "/users/password-reset/confirm", tt.body)
+
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.code, body.Code)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/example/go-clean-architecture/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RecoverMiddleware recovers from panics in later handlers, logs the stack
+// trace and responds with a 500 APIError
+func RecoverMiddleware(logger *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("panic recovered",
+					"requestId", middleware.RequestID(c),
+					"method", c.Method(),
+					"path", c.Path(),
+					"panic", fmt.Sprint(r),
+					"stack", string(debug.Stack()))
+				err = errorResponse(c, fiber.StatusInternalServerError, CodeInternal, "Internal server error")
+			}
+		}()
+		return c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/example/go-clean-architecture/pkg/logging"
+	"github.com/example/go-clean-architecture/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoverMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	app := fiber.New()
+	app.Use(middleware.NewRequestID())
+	app.Use(RecoverMiddleware(logging.New(&logs)))
+	app.Get("/panic", func(c *fiber.Ctx) error {
+		panic("something went wrong")
+	})
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/panic", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "req-123")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, APIError{Error: "Internal server error", Code: CodeInternal, RequestID: "req-123"}, decodeAPIError(t, resp.Body))
+	assert.Contains(t, logs.String(), `"panic":"something went wrong"`)
+	assert.Contains(t, logs.String(), `"requestId":"req-123"`)
+	assert.Contains(t, logs.String(), "recover.go")
+
+	// The app keeps serving requests after a panic
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/ok", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
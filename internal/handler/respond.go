@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MIMEApplicationMsgpack is the media type of MessagePack-encoded responses
+const MIMEApplicationMsgpack = "application/msgpack"
+
+// HeaderPretty asks for indented JSON responses, like the pretty query
+// parameter, when set to true
+const HeaderPretty = "X-Pretty"
+
+// prettyIndent is the indent of each nesting level in pretty-printed JSON
+const prettyIndent = "  "
+
+// respond writes payload with the given status, encoded as MessagePack when
+// the client prefers it in its Accept header and as JSON otherwise. MessagePack
+// uses the JSON field names, so both encodings decode into the same structs.
+// JSON is compact unless the request asks for it indented with ?pretty=true
+// or HeaderPretty, for reading while debugging.
+func respond(c *fiber.Ctx, status int, payload interface{}) error {
+	c.Vary(fiber.HeaderAccept, HeaderPretty)
+
+	if c.Accepts(fiber.MIMEApplicationJSON, MIMEApplicationMsgpack) != MIMEApplicationMsgpack {
+		if !prettyRequested(c) {
+			return c.Status(status).JSON(payload)
+		}
+		data, err := json.MarshalIndent(payload, "", prettyIndent)
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return c.Status(status).Send(data)
+	}
+
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetCustomStructTag("json")
+	if err := encoder.Encode(payload); err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, MIMEApplicationMsgpack)
+	return c.Status(status).Send(buf.Bytes())
+}
+
+// prettyRequested reports whether the pretty query parameter or HeaderPretty
+// asks for indented JSON; malformed values count as not asking
+func prettyRequested(c *fiber.Ctx) bool {
+	for _, value := range []string{c.Query("pretty"), c.Get(HeaderPretty)} {
+		if pretty, err := strconv.ParseBool(value); err == nil && pretty {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestRespond_NegotiatesEncoding(t *testing.T) {
+	user := entity.UserResponse{
+		ID:        1,
+		Name:      "John Doe",
+		Email:     "john.doe@example.com",
+		Role:      entity.RoleUser,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	app := fiber.New()
+	app.Get("/user", func(c *fiber.Ctx) error { return respond(c, fiber.StatusOK, user) })
+	app.Get("/users", func(c *fiber.Ctx) error { return respond(c, fiber.StatusOK, []entity.UserResponse{user, user}) })
+
+	decoders := map[string]func([]byte, interface{}) error{
+		fiber.MIMEApplicationJSON: json.Unmarshal,
+		MIMEApplicationMsgpack: func(data []byte, v interface{}) error {
+			decoder := msgpack.NewDecoder(bytes.NewReader(data))
+			decoder.SetCustomStructTag("json")
+			return decoder.Decode(v)
+		},
+	}
+
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", fiber.MIMEApplicationJSON},
+		{fiber.MIMEApplicationJSON, fiber.MIMEApplicationJSON},
+		{MIMEApplicationMsgpack, MIMEApplicationMsgpack},
+		{"application/json;q=0.5, application/msgpack", MIMEApplicationMsgpack},
+		{"text/html", fiber.MIMEApplicationJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run("Accept "+tt.accept, func(t *testing.T) {
+			get := func(path string) []byte {
+				req := httptest.NewRequest(fiber.MethodGet, path, nil)
+				if tt.accept != "" {
+					req.Header.Set(fiber.HeaderAccept, tt.accept)
+				}
+				resp, err := app.Test(req)
+				require.NoError(t, err)
+				assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+				assert.Contains(t, resp.Header.Get(fiber.HeaderContentType), tt.contentType)
+				assert.Contains(t, resp.Header.Get(fiber.HeaderVary), fiber.HeaderAccept)
+
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				return body
+			}
+			decode := decoders[tt.contentType]
+
+			var single entity.UserResponse
+			require.NoError(t, decode(get("/user"), &single))
+			assert.Equal(t, user.ID, single.ID)
+			assert.Equal(t, user.Email, single.Email)
+			assert.True(t, user.CreatedAt.Equal(single.CreatedAt))
+
+			var list []entity.UserResponse
+			require.NoError(t, decode(get("/users"), &list))
+			require.Len(t, list, 2)
+			assert.Equal(t, user.Name, list[1].Name)
+		})
+	}
+}
+
+func TestRespond_PrettyJSON(t *testing.T) {
+	app := fiber.New()
+	app.Get("/user", func(c *fiber.Ctx) error {
+		return respond(c, fiber.StatusOK, fiber.Map{"id": 1, "roles": []string{entity.RoleUser}})
+	})
+
+	const compact = `{"id":1,"roles":["user"]}`
+	const indented = "{\n  \"id\": 1,\n  \"roles\": [\n    \"user\"\n  ]\n}"
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   string
+	}{
+		{"default", "/user", "", compact},
+		{"query", "/user?pretty=true", "", indented},
+		{"header", "/user", "true", indented},
+		{"query false", "/user?pretty=false", "", compact},
+		{"header false", "/user", "false", compact},
+		{"malformed", "/user?pretty=yes", "", compact},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderPretty, tt.header)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+			assert.Contains(t, resp.Header.Get(fiber.HeaderVary), HeaderPretty)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(body))
+		})
+	}
+}
+
+func TestRespond_PrettyIgnoredForMsgpack(t *testing.T) {
+	app := fiber.New()
+	app.Get("/user", func(c *fiber.Ctx) error { return respond(c, fiber.StatusOK, fiber.Map{"id": 1}) })
+
+	req := httptest.NewRequest(fiber.MethodGet, "/user?pretty=true", nil)
+	req.Header.Set(fiber.HeaderAccept, MIMEApplicationMsgpack)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, MIMEApplicationMsgpack, resp.Header.Get(fiber.HeaderContentType))
+
+	var body map[string]int
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, msgpack.Unmarshal(data, &body))
+	assert.Equal(t, map[string]int{"id": 1}, body)
+}
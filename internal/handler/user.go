@@ -1,45 +1,105 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
 	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/example/go-clean-architecture/pkg/utils"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 // UserHandler represents the HTTP handler for user
 type UserHandler struct {
-	userUsecase usecase.UserUsecase
+	userUsecase    usecase.UserUsecase
+	requireIfMatch bool
+	pagination     Pagination
+}
+
+// UserHandlerOption configures optional user handler behavior
+type UserHandlerOption func(*UserHandler)
+
+// WithIfMatchRequired controls whether updates without an If-Match header are
+// rejected with 428 Precondition Required rather than applied unconditionally
+func WithIfMatchRequired(required bool) UserHandlerOption {
+	return func(h *UserHandler) {
+		h.requireIfMatch = required
+	}
+}
+
+// WithPagination sets the page sizes of the user listing
+func WithPagination(pagination Pagination) UserHandlerOption {
+	return func(h *UserHandler) {
+		h.pagination = pagination
+	}
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userUsecase usecase.UserUsecase) *UserHandler {
-	return &UserHandler{
+func NewUserHandler(userUsecase usecase.UserUsecase, opts ...UserHandlerOption) *UserHandler {
+	h := &UserHandler{
 		userUsecase: userUsecase,
+		pagination:  defaultPagination,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// usecaseFor returns the user usecase attributed to the request's authenticated user
+func (h *UserHandler) usecaseFor(c *fiber.Ctx) usecase.UserUsecase {
+	actor := entity.AuditActorAnonymous
+	if user := CurrentUser(c); user != nil {
+		actor = user.Email
+	}
+	return usecase.ForActor(h.userUsecase, actor)
+}
+
+// assignableRole returns the requested role when an admin is authenticated
+// and no role otherwise, so other callers get the default role or keep theirs
+func assignableRole(c *fiber.Ctx, requested string) string {
+	if user := CurrentUser(c); user != nil && user.Role == entity.RoleAdmin {
+		return requested
 	}
+	return ""
 }
 
 // CreateHandler handles the creation of a new user
 func (h *UserHandler) CreateHandler(c *fiber.Ctx) error {
 	var req entity.UserRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	if err := parseStrictBody(c, &req); err != nil {
+		return bodyParseError(c, err)
 	}
+	req.Role = assignableRole(c, req.Role)
 
-	response, err := h.userUsecase.CreateUser(req)
+	response, err := h.usecaseFor(c).CreateUser(c.UserContext(), req)
+	if errors.Is(err, repository.ErrServiceUnavailable) {
+		return serviceUnavailable(c)
+	}
+	if errors.Is(err, usecase.ErrBlankName) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, usecase.ErrUserLimitReached) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		// Check if it's a specific error type
 		switch err.(type) {
 		case *usecase.EmailAlreadyExistsError:
 			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		case *utils.PasswordStrengthError, *utils.EmailDomainError, *usecase.InvalidRoleError:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 		default:
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 		}
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(response)
+	return respond(c, fiber.StatusCreated, response)
 }
 
 // GetByIDHandler handles retrieving a user by ID
@@ -49,12 +109,20 @@ func (h *UserHandler) GetByIDHandler(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
 	}
 
-	response, err := h.userUsecase.GetUserByID(uint(id))
+	response, err := h.userUsecase.GetUserByID(c.UserContext(), uint(id))
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(response)
+	// Let polling clients revalidate cheaply with If-None-Match
+	etag := userETag(response)
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, "private, must-revalidate")
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	return respond(c, fiber.StatusOK, response)
 }
 
 // GetByEmailHandler handles retrieving a user by email
@@ -64,44 +132,164 @@ func (h *UserHandler) GetByEmailHandler(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Email parameter is required"})
 	}
 
-	response, err := h.userUsecase.GetUserByEmail(email)
+	response, err := h.userUsecase.GetUserByEmail(c.UserContext(), email)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(response)
+	return respond(c, fiber.StatusOK, response)
+}
+
+// ListHandler handles listing users filtered by email, name and creation date,
+// paged by offset or by the cursor returned as nextCursor. A request carrying
+// only an email keeps the single-user lookup of GetByEmailHandler.
+func (h *UserHandler) ListHandler(c *fiber.Ctx) error {
+	queries := c.Queries()
+	if _, ok := queries["email"]; ok && len(queries) == 1 {
+		return h.GetByEmailHandler(c)
+	}
+
+	filter := repository.UserFilter{
+		Email: c.Query("email"),
+		Query: c.Query("q"),
+	}
+	var err error
+	if filter.CreatedFrom, err = parseTimeQuery(c, "createdFrom"); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, err.Error())
+	}
+	if filter.CreatedTo, err = parseTimeQuery(c, "createdTo"); err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, err.Error())
+	}
+
+	offset, limit, err := h.pagination.Parse(c)
+	if err != nil {
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, err.Error())
+	}
+	page := offset/limit + 1
+
+	// Keyset pagination with a cursor; offset pages remain as the fallback
+	if after := c.Query("after"); after != "" {
+		if c.Query("page") != "" {
+			return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, "after and page cannot be combined")
+		}
+		cursor, err := repository.DecodeUserCursor(after)
+		if err != nil {
+			return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, "after must be a cursor returned as nextCursor")
+		}
+		filter.After = &cursor
+		page = 0
+	}
+
+	users, err := h.userUsecase.ListUsers(c.UserContext(), filter, page, limit)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidDateRange) {
+			return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, err.Error())
+		}
+		return errorResponse(c, fiber.StatusInternalServerError, CodeInternal, "Failed to list users")
+	}
+
+	response := entity.UserListResponse{Users: users, Page: page, Limit: limit}
+	if len(users) == limit {
+		response.NextCursor = repository.NewUserCursor(users[len(users)-1]).Encode()
+	}
+	return respond(c, fiber.StatusOK, response)
+}
+
+// parseTimeQuery parses an optional RFC 3339 timestamp query parameter
+func parseTimeQuery(c *fiber.Ctx, key string) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", key)
+	}
+	return &t, nil
+}
+
+// parsePositiveQuery parses an optional positive integer query parameter
+func parsePositiveQuery(c *fiber.Ctx, key string, fallback int) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", key)
+	}
+	return n, nil
 }
 
 // GetAllHandler handles retrieving all users
 func (h *UserHandler) GetAllHandler(c *fiber.Ctx) error {
-	responses, err := h.userUsecase.GetAllUsers()
-	fmt.Println(responses)
-	fmt.Println("debug")
+	responses, err := h.userUsecase.GetAllUsers(c.UserContext())
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(responses)
+	return respond(c, fiber.StatusOK, responses)
+}
+
+// CountHandler handles retrieving the total number of users
+func (h *UserHandler) CountHandler(c *fiber.Ctx) error {
+	count, err := h.userUsecase.CountUsers(c.UserContext())
+	if err != nil {
+		return errorResponse(c, fiber.StatusInternalServerError, CodeInternal, "Failed to count users")
+	}
+
+	return respond(c, fiber.StatusOK, fiber.Map{"count": count})
 }
 
-// UpdateHandler handles updating a user
+// UpdateHandler handles updating a user. An If-Match header must carry the
+// user's current ETag, so a client editing a stale copy gets 412 rather than
+// overwriting a newer update.
 func (h *UserHandler) UpdateHandler(c *fiber.Ctx) error {
 	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
 	}
 
-	var req entity.UserRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	precondition := c.Get(fiber.HeaderIfMatch)
+	if precondition == "" && h.requireIfMatch {
+		return errorResponse(c, fiber.StatusPreconditionRequired, CodePreconditionRequired,
+			"If-Match header with the user's current ETag is required")
+	}
+
+	var req entity.UpdateUserRequest
+	if err := parseStrictBody(c, &req); err != nil {
+		return bodyParseError(c, err)
+	}
+	req.Role = assignableRole(c, req.Role)
+
+	ctx := c.UserContext()
+	if precondition != "" {
+		ctx = usecase.ContextWithUpdatePrecondition(ctx, func(current *entity.UserResponse) bool {
+			return ifMatch(precondition, userETag(current))
+		})
 	}
 
-	response, err := h.userUsecase.UpdateUser(uint(id), req)
+	response, err := h.usecaseFor(c).UpdateUser(ctx, uint(id), req)
+	if errors.Is(err, repository.ErrServiceUnavailable) {
+		return serviceUnavailable(c)
+	}
+	if errors.Is(err, usecase.ErrPreconditionFailed) {
+		return errorResponse(c, fiber.StatusPreconditionFailed, CodePreconditionFailed,
+			"User has changed since the If-Match ETag was issued")
+	}
+	if errors.Is(err, usecase.ErrBlankName) || errors.Is(err, usecase.ErrEmailChangeNotAllowed) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		switch err.(type) {
+		case *usecase.InvalidRoleError:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		default:
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		}
 	}
 
-	return c.Status(fiber.StatusOK).JSON(response)
+	return respond(c, fiber.StatusOK, response)
 }
 
 // DeleteHandler handles deleting a user
@@ -111,10 +299,86 @@ func (h *UserHandler) DeleteHandler(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
 	}
 
-	err = h.userUsecase.DeleteUser(uint(id))
+	err = h.usecaseFor(c).DeleteUser(c.UserContext(), uint(id))
+	if errors.Is(err, repository.ErrServiceUnavailable) {
+		return serviceUnavailable(c)
+	}
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "User deleted successfully"})
 }
+
+// ChangePasswordHandler handles changing a user's password, which requires
+// their current password
+func (h *UserHandler) ChangePasswordHandler(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	var req entity.ChangePasswordRequest
+	if err := parseStrictBody(c, &req); err != nil {
+		return bodyParseError(c, err)
+	}
+
+	err = h.usecaseFor(c).ChangePassword(c.UserContext(), uint(id), req.CurrentPassword, req.NewPassword)
+	if errors.Is(err, repository.ErrServiceUnavailable) {
+		return serviceUnavailable(c)
+	}
+	if errors.Is(err, usecase.ErrIncorrectPassword) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		if _, ok := err.(*utils.PasswordStrengthError); ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		}
+		return errorResponse(c, fiber.StatusInternalServerError, CodeInternal, "Failed to change password")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Password changed successfully"})
+}
+
+// ChangeEmailHandler handles changing a user's email, which requires their
+// current password. The new address must be verified; depending on
+// configuration the old address stays in use until it is.
+func (h *UserHandler) ChangeEmailHandler(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	var req entity.ChangeEmailRequest
+	if err := parseStrictBody(c, &req); err != nil {
+		return bodyParseError(c, err)
+	}
+
+	err = h.usecaseFor(c).ChangeEmail(c.UserContext(), uint(id), req.CurrentPassword, req.Email)
+	if errors.Is(err, repository.ErrServiceUnavailable) {
+		return serviceUnavailable(c)
+	}
+	if errors.Is(err, usecase.ErrBlankEmail) {
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidBody, "email is required")
+	}
+	if errors.Is(err, usecase.ErrIncorrectPassword) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		switch err.(type) {
+		case *usecase.EmailAlreadyExistsError:
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		case *utils.EmailDomainError:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		}
+		return errorResponse(c, fiber.StatusInternalServerError, CodeInternal, "Failed to change email")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Email change requested; follow the link sent to the new address to verify it"})
+}
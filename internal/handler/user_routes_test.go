@@ -0,0 +1,692 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/example/go-clean-architecture/internal/usecase/mocks"
+	"github.com/example/go-clean-architecture/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// newUserRoutesTestApp registers the user CRUD routes backed by a mock usecase
+func newUserRoutesTestApp(t *testing.T, opts ...UserHandlerOption) (*fiber.App, *mocks.MockUserUsecase) {
+	uc := mocks.NewMockUserUsecase(t)
+	userHandler := NewUserHandler(uc, opts...)
+
+	app := fiber.New()
+	users := app.Group("/users", RequireBodyContentType())
+	users.Post("/", userHandler.CreateHandler)
+	users.Get("/", userHandler.ListHandler)
+	users.Get("/count", userHandler.CountHandler)
+	users.Get("/:id", userHandler.GetByIDHandler)
+	users.Put("/:id", userHandler.UpdateHandler)
+	users.Delete("/:id", userHandler.DeleteHandler)
+	users.Post("/:id/password", userHandler.ChangePasswordHandler)
+	users.Post("/:id/email", userHandler.ChangeEmailHandler)
+	return app, uc
+}
+
+// doJSON sends a request with an optional raw JSON body and decodes the JSON response
+func doJSON(t *testing.T, app *fiber.App, method, path, body string) (*http.Response, map[string]interface{}) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != "" {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	if len(raw) > 0 {
+		require.NoError(t, json.Unmarshal(raw, &decoded))
+	}
+	return resp, decoded
+}
+
+var testUserResponse = &entity.UserResponse{ID: 1, Name: "John Doe", Email: "john.doe@example.com", Role: entity.RoleUser}
+
+const testUserBody = `{"name":"John Doe","email":"john.doe@example.com","password":"S3curePassword","role":"admin"}`
+
+const testUpdateUserBody = `{"name":"John Doe","email":"john.doe@example.com","role":"admin"}`
+
+func TestCreateHandler(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+
+	// The role in the body must be discarded before reaching the usecase
+	uc.On("CreateUser", mock.Anything, entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "S3curePassword"}).
+		Return(testUserResponse, nil)
+
+	resp, body := doJSON(t, app, fiber.MethodPost, "/users", testUserBody)
+
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "john.doe@example.com", body["email"])
+	assert.Equal(t, float64(1), body["id"])
+	assert.NotContains(t, body, "password")
+}
+
+func TestUserHandler_OnlyAdminsAssignRoles(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller *entity.UserResponse
+		role   string
+	}{
+		{"anonymous", nil, ""},
+		{"user", &entity.UserResponse{ID: 2, Role: entity.RoleUser}, ""},
+		{"admin", &entity.UserResponse{ID: 3, Role: entity.RoleAdmin}, entity.RoleAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := mocks.NewMockUserUsecase(t)
+			h := NewUserHandler(uc, WithIfMatchRequired(false))
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				if tt.caller != nil {
+					c.Locals(currentUserKey, tt.caller)
+				}
+				return c.Next()
+			})
+			app.Post("/users", h.CreateHandler)
+			app.Put("/users/:id", h.UpdateHandler)
+			req := entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "S3curePassword", Role: tt.role}
+			uc.On("CreateUser", mock.Anything, req).Return(testUserResponse, nil)
+			update := entity.UpdateUserRequest{Name: "John Doe", Email: "john.doe@example.com", Role: tt.role}
+			uc.On("UpdateUser", mock.Anything, uint(1), update).Return(testUserResponse, nil)
+
+			resp, _ := doJSON(t, app, fiber.MethodPost, "/users", testUserBody)
+			assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+			resp, _ = doJSON(t, app, fiber.MethodPut, "/users/1", testUpdateUserBody)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestCreateHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"conflict", &usecase.EmailAlreadyExistsError{Email: "john.doe@example.com"}, fiber.StatusConflict},
+		{"weak password", &utils.PasswordStrengthError{Failures: []string{"is too common"}}, fiber.StatusBadRequest},
+		{"email domain without MX", &utils.EmailDomainError{Domain: "example.com"}, fiber.StatusBadRequest},
+		{"blank name", usecase.ErrBlankName, fiber.StatusBadRequest},
+		{"invalid role", &usecase.InvalidRoleError{Role: "superuser"}, fiber.StatusBadRequest},
+		{"user limit reached", usecase.ErrUserLimitReached, fiber.StatusForbidden},
+		{"internal error", errors.New("connection refused"), fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, uc := newUserRoutesTestApp(t)
+			uc.On("CreateUser", mock.Anything, entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "S3curePassword"}).
+				Return(nil, tt.err)
+
+			resp, body := doJSON(t, app, fiber.MethodPost, "/users", testUserBody)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.err.Error(), body["error"])
+		})
+	}
+}
+
+func TestCreateHandler_BadBody(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+
+	resp, body := doJSON(t, app, fiber.MethodPost, "/users", `{"name":`)
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, CodeMalformedJSON, body["code"])
+	uc.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+}
+
+func TestCreateHandler_YAMLBody(t *testing.T) {
+	for _, contentType := range []string{"application/yaml", "application/x-yaml; charset=utf-8", "text/yaml"} {
+		t.Run(contentType, func(t *testing.T) {
+			app, uc := newUserRoutesTestApp(t)
+			uc.On("CreateUser", mock.Anything, entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "S3curePassword"}).
+				Return(testUserResponse, nil)
+
+			req := httptest.NewRequest(fiber.MethodPost, "/users", strings.NewReader(
+				"name: John Doe\nemail: john.doe@example.com\npassword: S3curePassword\nrole: admin\n"))
+			req.Header.Set(fiber.HeaderContentType, contentType)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		})
+	}
+}
+
+func TestCreateHandler_YAMLBodyIgnoresUnknownFields(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+	uc.On("CreateUser", mock.Anything, entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "S3curePassword"}).
+		Return(testUserResponse, nil)
+
+	req := httptest.NewRequest(fiber.MethodPost, "/users", strings.NewReader(
+		"name: John Doe\nemail: john.doe@example.com\npassword: S3curePassword\nnickname: JJ\n"))
+	req.Header.Set(fiber.HeaderContentType, "application/yaml")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+}
+
+func TestCreateHandler_JSONWithoutContentType(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+	uc.On("CreateUser", mock.Anything, entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "S3curePassword"}).
+		Return(testUserResponse, nil)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/users", strings.NewReader(testUserBody)))
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+}
+
+func TestGetByIDHandler(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+	uc.On("GetUserByID", mock.Anything, uint(1)).Return(testUserResponse, nil)
+	uc.On("GetUserByID", mock.Anything, uint(2)).Return(nil, gorm.ErrRecordNotFound)
+
+	resp, body := doJSON(t, app, fiber.MethodGet, "/users/1", "")
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "John Doe", body["name"])
+
+	resp, body = doJSON(t, app, fiber.MethodGet, "/users/2", "")
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "User not found", body["error"])
+
+	resp, body = doJSON(t, app, fiber.MethodGet, "/users/abc", "")
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "Invalid user ID", body["error"])
+}
+
+func TestUpdateHandler(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+	req := entity.UpdateUserRequest{Name: "John Doe", Email: "john.doe@example.com"}
+	uc.On("UpdateUser", mock.Anything, uint(1), req).Return(testUserResponse, nil)
+
+	resp, body := doJSON(t, app, fiber.MethodPut, "/users/1", testUpdateUserBody)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "john.doe@example.com", body["email"])
+}
+
+func TestUpdateHandler_RejectsPassword(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+
+	// Passwords only change through the change password and reset flows
+	resp, body := doJSON(t, app, fiber.MethodPut, "/users/1", testUserBody)
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, CodeUnknownField, body["code"])
+	uc.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// putWithIfMatch sends testUpdateUserBody to PUT /users/1 with an optional If-Match header
+func putWithIfMatch(t *testing.T, app *fiber.App, ifMatch string) (*http.Response, APIError) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodPut, "/users/1", strings.NewReader(testUpdateUserBody))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if ifMatch != "" {
+		req.Header.Set(fiber.HeaderIfMatch, ifMatch)
+	}
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	var body APIError
+	if resp.StatusCode != fiber.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	}
+	return resp, body
+}
+
+// updatePrecondition matches contexts carrying an update precondition that
+// gives accepted for current, standing in for the usecase's check
+func updatePrecondition(current *entity.UserResponse, accepted bool) interface{} {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		check := usecase.UpdatePrecondition(ctx)
+		return check != nil && check(current) == accepted
+	})
+}
+
+func TestUpdateHandler_IfMatch(t *testing.T) {
+	req := entity.UpdateUserRequest{Name: "John Doe", Email: "john.doe@example.com"}
+	current := &entity.UserResponse{ID: 1, Name: "John Doe", UpdatedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	stale := &entity.UserResponse{ID: 1, Name: "John Doe", UpdatedAt: current.UpdatedAt.Add(-time.Minute)}
+
+	t.Run("match", func(t *testing.T) {
+		app, uc := newUserRoutesTestApp(t, WithIfMatchRequired(true))
+		uc.On("UpdateUser", updatePrecondition(current, true), uint(1), req).Return(testUserResponse, nil)
+
+		resp, _ := putWithIfMatch(t, app, userETag(current))
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("wildcard", func(t *testing.T) {
+		app, uc := newUserRoutesTestApp(t, WithIfMatchRequired(true))
+		uc.On("UpdateUser", updatePrecondition(current, true), uint(1), req).Return(testUserResponse, nil)
+
+		resp, _ := putWithIfMatch(t, app, "*")
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		app, uc := newUserRoutesTestApp(t, WithIfMatchRequired(true))
+		uc.On("UpdateUser", updatePrecondition(current, false), uint(1), req).Return(nil, usecase.ErrPreconditionFailed)
+
+		resp, body := putWithIfMatch(t, app, userETag(stale))
+
+		assert.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+		assert.Equal(t, CodePreconditionFailed, body.Code)
+	})
+
+	t.Run("weak etag never matches", func(t *testing.T) {
+		app, uc := newUserRoutesTestApp(t, WithIfMatchRequired(true))
+		uc.On("UpdateUser", updatePrecondition(current, false), uint(1), req).Return(nil, usecase.ErrPreconditionFailed)
+
+		resp, _ := putWithIfMatch(t, app, "W/"+userETag(current))
+
+		assert.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+	})
+
+	t.Run("changed before the write", func(t *testing.T) {
+		app, uc := newUserRoutesTestApp(t, WithIfMatchRequired(true))
+		uc.On("UpdateUser", updatePrecondition(current, true), uint(1), req).Return(nil, usecase.ErrPreconditionFailed)
+
+		resp, body := putWithIfMatch(t, app, userETag(current))
+
+		assert.Equal(t, fiber.StatusPreconditionFailed, resp.StatusCode)
+		assert.Equal(t, CodePreconditionFailed, body.Code)
+	})
+
+	t.Run("missing user", func(t *testing.T) {
+		app, uc := newUserRoutesTestApp(t, WithIfMatchRequired(true))
+		uc.On("UpdateUser", mock.Anything, uint(1), req).Return(nil, gorm.ErrRecordNotFound)
+
+		resp, _ := putWithIfMatch(t, app, userETag(current))
+
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("missing header rejected", func(t *testing.T) {
+		app, uc := newUserRoutesTestApp(t, WithIfMatchRequired(true))
+
+		resp, body := putWithIfMatch(t, app, "")
+
+		assert.Equal(t, fiber.StatusPreconditionRequired, resp.StatusCode)
+		assert.Equal(t, CodePreconditionRequired, body.Code)
+		uc.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing header allowed", func(t *testing.T) {
+		app, uc := newUserRoutesTestApp(t, WithIfMatchRequired(false))
+		noPrecondition := mock.MatchedBy(func(ctx context.Context) bool { return usecase.UpdatePrecondition(ctx) == nil })
+		uc.On("UpdateUser", noPrecondition, uint(1), req).Return(testUserResponse, nil)
+
+		resp, _ := putWithIfMatch(t, app, "")
+
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestUpdateHandler_Errors(t *testing.T) {
+	req := entity.UpdateUserRequest{Name: "John Doe", Email: "john.doe@example.com"}
+
+	t.Run("bad id", func(t *testing.T) {
+		app, _ := newUserRoutesTestApp(t)
+		resp, body := doJSON(t, app, fiber.MethodPut, "/users/abc", testUpdateUserBody)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, "Invalid user ID", body["error"])
+	})
+
+	t.Run("bad body", func(t *testing.T) {
+		app, _ := newUserRoutesTestApp(t)
+		resp, body := doJSON(t, app, fiber.MethodPut, "/users/1", `{"name":`)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, CodeMalformedJSON, body["code"])
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		app, uc := newUserRoutesTestApp(t)
+		resp, body := doJSON(t, app, fiber.MethodPut, "/users/1",
+			`{"name":"John Doe","emial":"john.doe@example.com"}`)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, CodeUnknownField, body["code"])
+		assert.Equal(t, `Unknown field "emial"`, body["error"])
+		uc.AssertNotCalled(t, "UpdateUser", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		app, uc := newUserRoutesTestApp(t)
+		uc.On("UpdateUser", mock.Anything, uint(1), req).Return(nil, gorm.ErrRecordNotFound)
+		resp, body := doJSON(t, app, fiber.MethodPut, "/users/1", testUpdateUserBody)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, "User not found", body["error"])
+	})
+
+	t.Run("email change", func(t *testing.T) {
+		app, uc := newUserRoutesTestApp(t)
+		uc.On("UpdateUser", mock.Anything, uint(1), req).Return(nil, usecase.ErrEmailChangeNotAllowed)
+		resp, body := doJSON(t, app, fiber.MethodPut, "/users/1", testUpdateUserBody)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, usecase.ErrEmailChangeNotAllowed.Error(), body["error"])
+	})
+}
+
+func TestDeleteHandler(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+	uc.On("DeleteUser", mock.Anything, uint(1)).Return(nil)
+	uc.On("DeleteUser", mock.Anything, uint(2)).Return(gorm.ErrRecordNotFound)
+
+	resp, body := doJSON(t, app, fiber.MethodDelete, "/users/1", "")
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "User deleted successfully", body["message"])
+
+	resp, body = doJSON(t, app, fiber.MethodDelete, "/users/2", "")
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "User not found", body["error"])
+
+	resp, body = doJSON(t, app, fiber.MethodDelete, "/users/abc", "")
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "Invalid user ID", body["error"])
+}
+
+func TestUserWriteHandlers_ReadOnlyDatabase(t *testing.T) {
+	// What the repository returns while PostgreSQL is read-only during a failover
+	errReadOnly := fmt.Errorf("%w: %w", repository.ErrServiceUnavailable,
+		&pgconn.PgError{Code: "25006", Message: "cannot execute UPDATE in a read-only transaction"})
+	req := entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "S3curePassword"}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		setup  func(uc *mocks.MockUserUsecase)
+	}{
+		{"create", fiber.MethodPost, "/users", testUserBody, func(uc *mocks.MockUserUsecase) {
+			uc.On("CreateUser", mock.Anything, req).Return(nil, errReadOnly)
+		}},
+		{"update", fiber.MethodPut, "/users/1", testUpdateUserBody, func(uc *mocks.MockUserUsecase) {
+			uc.On("UpdateUser", mock.Anything, uint(1), entity.UpdateUserRequest{Name: "John Doe", Email: "john.doe@example.com"}).Return(nil, errReadOnly)
+		}},
+		{"delete", fiber.MethodDelete, "/users/1", "", func(uc *mocks.MockUserUsecase) {
+			uc.On("DeleteUser", mock.Anything, uint(1)).Return(errReadOnly)
+		}},
+		{"change email", fiber.MethodPost, "/users/1/email", `{"currentPassword":"S3curePassword","email":"john.new@example.com"}`, func(uc *mocks.MockUserUsecase) {
+			uc.On("ChangeEmail", mock.Anything, uint(1), "S3curePassword", "john.new@example.com").Return(errReadOnly)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, uc := newUserRoutesTestApp(t, WithIfMatchRequired(false))
+			tt.setup(uc)
+
+			resp, body := doJSON(t, app, tt.method, tt.path, tt.body)
+
+			assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+			assert.Equal(t, CodeServiceUnavailable, body["code"])
+			assert.Equal(t, readOnlyRetryAfter, resp.Header.Get(fiber.HeaderRetryAfter))
+		})
+	}
+}
+
+func TestChangePasswordHandler(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+	uc.On("ChangePassword", mock.Anything, uint(1), "S3curePassword", "N3wSecurePassword").Return(nil)
+
+	resp, body := doJSON(t, app, fiber.MethodPost, "/users/1/password", `{"currentPassword":"S3curePassword","newPassword":"N3wSecurePassword"}`)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Password changed successfully", body["message"])
+}
+
+func TestChangePasswordHandler_Errors(t *testing.T) {
+	const reqBody = `{"currentPassword":"S3curePassword","newPassword":"N3wSecurePassword"}`
+
+	t.Run("unknown field", func(t *testing.T) {
+		app, uc := newUserRoutesTestApp(t)
+		resp, body := doJSON(t, app, fiber.MethodPost, "/users/1/password", `{"currentPassword":"S3curePassword","password":"N3wSecurePassword"}`)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, CodeUnknownField, body["code"])
+		uc.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"incorrect password", usecase.ErrIncorrectPassword, fiber.StatusForbidden},
+		{"weak password", &utils.PasswordStrengthError{Failures: []string{"must contain a digit"}}, fiber.StatusBadRequest},
+		{"not found", gorm.ErrRecordNotFound, fiber.StatusNotFound},
+		{"database error", errors.New("connection reset"), fiber.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, uc := newUserRoutesTestApp(t)
+			uc.On("ChangePassword", mock.Anything, uint(1), "S3curePassword", "N3wSecurePassword").Return(tt.err)
+			resp, _ := doJSON(t, app, fiber.MethodPost, "/users/1/password", reqBody)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestChangeEmailHandler(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+	uc.On("ChangeEmail", mock.Anything, uint(1), "S3curePassword", "john.new@example.com").Return(nil)
+
+	resp, body := doJSON(t, app, fiber.MethodPost, "/users/1/email", `{"currentPassword":"S3curePassword","email":"john.new@example.com"}`)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, body["message"])
+}
+
+func TestChangeEmailHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"taken", &usecase.EmailAlreadyExistsError{Email: "jane.doe@example.com"}, fiber.StatusConflict},
+		{"blank", usecase.ErrBlankEmail, fiber.StatusBadRequest},
+		{"undeliverable domain", &utils.EmailDomainError{Domain: "example.invalid"}, fiber.StatusBadRequest},
+		{"incorrect password", usecase.ErrIncorrectPassword, fiber.StatusForbidden},
+		{"not found", gorm.ErrRecordNotFound, fiber.StatusNotFound},
+		{"unexpected", errors.New("connection reset"), fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, uc := newUserRoutesTestApp(t)
+			uc.On("ChangeEmail", mock.Anything, uint(1), "S3curePassword", "jane.doe@example.com").Return(tt.err)
+
+			resp, _ := doJSON(t, app, fiber.MethodPost, "/users/1/email", `{"currentPassword":"S3curePassword","email":"jane.doe@example.com"}`)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		app, uc := newUserRoutesTestApp(t)
+		resp, body := doJSON(t, app, fiber.MethodPost, "/users/1/email", `{"emial":"jane.doe@example.com"}`)
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, CodeUnknownField, body["code"])
+		uc.AssertNotCalled(t, "ChangeEmail", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserRoutes_Count(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+	uc.On("CountUsers", mock.Anything).Return(int64(42), nil)
+
+	resp, body := doJSON(t, app, fiber.MethodGet, "/users/count", "")
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, float64(42), body["count"])
+}
+
+func TestUserRoutes_CountError(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+	uc.On("CountUsers", mock.Anything).Return(int64(0), errors.New("connection refused"))
+
+	resp, body := doJSON(t, app, fiber.MethodGet, "/users/count", "")
+
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, CodeInternal, body["code"])
+}
+
+func TestUserRoutes_ListByEmailKeepsSingleUserLookup(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+	uc.On("GetUserByEmail", mock.Anything, "john.doe@example.com").Return(testUserResponse, nil)
+
+	resp, body := doJSON(t, app, fiber.MethodGet, "/users?email=john.doe@example.com", "")
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, float64(1), body["id"])
+}
+
+func TestUserRoutes_ListFilters(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		query  string
+		filter repository.UserFilter
+		page   int
+		limit  int
+	}{
+		{"defaults", "", repository.UserFilter{}, 1, 20},
+		{"date range", "?createdFrom=2024-01-01T00:00:00Z&createdTo=2024-01-31T23:59:59Z",
+			repository.UserFilter{CreatedFrom: &from, CreatedTo: &to}, 1, 20},
+		{"start date with search and paging", "?createdFrom=2024-01-01T00:00:00Z&q=john&page=3&limit=5",
+			repository.UserFilter{Query: "john", CreatedFrom: &from}, 3, 5},
+		{"email with end date", "?email=john.doe@example.com&createdTo=2024-01-31T23:59:59Z",
+			repository.UserFilter{Email: "john.doe@example.com", CreatedTo: &to}, 1, 20},
+		{"limit is capped", "?limit=1000", repository.UserFilter{}, 1, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, uc := newUserRoutesTestApp(t)
+			uc.On("ListUsers", mock.Anything, mock.MatchedBy(func(f repository.UserFilter) bool {
+				return f.Email == tt.filter.Email && f.Query == tt.filter.Query &&
+					sameTime(f.CreatedFrom, tt.filter.CreatedFrom) && sameTime(f.CreatedTo, tt.filter.CreatedTo)
+			}), tt.page, tt.limit).Return([]entity.UserResponse{*testUserResponse}, nil)
+
+			resp, body := doJSON(t, app, fiber.MethodGet, "/users"+tt.query, "")
+
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			assert.Equal(t, float64(tt.page), body["page"])
+			assert.Equal(t, float64(tt.limit), body["limit"])
+			assert.Len(t, body["users"], 1)
+		})
+	}
+}
+
+func TestUserRoutes_ListRejectsInvalidQueries(t *testing.T) {
+	for _, query := range []string{
+		"?createdFrom=yesterday",
+		"?createdTo=2024-01-31",
+		"?page=0",
+		"?limit=abc",
+	} {
+		t.Run(query, func(t *testing.T) {
+			app, _ := newUserRoutesTestApp(t)
+
+			resp, body := doJSON(t, app, fiber.MethodGet, "/users"+query, "")
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, CodeInvalidQuery, body["code"])
+		})
+	}
+}
+
+func TestUserRoutes_ListInvertedDateRange(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+	uc.On("ListUsers", mock.Anything, mock.Anything, 1, 20).Return(nil, repository.ErrInvalidDateRange)
+
+	resp, body := doJSON(t, app, fiber.MethodGet, "/users?createdFrom=2024-02-01T00:00:00Z&createdTo=2024-01-01T00:00:00Z", "")
+
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, CodeInvalidQuery, body["code"])
+	assert.Equal(t, repository.ErrInvalidDateRange.Error(), body["error"])
+}
+
+func TestUserRoutes_ListCursor(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+	cursor := repository.UserCursor{CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ID: 5}
+	last := entity.UserResponse{ID: 7, CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	uc.On("ListUsers", mock.Anything, mock.MatchedBy(func(f repository.UserFilter) bool {
+		return f.After != nil && f.After.ID == 5 && f.After.CreatedAt.Equal(cursor.CreatedAt)
+	}), 0, 2).Return([]entity.UserResponse{{ID: 6}, last}, nil)
+
+	resp, body := doJSON(t, app, fiber.MethodGet, "/users?limit=2&after="+cursor.Encode(), "")
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.NotContains(t, body, "page")
+	assert.Equal(t, repository.NewUserCursor(last).Encode(), body["nextCursor"])
+}
+
+func TestUserRoutes_ListOmitsNextCursorOnLastPage(t *testing.T) {
+	app, uc := newUserRoutesTestApp(t)
+	uc.On("ListUsers", mock.Anything, mock.Anything, 1, 2).Return([]entity.UserResponse{*testUserResponse}, nil)
+
+	resp, body := doJSON(t, app, fiber.MethodGet, "/users?limit=2", "")
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.NotContains(t, body, "nextCursor")
+}
+
+func TestUserRoutes_ListRejectsInvalidCursor(t *testing.T) {
+	valid := repository.UserCursor{CreatedAt: time.Now(), ID: 1}.Encode()
+
+	for _, query := range []string{"?after=not-a-cursor", "?page=2&after=" + valid} {
+		t.Run(query, func(t *testing.T) {
+			app, _ := newUserRoutesTestApp(t)
+
+			resp, body := doJSON(t, app, fiber.MethodGet, "/users"+query, "")
+
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, CodeInvalidQuery, body["code"])
+		})
+	}
+}
+
+// sameTime reports whether two optional timestamps are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
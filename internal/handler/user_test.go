@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubUserUsecase overrides selected UserUsecase methods for handler tests;
+// calling a method without an override panics via the nil embedded interface
+type stubUserUsecase struct {
+	usecase.UserUsecase
+	authenticate func(email, password string) (*entity.UserResponse, error)
+	deleteUser   func(id uint) error
+	getUserByID  func(id uint) (*entity.UserResponse, error)
+}
+
+func (s *stubUserUsecase) GetUserByID(_ context.Context, id uint) (*entity.UserResponse, error) {
+	return s.getUserByID(id)
+}
+
+func (s *stubUserUsecase) Authenticate(_ context.Context, email, password string) (*entity.UserResponse, error) {
+	return s.authenticate(email, password)
+}
+
+func (s *stubUserUsecase) DeleteUser(_ context.Context, id uint) error {
+	return s.deleteUser(id)
+}
+
+func newGetByIDTestApp(user *entity.UserResponse) *fiber.App {
+	uc := &stubUserUsecase{
+		getUserByID: func(id uint) (*entity.UserResponse, error) {
+			if id != user.ID {
+				return nil, errors.New("record not found")
+			}
+			return user, nil
+		},
+	}
+
+	app := fiber.New()
+	app.Get("/users/:id", NewUserHandler(uc).GetByIDHandler)
+	return app
+}
+
+func TestGetByIDHandler_SetsETag(t *testing.T) {
+	user := &entity.UserResponse{ID: 1, Name: "John", UpdatedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	app := newGetByIDTestApp(user)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users/1", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, userETag(user), resp.Header.Get(fiber.HeaderETag))
+	assert.Equal(t, "private, must-revalidate", resp.Header.Get(fiber.HeaderCacheControl))
+}
+
+func TestGetByIDHandler_NotModifiedOnMatchingETag(t *testing.T) {
+	user := &entity.UserResponse{ID: 1, Name: "John", UpdatedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	app := newGetByIDTestApp(user)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/users/1", nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, userETag(user))
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, userETag(user), resp.Header.Get(fiber.HeaderETag))
+}
+
+func TestGetByIDHandler_OKOnStaleETag(t *testing.T) {
+	user := &entity.UserResponse{ID: 1, Name: "John", UpdatedAt: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	stale := userETag(&entity.UserResponse{ID: 1, UpdatedAt: user.UpdatedAt.Add(-time.Hour)})
+	app := newGetByIDTestApp(user)
+
+	req := httptest.NewRequest(fiber.MethodGet, "/users/1", nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, stale)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestETagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`"abc"`, `"abc"`))
+	assert.True(t, etagMatches(`"xyz", W/"abc"`, `"abc"`))
+	assert.True(t, etagMatches(`*`, `"abc"`))
+	assert.False(t, etagMatches(``, `"abc"`))
+	assert.False(t, etagMatches(`"xyz"`, `"abc"`))
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+)
+
+// EmailVerifier verifies users' email addresses from the tokens sent to them
+type EmailVerifier interface {
+	Verify(ctx context.Context, token string) (*entity.UserResponse, error)
+}
+
+// VerificationHandler represents the HTTP handler for email verification
+type VerificationHandler struct {
+	verifier EmailVerifier
+}
+
+// NewVerificationHandler creates a new verification handler
+func NewVerificationHandler(verifier EmailVerifier) *VerificationHandler {
+	return &VerificationHandler{verifier: verifier}
+}
+
+// VerifyHandler handles following an email verification link
+func (h *VerificationHandler) VerifyHandler(c *fiber.Ctx) error {
+	token := c.Query("token")
+	if token == "" {
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidQuery, "token is required")
+	}
+
+	user, err := h.verifier.Verify(c.UserContext(), token)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidToken):
+		return errorResponse(c, fiber.StatusBadRequest, CodeInvalidToken, err.Error())
+	case errors.Is(err, usecase.ErrTokenExpired):
+		return errorResponse(c, fiber.StatusBadRequest, CodeTokenExpired, err.Error())
+	case errors.As(err, new(*usecase.EmailAlreadyExistsError)):
+		return errorResponse(c, fiber.StatusConflict, CodeEmailTaken, err.Error())
+	case errors.Is(err, repository.ErrServiceUnavailable):
+		return serviceUnavailable(c)
+	case err != nil:
+		return errorResponse(c, fiber.StatusInternalServerError, CodeInternal, "Failed to verify email")
+	}
+
+	return respond(c, fiber.StatusOK, user)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/usecase"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEmailVerifier verifies a single known token
+type fakeEmailVerifier struct {
+	err error
+}
+
+func (f fakeEmailVerifier) Verify(_ context.Context, token string) (*entity.UserResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if token != "good" {
+		return nil, usecase.ErrInvalidToken
+	}
+	return &entity.UserResponse{ID: 1, Email: "john@example.com", Verified: true}, nil
+}
+
+func TestVerificationHandler_Verify(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		verifier fakeEmailVerifier
+		status   int
+		code     string
+	}{
+		{"success", "?token=good", fakeEmailVerifier{}, fiber.StatusOK, ""},
+		{"missing token", "", fakeEmailVerifier{}, fiber.StatusBadRequest, CodeInvalidQuery},
+		{"unknown token", "?token=bad", fakeEmailVerifier{}, fiber.StatusBadRequest, CodeInvalidToken},
+		{"expired token", "?token=good", fakeEmailVerifier{err: usecase.ErrTokenExpired}, fiber.StatusBadRequest, CodeTokenExpired},
+		{"email taken", "?token=good", fakeEmailVerifier{err: &usecase.EmailAlreadyExistsError{Email: "jane.doe@example.com"}}, fiber.StatusConflict, CodeEmailTaken},
+		{"store failure", "?token=good", fakeEmailVerifier{err: errors.New("mongo unavailable")}, fiber.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/users/verify", NewVerificationHandler(tt.verifier).VerifyHandler)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/users/verify"+tt.query, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+
+			if tt.code == "" {
+				var user entity.UserResponse
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+				assert.True(t, user.Verified)
+				return
+			}
+			var body APIError
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, tt.code, body.Code)
+		})
+	}
+}
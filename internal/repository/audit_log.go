@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/driver"
+	"github.com/example/go-clean-architecture/internal/entity"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditLogFilter narrows an audit log listing; zero-valued fields are ignored
+type AuditLogFilter struct {
+	TargetID uint
+	Action   string
+	Offset   int // number of matching entries to skip
+}
+
+// AuditLogRepository stores the audit trail of user mutations
+type AuditLogRepository struct {
+	mongo    *driver.Mongo
+	database string
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(mongo *driver.Mongo) *AuditLogRepository {
+	return &AuditLogRepository{mongo: mongo, database: "go_clean_arch"}
+}
+
+// collection returns the audit logs collection
+func (r *AuditLogRepository) collection() *mongo.Collection {
+	return r.mongo.GetCollection(r.database, "audit_logs")
+}
+
+// Create stores an audit entry, assigning its ID and timestamp
+func (r *AuditLogRepository) Create(entry *entity.AuditLog) error {
+	if entry.ID == "" {
+		entry.ID = primitive.NewObjectID().Hex()
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	_, err := r.collection().InsertOne(context.Background(), entry)
+	return err
+}
+
+// Find returns up to limit audit entries matching the filter, newest first
+func (r *AuditLogRepository) Find(filter AuditLogFilter, limit int) ([]*entity.AuditLog, error) {
+	query := bson.M{}
+	if filter.TargetID != 0 {
+		query["targetId"] = filter.TargetID
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	if filter.Offset > 0 {
+		opts.SetSkip(int64(filter.Offset))
+	}
+
+	cursor, err := r.collection().Find(context.Background(), query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	entries := []*entity.AuditLog{}
+	if err = cursor.All(context.Background(), &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
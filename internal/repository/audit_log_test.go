@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogRepository_CreateAndFind(t *testing.T) {
+	memoryLogRepo := newTestMemoryLogRepository(t)
+	repo := &AuditLogRepository{mongo: testMongo(memoryLogRepo), database: memoryLogRepo.database}
+
+	created := &entity.AuditLog{Actor: entity.AuditActorAnonymous, Action: entity.AuditActionCreate, TargetID: 1,
+		Timestamp: time.Now().Add(-time.Hour),
+		Changes:   map[string]entity.AuditChange{"name": {After: "John Doe"}}}
+	updated := &entity.AuditLog{Actor: "admin@example.com", Action: entity.AuditActionUpdate, TargetID: 1,
+		Changes: map[string]entity.AuditChange{"name": {Before: "John Doe", After: "Johnny Doe"}}}
+	other := &entity.AuditLog{Actor: "admin@example.com", Action: entity.AuditActionDelete, TargetID: 2}
+	for _, entry := range []*entity.AuditLog{created, updated, other} {
+		require.NoError(t, repo.Create(entry))
+	}
+	assert.NotEmpty(t, updated.ID)
+	assert.False(t, updated.Timestamp.IsZero())
+
+	entries, err := repo.Find(AuditLogFilter{TargetID: 1}, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, updated.ID, entries[0].ID)
+	assert.Equal(t, created.ID, entries[1].ID)
+	assert.Equal(t, "Johnny Doe", entries[0].Changes["name"].After)
+
+	entries, err = repo.Find(AuditLogFilter{Action: entity.AuditActionDelete}, 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint(2), entries[0].TargetID)
+
+	entries, err = repo.Find(AuditLogFilter{}, 1)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
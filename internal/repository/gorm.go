@@ -0,0 +1,46 @@
+package repository
+
+// GormRepository provides the create, read, save and delete operations every
+// GORM-backed entity needs, so new repositories only add their own queries
+type GormRepository[T any] struct {
+	db Database
+}
+
+// NewGormRepository creates a generic repository for T stored through db
+func NewGormRepository[T any](db Database) *GormRepository[T] {
+	return &GormRepository[T]{db: db}
+}
+
+// Create inserts a new record
+func (r *GormRepository[T]) Create(record *T) error {
+	return r.db.Create(record)
+}
+
+// GetByID retrieves the record with the given primary key
+func (r *GormRepository[T]) GetByID(id uint) (*T, error) {
+	var record T
+	if err := r.db.First(&record, id); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Find retrieves every record matching the optional GORM conditions, e.g.
+// "email = ?", email
+func (r *GormRepository[T]) Find(conditions ...interface{}) ([]T, error) {
+	var records []T
+	if err := r.db.Find(&records, conditions...); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Save updates a record, inserting it if it has no primary key yet
+func (r *GormRepository[T]) Save(record *T) error {
+	return r.db.Save(record)
+}
+
+// Delete deletes the record with the given primary key
+func (r *GormRepository[T]) Delete(id uint) error {
+	return r.db.Delete(new(T), id)
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	dbdriver "github.com/example/go-clean-architecture/internal/driver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// widget is a sample entity for exercising GormRepository
+type widget struct {
+	ID   uint
+	Name string
+}
+
+// newSQLMockWidgetRepository returns a generic widget repository backed by GORM over sqlmock
+func newSQLMockWidgetRepository(t *testing.T) (*GormRepository[widget], sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+		sqlDB.Close()
+	})
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+
+	return NewGormRepository[widget](&dbdriver.DB{DB: gormDB}), sqlMock
+}
+
+func TestGormRepository_Create(t *testing.T) {
+	repo, sqlMock := newSQLMockWidgetRepository(t)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "widgets" ("name") VALUES ($1) RETURNING "id"`)).
+		WithArgs("gear").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	sqlMock.ExpectCommit()
+
+	w := &widget{Name: "gear"}
+	require.NoError(t, repo.Create(w))
+
+	assert.Equal(t, uint(7), w.ID)
+}
+
+func TestGormRepository_GetByID(t *testing.T) {
+	repo, sqlMock := newSQLMockWidgetRepository(t)
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "widgets" WHERE "widgets"."id" = $1 ORDER BY "widgets"."id" LIMIT $2`)).
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "gear"))
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "widgets" WHERE "widgets"."id" = $1 ORDER BY "widgets"."id" LIMIT $2`)).
+		WithArgs(8, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+
+	w, err := repo.GetByID(7)
+	require.NoError(t, err)
+	assert.Equal(t, &widget{ID: 7, Name: "gear"}, w)
+
+	_, err = repo.GetByID(8)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestGormRepository_Find(t *testing.T) {
+	repo, sqlMock := newSQLMockWidgetRepository(t)
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "widgets"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "gear").AddRow(2, "cog"))
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "widgets" WHERE name = $1`)).
+		WithArgs("cog").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "cog"))
+
+	all, err := repo.Find()
+	require.NoError(t, err)
+	assert.Equal(t, []widget{{ID: 1, Name: "gear"}, {ID: 2, Name: "cog"}}, all)
+
+	matching, err := repo.Find("name = ?", "cog")
+	require.NoError(t, err)
+	assert.Equal(t, []widget{{ID: 2, Name: "cog"}}, matching)
+}
+
+func TestGormRepository_Save(t *testing.T) {
+	repo, sqlMock := newSQLMockWidgetRepository(t)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "widgets" SET "name"=$1 WHERE "id" = $2`)).
+		WithArgs("sprocket", 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+
+	require.NoError(t, repo.Save(&widget{ID: 7, Name: "sprocket"}))
+}
+
+func TestGormRepository_Delete(t *testing.T) {
+	repo, sqlMock := newSQLMockWidgetRepository(t)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "widgets" WHERE "widgets"."id" = $1`)).
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+
+	require.NoError(t, repo.Delete(7))
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/driver"
+	"github.com/example/go-clean-architecture/internal/entity"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrHeapProfileNotFound is returned when no heap profile has the requested ID
+var ErrHeapProfileNotFound = errors.New("heap profile not found")
+
+// HeapProfileRepository stores heap profiles captured on memory alerts
+type HeapProfileRepository struct {
+	mongo    *driver.Mongo
+	database string
+}
+
+// NewHeapProfileRepository creates a new heap profile repository
+func NewHeapProfileRepository(mongo *driver.Mongo) *HeapProfileRepository {
+	return &HeapProfileRepository{mongo: mongo, database: "go_clean_arch"}
+}
+
+// collection returns the heap profiles collection
+func (r *HeapProfileRepository) collection() *mongo.Collection {
+	return r.mongo.GetCollection(r.database, "heap_profiles")
+}
+
+// Create stores a heap profile, assigning its ID, timestamp and size
+func (r *HeapProfileRepository) Create(profile *entity.HeapProfile) error {
+	if profile.ID == "" {
+		profile.ID = primitive.NewObjectID().Hex()
+	}
+	if profile.Timestamp.IsZero() {
+		profile.Timestamp = time.Now()
+	}
+	profile.Size = len(profile.Data)
+
+	_, err := r.collection().InsertOne(context.Background(), profile)
+	return err
+}
+
+// List returns the metadata of all captured profiles, newest first, without
+// the profile data
+func (r *HeapProfileRepository) List() ([]*entity.HeapProfile, error) {
+	opts := options.Find().
+		SetProjection(bson.M{"data": 0}).
+		SetSort(bson.D{{Key: "timestamp", Value: -1}})
+
+	cursor, err := r.collection().Find(context.Background(), bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var profiles []*entity.HeapProfile
+	if err = cursor.All(context.Background(), &profiles); err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// FindByID returns a captured profile including its data
+func (r *HeapProfileRepository) FindByID(id string) (*entity.HeapProfile, error) {
+	var profile entity.HeapProfile
+	err := r.collection().FindOne(context.Background(), bson.M{"_id": id}).Decode(&profile)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrHeapProfileNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeapProfileRepository_CreateListFind(t *testing.T) {
+	memoryLogRepo := newTestMemoryLogRepository(t)
+	repo := &HeapProfileRepository{mongo: testMongo(memoryLogRepo), database: memoryLogRepo.database}
+
+	older := &entity.HeapProfile{Timestamp: time.Now().Add(-time.Hour), Alloc: 1, Data: []byte{0x1f, 0x8b, 1}}
+	newer := &entity.HeapProfile{Alloc: 2, Sys: 4, Data: []byte{0x1f, 0x8b, 2, 3}}
+	require.NoError(t, repo.Create(older))
+	require.NoError(t, repo.Create(newer))
+
+	assert.NotEmpty(t, newer.ID)
+	assert.False(t, newer.Timestamp.IsZero())
+	assert.Equal(t, 4, newer.Size)
+
+	profiles, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+	assert.Equal(t, newer.ID, profiles[0].ID)
+	assert.Equal(t, older.ID, profiles[1].ID)
+	assert.Equal(t, 4, profiles[0].Size)
+	assert.Empty(t, profiles[0].Data)
+
+	found, err := repo.FindByID(newer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, newer.Data, found.Data)
+	assert.Equal(t, uint64(2), found.Alloc)
+}
+
+func TestHeapProfileRepository_FindByIDNotFound(t *testing.T) {
+	memoryLogRepo := newTestMemoryLogRepository(t)
+	repo := &HeapProfileRepository{mongo: testMongo(memoryLogRepo), database: memoryLogRepo.database}
+
+	_, err := repo.FindByID("missing")
+	assert.ErrorIs(t, err, ErrHeapProfileNotFound)
+}
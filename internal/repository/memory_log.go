@@ -2,26 +2,356 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/example/go-clean-architecture/internal/driver"
 	"github.com/example/go-clean-architecture/internal/entity"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// MemoryLogRepository represents the repository for memory logs
+// ErrMemoryLogNotFound is returned when no memory log has the requested ID
+var ErrMemoryLogNotFound = errors.New("memory log not found")
+
+// ErrInvalidMemoryLogID is returned for IDs that are not hex ObjectIDs
+var ErrInvalidMemoryLogID = errors.New("invalid memory log ID")
+
+// memoryLogTimestampIndex is the name of the ascending index on timestamp
+const memoryLogTimestampIndex = "timestamp_1"
+
+// memoryLogCollection is the name of the memory logs collection, and the
+// prefix of its monthly buckets
+const memoryLogCollection = "memory_logs"
+
+// Error labels marking transient write failures
+const (
+	retryableWriteErrorLabel = "RetryableWriteError"
+	networkErrorLabel        = "NetworkError"
+)
+
+// memoryLogBucketLayout formats the month in a bucket's name
+const memoryLogBucketLayout = "2006_01"
+
+// DefaultMongoOperationTimeout bounds each memory log operation unless
+// SetOperationTimeout changes it
+const DefaultMongoOperationTimeout = 10 * time.Second
+
+// DefaultMemoryLogWriteRetries is how many times a memory log insert that
+// failed transiently is retried unless SetWriteRetries changes it
+const DefaultMemoryLogWriteRetries = 3
+
+// defaultWriteRetryBackoff is the wait before the first retry of an insert;
+// it doubles for every further retry
+const defaultWriteRetryBackoff = 100 * time.Millisecond
+
+// MemoryLogRepository represents the repository for memory logs. Every
+// operation runs under the caller's context and the operation timeout,
+// whichever ends first, so an unresponsive MongoDB cannot block it forever.
 type MemoryLogRepository struct {
-	mongo *driver.Mongo
+	collections    CollectionProvider
+	database       string
+	timeout        time.Duration
+	writeRetries   int
+	retryBackoff   time.Duration
+	monthlyBuckets bool
+	indexedBuckets *sync.Map       // names of the buckets whose indexes were ensured
+	ctx            context.Context // set on repositories bound to a transaction
 }
 
 // NewMemoryLogRepository creates a new memory log repository
 func NewMemoryLogRepository(mongo *driver.Mongo) *MemoryLogRepository {
-	return &MemoryLogRepository{mongo: mongo}
+	return NewMemoryLogRepositoryWithProvider(NewCollectionProvider(mongo))
+}
+
+// NewMemoryLogRepositoryWithProvider creates a memory log repository on top of
+// any collection provider, such as a fake in tests
+func NewMemoryLogRepositoryWithProvider(collections CollectionProvider) *MemoryLogRepository {
+	return &MemoryLogRepository{
+		collections:  collections,
+		database:     "go_clean_arch",
+		timeout:      DefaultMongoOperationTimeout,
+		writeRetries: DefaultMemoryLogWriteRetries,
+		retryBackoff: defaultWriteRetryBackoff,
+	}
+}
+
+// SetOperationTimeout changes how long each operation may take; zero or less
+// leaves operations bounded only by the caller's context
+func (r *MemoryLogRepository) SetOperationTimeout(timeout time.Duration) {
+	r.timeout = timeout
+}
+
+// SetWriteRetries changes how many times Create retries an insert that failed
+// with a retryable error; zero or less disables retries
+func (r *MemoryLogRepository) SetWriteRetries(retries int) {
+	r.writeRetries = retries
+}
+
+// SetMonthlyBuckets switches between storing every memory log in the
+// memory_logs collection and storing each in a collection for the month it
+// was recorded in, such as memory_logs_2024_06, which spreads the writes of
+// busy deployments and lets old months be dropped whole. Logs already stored
+// in the other layout are not moved.
+func (r *MemoryLogRepository) SetMonthlyBuckets(enabled bool) {
+	r.monthlyBuckets = enabled
+	if enabled && r.indexedBuckets == nil {
+		r.indexedBuckets = &sync.Map{}
+	}
+}
+
+// memoryLogBucket returns the name of the monthly bucket for memory logs
+// recorded at t, taking months in UTC
+func memoryLogBucket(t time.Time) string {
+	return memoryLogCollection + "_" + t.UTC().Format(memoryLogBucketLayout)
+}
+
+// memoryLogBucketMonth returns the start of the month a bucket holds, and
+// false for names that are not monthly buckets
+func memoryLogBucketMonth(name string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(name, memoryLogCollection+"_")
+	if !ok {
+		return time.Time{}, false
+	}
+	month, err := time.Parse(memoryLogBucketLayout, suffix)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return month, true
+}
+
+// collection returns the named collection in the memory logs database
+func (r *MemoryLogRepository) collection(name string) Collection {
+	return r.collections.GetCollection(r.database, name)
+}
+
+// collectionFor returns the name of the collection memory logs recorded at t
+// are stored in
+func (r *MemoryLogRepository) collectionFor(t time.Time) string {
+	if r.monthlyBuckets {
+		return memoryLogBucket(t)
+	}
+	return memoryLogCollection
+}
+
+// collectionsBetween returns the names of the collections that may hold
+// memory logs recorded between start and end, oldest first. A zero start or
+// end leaves that side of the range open.
+func (r *MemoryLogRepository) collectionsBetween(ctx context.Context, start, end time.Time) ([]string, error) {
+	if !r.monthlyBuckets {
+		return []string{memoryLogCollection}, nil
+	}
+
+	names, err := r.collections.CollectionNames(ctx, r.database)
+	if err != nil {
+		return nil, err
+	}
+	var buckets []string
+	for _, name := range names {
+		month, ok := memoryLogBucketMonth(name)
+		if !ok {
+			continue
+		}
+		if (!end.IsZero() && month.After(end)) || (!start.IsZero() && !month.AddDate(0, 1, 0).After(start)) {
+			continue
+		}
+		buckets = append(buckets, name)
+	}
+	// Zero-padded months sort chronologically
+	sort.Strings(buckets)
+	return buckets, nil
+}
+
+// operationContext derives the context of a single operation from ctx by
+// applying the operation timeout
+func (r *MemoryLogRepository) operationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.timeout > 0 {
+		return context.WithTimeout(ctx, r.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// inTransaction joins ctx to the transaction the repository is bound to, if
+// any. Bucket listing and index creation use ctx as is, since MongoDB does
+// not allow them inside transactions.
+func (r *MemoryLogRepository) inTransaction(ctx context.Context) context.Context {
+	if r.ctx != nil {
+		if session := mongo.SessionFromContext(r.ctx); session != nil {
+			return mongo.NewSessionContext(ctx, session)
+		}
+	}
+	return ctx
+}
+
+// WithTransaction runs fn with a repository whose operations share a single
+// MongoDB transaction, so they are committed or rolled back together. On a
+// standalone server the operations run without a transaction.
+func (r *MemoryLogRepository) WithTransaction(ctx context.Context, fn func(tx *MemoryLogRepository) error) error {
+	return r.collections.WithTransaction(ctx, func(txCtx context.Context) error {
+		tx := *r
+		tx.ctx = txCtx
+		return fn(&tx)
+	})
+}
+
+// EnsureIndexes creates the indexes used by memory log queries, on every
+// monthly bucket when bucketing is enabled. It is safe to call repeatedly, as
+// MongoDB ignores an identical existing index. Collections without index
+// management, such as fakes, are left alone.
+func (r *MemoryLogRepository) EnsureIndexes(ctx context.Context) error {
+	names, err := r.collectionsBetween(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := r.ensureCollectionIndexes(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureCollectionIndexes creates the memory log indexes on one collection
+func (r *MemoryLogRepository) ensureCollectionIndexes(ctx context.Context, name string) error {
+	collection, ok := r.collection(name).(indexedCollection)
+	if !ok {
+		return nil
+	}
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "timestamp", Value: 1}},
+		Options: options.Index().SetName(memoryLogTimestampIndex),
+	})
+	return err
+}
+
+// ensureBucketIndexes creates the indexes of a monthly bucket the first time
+// this process writes to it, so buckets started by a new month are indexed
+// too. A failure is retried on the next write.
+func (r *MemoryLogRepository) ensureBucketIndexes(ctx context.Context, name string) error {
+	if !r.monthlyBuckets {
+		return nil
+	}
+	if _, done := r.indexedBuckets.Load(name); done {
+		return nil
+	}
+	if err := r.ensureCollectionIndexes(ctx, name); err != nil {
+		return err
+	}
+	r.indexedBuckets.Store(name, true)
+	return nil
+}
+
+// Create inserts a new memory log into MongoDB. Inserts failing with an
+// error MongoDB labels as retryable, such as during a primary stepdown, are
+// retried with exponential backoff; other errors are returned at once. A
+// retry rejected as a duplicate means an attempt that reported an error was
+// stored after all, so it counts as success. Inside a transaction nothing is
+// retried, as the whole transaction has to be.
+func (r *MemoryLogRepository) Create(ctx context.Context, memoryLog *entity.MemoryLog) error {
+	setMemoryLogDefaults(memoryLog)
+
+	ctx, cancel := r.operationContext(ctx)
+	defer cancel()
+
+	name := r.collectionFor(memoryLog.Timestamp)
+	if err := r.ensureBucketIndexes(ctx, name); err != nil {
+		return err
+	}
+
+	backoff := r.retryBackoff
+	for attempt := 0; ; attempt++ {
+		_, err := r.collection(name).InsertOne(r.inTransaction(ctx), memoryLog)
+		if attempt > 0 && mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		if err == nil || r.ctx != nil || attempt >= r.writeRetries || !isRetryableWriteError(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// isRetryableWriteError reports whether a write failed transiently, going by
+// the error labels MongoDB and the driver attach
+func isRetryableWriteError(err error) bool {
+	var labeled mongo.LabeledError
+	if !errors.As(err, &labeled) {
+		return false
+	}
+	return labeled.HasErrorLabel(retryableWriteErrorLabel) || labeled.HasErrorLabel(networkErrorLabel)
+}
+
+// CreateMany inserts multiple memory logs into MongoDB in a single batch per
+// collection. The insert is unordered, so one failing document does not stop
+// the rest. Documents rejected as duplicates were stored by an earlier
+// attempt that reported an error, so a bucket failing only with duplicate
+// keys counts as stored; any other failure is returned.
+func (r *MemoryLogRepository) CreateMany(ctx context.Context, memoryLogs []*entity.MemoryLog) error {
+	if len(memoryLogs) == 0 {
+		return nil
+	}
+
+	var names []string
+	documents := make(map[string][]interface{})
+	for _, memoryLog := range memoryLogs {
+		setMemoryLogDefaults(memoryLog)
+		name := r.collectionFor(memoryLog.Timestamp)
+		if documents[name] == nil {
+			names = append(names, name)
+		}
+		documents[name] = append(documents[name], memoryLog)
+	}
+
+	ctx, cancel := r.operationContext(ctx)
+	defer cancel()
+
+	var errs []error
+	for _, name := range names {
+		if err := r.ensureBucketIndexes(ctx, name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		_, err := r.collection(name).InsertMany(r.inTransaction(ctx), documents[name], options.InsertMany().SetOrdered(false))
+		if err != nil && !onlyDuplicateKeys(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
-// Create inserts a new memory log into MongoDB
-func (r *MemoryLogRepository) Create(memoryLog *entity.MemoryLog) error {
+// onlyDuplicateKeys reports whether every document of a failed insert was
+// rejected for a duplicate key, and nothing else went wrong
+func onlyDuplicateKeys(err error) bool {
+	var bulk mongo.BulkWriteException
+	if !errors.As(err, &bulk) {
+		return mongo.IsDuplicateKeyError(err)
+	}
+	if bulk.WriteConcernError != nil || len(bulk.WriteErrors) == 0 {
+		return false
+	}
+	for _, writeErr := range bulk.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeErr.WriteError) {
+			return false
+		}
+	}
+	return true
+}
+
+// setMemoryLogDefaults assigns an ID and timestamp when they are missing
+func setMemoryLogDefaults(memoryLog *entity.MemoryLog) {
 	// Generate a new ObjectID if ID is empty
 	if memoryLog.ID == "" {
 		memoryLog.ID = primitive.NewObjectID().Hex()
@@ -31,58 +361,104 @@ func (r *MemoryLogRepository) Create(memoryLog *entity.MemoryLog) error {
 	if memoryLog.Timestamp.IsZero() {
 		memoryLog.Timestamp = time.Now()
 	}
-
-	collection := r.mongo.GetCollection("go_clean_arch", "memory_logs")
-	_, err := collection.InsertOne(context.Background(), memoryLog)
-	return err
 }
 
-// FindByTimeRange finds memory logs within a time range
-func (r *MemoryLogRepository) FindByTimeRange(start, end time.Time) ([]*entity.MemoryLog, error) {
-	collection := r.mongo.GetCollection("go_clean_arch", "memory_logs")
-
-	filter := bson.M{
-		"timestamp": bson.M{
-			"$gte": start,
-			"$lte": end,
-		},
+// FindByID finds the memory log with the given hex ObjectID. With monthly
+// buckets, the buckets are searched newest first.
+func (r *MemoryLogRepository) FindByID(ctx context.Context, id string) (*entity.MemoryLog, error) {
+	if !primitive.IsValidObjectID(id) {
+		return nil, ErrInvalidMemoryLogID
 	}
 
-	cursor, err := collection.Find(context.Background(), filter)
+	ctx, cancel := r.operationContext(ctx)
+	defer cancel()
+
+	names, err := r.collectionsBetween(ctx, time.Time{}, time.Time{})
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	for i := len(names) - 1; i >= 0; i-- {
+		var memoryLog entity.MemoryLog
+		err := r.collection(names[i]).FindOne(r.inTransaction(ctx), bson.M{"_id": id}).Decode(&memoryLog)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &memoryLog, nil
+	}
 
-	var memoryLogs []*entity.MemoryLog
-	if err = cursor.All(context.Background(), &memoryLogs); err != nil {
+	return nil, ErrMemoryLogNotFound
+}
+
+// FindByTimeRange finds memory logs within a time range, oldest first
+func (r *MemoryLogRepository) FindByTimeRange(ctx context.Context, start, end time.Time) ([]*entity.MemoryLog, error) {
+	ctx, cancel := r.operationContext(ctx)
+	defer cancel()
+
+	names, err := r.collectionsBetween(ctx, start, end)
+	if err != nil {
 		return nil, err
 	}
 
-	return memoryLogs, nil
+	filter := bson.M{
+		"timestamp": bson.M{
+			"$gte": start,
+			"$lte": end,
+		},
+	}
+
+	return r.find(ctx, names, filter)
 }
 
 // FindAll retrieves all memory logs
-func (r *MemoryLogRepository) FindAll() ([]*entity.MemoryLog, error) {
-	collection := r.mongo.GetCollection("go_clean_arch", "memory_logs")
+func (r *MemoryLogRepository) FindAll(ctx context.Context) ([]*entity.MemoryLog, error) {
+	ctx, cancel := r.operationContext(ctx)
+	defer cancel()
 
-	cursor, err := collection.Find(context.Background(), bson.M{})
+	names, err := r.collectionsBetween(ctx, time.Time{}, time.Time{})
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+
+	return r.find(ctx, names, bson.M{})
+}
+
+// find returns the memory logs matching filter in each named collection in
+// turn, sorted by timestamp within each. With collections given oldest first,
+// as collectionsBetween returns them, the result is sorted overall.
+func (r *MemoryLogRepository) find(ctx context.Context, names []string, filter bson.M) ([]*entity.MemoryLog, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}})
 
 	var memoryLogs []*entity.MemoryLog
-	if err = cursor.All(context.Background(), &memoryLogs); err != nil {
-		return nil, err
+	for _, name := range names {
+		cursor, err := r.collection(name).Find(r.inTransaction(ctx), filter, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		var found []*entity.MemoryLog
+		err = cursor.All(ctx, &found)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+		memoryLogs = append(memoryLogs, found...)
 	}
 
 	return memoryLogs, nil
 }
 
 // DeleteOlderThan deletes memory logs older than a specific time
-func (r *MemoryLogRepository) DeleteOlderThan(olderThan time.Time) (int64, error) {
-	collection := r.mongo.GetCollection("go_clean_arch", "memory_logs")
+func (r *MemoryLogRepository) DeleteOlderThan(ctx context.Context, olderThan time.Time) (int64, error) {
+	ctx, cancel := r.operationContext(ctx)
+	defer cancel()
+
+	names, err := r.collectionsBetween(ctx, time.Time{}, olderThan)
+	if err != nil {
+		return 0, err
+	}
 
 	filter := bson.M{
 		"timestamp": bson.M{
@@ -90,10 +466,14 @@ func (r *MemoryLogRepository) DeleteOlderThan(olderThan time.Time) (int64, error
 		},
 	}
 
-	result, err := collection.DeleteMany(context.Background(), filter)
-	if err != nil {
-		return 0, err
+	var deleted int64
+	for _, name := range names {
+		result, err := r.collection(name).DeleteMany(r.inTransaction(ctx), filter)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += result.DeletedCount
 	}
 
-	return result.DeletedCount, nil
+	return deleted, nil
 }
@@ -0,0 +1,610 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/driver"
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// newTestMemoryLogRepository returns a repository backed by a throwaway database
+// on the MongoDB instance at MONGO_TEST_URL, skipping the test when it is unset
+func newTestMemoryLogRepository(t *testing.T) *MemoryLogRepository {
+	t.Helper()
+
+	mongoURL := os.Getenv("MONGO_TEST_URL")
+	if mongoURL == "" {
+		t.Skip("MONGO_TEST_URL not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURL))
+	require.NoError(t, err)
+	require.NoError(t, client.Ping(ctx, nil))
+
+	repo := &MemoryLogRepository{
+		collections: NewCollectionProvider(&driver.Mongo{Client: client}),
+		database:    fmt.Sprintf("go_clean_arch_test_%d", time.Now().UnixNano()),
+		timeout:     DefaultMongoOperationTimeout,
+	}
+	t.Cleanup(func() {
+		_ = client.Database(repo.database).Drop(context.Background())
+		_ = client.Disconnect(context.Background())
+	})
+
+	return repo
+}
+
+// testMongo returns the MongoDB connection behind a repository from newTestMemoryLogRepository
+func testMongo(repo *MemoryLogRepository) *driver.Mongo {
+	return repo.collections.(mongoProvider).Mongo
+}
+
+func TestSetMemoryLogDefaults(t *testing.T) {
+	memoryLog := &entity.MemoryLog{}
+
+	setMemoryLogDefaults(memoryLog)
+
+	assert.NotEmpty(t, memoryLog.ID)
+	assert.False(t, memoryLog.Timestamp.IsZero())
+}
+
+func TestSetMemoryLogDefaults_KeepsExistingValues(t *testing.T) {
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	memoryLog := &entity.MemoryLog{ID: "existing", Timestamp: timestamp}
+
+	setMemoryLogDefaults(memoryLog)
+
+	assert.Equal(t, "existing", memoryLog.ID)
+	assert.Equal(t, timestamp, memoryLog.Timestamp)
+}
+
+func TestMemoryLogRepository_CreateMany(t *testing.T) {
+	repo := newTestMemoryLogRepository(t)
+
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	memoryLogs := []*entity.MemoryLog{
+		{Alloc: 1},
+		{Alloc: 2, ID: "custom-id"},
+		{Alloc: 3, Timestamp: timestamp},
+	}
+
+	require.NoError(t, repo.CreateMany(context.Background(), memoryLogs))
+
+	count, err := testMongo(repo).GetCollection(repo.database, "memory_logs").CountDocuments(context.Background(), bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	stored, err := repo.FindAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, stored, 3)
+	for _, memoryLog := range stored {
+		assert.NotEmpty(t, memoryLog.ID)
+		assert.False(t, memoryLog.Timestamp.IsZero())
+	}
+
+	assert.Equal(t, "custom-id", memoryLogs[1].ID)
+	assert.True(t, memoryLogs[2].Timestamp.Equal(timestamp))
+}
+
+func TestMemoryLogRepository_CreateManyEmpty(t *testing.T) {
+	repo := &MemoryLogRepository{}
+
+	assert.NoError(t, repo.CreateMany(context.Background(), nil))
+}
+
+func TestMemoryLogRepository_EnsureIndexesIsIdempotent(t *testing.T) {
+	repo := newTestMemoryLogRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.EnsureIndexes(ctx))
+	require.NoError(t, repo.EnsureIndexes(ctx))
+
+	cursor, err := testMongo(repo).GetCollection(repo.database, "memory_logs").Indexes().List(ctx)
+	require.NoError(t, err)
+	var indexes []bson.M
+	require.NoError(t, cursor.All(ctx, &indexes))
+
+	var names []string
+	for _, index := range indexes {
+		names = append(names, index["name"].(string))
+	}
+	assert.ElementsMatch(t, []string{"_id_", memoryLogTimestampIndex}, names)
+}
+
+func TestMemoryLogRepository_MonthlyBucketsAreIndexed(t *testing.T) {
+	repo := newTestMemoryLogRepository(t)
+	repo.SetMonthlyBuckets(true)
+	ctx := context.Background()
+	june := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.CreateMany(ctx, []*entity.MemoryLog{
+		{Alloc: 1, Timestamp: june},
+		{Alloc: 2, Timestamp: june.AddDate(0, 1, 0)},
+	}))
+
+	found, err := repo.FindByTimeRange(ctx, june, june.AddDate(0, 1, 0))
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2}, memoryLogAllocs(found))
+
+	for _, bucket := range []string{"memory_logs_2024_06", "memory_logs_2024_07"} {
+		cursor, err := testMongo(repo).GetCollection(repo.database, bucket).Indexes().List(ctx)
+		require.NoError(t, err)
+		var indexes []bson.M
+		require.NoError(t, cursor.All(ctx, &indexes))
+		require.Len(t, indexes, 2, bucket)
+		assert.Equal(t, memoryLogTimestampIndex, indexes[1]["name"], bucket)
+	}
+}
+
+func TestMemoryLogRepository_FindByTimeRangeUsesTimestampIndex(t *testing.T) {
+	repo := newTestMemoryLogRepository(t)
+	ctx := context.Background()
+
+	require.NoError(t, repo.EnsureIndexes(ctx))
+	require.NoError(t, repo.CreateMany(ctx, []*entity.MemoryLog{{Alloc: 1}, {Alloc: 2}}))
+
+	start, end := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	explain := bson.D{
+		{Key: "explain", Value: bson.D{
+			{Key: "find", Value: "memory_logs"},
+			{Key: "filter", Value: bson.M{"timestamp": bson.M{"$gte": start, "$lte": end}}},
+		}},
+		{Key: "verbosity", Value: "queryPlanner"},
+	}
+	result, err := testMongo(repo).Client.Database(repo.database).RunCommand(ctx, explain).Raw()
+	require.NoError(t, err)
+
+	plan := result.Lookup("queryPlanner", "winningPlan").String()
+	assert.Contains(t, plan, "IXSCAN")
+	assert.Contains(t, plan, memoryLogTimestampIndex)
+}
+
+// newTestTransactionalMemoryLogRepository returns a test repository, skipping
+// the test unless MONGO_TEST_URL points at a replica set
+func newTestTransactionalMemoryLogRepository(t *testing.T) *MemoryLogRepository {
+	t.Helper()
+
+	repo := newTestMemoryLogRepository(t)
+	if !testMongo(repo).SupportsTransactions(context.Background()) {
+		t.Skip("MONGO_TEST_URL is not a replica set")
+	}
+
+	// Create the collection up front, as older servers cannot create it inside a transaction
+	require.NoError(t, repo.EnsureIndexes(context.Background()))
+	return repo
+}
+
+func TestMemoryLogRepository_WithTransactionCommits(t *testing.T) {
+	repo := newTestTransactionalMemoryLogRepository(t)
+	cutoff := time.Now().Add(-time.Hour)
+	require.NoError(t, repo.Create(context.Background(), &entity.MemoryLog{Alloc: 1, Timestamp: cutoff.Add(-time.Hour)}))
+
+	err := repo.WithTransaction(context.Background(), func(tx *MemoryLogRepository) error {
+		if _, err := tx.DeleteOlderThan(context.Background(), cutoff); err != nil {
+			return err
+		}
+		return tx.CreateMany(context.Background(), []*entity.MemoryLog{{Alloc: 2}, {Alloc: 3}})
+	})
+	require.NoError(t, err)
+
+	stored, err := repo.FindAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, stored, 2)
+}
+
+func TestMemoryLogRepository_WithTransactionRollsBack(t *testing.T) {
+	repo := newTestTransactionalMemoryLogRepository(t)
+	cutoff := time.Now().Add(-time.Hour)
+	require.NoError(t, repo.Create(context.Background(), &entity.MemoryLog{Alloc: 1, Timestamp: cutoff.Add(-time.Hour)}))
+
+	errAudit := errors.New("audit failed")
+	err := repo.WithTransaction(context.Background(), func(tx *MemoryLogRepository) error {
+		if _, err := tx.DeleteOlderThan(context.Background(), cutoff); err != nil {
+			return err
+		}
+		if err := tx.CreateMany(context.Background(), []*entity.MemoryLog{{Alloc: 2}}); err != nil {
+			return err
+		}
+		return errAudit
+	})
+	require.ErrorIs(t, err, errAudit)
+
+	stored, err := repo.FindAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, uint64(1), stored[0].Alloc)
+}
+
+func TestMemoryLogRepository_FindByID(t *testing.T) {
+	repo := newTestMemoryLogRepository(t)
+	memoryLog := &entity.MemoryLog{Alloc: 42}
+	require.NoError(t, repo.Create(context.Background(), memoryLog))
+
+	found, err := repo.FindByID(context.Background(), memoryLog.ID)
+	require.NoError(t, err)
+	assert.Equal(t, memoryLog.ID, found.ID)
+	assert.Equal(t, uint64(42), found.Alloc)
+}
+
+func TestMemoryLogRepository_FindByIDNotFound(t *testing.T) {
+	repo := newTestMemoryLogRepository(t)
+
+	_, err := repo.FindByID(context.Background(), primitive.NewObjectID().Hex())
+	assert.ErrorIs(t, err, ErrMemoryLogNotFound)
+}
+
+func TestMemoryLogRepository_FindByIDInvalidID(t *testing.T) {
+	repo := &MemoryLogRepository{}
+
+	for _, id := range []string{"", "not-hex", "123", "zzzzzzzzzzzzzzzzzzzzzzzz"} {
+		_, err := repo.FindByID(context.Background(), id)
+		assert.ErrorIs(t, err, ErrInvalidMemoryLogID, id)
+	}
+}
+
+// newFakeMemoryLogRepository returns a repository on an in-memory fake and its memory logs collection
+func newFakeMemoryLogRepository() (*MemoryLogRepository, *fakeCollection) {
+	collections := newFakeCollections()
+	repo := NewMemoryLogRepositoryWithProvider(collections)
+	return repo, collections.collection(repo.database, "memory_logs")
+}
+
+func TestMemoryLogRepository_CreateWithFake(t *testing.T) {
+	repo, collection := newFakeMemoryLogRepository()
+
+	memoryLog := &entity.MemoryLog{Alloc: 42}
+	require.NoError(t, repo.Create(context.Background(), memoryLog))
+
+	assert.True(t, primitive.IsValidObjectID(memoryLog.ID))
+	assert.False(t, memoryLog.Timestamp.IsZero())
+	require.Len(t, collection.documents, 1)
+
+	found, err := repo.FindByID(context.Background(), memoryLog.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), found.Alloc)
+
+	_, err = repo.FindByID(context.Background(), primitive.NewObjectID().Hex())
+	assert.ErrorIs(t, err, ErrMemoryLogNotFound)
+}
+
+func TestMemoryLogRepository_FindByTimeRangeWithFake(t *testing.T) {
+	repo, _ := newFakeMemoryLogRepository()
+	base := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, repo.Create(context.Background(), &entity.MemoryLog{Alloc: uint64(i), Timestamp: base.Add(time.Duration(i) * time.Hour)}))
+	}
+
+	found, err := repo.FindByTimeRange(context.Background(), base.Add(time.Hour), base.Add(3*time.Hour))
+	require.NoError(t, err)
+
+	var allocs []uint64
+	for _, memoryLog := range found {
+		allocs = append(allocs, memoryLog.Alloc)
+	}
+	assert.Equal(t, []uint64{1, 2, 3}, allocs, "both ends of the range are inclusive")
+
+	found, err = repo.FindByTimeRange(context.Background(), base.Add(-2*time.Hour), base.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestMemoryLogRepository_DeleteOlderThanWithFake(t *testing.T) {
+	repo, collection := newFakeMemoryLogRepository()
+	base := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, repo.Create(context.Background(), &entity.MemoryLog{Alloc: uint64(i), Timestamp: base.Add(time.Duration(i) * time.Hour)}))
+	}
+
+	deleted, err := repo.DeleteOlderThan(context.Background(), base.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted, "the cutoff itself is kept")
+
+	remaining, err := repo.FindAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, remaining, 2)
+	assert.Equal(t, uint64(2), remaining[0].Alloc)
+	assert.Len(t, collection.documents, 2)
+}
+
+func TestMemoryLogRepository_PropagatesCollectionErrors(t *testing.T) {
+	repo, collection := newFakeMemoryLogRepository()
+	errUnavailable := errors.New("server selection timeout")
+	collection.err = errUnavailable
+
+	assert.ErrorIs(t, repo.Create(context.Background(), &entity.MemoryLog{}), errUnavailable)
+
+	_, err := repo.FindByTimeRange(context.Background(), time.Now().Add(-time.Hour), time.Now())
+	assert.ErrorIs(t, err, errUnavailable)
+
+	_, err = repo.DeleteOlderThan(context.Background(), time.Now())
+	assert.ErrorIs(t, err, errUnavailable)
+}
+
+func TestMemoryLogRepository_EnsureIndexesSkipsFakes(t *testing.T) {
+	repo, _ := newFakeMemoryLogRepository()
+
+	assert.NoError(t, repo.EnsureIndexes(context.Background()))
+}
+
+func TestMemoryLogRepository_CancelledContext(t *testing.T) {
+	repo, collection := newFakeMemoryLogRepository()
+	collection.hang = true
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	started := time.Now()
+	assert.ErrorIs(t, repo.Create(ctx, &entity.MemoryLog{}), context.Canceled)
+	assert.ErrorIs(t, repo.CreateMany(ctx, []*entity.MemoryLog{{}}), context.Canceled)
+	_, err := repo.FindByID(ctx, primitive.NewObjectID().Hex())
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = repo.FindByTimeRange(ctx, time.Now().Add(-time.Hour), time.Now())
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = repo.FindAll(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = repo.DeleteOlderThan(ctx, time.Now())
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.Less(t, time.Since(started), time.Second)
+	assert.Empty(t, collection.documents)
+}
+
+func TestMemoryLogRepository_OperationTimeout(t *testing.T) {
+	repo, collection := newFakeMemoryLogRepository()
+	collection.hang = true
+	repo.SetOperationTimeout(20 * time.Millisecond)
+
+	started := time.Now()
+	_, err := repo.FindByTimeRange(context.Background(), time.Now().Add(-time.Hour), time.Now())
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(started), time.Second)
+}
+
+func TestMemoryLogBucket(t *testing.T) {
+	tests := []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC), "memory_logs_2024_06"},
+		{time.Date(2024, 6, 30, 23, 59, 59, 0, time.UTC), "memory_logs_2024_06"},
+		{time.Date(2024, 12, 31, 23, 0, 0, 0, time.UTC), "memory_logs_2024_12"},
+		{time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), "memory_logs_2025_01"},
+		// Months are taken in UTC, whatever the timestamp's zone
+		{time.Date(2024, 7, 1, 1, 0, 0, 0, time.FixedZone("CEST", 2*60*60)), "memory_logs_2024_06"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, memoryLogBucket(tt.at), tt.at.String())
+	}
+}
+
+func TestMemoryLogBucketMonth(t *testing.T) {
+	month, ok := memoryLogBucketMonth("memory_logs_2024_06")
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), month)
+
+	for _, name := range []string{"memory_logs", "memory_logs_2024_13", "memory_logs_2024_6", "audit_logs", "heap_profiles_2024_06"} {
+		_, ok := memoryLogBucketMonth(name)
+		assert.False(t, ok, name)
+	}
+}
+
+// newFakeBucketedMemoryLogRepository returns a repository writing monthly
+// buckets on an in-memory fake, and the fake's collections
+func newFakeBucketedMemoryLogRepository() (*MemoryLogRepository, *fakeCollections) {
+	collections := newFakeCollections()
+	repo := NewMemoryLogRepositoryWithProvider(collections)
+	repo.SetMonthlyBuckets(true)
+	return repo, collections
+}
+
+func TestMemoryLogRepository_CreateManyDuplicatesPerBucket(t *testing.T) {
+	ctx := context.Background()
+	june := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	july := june.AddDate(0, 1, 0)
+	duplicate := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+		{WriteError: mongo.WriteError{Code: 11000, Message: "duplicate key"}},
+	}}
+	network := mongo.CommandError{Code: 6, Message: "host unreachable", Labels: []string{"NetworkError"}}
+
+	t.Run("only duplicates", func(t *testing.T) {
+		repo, collections := newFakeBucketedMemoryLogRepository()
+		collections.collection(repo.database, "memory_logs_2024_06").errs = []error{duplicate}
+
+		assert.NoError(t, repo.CreateMany(ctx, []*entity.MemoryLog{{Timestamp: june}, {Timestamp: july}}))
+	})
+
+	t.Run("duplicates and another failure", func(t *testing.T) {
+		repo, collections := newFakeBucketedMemoryLogRepository()
+		collections.collection(repo.database, "memory_logs_2024_06").errs = []error{duplicate}
+		collections.collection(repo.database, "memory_logs_2024_07").errs = []error{network}
+
+		err := repo.CreateMany(ctx, []*entity.MemoryLog{{Timestamp: june}, {Timestamp: july}})
+
+		require.Error(t, err)
+		assert.False(t, mongo.IsDuplicateKeyError(err), "the failed bucket must not pass for a duplicate")
+	})
+
+	t.Run("duplicate mixed with another write error", func(t *testing.T) {
+		repo, collections := newFakeBucketedMemoryLogRepository()
+		mixed := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+			{WriteError: mongo.WriteError{Code: 11000, Message: "duplicate key"}},
+			{WriteError: mongo.WriteError{Code: 121, Message: "document failed validation"}},
+		}}
+		collections.collection(repo.database, "memory_logs_2024_06").errs = []error{mixed}
+
+		assert.Error(t, repo.CreateMany(ctx, []*entity.MemoryLog{{Timestamp: june}}))
+	})
+}
+
+func TestMemoryLogRepository_MonthlyBucketsWithFake(t *testing.T) {
+	repo, collections := newFakeBucketedMemoryLogRepository()
+	ctx := context.Background()
+	may := &entity.MemoryLog{Alloc: 1, Timestamp: time.Date(2024, 5, 31, 23, 0, 0, 0, time.UTC)}
+	require.NoError(t, repo.Create(ctx, may))
+	require.NoError(t, repo.CreateMany(ctx, []*entity.MemoryLog{
+		{Alloc: 2, Timestamp: time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)},
+		{Alloc: 3, Timestamp: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{Alloc: 4, Timestamp: time.Date(2024, 8, 10, 0, 0, 0, 0, time.UTC)},
+	}))
+
+	for _, bucket := range []string{"memory_logs_2024_05", "memory_logs_2024_06", "memory_logs_2024_07", "memory_logs_2024_08"} {
+		assert.Len(t, collections.collection(repo.database, bucket).documents, 1, bucket)
+	}
+	names, err := collections.CollectionNames(ctx, repo.database)
+	require.NoError(t, err)
+	assert.NotContains(t, names, "memory_logs")
+
+	found, err := repo.FindByID(ctx, may.ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), found.Alloc)
+	_, err = repo.FindByID(ctx, primitive.NewObjectID().Hex())
+	assert.ErrorIs(t, err, ErrMemoryLogNotFound)
+
+	deleted, err := repo.DeleteOlderThan(ctx, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	remaining, err := repo.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{3, 4}, memoryLogAllocs(remaining))
+}
+
+func TestMemoryLogRepository_FindByTimeRangeAcrossBucketsWithFake(t *testing.T) {
+	repo, _ := newFakeBucketedMemoryLogRepository()
+	ctx := context.Background()
+	// Start later months first, so bucket creation order differs from time order
+	for _, at := range []time.Time{
+		time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 30, 22, 0, 0, 0, time.UTC),
+		time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC),
+		time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC),
+	} {
+		require.NoError(t, repo.Create(ctx, &entity.MemoryLog{Alloc: uint64(at.Unix()), Timestamp: at}))
+	}
+
+	found, err := repo.FindByTimeRange(ctx, time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC), time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	var times []time.Time
+	for _, memoryLog := range found {
+		times = append(times, memoryLog.Timestamp.UTC())
+	}
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC),
+		time.Date(2024, 7, 15, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC),
+	}, times, "logs from every overlapping bucket, oldest first")
+
+	found, err = repo.FindByTimeRange(ctx, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Len(t, found, 5, "a range spanning a year boundary")
+
+	found, err = repo.FindByTimeRange(ctx, time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func memoryLogAllocs(memoryLogs []*entity.MemoryLog) []uint64 {
+	allocs := make([]uint64, len(memoryLogs))
+	for i, memoryLog := range memoryLogs {
+		allocs[i] = memoryLog.Alloc
+	}
+	return allocs
+}
+
+// errStepdown is the error a write gets while the primary steps down
+var errStepdown = mongo.CommandError{
+	Code:   11602,
+	Name:   "InterruptedDueToReplStateChange",
+	Labels: []string{"RetryableWriteError"},
+}
+
+func TestMemoryLogRepository_CreateRetriesTransientErrors(t *testing.T) {
+	repo, collection := newFakeMemoryLogRepository()
+	repo.retryBackoff = time.Millisecond
+	collection.errs = []error{
+		errStepdown,
+		mongo.WriteException{Labels: []string{"NetworkError"}},
+	}
+
+	require.NoError(t, repo.Create(context.Background(), &entity.MemoryLog{Alloc: 1}))
+
+	assert.Equal(t, 3, collection.calls)
+	assert.Len(t, collection.documents, 1)
+}
+
+func TestMemoryLogRepository_CreateRetryRejectedAsDuplicateSucceeds(t *testing.T) {
+	repo, collection := newFakeMemoryLogRepository()
+	repo.retryBackoff = time.Millisecond
+	// The first insert reached MongoDB before the connection dropped
+	collection.errs = []error{
+		mongo.WriteException{Labels: []string{"NetworkError"}},
+		mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "duplicate key"}}},
+	}
+
+	assert.NoError(t, repo.Create(context.Background(), &entity.MemoryLog{Alloc: 1}))
+	assert.Equal(t, 2, collection.calls)
+}
+
+func TestMemoryLogRepository_CreateFailsPermanentErrorsAtOnce(t *testing.T) {
+	repo, collection := newFakeMemoryLogRepository()
+	repo.retryBackoff = time.Millisecond
+	duplicate := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "duplicate key"}}}
+	collection.errs = []error{duplicate}
+
+	err := repo.Create(context.Background(), &entity.MemoryLog{})
+
+	assert.ErrorAs(t, err, &mongo.WriteException{})
+	assert.Equal(t, 1, collection.calls)
+	assert.Empty(t, collection.documents)
+}
+
+func TestMemoryLogRepository_CreateGivesUpAfterRetries(t *testing.T) {
+	repo, collection := newFakeMemoryLogRepository()
+	repo.retryBackoff = time.Millisecond
+	repo.SetWriteRetries(2)
+	collection.err = errStepdown
+
+	err := repo.Create(context.Background(), &entity.MemoryLog{})
+
+	assert.True(t, isRetryableWriteError(err), "the last error is returned")
+	assert.Equal(t, 3, collection.calls, "the first attempt and two retries")
+}
+
+func TestMemoryLogRepository_CreateWithoutRetries(t *testing.T) {
+	repo, collection := newFakeMemoryLogRepository()
+	repo.SetWriteRetries(0)
+	collection.errs = []error{errStepdown}
+
+	assert.True(t, isRetryableWriteError(repo.Create(context.Background(), &entity.MemoryLog{})))
+	assert.Equal(t, 1, collection.calls)
+}
+
+func TestMemoryLogRepository_CreateStopsRetryingWhenContextDone(t *testing.T) {
+	repo, collection := newFakeMemoryLogRepository()
+	repo.retryBackoff = time.Minute
+	repo.SetOperationTimeout(20 * time.Millisecond)
+	collection.err = errStepdown
+
+	started := time.Now()
+	err := repo.Create(context.Background(), &entity.MemoryLog{})
+
+	assert.True(t, isRetryableWriteError(err))
+	assert.Equal(t, 1, collection.calls)
+	assert.Less(t, time.Since(started), time.Second)
+}
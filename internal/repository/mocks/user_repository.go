@@ -0,0 +1,91 @@
+// Package mocks provides testify mocks of the repository interfaces
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockUserRepository is a testify mock implementing repository.UserRepository
+type MockUserRepository struct {
+	mock.Mock
+}
+
+// NewMockUserRepository creates a mock whose expectations are asserted when the test ends
+func NewMockUserRepository(t mock.TestingT) *MockUserRepository {
+	m := &MockUserRepository{}
+	m.Test(t)
+	if c, ok := t.(interface{ Cleanup(func()) }); ok {
+		c.Cleanup(func() { m.AssertExpectations(t) })
+	}
+	return m
+}
+
+// Create mocks UserRepository.Create
+func (m *MockUserRepository) Create(ctx context.Context, user *entity.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+// GetByID mocks UserRepository.GetByID
+func (m *MockUserRepository) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	args := m.Called(ctx, id)
+	user, _ := args.Get(0).(*entity.User)
+	return user, args.Error(1)
+}
+
+// GetByEmail mocks UserRepository.GetByEmail
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	args := m.Called(ctx, email)
+	user, _ := args.Get(0).(*entity.User)
+	return user, args.Error(1)
+}
+
+// GetAll mocks UserRepository.GetAll
+func (m *MockUserRepository) GetAll(ctx context.Context) ([]entity.User, error) {
+	args := m.Called(ctx)
+	users, _ := args.Get(0).([]entity.User)
+	return users, args.Error(1)
+}
+
+// Update mocks UserRepository.Update
+func (m *MockUserRepository) Update(ctx context.Context, user *entity.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+// UpdateIfUnchanged mocks UserRepository.UpdateIfUnchanged
+func (m *MockUserRepository) UpdateIfUnchanged(ctx context.Context, user *entity.User, updatedAt time.Time) error {
+	args := m.Called(ctx, user, updatedAt)
+	return args.Error(0)
+}
+
+// Delete mocks UserRepository.Delete
+func (m *MockUserRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// Count mocks UserRepository.Count
+func (m *MockUserRepository) Count(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+// Find mocks UserRepository.Find
+func (m *MockUserRepository) Find(ctx context.Context, filter repository.UserFilter, page, limit int) ([]entity.User, error) {
+	args := m.Called(ctx, filter, page, limit)
+	users, _ := args.Get(0).([]entity.User)
+	return users, args.Error(1)
+}
+
+// WithTransaction runs fn against the mock itself, so expectations set on the
+// mock apply inside the transaction
+func (m *MockUserRepository) WithTransaction(ctx context.Context, fn func(tx repository.UserRepository) error) error {
+	return fn(m)
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/example/go-clean-architecture/internal/driver"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection is the subset of *mongo.Collection used by the memory log
+// repository, so it can be tested against a fake
+type Collection interface {
+	InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error)
+	InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error)
+	FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult
+	Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error)
+	DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+}
+
+// CollectionProvider hands out and lists collections and runs multi-document
+// transactions
+type CollectionProvider interface {
+	GetCollection(database, collection string) Collection
+	CollectionNames(ctx context.Context, database string) ([]string, error)
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// indexedCollection is implemented by collections that manage indexes, such
+// as *mongo.Collection
+type indexedCollection interface {
+	Indexes() mongo.IndexView
+}
+
+// mongoProvider adapts *driver.Mongo to CollectionProvider
+type mongoProvider struct {
+	*driver.Mongo
+}
+
+// NewCollectionProvider returns a CollectionProvider backed by a MongoDB connection
+func NewCollectionProvider(mongo *driver.Mongo) CollectionProvider {
+	return mongoProvider{mongo}
+}
+
+// GetCollection returns a MongoDB collection
+func (p mongoProvider) GetCollection(database, collection string) Collection {
+	return p.Mongo.GetCollection(database, collection)
+}
+
+// CollectionNames returns the names of the collections in a database
+func (p mongoProvider) CollectionNames(ctx context.Context, database string) ([]string, error) {
+	return p.Client.Database(database).ListCollectionNames(ctx, bson.D{})
+}
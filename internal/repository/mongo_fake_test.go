@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// fakeCollections is an in-memory CollectionProvider. Transactions run fn
+// directly and are not rolled back.
+type fakeCollections struct {
+	mu          sync.Mutex
+	collections map[string]*fakeCollection
+}
+
+func newFakeCollections() *fakeCollections {
+	return &fakeCollections{collections: make(map[string]*fakeCollection)}
+}
+
+// GetCollection returns the named collection, creating it empty on first use
+func (f *fakeCollections) GetCollection(database, collection string) Collection {
+	return f.collection(database, collection)
+}
+
+func (f *fakeCollections) collection(database, collection string) *fakeCollection {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := database + "." + collection
+	if f.collections[name] == nil {
+		f.collections[name] = &fakeCollection{}
+	}
+	return f.collections[name]
+}
+
+// CollectionNames returns the names of the database's collections, in no
+// particular order, like MongoDB
+func (f *fakeCollections) CollectionNames(ctx context.Context, database string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var names []string
+	for name := range f.collections {
+		if collection, ok := strings.CutPrefix(name, database+"."); ok {
+			names = append(names, collection)
+		}
+	}
+	return names, nil
+}
+
+func (f *fakeCollections) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakeCollection keeps documents in insertion order. Filters support field
+// equality and the $gt, $gte, $lt and $lte operators on time values. When err
+// is set every operation fails with it; errs are returned first, one per
+// operation. Like MongoDB, operations fail with the context's error once it
+// is done; when hang is set they wait for that, as against an unresponsive
+// server.
+type fakeCollection struct {
+	mu        sync.Mutex
+	documents []bson.Raw
+	err       error
+	errs      []error
+	calls     int
+	hang      bool
+}
+
+// nextErr counts an operation and returns the error it should fail with, if
+// any. The caller holds mu.
+func (c *fakeCollection) nextErr() error {
+	c.calls++
+	if len(c.errs) > 0 {
+		err := c.errs[0]
+		c.errs = c.errs[1:]
+		return err
+	}
+	return c.err
+}
+
+// wait blocks a hanging collection until ctx is done and returns ctx's error
+func (c *fakeCollection) wait(ctx context.Context) error {
+	if c.hang {
+		<-ctx.Done()
+	}
+	return ctx.Err()
+}
+
+func (c *fakeCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	if _, err := c.InsertMany(ctx, []interface{}{document}); err != nil {
+		return nil, err
+	}
+	return &mongo.InsertOneResult{}, nil
+}
+
+func (c *fakeCollection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.nextErr(); err != nil {
+		return nil, err
+	}
+	for _, document := range documents {
+		raw, err := bson.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+		c.documents = append(c.documents, raw)
+	}
+	return &mongo.InsertManyResult{}, nil
+}
+
+func (c *fakeCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	documents, err := c.Find(ctx, filter)
+	if err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	defer documents.Close(ctx)
+	if !documents.Next(ctx) {
+		return mongo.NewSingleResultFromDocument(bson.D{}, mongo.ErrNoDocuments, nil)
+	}
+	return mongo.NewSingleResultFromDocument(documents.Current, nil, nil)
+}
+
+func (c *fakeCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.nextErr(); err != nil {
+		return nil, err
+	}
+	var found []interface{}
+	for _, document := range c.documents {
+		ok, err := fakeMatches(document, filter)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			found = append(found, document)
+		}
+	}
+	return mongo.NewCursorFromDocuments(found, nil, nil)
+}
+
+func (c *fakeCollection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.nextErr(); err != nil {
+		return nil, err
+	}
+	kept := c.documents[:0]
+	for _, document := range c.documents {
+		ok, err := fakeMatches(document, filter)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			kept = append(kept, document)
+		}
+	}
+	deleted := len(c.documents) - len(kept)
+	c.documents = kept
+	return &mongo.DeleteResult{DeletedCount: int64(deleted)}, nil
+}
+
+// fakeMatches reports whether document satisfies a bson.M filter
+func fakeMatches(document bson.Raw, filter interface{}) (bool, error) {
+	conditions, ok := filter.(bson.M)
+	if !ok {
+		return false, fmt.Errorf("fake collection: unsupported filter type %T", filter)
+	}
+	for field, condition := range conditions {
+		value, err := document.LookupErr(field)
+		if err != nil {
+			return false, nil
+		}
+		operators, ok := condition.(bson.M)
+		if !ok {
+			_, want, err := bson.MarshalValue(condition)
+			if err != nil {
+				return false, err
+			}
+			if string(value.Value) != string(want) {
+				return false, nil
+			}
+			continue
+		}
+		for operator, operand := range operators {
+			bound, ok := operand.(time.Time)
+			if !ok {
+				return false, fmt.Errorf("fake collection: %s supports only time values", operator)
+			}
+			at := value.Time()
+			var match bool
+			switch operator {
+			case "$gt":
+				match = at.After(bound)
+			case "$gte":
+				match = !at.Before(bound)
+			case "$lt":
+				match = at.Before(bound)
+			case "$lte":
+				match = !at.After(bound)
+			default:
+				return false, fmt.Errorf("fake collection: unsupported operator %s", operator)
+			}
+			if !match {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
@@ -1,29 +1,242 @@
 package repository
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/driver"
 	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
-	Create(user *entity.User) error
-	GetByID(id uint) (*entity.User, error)
-	GetByEmail(email string) (*entity.User, error)
-	GetAll() ([]entity.User, error)
-	Update(user *entity.User) error
-	Delete(id uint) error
+	Create(ctx context.Context, user *entity.User) error
+	GetByID(ctx context.Context, id uint) (*entity.User, error)
+	GetByEmail(ctx context.Context, email string) (*entity.User, error)
+	GetAll(ctx context.Context) ([]entity.User, error)
+	Update(ctx context.Context, user *entity.User) error
+	UpdateIfUnchanged(ctx context.Context, user *entity.User, updatedAt time.Time) error
+	Delete(ctx context.Context, id uint) error
+	Count(ctx context.Context) (int64, error)
+	Find(ctx context.Context, filter UserFilter, page, limit int) ([]entity.User, error)
+	WithTransaction(ctx context.Context, fn func(tx UserRepository) error) error
+}
+
+// ErrInvalidDateRange is returned when a filter's creation range starts after it ends
+var ErrInvalidDateRange = errors.New("createdFrom must not be after createdTo")
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// ErrUserChanged is returned by UpdateIfUnchanged when the stored user was
+// updated or deleted after the given version was read
+var ErrUserChanged = errors.New("user has changed since it was read")
+
+// ErrServiceUnavailable is returned, wrapping the database error, for writes
+// rejected because PostgreSQL is read-only, as it briefly is during a failover
+var ErrServiceUnavailable = errors.New("database is temporarily read-only")
+
+// sqlStateReadOnlyTransaction is the SQLSTATE of writes rejected by a
+// read-only server or transaction
+const sqlStateReadOnlyTransaction = "25006"
+
+// isReadOnlyError reports whether PostgreSQL rejected a write for being read-only
+func isReadOnlyError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == sqlStateReadOnlyTransaction
+}
+
+// writeError marks read-only rejections with ErrServiceUnavailable and
+// returns other errors unchanged
+func writeError(err error) error {
+	if isReadOnlyError(err) && !errors.Is(err, ErrServiceUnavailable) {
+		return fmt.Errorf("%w: %w", ErrServiceUnavailable, err)
+	}
+	return err
+}
+
+// UserFilter narrows a user listing; zero-valued fields are ignored.
+// When After is set the listing continues after that cursor and the page is ignored.
+type UserFilter struct {
+	Email       string
+	Query       string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	After       *UserCursor
+}
+
+// UserCursor marks a position in a user listing ordered by creation time and ID
+type UserCursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// NewUserCursor returns the cursor positioned at the given user
+func NewUserCursor(user entity.UserResponse) UserCursor {
+	return UserCursor{CreatedAt: user.CreatedAt, ID: user.ID}
+}
+
+// Encode returns the cursor as an opaque URL-safe string
+func (c UserCursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + strconv.FormatUint(uint64(c.ID), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeUserCursor parses a cursor produced by UserCursor.Encode
+func DecodeUserCursor(s string) (UserCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return UserCursor{}, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return UserCursor{}, ErrInvalidCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return UserCursor{}, ErrInvalidCursor
+	}
+	userID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return UserCursor{}, ErrInvalidCursor
+	}
+	return UserCursor{CreatedAt: time.Unix(0, unixNano).UTC(), ID: uint(userID)}, nil
+}
+
+// before reports whether the cursor sorts before the user in listing order
+func (c UserCursor) before(user entity.User) bool {
+	if !c.CreatedAt.Equal(user.CreatedAt) {
+		return c.CreatedAt.Before(user.CreatedAt)
+	}
+	return c.ID < user.ID
+}
+
+// Validate reports whether the filter's creation date range is well formed
+func (f UserFilter) Validate() error {
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedFrom.After(*f.CreatedTo) {
+		return ErrInvalidDateRange
+	}
+	return nil
+}
+
+// Matches reports whether the user satisfies every condition of the filter
+func (f UserFilter) Matches(user entity.User) bool {
+	if f.Email != "" && user.Email != f.Email {
+		return false
+	}
+	if f.Query != "" && !strings.Contains(strings.ToLower(user.Name), strings.ToLower(f.Query)) {
+		return false
+	}
+	if f.CreatedFrom != nil && user.CreatedAt.Before(*f.CreatedFrom) {
+		return false
+	}
+	if f.CreatedTo != nil && user.CreatedAt.After(*f.CreatedTo) {
+		return false
+	}
+	if f.After != nil && !f.After.before(user) {
+		return false
+	}
+	return true
+}
+
+// conditions builds the SQL WHERE clause and arguments for the filter
+func (f UserFilter) conditions() []interface{} {
+	var clauses []string
+	var args []interface{}
+	if f.Email != "" {
+		clauses = append(clauses, "email = ?")
+		args = append(args, f.Email)
+	}
+	if f.Query != "" {
+		clauses = append(clauses, "name ILIKE ?")
+		args = append(args, "%"+likeEscaper.Replace(f.Query)+"%")
+	}
+	if f.CreatedFrom != nil {
+		clauses = append(clauses, "created_at >= ?")
+		args = append(args, *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		clauses = append(clauses, "created_at <= ?")
+		args = append(args, *f.CreatedTo)
+	}
+	if f.After != nil {
+		clauses = append(clauses, "(created_at, id) > (?, ?)")
+		args = append(args, f.After.CreatedAt, f.After.ID)
+	}
+	if len(clauses) == 0 {
+		return nil
+	}
+	return append([]interface{}{strings.Join(clauses, " AND ")}, args...)
+}
+
+// likeEscaper escapes LIKE wildcards so a search query matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// primaryReadsContextKey marks contexts whose user lookups read from the primary
+type primaryReadsContextKey struct{}
+
+// ContextWithPrimaryReads returns a copy of ctx whose user lookups read from
+// the primary, bypassing replicas and caches. Reads that a write is based on
+// use it, so they never act on a lagging or stale copy of the user.
+func ContextWithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsContextKey{}, true)
+}
+
+// primaryReads reports whether ctx asks for user lookups on the primary
+func primaryReads(ctx context.Context) bool {
+	enabled, _ := ctx.Value(primaryReadsContextKey{}).(bool)
+	return enabled
 }
 
-// userRepository implements UserRepository interface
+// userRepository implements UserRepository interface, building the basic
+// operations on GormRepository. Lookups by ID or email and GetAll read from
+// the database's reader, taking turns between replicas query by query, unless
+// the context asks for primary reads; everything else, including counts
+// guarding writes, uses the primary. Queries run with the caller's context.
 type userRepository struct {
-	db Database
+	db                 Database
+	readOnlyRetries    int
+	readOnlyRetryDelay time.Duration
+}
+
+// UserRepositoryOption configures the user repository
+type UserRepositoryOption func(*userRepository)
+
+// WithReadOnlyRetries retries writes rejected because the database is
+// read-only up to retries times, delay apart, to ride out a failover before
+// giving up with ErrServiceUnavailable. Transactions are retried as a whole,
+// so their function may run more than once.
+func WithReadOnlyRetries(retries int, delay time.Duration) UserRepositoryOption {
+	return func(r *userRepository) {
+		r.readOnlyRetries = retries
+		r.readOnlyRetryDelay = delay
+	}
 }
 
 // NewUserRepository creates a new user repository
-func NewUserRepository(db Database) UserRepository {
-	return &userRepository{
-		db: db,
+func NewUserRepository(db Database, opts ...UserRepositoryOption) UserRepository {
+	r := &userRepository{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// write runs a write, retrying it while the database is read-only as
+// configured, and marks a final read-only rejection with ErrServiceUnavailable
+func (r *userRepository) write(op func() error) error {
+	err := op()
+	for attempt := 0; attempt < r.readOnlyRetries && isReadOnlyError(err); attempt++ {
+		time.Sleep(r.readOnlyRetryDelay)
+		err = op()
 	}
+	return writeError(err)
 }
 
 // Database interface for database operations
@@ -32,28 +245,49 @@ type Database interface {
 	First(dest interface{}, conditions ...interface{}) error
 	Find(dest interface{}, conditions ...interface{}) error
 	Save(value interface{}) error
+	SaveIf(value interface{}, query interface{}, args ...interface{}) (int64, error)
 	Delete(value interface{}, conditions ...interface{}) error
+	Count(ctx context.Context, model interface{}, count *int64) error
+	FindPage(dest interface{}, order string, offset, limit int, conditions ...interface{}) error
+	Exec(sql string, values ...interface{}) error
+	Transaction(ctx context.Context, fn func(tx *driver.DB) error) error
+	Reader() *driver.DB
+	WithContext(ctx context.Context) *driver.DB
+}
+
+// users returns the user operations on the primary, run with ctx
+func (r *userRepository) users(ctx context.Context) *GormRepository[entity.User] {
+	return NewGormRepository[entity.User](r.db.WithContext(ctx))
+}
+
+// reader returns the connection for user lookups, run with ctx: the next
+// replica, or the primary when ctx asks for primary reads
+func (r *userRepository) reader(ctx context.Context) *driver.DB {
+	if primaryReads(ctx) {
+		return r.db.WithContext(ctx)
+	}
+	return r.db.Reader().WithContext(ctx)
+}
+
+// readerUsers returns the user operations on the reader, run with ctx
+func (r *userRepository) readerUsers(ctx context.Context) *GormRepository[entity.User] {
+	return NewGormRepository[entity.User](r.reader(ctx))
 }
 
 // Create creates a new user
-func (r *userRepository) Create(user *entity.User) error {
-	return r.db.Create(user)
+func (r *userRepository) Create(ctx context.Context, user *entity.User) error {
+	return r.write(func() error { return r.users(ctx).Create(user) })
 }
 
 // GetByID retrieves a user by ID
-func (r *userRepository) GetByID(id uint) (*entity.User, error) {
-	var user entity.User
-	err := r.db.First(&user, id)
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
+func (r *userRepository) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	return r.readerUsers(ctx).GetByID(id)
 }
 
 // GetByEmail retrieves a user by email
-func (r *userRepository) GetByEmail(email string) (*entity.User, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	err := r.db.First(&user, "email = ?", email)
+	err := r.reader(ctx).First(&user, "email = ?", email)
 	if err != nil {
 		return nil, err
 	}
@@ -61,21 +295,79 @@ func (r *userRepository) GetByEmail(email string) (*entity.User, error) {
 }
 
 // GetAll retrieves all users
-func (r *userRepository) GetAll() ([]entity.User, error) {
+func (r *userRepository) GetAll(ctx context.Context) ([]entity.User, error) {
+	return r.readerUsers(ctx).Find()
+}
+
+// Update updates a user
+func (r *userRepository) Update(ctx context.Context, user *entity.User) error {
+	return r.write(func() error { return r.users(ctx).Save(user) })
+}
+
+// UpdateIfUnchanged updates a user only while its stored UpdatedAt still
+// equals updatedAt, in a single conditional UPDATE, so two writers working
+// from the same version cannot both succeed
+func (r *userRepository) UpdateIfUnchanged(ctx context.Context, user *entity.User, updatedAt time.Time) error {
+	return r.write(func() error {
+		updated, err := r.db.WithContext(ctx).SaveIf(user, "updated_at = ?", updatedAt)
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrUserChanged
+		}
+		return nil
+	})
+}
+
+// Delete deletes a user by ID
+func (r *userRepository) Delete(ctx context.Context, id uint) error {
+	return r.write(func() error { return r.users(ctx).Delete(id) })
+}
+
+// Count returns the total number of users without loading them
+func (r *userRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Count(ctx, &entity.User{}, &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Find retrieves a page of users matching the filter, ordered by creation time and ID.
+// Pages start at 1; a non-positive limit returns every match.
+func (r *userRepository) Find(ctx context.Context, filter UserFilter, page, limit int) ([]entity.User, error) {
 	var users []entity.User
-	err := r.db.Find(&users)
-	if err != nil {
+	if err := r.db.WithContext(ctx).FindPage(&users, "created_at, id", filter.offset(page, limit), limit, filter.conditions()...); err != nil {
 		return nil, err
 	}
 	return users, nil
 }
 
-// Update updates a user
-func (r *userRepository) Update(user *entity.User) error {
-	return r.db.Save(user)
+// usersWriteLock serializes user write transactions while still allowing reads,
+// so checks such as the user count stay valid until the transaction commits
+const usersWriteLock = "LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE"
+
+// WithTransaction runs fn with a repository whose operations share a single
+// database transaction, committed when fn returns nil and rolled back otherwise.
+// Concurrent user writes wait until the transaction finishes. Writes inside
+// the transaction are not retried on their own, as the transaction is aborted
+// once one fails.
+func (r *userRepository) WithTransaction(ctx context.Context, fn func(tx UserRepository) error) error {
+	return r.write(func() error {
+		return r.db.Transaction(ctx, func(tx *driver.DB) error {
+			if err := tx.Exec(usersWriteLock); err != nil {
+				return err
+			}
+			return fn(NewUserRepository(tx))
+		})
+	})
 }
 
-// Delete deletes a user by ID
-func (r *userRepository) Delete(id uint) error {
-	return r.db.Delete(&entity.User{}, id)
+// offset converts a 1-based page number into a row offset; cursor listings always start at 0
+func (f UserFilter) offset(page, limit int) int {
+	if f.After != nil || page < 1 || limit < 1 {
+		return 0
+	}
+	return (page - 1) * limit
 }
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/pkg/cache"
+)
+
+// CachingUserRepository decorates a UserRepository with a cache for GetByID
+// and GetByEmail lookups. Cache failures never fail a request; the repository
+// falls back to the underlying implementation instead. Password hashes are
+// never cached, so users served from the cache come without one; lookups
+// that need the hash use primary reads, which bypass the cache.
+type CachingUserRepository struct {
+	next  UserRepository
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+// NewCachingUserRepository creates a caching decorator around an existing user repository
+func NewCachingUserRepository(next UserRepository, c cache.Cache, ttl time.Duration) *CachingUserRepository {
+	return &CachingUserRepository{
+		next:  next,
+		cache: c,
+		ttl:   ttl,
+	}
+}
+
+// Create creates a new user
+func (r *CachingUserRepository) Create(ctx context.Context, user *entity.User) error {
+	return r.next.Create(ctx, user)
+}
+
+// GetByID retrieves a user by ID, serving from the cache when possible.
+// Primary reads bypass the cache.
+func (r *CachingUserRepository) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	if primaryReads(ctx) {
+		return r.next.GetByID(ctx, id)
+	}
+	if user, ok := r.cachedUser(ctx, id); ok {
+		return user, nil
+	}
+
+	user, err := r.next.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.store(ctx, user)
+	return user, nil
+}
+
+// GetByEmail retrieves a user by email, serving from the cache when possible.
+// Primary reads bypass the cache.
+func (r *CachingUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	if primaryReads(ctx) {
+		return r.next.GetByEmail(ctx, email)
+	}
+	// The email key only maps to an ID; the entry is trusted only while the
+	// cached user still carries the same email
+	if data, err := r.cache.Get(ctx, emailCacheKey(email)); err == nil {
+		if id, err := strconv.ParseUint(string(data), 10, 64); err == nil {
+			if user, ok := r.cachedUser(ctx, uint(id)); ok && user.Email == email {
+				return user, nil
+			}
+		}
+	} else {
+		r.logError("get", err)
+	}
+
+	user, err := r.next.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	r.store(ctx, user)
+	return user, nil
+}
+
+// GetAll retrieves all users
+func (r *CachingUserRepository) GetAll(ctx context.Context) ([]entity.User, error) {
+	return r.next.GetAll(ctx)
+}
+
+// Update updates a user and invalidates its cached entry
+func (r *CachingUserRepository) Update(ctx context.Context, user *entity.User) error {
+	defer r.invalidate(ctx, user.ID)
+	return r.next.Update(ctx, user)
+}
+
+// UpdateIfUnchanged conditionally updates a user and invalidates its cached entry
+func (r *CachingUserRepository) UpdateIfUnchanged(ctx context.Context, user *entity.User, updatedAt time.Time) error {
+	defer r.invalidate(ctx, user.ID)
+	return r.next.UpdateIfUnchanged(ctx, user, updatedAt)
+}
+
+// Delete deletes a user by ID and invalidates its cached entry
+func (r *CachingUserRepository) Delete(ctx context.Context, id uint) error {
+	defer r.invalidate(ctx, id)
+	return r.next.Delete(ctx, id)
+}
+
+// Count returns the total number of users; counts are not cached
+func (r *CachingUserRepository) Count(ctx context.Context) (int64, error) {
+	return r.next.Count(ctx)
+}
+
+// Find retrieves a page of users matching the filter; listings are not cached
+func (r *CachingUserRepository) Find(ctx context.Context, filter UserFilter, page, limit int) ([]entity.User, error) {
+	return r.next.Find(ctx, filter, page, limit)
+}
+
+// WithTransaction runs fn in a transaction of the underlying repository. The
+// transaction's writes invalidate cached entries as they happen.
+func (r *CachingUserRepository) WithTransaction(ctx context.Context, fn func(tx UserRepository) error) error {
+	return r.next.WithTransaction(ctx, func(tx UserRepository) error {
+		return fn(&CachingUserRepository{next: tx, cache: r.cache, ttl: r.ttl})
+	})
+}
+
+// cachedUser decodes the cached user with the given ID, if present
+func (r *CachingUserRepository) cachedUser(ctx context.Context, id uint) (*entity.User, bool) {
+	data, err := r.cache.Get(ctx, idCacheKey(id))
+	if err != nil {
+		r.logError("get", err)
+		return nil, false
+	}
+
+	var user entity.User
+	if err := json.Unmarshal(data, &user); err != nil {
+		r.logError("decode", err)
+		return nil, false
+	}
+	return &user, true
+}
+
+// store caches the user under its ID and email keys
+func (r *CachingUserRepository) store(ctx context.Context, user *entity.User) {
+	// entity.User hides the password hash from JSON, so it stays out of the cache
+	data, err := json.Marshal(user)
+	if err != nil {
+		r.logError("encode", err)
+		return
+	}
+
+	if err := r.cache.Set(ctx, idCacheKey(user.ID), data, r.ttl); err != nil {
+		r.logError("set", err)
+		return
+	}
+	if err := r.cache.Set(ctx, emailCacheKey(user.Email), []byte(strconv.FormatUint(uint64(user.ID), 10)), r.ttl); err != nil {
+		r.logError("set", err)
+	}
+}
+
+// invalidate removes the cached user with the given ID
+func (r *CachingUserRepository) invalidate(ctx context.Context, id uint) {
+	if err := r.cache.Delete(ctx, idCacheKey(id)); err != nil {
+		r.logError("delete", err)
+	}
+}
+
+// logError reports cache failures other than plain misses
+func (r *CachingUserRepository) logError(op string, err error) {
+	if errors.Is(err, cache.ErrMiss) {
+		return
+	}
+	log.Printf("WARN: user cache %s failed: %v", op, err)
+}
+
+func idCacheKey(id uint) string {
+	return "user:id:" + strconv.FormatUint(uint64(id), 10)
+}
+
+func emailCacheKey(email string) string {
+	return "user:email:" + email
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/pkg/cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingUserRepository is a map-backed UserRepository that counts lookups
+type countingUserRepository struct {
+	users        map[uint]entity.User
+	getByIDCalls int
+	getByEmail   int
+}
+
+func newCountingUserRepository(users ...entity.User) *countingUserRepository {
+	r := &countingUserRepository{users: make(map[uint]entity.User)}
+	for _, user := range users {
+		r.users[user.ID] = user
+	}
+	return r
+}
+
+func (r *countingUserRepository) Create(_ context.Context, user *entity.User) error {
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *countingUserRepository) GetByID(_ context.Context, id uint) (*entity.User, error) {
+	r.getByIDCalls++
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &user, nil
+}
+
+func (r *countingUserRepository) GetByEmail(_ context.Context, email string) (*entity.User, error) {
+	r.getByEmail++
+	for _, user := range r.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *countingUserRepository) GetAll(context.Context) ([]entity.User, error) {
+	var users []entity.User
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *countingUserRepository) Update(_ context.Context, user *entity.User) error {
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *countingUserRepository) UpdateIfUnchanged(ctx context.Context, user *entity.User, _ time.Time) error {
+	return r.Update(ctx, user)
+}
+
+func (r *countingUserRepository) Delete(_ context.Context, id uint) error {
+	delete(r.users, id)
+	return nil
+}
+
+func (r *countingUserRepository) Count(context.Context) (int64, error) {
+	return int64(len(r.users)), nil
+}
+
+func (r *countingUserRepository) Find(context.Context, UserFilter, int, int) ([]entity.User, error) {
+	return nil, nil
+}
+
+func (r *countingUserRepository) WithTransaction(ctx context.Context, fn func(tx UserRepository) error) error {
+	return fn(r)
+}
+
+func TestCachingUserRepository_GetByIDHitAvoidsUnderlyingCall(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingUserRepository(entity.User{ID: 1, Name: "John", Email: "john@example.com"})
+	repo := NewCachingUserRepository(next, cache.NewMemoryCache(10), time.Minute)
+
+	for i := 0; i < 3; i++ {
+		user, err := repo.GetByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "John", user.Name)
+	}
+
+	assert.Equal(t, 1, next.getByIDCalls)
+}
+
+func TestCachingUserRepository_GetByEmailHitAvoidsUnderlyingCall(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingUserRepository(entity.User{ID: 1, Name: "John", Email: "john@example.com"})
+	repo := NewCachingUserRepository(next, cache.NewMemoryCache(10), time.Minute)
+
+	_, err := repo.GetByEmail(ctx, "john@example.com")
+	require.NoError(t, err)
+	user, err := repo.GetByEmail(ctx, "john@example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, uint(1), user.ID)
+	assert.Equal(t, 1, next.getByEmail)
+}
+
+func TestCachingUserRepository_PrimaryReadsBypassCache(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingUserRepository(entity.User{ID: 1, Name: "John", Email: "john@example.com"})
+	repo := NewCachingUserRepository(next, cache.NewMemoryCache(10), time.Minute)
+
+	_, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	primaryCtx := ContextWithPrimaryReads(ctx)
+	_, err = repo.GetByID(primaryCtx, 1)
+	require.NoError(t, err)
+	_, err = repo.GetByEmail(primaryCtx, "john@example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, next.getByIDCalls)
+	assert.Equal(t, 1, next.getByEmail)
+}
+
+func TestCachingUserRepository_UpdateInvalidates(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingUserRepository(entity.User{ID: 1, Name: "John", Email: "john@example.com"})
+	repo := NewCachingUserRepository(next, cache.NewMemoryCache(10), time.Minute)
+
+	user, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	user.Name = "Johnny"
+	user.Email = "johnny@example.com"
+	require.NoError(t, repo.Update(ctx, user))
+
+	updated, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Johnny", updated.Name)
+	assert.Equal(t, 2, next.getByIDCalls)
+
+	_, err = repo.GetByEmail(ctx, "john@example.com")
+	assert.Error(t, err)
+}
+
+func TestCachingUserRepository_DeleteInvalidates(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingUserRepository(entity.User{ID: 1, Name: "John", Email: "john@example.com"})
+	repo := NewCachingUserRepository(next, cache.NewMemoryCache(10), time.Minute)
+
+	_, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(ctx, 1))
+
+	_, err = repo.GetByID(ctx, 1)
+	assert.Error(t, err)
+}
+
+func TestCachingUserRepository_ReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingUserRepository(entity.User{ID: 1, Name: "John", Email: "john@example.com"})
+	repo := NewCachingUserRepository(next, cache.NewMemoryCache(10), time.Minute)
+
+	user, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	user.Name = "Mutated"
+
+	cached, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "John", cached.Name)
+}
+
+func TestCachingUserRepository_OmitsPasswordHash(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingUserRepository(entity.User{ID: 1, Email: "john@example.com", Password: "hash"})
+	userCache := cache.NewMemoryCache(10)
+	repo := NewCachingUserRepository(next, userCache, time.Minute)
+
+	_, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	cached, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	data, err := userCache.Get(ctx, idCacheKey(1))
+	require.NoError(t, err)
+
+	assert.Empty(t, cached.Password)
+	assert.NotContains(t, string(data), "hash")
+	assert.Equal(t, 1, next.getByIDCalls)
+
+	fresh, err := repo.GetByID(ContextWithPrimaryReads(ctx), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "hash", fresh.Password)
+}
+
+func TestCachingUserRepository_RedisBackend(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	next := newCountingUserRepository(entity.User{ID: 1, Name: "John", Email: "john@example.com"})
+	repo := NewCachingUserRepository(next, cache.NewRedisCache(client, "test:"), time.Minute)
+
+	_, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	user, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, "John", user.Name)
+	assert.Equal(t, 1, next.getByIDCalls)
+	assert.True(t, server.Exists("test:user:id:1"))
+}
+
+// failingCache is a Cache whose every operation fails, simulating an unreachable backend
+type failingCache struct{}
+
+func (failingCache) Get(context.Context, string) ([]byte, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingCache) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("connection refused")
+}
+
+func (failingCache) Delete(context.Context, ...string) error {
+	return errors.New("connection refused")
+}
+
+func TestCachingUserRepository_DegradesOnCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingUserRepository(entity.User{ID: 1, Name: "John", Email: "john@example.com"})
+	repo := NewCachingUserRepository(next, failingCache{}, time.Minute)
+
+	user, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "John", user.Name)
+
+	user, err = repo.GetByEmail(ctx, "john@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), user.ID)
+
+	user.Name = "Johnny"
+	assert.NoError(t, repo.Update(ctx, user))
+}
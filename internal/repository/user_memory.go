@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"gorm.io/gorm"
+)
+
+// inMemoryUserRepository implements UserRepository with a map, for tests and
+// local development without PostgreSQL
+type inMemoryUserRepository struct {
+	mu     sync.RWMutex
+	users  map[uint]entity.User
+	nextID uint
+}
+
+// NewInMemoryUserRepository creates a new concurrency-safe in-memory user repository.
+// Like the GORM repository, it returns gorm.ErrRecordNotFound for missing users
+// and gorm.ErrDuplicatedKey for duplicate emails.
+func NewInMemoryUserRepository() UserRepository {
+	return &inMemoryUserRepository{
+		users: make(map[uint]entity.User),
+	}
+}
+
+// Create stores a new user and assigns its ID and timestamps
+func (r *inMemoryUserRepository) Create(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.create(user)
+}
+
+// GetByID retrieves a user by ID
+func (r *inMemoryUserRepository) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.getByID(id)
+}
+
+// GetByEmail retrieves a user by email
+func (r *inMemoryUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.getByEmail(email)
+}
+
+// GetAll retrieves all users ordered by ID
+func (r *inMemoryUserRepository) GetAll(ctx context.Context) ([]entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.getAll(), nil
+}
+
+// Update replaces an existing user and refreshes its UpdatedAt timestamp
+func (r *inMemoryUserRepository) Update(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.update(user)
+}
+
+// UpdateIfUnchanged replaces an existing user while its UpdatedAt still equals updatedAt
+func (r *inMemoryUserRepository) UpdateIfUnchanged(ctx context.Context, user *entity.User, updatedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.updateIfUnchanged(user, updatedAt)
+}
+
+// Delete deletes a user by ID
+func (r *inMemoryUserRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.delete(id)
+}
+
+// Count returns the number of stored users
+func (r *inMemoryUserRepository) Count(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return int64(len(r.users)), nil
+}
+
+// Find retrieves a page of users matching the filter, ordered by creation time and ID
+func (r *inMemoryUserRepository) Find(ctx context.Context, filter UserFilter, page, limit int) ([]entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.find(filter, page, limit), nil
+}
+
+// WithTransaction runs fn while holding the write lock, so no other caller
+// observes or changes the users until it returns. When fn fails, every
+// change it made is rolled back.
+func (r *inMemoryUserRepository) WithTransaction(ctx context.Context, fn func(tx UserRepository) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users, nextID := maps.Clone(r.users), r.nextID
+	if err := fn(&inMemoryUserTx{r: r}); err != nil {
+		r.users, r.nextID = users, nextID
+		return err
+	}
+	return nil
+}
+
+// The methods below implement the repository operations. Callers must hold r.mu.
+
+func (r *inMemoryUserRepository) create(user *entity.User) error {
+	if r.emailTaken(user.Email, 0) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	r.nextID++
+	now := time.Now()
+	user.ID = r.nextID
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	if user.Role == "" {
+		user.Role = entity.RoleUser
+	}
+
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *inMemoryUserRepository) getByID(id uint) (*entity.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &user, nil
+}
+
+func (r *inMemoryUserRepository) getByEmail(email string) (*entity.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return &user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *inMemoryUserRepository) getAll() []entity.User {
+	users := make([]entity.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users
+}
+
+func (r *inMemoryUserRepository) update(user *entity.User) error {
+	existing, ok := r.users[user.ID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	if r.emailTaken(user.Email, user.ID) {
+		return gorm.ErrDuplicatedKey
+	}
+
+	user.CreatedAt = existing.CreatedAt
+	user.UpdatedAt = time.Now()
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *inMemoryUserRepository) updateIfUnchanged(user *entity.User, updatedAt time.Time) error {
+	if existing, ok := r.users[user.ID]; !ok || !existing.UpdatedAt.Equal(updatedAt) {
+		return ErrUserChanged
+	}
+	return r.update(user)
+}
+
+func (r *inMemoryUserRepository) delete(id uint) error {
+	if _, ok := r.users[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+func (r *inMemoryUserRepository) find(filter UserFilter, page, limit int) []entity.User {
+	all := r.getAll()
+	sort.SliceStable(all, func(i, j int) bool { return all[i].CreatedAt.Before(all[j].CreatedAt) })
+
+	users := make([]entity.User, 0, len(all))
+	for _, user := range all {
+		if filter.Matches(user) {
+			users = append(users, user)
+		}
+	}
+
+	if limit < 1 {
+		return users
+	}
+	offset := filter.offset(page, limit)
+	if offset >= len(users) {
+		return []entity.User{}
+	}
+	return users[offset:min(offset+limit, len(users))]
+}
+
+// emailTaken reports whether another user already uses the email. Callers must hold r.mu.
+func (r *inMemoryUserRepository) emailTaken(email string, exceptID uint) bool {
+	for id, user := range r.users {
+		if id != exceptID && user.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+// inMemoryUserTx is the repository handed to an in-memory transaction. The
+// transaction already holds the write lock, so its methods do not lock again.
+type inMemoryUserTx struct {
+	r *inMemoryUserRepository
+}
+
+// Create stores a new user within the transaction
+func (tx *inMemoryUserTx) Create(ctx context.Context, user *entity.User) error {
+	return tx.r.create(user)
+}
+
+// GetByID retrieves a user by ID within the transaction
+func (tx *inMemoryUserTx) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	return tx.r.getByID(id)
+}
+
+// GetByEmail retrieves a user by email within the transaction
+func (tx *inMemoryUserTx) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return tx.r.getByEmail(email)
+}
+
+// GetAll retrieves all users within the transaction
+func (tx *inMemoryUserTx) GetAll(ctx context.Context) ([]entity.User, error) {
+	return tx.r.getAll(), nil
+}
+
+// Update replaces an existing user within the transaction
+func (tx *inMemoryUserTx) Update(ctx context.Context, user *entity.User) error {
+	return tx.r.update(user)
+}
+
+// UpdateIfUnchanged replaces an unchanged user within the transaction
+func (tx *inMemoryUserTx) UpdateIfUnchanged(ctx context.Context, user *entity.User, updatedAt time.Time) error {
+	return tx.r.updateIfUnchanged(user, updatedAt)
+}
+
+// Delete deletes a user within the transaction
+func (tx *inMemoryUserTx) Delete(ctx context.Context, id uint) error {
+	return tx.r.delete(id)
+}
+
+// Count returns the number of users within the transaction
+func (tx *inMemoryUserTx) Count(ctx context.Context) (int64, error) {
+	return int64(len(tx.r.users)), nil
+}
+
+// Find retrieves a page of users within the transaction
+func (tx *inMemoryUserTx) Find(ctx context.Context, filter UserFilter, page, limit int) ([]entity.User, error) {
+	return tx.r.find(filter, page, limit), nil
+}
+
+// WithTransaction runs fn within the enclosing transaction
+func (tx *inMemoryUserTx) WithTransaction(ctx context.Context, fn func(tx UserRepository) error) error {
+	return fn(tx)
+}
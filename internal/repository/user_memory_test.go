@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestInMemoryUserRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+
+	user := &entity.User{Name: "John Doe", Email: "john.doe@example.com", Password: "hash"}
+	require.NoError(t, repo.Create(ctx, user))
+	assert.Equal(t, uint(1), user.ID)
+	assert.Equal(t, entity.RoleUser, user.Role)
+	assert.False(t, user.CreatedAt.IsZero())
+
+	byID, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "john.doe@example.com", byID.Email)
+
+	byEmail, err := repo.GetByEmail(ctx, "john.doe@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, byEmail.ID)
+
+	byID.Name = "Johnny Doe"
+	require.NoError(t, repo.Update(ctx, byID))
+	updated, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Johnny Doe", updated.Name)
+	assert.Equal(t, user.CreatedAt, updated.CreatedAt)
+
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	_, err = repo.GetByID(ctx, user.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestInMemoryUserRepository_ReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+	require.NoError(t, repo.Create(ctx, &entity.User{Name: "John Doe", Email: "john.doe@example.com"}))
+
+	user, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	user.Name = "Changed"
+
+	stored, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "John Doe", stored.Name)
+}
+
+func TestInMemoryUserRepository_DuplicateEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+	require.NoError(t, repo.Create(ctx, &entity.User{Email: "john.doe@example.com"}))
+	jane := &entity.User{Email: "jane.doe@example.com"}
+	require.NoError(t, repo.Create(ctx, jane))
+
+	err := repo.Create(ctx, &entity.User{Email: "john.doe@example.com"})
+	assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+
+	jane.Email = "john.doe@example.com"
+	assert.ErrorIs(t, repo.Update(ctx, jane), gorm.ErrDuplicatedKey)
+}
+
+func TestInMemoryUserRepository_NotFound(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+
+	_, err := repo.GetByID(ctx, 42)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = repo.GetByEmail(ctx, "missing@example.com")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	assert.ErrorIs(t, repo.Update(ctx, &entity.User{ID: 42}), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, 42), gorm.ErrRecordNotFound)
+}
+
+func TestInMemoryUserRepository_GetAllOrderedByID(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, repo.Create(ctx, &entity.User{Email: fmt.Sprintf("user%d@example.com", i)}))
+	}
+
+	users, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 5)
+	for i, user := range users {
+		assert.Equal(t, uint(i+1), user.ID)
+	}
+}
+
+func TestInMemoryUserRepository_Count(t *testing.T) {
+	repo := NewInMemoryUserRepository()
+	ctx := context.Background()
+
+	count, err := repo.Count(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	require.NoError(t, repo.Create(ctx, &entity.User{Name: "A", Email: "a@example.com"}))
+	require.NoError(t, repo.Create(ctx, &entity.User{Name: "B", Email: "b@example.com"}))
+	count, err = repo.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestInMemoryUserRepository_ConcurrentCreate(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, repo.Create(ctx, &entity.User{Email: fmt.Sprintf("user%d@example.com", i)}))
+		}(i)
+	}
+	wg.Wait()
+
+	users, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, users, 50)
+}
+
+func TestInMemoryUserRepository_Find(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+	for _, name := range []string{"Alice Smith", "Bob Jones", "Carol Smith", "Dave Smith"} {
+		email := strings.ToLower(strings.Fields(name)[0]) + "@example.com"
+		require.NoError(t, repo.Create(ctx, &entity.User{Name: name, Email: email, Password: "hash"}))
+	}
+
+	users, err := repo.Find(ctx, UserFilter{Query: "smith"}, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Alice Smith", "Carol Smith"}, userNames(users))
+
+	users, err = repo.Find(ctx, UserFilter{Query: "smith"}, 2, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Dave Smith"}, userNames(users))
+
+	users, err = repo.Find(ctx, UserFilter{Query: "smith"}, 3, 2)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	future := time.Now().Add(time.Hour)
+	users, err = repo.Find(ctx, UserFilter{CreatedFrom: &future}, 1, 10)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	users, err = repo.Find(ctx, UserFilter{Email: "bob@example.com", CreatedTo: &future}, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bob Jones"}, userNames(users))
+}
+
+func userNames(users []entity.User) []string {
+	names := make([]string, 0, len(users))
+	for _, user := range users {
+		names = append(names, user.Name)
+	}
+	return names
+}
+
+func TestInMemoryUserRepository_FindCursorIsStableAcrossInserts(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+	create := func(name string) {
+		require.NoError(t, repo.Create(ctx, &entity.User{Name: name, Email: strings.ToLower(name) + "@example.com", Password: "hash"}))
+	}
+	for _, name := range []string{"Alice", "Bob", "Carol", "Dave", "Erin"} {
+		create(name)
+	}
+
+	var seen []string
+	filter := UserFilter{}
+	for page := 0; ; page++ {
+		users, err := repo.Find(ctx, filter, 1, 2)
+		require.NoError(t, err)
+		if len(users) == 0 {
+			break
+		}
+		seen = append(seen, userNames(users)...)
+
+		// Inserts between pages must neither repeat nor skip existing users
+		if page == 0 {
+			create("Frank")
+		}
+		last := users[len(users)-1]
+		filter.After = &UserCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	assert.Equal(t, []string{"Alice", "Bob", "Carol", "Dave", "Erin", "Frank"}, seen)
+}
+
+func TestInMemoryUserRepository_FindCursorCombinesWithFilter(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+	for _, name := range []string{"Alice Smith", "Bob Jones", "Carol Smith", "Dave Smith"} {
+		email := strings.ToLower(strings.Fields(name)[0]) + "@example.com"
+		require.NoError(t, repo.Create(ctx, &entity.User{Name: name, Email: email, Password: "hash"}))
+	}
+
+	first, err := repo.Find(ctx, UserFilter{Query: "smith"}, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+
+	rest, err := repo.Find(ctx, UserFilter{Query: "smith", After: &UserCursor{CreatedAt: first[0].CreatedAt, ID: first[0].ID}}, 5, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Carol Smith", "Dave Smith"}, userNames(rest))
+}
+
+func TestInMemoryUserRepository_WithTransaction(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+	require.NoError(t, repo.Create(ctx, &entity.User{Name: "Alice", Email: "alice@example.com"}))
+
+	err := repo.WithTransaction(context.Background(), func(tx UserRepository) error {
+		return tx.Create(ctx, &entity.User{Name: "Bob", Email: "bob@example.com"})
+	})
+	require.NoError(t, err)
+
+	count, err := repo.Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestInMemoryUserRepository_WithTransactionRollsBack(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+	require.NoError(t, repo.Create(ctx, &entity.User{Name: "Alice", Email: "alice@example.com"}))
+	errAbort := fmt.Errorf("abort")
+
+	err := repo.WithTransaction(context.Background(), func(tx UserRepository) error {
+		require.NoError(t, tx.Create(ctx, &entity.User{Name: "Bob", Email: "bob@example.com"}))
+		require.NoError(t, tx.Delete(ctx, 1))
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort)
+
+	users, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "Alice", users[0].Name)
+
+	// IDs handed out inside the rolled back transaction are reused
+	require.NoError(t, repo.Create(ctx, &entity.User{Name: "Carol", Email: "carol@example.com"}))
+	carol, err := repo.GetByEmail(ctx, "carol@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), carol.ID)
+}
@@ -0,0 +1,433 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	dbdriver "github.com/example/go-clean-architecture/internal/driver"
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/pkg/logging"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newSQLMockUserRepository returns a user repository backed by GORM over sqlmock
+func newSQLMockUserRepository(t *testing.T) (UserRepository, sqlmock.Sqlmock) {
+	t.Helper()
+
+	gormDB, sqlMock := newSQLMockGorm(t)
+	return NewUserRepository(&dbdriver.DB{DB: gormDB}), sqlMock
+}
+
+// newSQLMockGorm returns a GORM connection over sqlmock whose expectations are checked on cleanup
+func newSQLMockGorm(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, sqlMock.ExpectationsWereMet())
+		sqlDB.Close()
+	})
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	return gormDB, sqlMock
+}
+
+func TestUserRepository_RoutesReadsToReplica(t *testing.T) {
+	ctx := context.Background()
+	primary, primaryMock := newSQLMockGorm(t)
+	replica, replicaMock := newSQLMockGorm(t)
+	repo := NewUserRepository(dbdriver.NewDB(primary, replica))
+	userRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "John Doe", "john@example.com")
+	}
+
+	replicaMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1`)).
+		WithArgs(1, 1).WillReturnRows(userRows())
+	replicaMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE email = $1`)).
+		WithArgs("john@example.com", 1).WillReturnRows(userRows())
+	replicaMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).WillReturnRows(userRows())
+
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "role", "verified"}).AddRow(2, "user", false))
+	primaryMock.ExpectCommit()
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectExec(regexp.QuoteMeta(`UPDATE "users"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	primaryMock.ExpectCommit()
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "users"`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	primaryMock.ExpectCommit()
+	primaryMock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	_, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	_, err = repo.GetByEmail(ctx, "john@example.com")
+	require.NoError(t, err)
+	_, err = repo.GetAll(ctx)
+	require.NoError(t, err)
+
+	user := &entity.User{Name: "Jane Doe", Email: "jane@example.com", Password: "hashed"}
+	require.NoError(t, repo.Create(ctx, user))
+	user.Name = "Jane Roe"
+	require.NoError(t, repo.Update(ctx, user))
+	require.NoError(t, repo.Delete(ctx, user.ID))
+	_, err = repo.Count(ctx)
+	require.NoError(t, err)
+}
+
+func TestUserRepository_TakesTurnsBetweenReplicasPerQuery(t *testing.T) {
+	ctx := context.Background()
+	primary, _ := newSQLMockGorm(t)
+	first, firstMock := newSQLMockGorm(t)
+	second, secondMock := newSQLMockGorm(t)
+	repo := NewUserRepository(dbdriver.NewDB(primary, first, second))
+
+	for _, replicaMock := range []sqlmock.Sqlmock{firstMock, secondMock} {
+		replicaMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1`)).
+			WithArgs(1, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.GetByID(ctx, 1)
+		require.NoError(t, err)
+	}
+}
+
+func TestUserRepository_PrimaryReadsSkipReplicas(t *testing.T) {
+	ctx := ContextWithPrimaryReads(context.Background())
+	primary, primaryMock := newSQLMockGorm(t)
+	replica, _ := newSQLMockGorm(t)
+	repo := NewUserRepository(dbdriver.NewDB(primary, replica))
+
+	primaryMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE "users"."id" = $1`)).
+		WithArgs(1, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	primaryMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE email = $1`)).
+		WithArgs("john@example.com", 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	_, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	_, err = repo.GetByEmail(ctx, "john@example.com")
+	require.NoError(t, err)
+}
+
+func TestUserRepository_Count(t *testing.T) {
+	repo, sqlMock := newSQLMockUserRepository(t)
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(42))
+
+	count, err := repo.Count(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), count)
+}
+
+func TestUserRepository_CountError(t *testing.T) {
+	repo, sqlMock := newSQLMockUserRepository(t)
+	errQuery := errors.New("connection refused")
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).WillReturnError(errQuery)
+
+	_, err := repo.Count(context.Background())
+
+	assert.ErrorIs(t, err, errQuery)
+}
+
+func TestUserFilter_Validate(t *testing.T) {
+	early := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := early.AddDate(0, 1, 0)
+
+	assert.NoError(t, UserFilter{}.Validate())
+	assert.NoError(t, UserFilter{CreatedFrom: &early}.Validate())
+	assert.NoError(t, UserFilter{CreatedFrom: &early, CreatedTo: &late}.Validate())
+	assert.NoError(t, UserFilter{CreatedFrom: &early, CreatedTo: &early}.Validate())
+	assert.ErrorIs(t, UserFilter{CreatedFrom: &late, CreatedTo: &early}.Validate(), ErrInvalidDateRange)
+}
+
+func TestUserFilter_Matches(t *testing.T) {
+	created := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	before := created.Add(-time.Hour)
+	after := created.Add(time.Hour)
+	user := entity.User{Name: "John Doe", Email: "john.doe@example.com", CreatedAt: created}
+
+	tests := []struct {
+		name   string
+		filter UserFilter
+		want   bool
+	}{
+		{"empty filter", UserFilter{}, true},
+		{"email match", UserFilter{Email: "john.doe@example.com"}, true},
+		{"email mismatch", UserFilter{Email: "jane@example.com"}, false},
+		{"name query is case-insensitive", UserFilter{Query: "JOHN"}, true},
+		{"name query mismatch", UserFilter{Query: "jane"}, false},
+		{"inside range", UserFilter{CreatedFrom: &before, CreatedTo: &after}, true},
+		{"range bounds are inclusive", UserFilter{CreatedFrom: &created, CreatedTo: &created}, true},
+		{"created before range", UserFilter{CreatedFrom: &after}, false},
+		{"created after range", UserFilter{CreatedTo: &before}, false},
+		{"query and range", UserFilter{Query: "doe", CreatedFrom: &before}, true},
+		{"query matches but range does not", UserFilter{Query: "doe", CreatedTo: &before}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(user))
+		})
+	}
+}
+
+func TestUserRepository_Find(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter UserFilter
+		page   int
+		limit  int
+		query  string
+		args   []driver.Value
+	}{
+		{
+			name:  "no filter",
+			page:  1,
+			limit: 10,
+			query: `SELECT * FROM "users" ORDER BY created_at, id LIMIT $1`,
+			args:  []driver.Value{10},
+		},
+		{
+			name:   "date range",
+			filter: UserFilter{CreatedFrom: &from, CreatedTo: &to},
+			page:   2,
+			limit:  10,
+			query:  `SELECT * FROM "users" WHERE created_at >= $1 AND created_at <= $2 ORDER BY created_at, id LIMIT $3 OFFSET $4`,
+			args:   []driver.Value{from, to, 10, 10},
+		},
+		{
+			name:   "email, name query and start date",
+			filter: UserFilter{Email: "john@example.com", Query: "50%_off", CreatedFrom: &from},
+			page:   1,
+			limit:  5,
+			query:  `SELECT * FROM "users" WHERE email = $1 AND name ILIKE $2 AND created_at >= $3 ORDER BY created_at, id LIMIT $4`,
+			args:   []driver.Value{"john@example.com", `%50\%\_off%`, from, 5},
+		},
+		{
+			name:   "cursor ignores page",
+			filter: UserFilter{Query: "john", After: &UserCursor{CreatedAt: from, ID: 7}},
+			page:   3,
+			limit:  10,
+			query:  `SELECT * FROM "users" WHERE name ILIKE $1 AND (created_at, id) > ($2, $3) ORDER BY created_at, id LIMIT $4`,
+			args:   []driver.Value{"%john%", from, 7, 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, sqlMock := newSQLMockUserRepository(t)
+			sqlMock.ExpectQuery(regexp.QuoteMeta(tt.query)).
+				WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "email"}).AddRow(1, "John Doe", "john@example.com"))
+
+			users, err := repo.Find(ctx, tt.filter, tt.page, tt.limit)
+
+			require.NoError(t, err)
+			require.Len(t, users, 1)
+			assert.Equal(t, "john@example.com", users[0].Email)
+		})
+	}
+}
+
+func TestUserCursor_RoundTrip(t *testing.T) {
+	cursor := UserCursor{CreatedAt: time.Date(2024, 3, 15, 12, 30, 45, 123456789, time.UTC), ID: 42}
+
+	decoded, err := DecodeUserCursor(cursor.Encode())
+
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+}
+
+func TestDecodeUserCursor_Invalid(t *testing.T) {
+	// "nocolon", "x:1" and "1:x" base64-encoded, plus strings that are not base64
+	for _, raw := range []string{"", "!!!", "bm9jb2xvbg", "eDox", "MTp4"} {
+		_, err := DecodeUserCursor(raw)
+		assert.ErrorIs(t, err, ErrInvalidCursor, raw)
+	}
+}
+
+func TestUserRepository_WithTransaction(t *testing.T) {
+	repo, sqlMock := newSQLMockUserRepository(t)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(`LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	sqlMock.ExpectCommit()
+
+	var count int64
+	err := repo.WithTransaction(context.Background(), func(tx UserRepository) error {
+		var err error
+		count, err = tx.Count(context.Background())
+		return err
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestUserRepository_WithTransactionRollsBackOnError(t *testing.T) {
+	repo, sqlMock := newSQLMockUserRepository(t)
+	errLimit := errors.New("limit reached")
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(`LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectRollback()
+
+	err := repo.WithTransaction(context.Background(), func(tx UserRepository) error {
+		return errLimit
+	})
+
+	assert.ErrorIs(t, err, errLimit)
+}
+
+// errReadOnly is what PostgreSQL returns for writes while it is read-only
+var errReadOnly = &pgconn.PgError{
+	Severity: "ERROR",
+	Code:     "25006",
+	Message:  "cannot execute INSERT in a read-only transaction",
+}
+
+func TestUserRepository_ReadOnlyWrites(t *testing.T) {
+	ctx := context.Background()
+	repo, sqlMock := newSQLMockUserRepository(t)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).WillReturnError(errReadOnly)
+	sqlMock.ExpectRollback()
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(`UPDATE "users"`)).WillReturnError(errReadOnly)
+	sqlMock.ExpectRollback()
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "users"`)).WillReturnError(errReadOnly)
+	sqlMock.ExpectRollback()
+
+	user := &entity.User{ID: 1, Name: "Jane Doe", Email: "jane@example.com", Password: "hashed"}
+	err := repo.Create(ctx, &entity.User{Name: "Jane Doe", Email: "jane@example.com", Password: "hashed"})
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+	assert.ErrorIs(t, err, errReadOnly, "the database error stays in the chain")
+	assert.ErrorIs(t, repo.Update(ctx, user), ErrServiceUnavailable)
+	assert.ErrorIs(t, repo.Delete(ctx, user.ID), ErrServiceUnavailable)
+}
+
+func TestUserRepository_UpdateIfUnchanged(t *testing.T) {
+	ctx := context.Background()
+	repo, sqlMock := newSQLMockUserRepository(t)
+	readAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	conditionalUpdate := regexp.QuoteMeta(`UPDATE "users" SET`) + `.*` + regexp.QuoteMeta(`WHERE updated_at = $`) + `.*` + regexp.QuoteMeta(`"id" = $`)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(conditionalUpdate).WillReturnResult(sqlmock.NewResult(0, 1))
+	sqlMock.ExpectCommit()
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(conditionalUpdate).WillReturnResult(sqlmock.NewResult(0, 0))
+	sqlMock.ExpectCommit()
+
+	user := &entity.User{ID: 1, Name: "Jane Doe", Email: "jane@example.com", Password: "hashed", UpdatedAt: readAt}
+	require.NoError(t, repo.UpdateIfUnchanged(ctx, user, readAt))
+
+	user.UpdatedAt = readAt
+	assert.ErrorIs(t, repo.UpdateIfUnchanged(ctx, user, readAt), ErrUserChanged)
+}
+
+func TestUserRepository_ReadOnlyTransaction(t *testing.T) {
+	repo, sqlMock := newSQLMockUserRepository(t)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(`LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE`)).WillReturnError(errReadOnly)
+	sqlMock.ExpectRollback()
+
+	err := repo.WithTransaction(context.Background(), func(tx UserRepository) error {
+		t.Fatal("fn runs after the lock failed")
+		return nil
+	})
+
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+}
+
+func TestUserRepository_OtherWriteErrorsAreUnchanged(t *testing.T) {
+	ctx := context.Background()
+	repo, sqlMock := newSQLMockUserRepository(t)
+	errConstraint := &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).WillReturnError(errConstraint)
+	sqlMock.ExpectRollback()
+
+	err := repo.Create(ctx, &entity.User{Name: "Jane Doe", Email: "jane@example.com", Password: "hashed"})
+
+	assert.ErrorIs(t, err, errConstraint)
+	assert.NotErrorIs(t, err, ErrServiceUnavailable)
+}
+
+func TestUserRepository_RetriesReadOnlyWrites(t *testing.T) {
+	ctx := context.Background()
+	gormDB, sqlMock := newSQLMockGorm(t)
+	repo := NewUserRepository(&dbdriver.DB{DB: gormDB}, WithReadOnlyRetries(2, time.Millisecond))
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).WillReturnError(errReadOnly)
+	sqlMock.ExpectRollback()
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "users"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "role", "verified"}).AddRow(2, "user", false))
+	sqlMock.ExpectCommit()
+	// Transactions are retried as a whole
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(`LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE`)).WillReturnError(errReadOnly)
+	sqlMock.ExpectRollback()
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(`LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE`)).WillReturnError(errReadOnly)
+	sqlMock.ExpectRollback()
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec(regexp.QuoteMeta(`LOCK TABLE users IN SHARE ROW EXCLUSIVE MODE`)).WillReturnError(errReadOnly)
+	sqlMock.ExpectRollback()
+
+	user := &entity.User{Name: "Jane Doe", Email: "jane@example.com", Password: "hashed"}
+	require.NoError(t, repo.Create(ctx, user))
+	assert.Equal(t, uint(2), user.ID)
+
+	err := repo.WithTransaction(context.Background(), func(tx UserRepository) error { return nil })
+	assert.ErrorIs(t, err, ErrServiceUnavailable, "gives up after the configured retries")
+}
+
+func TestUserRepository_QueriesCarryRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	sqlDB, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: dbdriver.NewGormLogger(logging.New(&logs), 0),
+	})
+	require.NoError(t, err)
+	repo := NewUserRepository(&dbdriver.DB{DB: gormDB})
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE email = $1`)).WillReturnError(errors.New("connection reset"))
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users"`)).WillReturnError(errors.New("connection reset"))
+	ctx := logging.ContextWithRequestID(context.Background(), "req-42")
+
+	_, err = repo.GetByEmail(ctx, "john@example.com")
+	require.Error(t, err)
+	_, err = repo.Find(ctx, UserFilter{}, 1, 10)
+	require.Error(t, err)
+
+	assert.Equal(t, 2, strings.Count(logs.String(), `"requestId":"req-42"`))
+}
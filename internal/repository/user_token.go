@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/driver"
+	"github.com/example/go-clean-architecture/internal/entity"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrUserTokenNotFound is returned when no unused token matches
+var ErrUserTokenNotFound = errors.New("user token not found")
+
+// userTokenExpiryIndex is the name of the TTL index on expiresAt
+const userTokenExpiryIndex = "expiresAt_ttl"
+
+// UserTokenRepository stores single-use user tokens such as email verification links
+type UserTokenRepository struct {
+	mongo    *driver.Mongo
+	database string
+}
+
+// NewUserTokenRepository creates a new user token repository
+func NewUserTokenRepository(mongo *driver.Mongo) *UserTokenRepository {
+	return &UserTokenRepository{mongo: mongo, database: "go_clean_arch"}
+}
+
+// collection returns the user tokens collection
+func (r *UserTokenRepository) collection() *mongo.Collection {
+	return r.mongo.GetCollection(r.database, "user_tokens")
+}
+
+// EnsureIndexes creates the TTL index that lets MongoDB remove expired tokens.
+// MongoDB removes them in the background, so callers must still check expiry.
+func (r *UserTokenRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetName(userTokenExpiryIndex).SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+// Create stores a token, setting its creation time
+func (r *UserTokenRepository) Create(token *entity.UserToken) error {
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+
+	_, err := r.collection().InsertOne(context.Background(), token)
+	return err
+}
+
+// Consume atomically removes and returns the token with the given purpose and
+// ID, so each token can be used at most once
+func (r *UserTokenRepository) Consume(purpose, id string) (*entity.UserToken, error) {
+	var token entity.UserToken
+	err := r.collection().FindOneAndDelete(context.Background(), bson.M{"_id": id, "purpose": purpose}).Decode(&token)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrUserTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DeleteForUser removes every unused token with the given purpose issued to the user
+func (r *UserTokenRepository) DeleteForUser(purpose string, userID uint) error {
+	_, err := r.collection().DeleteMany(context.Background(), bson.M{"purpose": purpose, "userId": userID})
+	return err
+}
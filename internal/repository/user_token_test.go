@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserTokenRepository_CreateAndConsume(t *testing.T) {
+	memoryLogRepo := newTestMemoryLogRepository(t)
+	repo := &UserTokenRepository{mongo: testMongo(memoryLogRepo), database: memoryLogRepo.database}
+	require.NoError(t, repo.EnsureIndexes(context.Background()))
+
+	token := &entity.UserToken{ID: "hash", Purpose: entity.TokenPurposeEmailVerification, UserID: 7,
+		ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, repo.Create(token))
+	assert.False(t, token.CreatedAt.IsZero())
+
+	_, err := repo.Consume("other_purpose", "hash")
+	assert.ErrorIs(t, err, ErrUserTokenNotFound)
+
+	consumed, err := repo.Consume(entity.TokenPurposeEmailVerification, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), consumed.UserID)
+
+	_, err = repo.Consume(entity.TokenPurposeEmailVerification, "hash")
+	assert.ErrorIs(t, err, ErrUserTokenNotFound, "tokens are single-use")
+}
+
+func TestUserTokenRepository_DeleteForUser(t *testing.T) {
+	memoryLogRepo := newTestMemoryLogRepository(t)
+	repo := &UserTokenRepository{mongo: testMongo(memoryLogRepo), database: memoryLogRepo.database}
+	expires := time.Now().Add(time.Hour)
+	for _, token := range []*entity.UserToken{
+		{ID: "first", Purpose: entity.TokenPurposePasswordReset, UserID: 7, ExpiresAt: expires},
+		{ID: "second", Purpose: entity.TokenPurposePasswordReset, UserID: 7, ExpiresAt: expires},
+		{ID: "verification", Purpose: entity.TokenPurposeEmailVerification, UserID: 7, ExpiresAt: expires},
+		{ID: "other user", Purpose: entity.TokenPurposePasswordReset, UserID: 8, ExpiresAt: expires},
+	} {
+		require.NoError(t, repo.Create(token))
+	}
+
+	require.NoError(t, repo.DeleteForUser(entity.TokenPurposePasswordReset, 7))
+
+	for _, id := range []string{"first", "second"} {
+		_, err := repo.Consume(entity.TokenPurposePasswordReset, id)
+		assert.ErrorIs(t, err, ErrUserTokenNotFound, id)
+	}
+	_, err := repo.Consume(entity.TokenPurposeEmailVerification, "verification")
+	assert.NoError(t, err)
+	_, err = repo.Consume(entity.TokenPurposePasswordReset, "other user")
+	assert.NoError(t, err)
+}
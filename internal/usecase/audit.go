@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/pkg/logging"
+)
+
+// AuditLogWriter persists audit entries
+type AuditLogWriter interface {
+	Create(entry *entity.AuditLog) error
+}
+
+// ActorBinder is implemented by usecases that attribute mutations to the acting user
+type ActorBinder interface {
+	WithActor(actor string) UserUsecase
+}
+
+// ForActor returns uc bound to actor when it records actors, and uc unchanged otherwise
+func ForActor(uc UserUsecase, actor string) UserUsecase {
+	if binder, ok := uc.(ActorBinder); ok {
+		return binder.WithActor(actor)
+	}
+	return uc
+}
+
+// AuditUserUsecase decorates a UserUsecase and records every successful
+// create, update, delete, password change and email change in the audit log.
+// Reads are passed through untouched. Audit write failures are logged rather
+// than returned, since the mutation has already happened.
+type AuditUserUsecase struct {
+	next  UserUsecase
+	audit AuditLogWriter
+	actor string
+	now   func() time.Time
+}
+
+// NewAuditUserUsecase creates an auditing decorator around an existing user usecase
+func NewAuditUserUsecase(next UserUsecase, audit AuditLogWriter) *AuditUserUsecase {
+	return &AuditUserUsecase{
+		next:  next,
+		audit: audit,
+		actor: entity.AuditActorAnonymous,
+		now:   time.Now,
+	}
+}
+
+// WithActor returns a copy of the usecase that records actor on its audit entries
+func (u *AuditUserUsecase) WithActor(actor string) UserUsecase {
+	bound := *u
+	bound.actor = actor
+	return &bound
+}
+
+// CreateUser creates a user and records the new field values
+func (u *AuditUserUsecase) CreateUser(ctx context.Context, req entity.UserRequest) (*entity.UserResponse, error) {
+	user, err := u.next.CreateUser(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	u.record(entity.AuditActionCreate, user.ID, diffUsers(nil, user))
+	return user, nil
+}
+
+// GetUserByID retrieves a user by ID
+func (u *AuditUserUsecase) GetUserByID(ctx context.Context, id uint) (*entity.UserResponse, error) {
+	return u.next.GetUserByID(ctx, id)
+}
+
+// GetUserByEmail retrieves a user by email
+func (u *AuditUserUsecase) GetUserByEmail(ctx context.Context, email string) (*entity.UserResponse, error) {
+	return u.next.GetUserByEmail(ctx, email)
+}
+
+// GetAllUsers retrieves all users
+func (u *AuditUserUsecase) GetAllUsers(ctx context.Context) ([]entity.UserResponse, error) {
+	return u.next.GetAllUsers(ctx)
+}
+
+// UpdateUser updates a user and records the fields that changed
+func (u *AuditUserUsecase) UpdateUser(ctx context.Context, id uint, req entity.UpdateUserRequest) (*entity.UserResponse, error) {
+	before, _ := u.next.GetUserByID(ctx, id)
+
+	user, err := u.next.UpdateUser(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	u.record(entity.AuditActionUpdate, id, diffUsers(before, user))
+	return user, nil
+}
+
+// DeleteUser deletes a user and records its last field values
+func (u *AuditUserUsecase) DeleteUser(ctx context.Context, id uint) error {
+	before, _ := u.next.GetUserByID(ctx, id)
+
+	if err := u.next.DeleteUser(ctx, id); err != nil {
+		return err
+	}
+	u.record(entity.AuditActionDelete, id, diffUsers(before, nil))
+	return nil
+}
+
+// ChangePassword changes a user's password and records that it changed
+func (u *AuditUserUsecase) ChangePassword(ctx context.Context, id uint, current, newPassword string) error {
+	if err := u.next.ChangePassword(ctx, id, current, newPassword); err != nil {
+		return err
+	}
+	u.record(entity.AuditActionChangePassword, id, map[string]entity.AuditChange{
+		"password": {After: logging.Redacted},
+	})
+	return nil
+}
+
+// ChangeEmail changes a user's email and records the change. When the change
+// waits for the new address to be verified, the email is unchanged and the
+// new address is recorded as pending_email instead.
+func (u *AuditUserUsecase) ChangeEmail(ctx context.Context, id uint, currentPassword, newEmail string) error {
+	before, _ := u.next.GetUserByID(ctx, id)
+
+	if err := u.next.ChangeEmail(ctx, id, currentPassword, newEmail); err != nil {
+		return err
+	}
+
+	after, _ := u.next.GetUserByID(ctx, id)
+	changes := diffUsers(before, after)
+	if _, changed := changes["email"]; !changed {
+		changes["pending_email"] = entity.AuditChange{After: normalizeEmail(newEmail)}
+	}
+	u.record(entity.AuditActionChangeEmail, id, changes)
+	return nil
+}
+
+// Authenticate verifies a user's credentials
+func (u *AuditUserUsecase) Authenticate(ctx context.Context, email, password string) (*entity.UserResponse, error) {
+	return u.next.Authenticate(ctx, email, password)
+}
+
+// CountUsers returns the total number of users
+func (u *AuditUserUsecase) CountUsers(ctx context.Context) (int64, error) {
+	return u.next.CountUsers(ctx)
+}
+
+// ListUsers retrieves a page of users matching the filter
+func (u *AuditUserUsecase) ListUsers(ctx context.Context, filter repository.UserFilter, page, limit int) ([]entity.UserResponse, error) {
+	return u.next.ListUsers(ctx, filter, page, limit)
+}
+
+// record writes an audit entry, logging instead of failing when the write fails
+func (u *AuditUserUsecase) record(action string, targetID uint, changes map[string]entity.AuditChange) {
+	entry := &entity.AuditLog{
+		Actor:     u.actor,
+		Action:    action,
+		TargetID:  targetID,
+		Changes:   changes,
+		Timestamp: u.now(),
+	}
+	if err := u.audit.Create(entry); err != nil {
+		log.Printf("ERROR: Failed to write audit log for %s of user %d: %v", action, targetID, err)
+	}
+}
+
+// diffUsers returns the audited fields that differ between two versions of a
+// user; a nil version means the user did not exist on that side
+func diffUsers(before, after *entity.UserResponse) map[string]entity.AuditChange {
+	var b, a entity.UserResponse
+	if before != nil {
+		b = *before
+	}
+	if after != nil {
+		a = *after
+	}
+
+	changes := make(map[string]entity.AuditChange)
+	addChange := func(field, beforeValue, afterValue string) {
+		if beforeValue != afterValue {
+			changes[field] = entity.AuditChange{Before: emptyToNil(beforeValue), After: emptyToNil(afterValue)}
+		}
+	}
+	addChange("name", b.Name, a.Name)
+	addChange("email", b.Email, a.Email)
+	addChange("role", b.Role, a.Role)
+	return changes
+}
+
+// emptyToNil maps an empty string to nil so absent values are omitted from the diff
+func emptyToNil(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditWriter keeps the audit entries written through it
+type recordingAuditWriter struct {
+	entries []*entity.AuditLog
+	err     error
+}
+
+func (w *recordingAuditWriter) Create(entry *entity.AuditLog) error {
+	w.entries = append(w.entries, entry)
+	return w.err
+}
+
+func newAuditTestUsecase(t *testing.T) (*AuditUserUsecase, *recordingAuditWriter) {
+	t.Helper()
+	writer := &recordingAuditWriter{}
+	base := NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), testHasher)
+	return NewAuditUserUsecase(base, writer), writer
+}
+
+func TestAuditUserUsecase_RecordsEachMutation(t *testing.T) {
+	ctx := context.Background()
+	audited, writer := newAuditTestUsecase(t)
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	audited.now = func() time.Time { return now }
+
+	user := createTestUser(t, audited)
+	require.Len(t, writer.entries, 1)
+	created := writer.entries[0]
+	assert.Equal(t, entity.AuditActionCreate, created.Action)
+	assert.Equal(t, entity.AuditActorAnonymous, created.Actor)
+	assert.Equal(t, user.ID, created.TargetID)
+	assert.Equal(t, now, created.Timestamp)
+	assert.Equal(t, entity.AuditChange{After: "john.doe@example.com"}, created.Changes["email"])
+	assert.NotContains(t, created.Changes, "password")
+
+	admin := ForActor(audited, "admin@example.com")
+	_, err := admin.UpdateUser(ctx, user.ID, entity.UpdateUserRequest{Name: "Johnny Doe", Email: "john.doe@example.com"})
+	require.NoError(t, err)
+	require.Len(t, writer.entries, 2)
+	updated := writer.entries[1]
+	assert.Equal(t, entity.AuditActionUpdate, updated.Action)
+	assert.Equal(t, "admin@example.com", updated.Actor)
+	assert.Equal(t, map[string]entity.AuditChange{
+		"name": {Before: "John Doe", After: "Johnny Doe"},
+	}, updated.Changes)
+
+	require.NoError(t, admin.ChangePassword(ctx, user.ID, "S3curePassword", "N3wSecurePassword"))
+	require.Len(t, writer.entries, 3)
+	assert.Equal(t, entity.AuditActionChangePassword, writer.entries[2].Action)
+
+	require.NoError(t, admin.DeleteUser(ctx, user.ID))
+	require.Len(t, writer.entries, 4)
+	deleted := writer.entries[3]
+	assert.Equal(t, entity.AuditActionDelete, deleted.Action)
+	assert.Equal(t, entity.AuditChange{Before: "Johnny Doe"}, deleted.Changes["name"])
+
+	// Binding an actor must not change the actor of the original usecase
+	assert.Equal(t, entity.AuditActorAnonymous, audited.actor)
+}
+
+func TestAuditUserUsecase_RecordsEmailChanges(t *testing.T) {
+	ctx := context.Background()
+	audited, writer := newAuditTestUsecase(t)
+	user := createTestUser(t, audited)
+
+	require.NoError(t, audited.ChangeEmail(ctx, user.ID, "S3curePassword", "john.new@example.com"))
+	require.Len(t, writer.entries, 2)
+	changed := writer.entries[1]
+	assert.Equal(t, entity.AuditActionChangeEmail, changed.Action)
+	assert.Equal(t, map[string]entity.AuditChange{
+		"email": {Before: "john.doe@example.com", After: "john.new@example.com"},
+	}, changed.Changes)
+
+	// A change waiting for verification leaves the email as it was
+	repo := repository.NewInMemoryUserRepository()
+	verifier := NewEmailVerifier(repo, newMemoryTokenStore(), &recordingNotifier{}, testHasher, "http://localhost:8080/users/verify", time.Hour)
+	verifier.SetKeepOldEmail(true)
+	writer = &recordingAuditWriter{}
+	deferred := Chain(NewUserUsecaseWithHasher(repo, testHasher), AuditDecorator(writer), VerificationDecorator(verifier))
+	user = createTestUser(t, deferred)
+
+	require.NoError(t, deferred.ChangeEmail(ctx, user.ID, "S3curePassword", "John.New@example.com"))
+	require.Len(t, writer.entries, 2)
+	assert.Equal(t, map[string]entity.AuditChange{
+		"pending_email": {After: "john.new@example.com"},
+	}, writer.entries[1].Changes)
+}
+
+func TestAuditUserUsecase_SkipsFailedMutationsAndReads(t *testing.T) {
+	ctx := context.Background()
+	audited, writer := newAuditTestUsecase(t)
+	user := createTestUser(t, audited)
+	writer.entries = nil
+
+	_, err := audited.CreateUser(ctx, entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "S3curePassword"})
+	assert.Error(t, err)
+	assert.Error(t, audited.DeleteUser(ctx, 999))
+	assert.ErrorIs(t, audited.ChangePassword(ctx, user.ID, "WrongPassword1", "N3wSecurePassword"), ErrIncorrectPassword)
+	_, err = audited.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	_, err = audited.Authenticate(ctx, "john.doe@example.com", "S3curePassword")
+	require.NoError(t, err)
+
+	assert.Empty(t, writer.entries)
+}
+
+func TestAuditUserUsecase_AuditFailureDoesNotFailMutation(t *testing.T) {
+	audited, writer := newAuditTestUsecase(t)
+	writer.err = errors.New("mongo unavailable")
+
+	user := createTestUser(t, audited)
+
+	assert.NotZero(t, user.ID)
+	assert.Len(t, writer.entries, 1)
+}
+
+func TestForActor_LeavesPlainUsecaseUnchanged(t *testing.T) {
+	base := NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), testHasher)
+
+	assert.Same(t, base, ForActor(base, "admin@example.com"))
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+)
+
+// UserUsecaseDecorator wraps a UserUsecase with a cross-cutting concern
+type UserUsecaseDecorator func(UserUsecase) UserUsecase
+
+// Chain wraps base with the decorators so that the first decorator is the
+// outermost: Chain(base, a, b) calls a, then b, then base
+func Chain(base UserUsecase, decorators ...UserUsecaseDecorator) UserUsecase {
+	uc := base
+	for i := len(decorators) - 1; i >= 0; i-- {
+		uc = decorators[i](uc)
+	}
+	return uc
+}
+
+// LoggingDecorator logs every usecase call with its duration and outcome
+func LoggingDecorator(logger *slog.Logger) UserUsecaseDecorator {
+	return func(next UserUsecase) UserUsecase {
+		return NewLoggingUserUsecase(next, logger)
+	}
+}
+
+// MetricsDecorator records the latency and outcome of every usecase call
+func MetricsDecorator(recorder MetricsRecorder) UserUsecaseDecorator {
+	return func(next UserUsecase) UserUsecase {
+		return NewMetricsUserUsecase(next, recorder)
+	}
+}
+
+// AuditDecorator records successful user mutations in the audit log
+func AuditDecorator(audit AuditLogWriter) UserUsecaseDecorator {
+	return func(next UserUsecase) UserUsecase {
+		return NewAuditUserUsecase(next, audit)
+	}
+}
+
+// LoggingUserUsecase decorates a UserUsecase with call logging. Arguments are
+// never logged, so credentials and personal data stay out of the logs.
+type LoggingUserUsecase struct {
+	interceptedUserUsecase
+}
+
+// NewLoggingUserUsecase creates a logging decorator around an existing user usecase.
+// Successful calls are logged at debug level and failed calls at info level.
+func NewLoggingUserUsecase(next UserUsecase, logger *slog.Logger) *LoggingUserUsecase {
+	return &LoggingUserUsecase{interceptedUserUsecase{
+		next: next,
+		intercept: func(ctx context.Context, method string, call func() error) error {
+			start := time.Now()
+			err := call()
+			if err != nil {
+				logger.InfoContext(ctx, "usecase call failed", "method", method, "duration", time.Since(start), "error", err)
+			} else {
+				logger.DebugContext(ctx, "usecase call", "method", method, "duration", time.Since(start))
+			}
+			return err
+		},
+	}}
+}
+
+// MetricsRecorder receives the latency and outcome of usecase calls
+type MetricsRecorder interface {
+	Observe(method string, duration time.Duration, err error)
+}
+
+// MetricsUserUsecase decorates a UserUsecase with call metrics
+type MetricsUserUsecase struct {
+	interceptedUserUsecase
+}
+
+// NewMetricsUserUsecase creates a metrics decorator around an existing user usecase
+func NewMetricsUserUsecase(next UserUsecase, recorder MetricsRecorder) *MetricsUserUsecase {
+	return &MetricsUserUsecase{interceptedUserUsecase{
+		next: next,
+		intercept: func(_ context.Context, method string, call func() error) error {
+			start := time.Now()
+			err := call()
+			recorder.Observe(method, time.Since(start), err)
+			return err
+		},
+	}}
+}
+
+// interceptedUserUsecase delegates every call to next through intercept,
+// which receives the call's context and method name and runs the call
+type interceptedUserUsecase struct {
+	next      UserUsecase
+	intercept func(ctx context.Context, method string, call func() error) error
+}
+
+// WithActor returns a copy of the decorator whose inner usecase is bound to actor
+func (u *interceptedUserUsecase) WithActor(actor string) UserUsecase {
+	bound := *u
+	bound.next = ForActor(u.next, actor)
+	return &bound
+}
+
+// CreateUser creates a new user
+func (u *interceptedUserUsecase) CreateUser(ctx context.Context, req entity.UserRequest) (user *entity.UserResponse, err error) {
+	err = u.intercept(ctx, "CreateUser", func() error {
+		user, err = u.next.CreateUser(ctx, req)
+		return err
+	})
+	return user, err
+}
+
+// GetUserByID retrieves a user by ID
+func (u *interceptedUserUsecase) GetUserByID(ctx context.Context, id uint) (user *entity.UserResponse, err error) {
+	err = u.intercept(ctx, "GetUserByID", func() error {
+		user, err = u.next.GetUserByID(ctx, id)
+		return err
+	})
+	return user, err
+}
+
+// GetUserByEmail retrieves a user by email
+func (u *interceptedUserUsecase) GetUserByEmail(ctx context.Context, email string) (user *entity.UserResponse, err error) {
+	err = u.intercept(ctx, "GetUserByEmail", func() error {
+		user, err = u.next.GetUserByEmail(ctx, email)
+		return err
+	})
+	return user, err
+}
+
+// GetAllUsers retrieves all users
+func (u *interceptedUserUsecase) GetAllUsers(ctx context.Context) (users []entity.UserResponse, err error) {
+	err = u.intercept(ctx, "GetAllUsers", func() error {
+		users, err = u.next.GetAllUsers(ctx)
+		return err
+	})
+	return users, err
+}
+
+// UpdateUser updates a user
+func (u *interceptedUserUsecase) UpdateUser(ctx context.Context, id uint, req entity.UpdateUserRequest) (user *entity.UserResponse, err error) {
+	err = u.intercept(ctx, "UpdateUser", func() error {
+		user, err = u.next.UpdateUser(ctx, id, req)
+		return err
+	})
+	return user, err
+}
+
+// DeleteUser deletes a user by ID
+func (u *interceptedUserUsecase) DeleteUser(ctx context.Context, id uint) error {
+	return u.intercept(ctx, "DeleteUser", func() error {
+		return u.next.DeleteUser(ctx, id)
+	})
+}
+
+// ChangePassword replaces a user's password after verifying the current one
+func (u *interceptedUserUsecase) ChangePassword(ctx context.Context, id uint, current, newPassword string) error {
+	return u.intercept(ctx, "ChangePassword", func() error {
+		return u.next.ChangePassword(ctx, id, current, newPassword)
+	})
+}
+
+// ChangeEmail replaces a user's email and marks it unverified
+func (u *interceptedUserUsecase) ChangeEmail(ctx context.Context, id uint, currentPassword, newEmail string) error {
+	return u.intercept(ctx, "ChangeEmail", func() error {
+		return u.next.ChangeEmail(ctx, id, currentPassword, newEmail)
+	})
+}
+
+// Authenticate verifies a user's credentials
+func (u *interceptedUserUsecase) Authenticate(ctx context.Context, email, password string) (user *entity.UserResponse, err error) {
+	err = u.intercept(ctx, "Authenticate", func() error {
+		user, err = u.next.Authenticate(ctx, email, password)
+		return err
+	})
+	return user, err
+}
+
+// CountUsers returns the total number of users
+func (u *interceptedUserUsecase) CountUsers(ctx context.Context) (count int64, err error) {
+	err = u.intercept(ctx, "CountUsers", func() error {
+		count, err = u.next.CountUsers(ctx)
+		return err
+	})
+	return count, err
+}
+
+// ListUsers retrieves a page of users matching the filter
+func (u *interceptedUserUsecase) ListUsers(ctx context.Context, filter repository.UserFilter, page, limit int) (users []entity.UserResponse, err error) {
+	err = u.intercept(ctx, "ListUsers", func() error {
+		users, err = u.next.ListUsers(ctx, filter, page, limit)
+		return err
+	})
+	return users, err
+}
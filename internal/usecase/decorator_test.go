@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// tracingDecorator appends entries to trace before and after each intercepted call
+func tracingDecorator(name string, trace *[]string) UserUsecaseDecorator {
+	return func(next UserUsecase) UserUsecase {
+		return &interceptedUserUsecase{
+			next: next,
+			intercept: func(_ context.Context, method string, call func() error) error {
+				*trace = append(*trace, name+" before "+method)
+				err := call()
+				*trace = append(*trace, name+" after "+method)
+				return err
+			},
+		}
+	}
+}
+
+func TestChain_InvokesDecoratorsInOrderAroundCreate(t *testing.T) {
+	ctx := context.Background()
+	var trace []string
+	mockRepo := mocks.NewMockUserRepository(t)
+	mockRepo.On("GetByEmail", mock.Anything, "john.doe@example.com").Return(nil, gorm.ErrRecordNotFound).Run(func(_ mock.Arguments) {
+		trace = append(trace, "base")
+	})
+	mockRepo.On("Count", mock.Anything).Return(int64(1), nil)
+	mockRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	uc := Chain(NewUserUsecaseWithHasher(mockRepo, fakeHasher{}),
+		tracingDecorator("outer", &trace),
+		tracingDecorator("inner", &trace),
+	)
+	_, err := uc.CreateUser(ctx, entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "S3curePassword"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"outer before CreateUser",
+		"inner before CreateUser",
+		"base",
+		"inner after CreateUser",
+		"outer after CreateUser",
+	}, trace)
+}
+
+func TestChain_WithoutDecoratorsReturnsBase(t *testing.T) {
+	base := NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), fakeHasher{})
+
+	assert.Same(t, base, Chain(base))
+}
+
+func TestChain_ForwardsActorToAudit(t *testing.T) {
+	writer := &recordingAuditWriter{}
+	uc := Chain(NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), fakeHasher{}),
+		LoggingDecorator(slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))),
+		MetricsDecorator(NewUsecaseMetrics()),
+		AuditDecorator(writer),
+	)
+
+	createTestUser(t, ForActor(uc, "admin@example.com"))
+
+	require.Len(t, writer.entries, 1)
+	assert.Equal(t, "admin@example.com", writer.entries[0].Actor)
+}
+
+func TestLoggingUserUsecase(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	uc := NewLoggingUserUsecase(NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), fakeHasher{}), logger)
+
+	createTestUser(t, uc)
+	_, err := uc.GetUserByID(ctx, 99)
+	require.Error(t, err)
+
+	logs := buf.String()
+	assert.Contains(t, logs, "level=DEBUG msg=\"usecase call\" method=CreateUser")
+	assert.Contains(t, logs, "level=INFO msg=\"usecase call failed\" method=GetUserByID")
+	assert.NotContains(t, logs, "S3curePassword")
+	assert.NotContains(t, logs, "john.doe@example.com")
+}
+
+func TestMetricsUserUsecase(t *testing.T) {
+	ctx := context.Background()
+	metrics := NewUsecaseMetrics()
+	uc := NewMetricsUserUsecase(NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), fakeHasher{}), metrics)
+
+	user := createTestUser(t, uc)
+	_, err := uc.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	_, err = uc.GetUserByID(ctx, 99)
+	require.Error(t, err)
+
+	snapshot := metrics.Snapshot()
+	assert.Equal(t, int64(1), snapshot["CreateUser"].Calls)
+	assert.Equal(t, int64(2), snapshot["GetUserByID"].Calls)
+	assert.Equal(t, int64(1), snapshot["GetUserByID"].Errors)
+	assert.NotContains(t, snapshot, "DeleteUser")
+}
+
+func TestUsecaseMetrics_Snapshot(t *testing.T) {
+	metrics := NewUsecaseMetrics()
+	metrics.Observe("CreateUser", 10*time.Millisecond, nil)
+	metrics.Observe("CreateUser", 30*time.Millisecond, errors.New("boom"))
+
+	stats := metrics.Snapshot()["CreateUser"]
+
+	assert.Equal(t, MethodStats{Calls: 2, Errors: 1, AvgDurationMs: 20, MaxDurationMs: 30}, stats)
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+)
+
+// MethodStats summarizes the calls made to one usecase method
+type MethodStats struct {
+	Calls         int64   `json:"calls"`
+	Errors        int64   `json:"errors"`
+	AvgDurationMs float64 `json:"avgDurationMs"`
+	MaxDurationMs float64 `json:"maxDurationMs"`
+}
+
+// UsecaseMetrics is an in-process MetricsRecorder that keeps per-method call
+// counts, failures and latency
+type UsecaseMetrics struct {
+	mu      sync.Mutex
+	methods map[string]*methodTotals
+}
+
+// methodTotals accumulates the raw totals behind MethodStats
+type methodTotals struct {
+	calls  int64
+	errors int64
+	total  time.Duration
+	max    time.Duration
+}
+
+// NewUsecaseMetrics creates an empty metrics recorder
+func NewUsecaseMetrics() *UsecaseMetrics {
+	return &UsecaseMetrics{methods: make(map[string]*methodTotals)}
+}
+
+// Observe records one call of method
+func (m *UsecaseMetrics) Observe(method string, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	totals, ok := m.methods[method]
+	if !ok {
+		totals = &methodTotals{}
+		m.methods[method] = totals
+	}
+	totals.calls++
+	if err != nil {
+		totals.errors++
+	}
+	totals.total += duration
+	totals.max = max(totals.max, duration)
+}
+
+// Snapshot returns the current statistics keyed by method name
+func (m *UsecaseMetrics) Snapshot() map[string]MethodStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]MethodStats, len(m.methods))
+	for method, totals := range m.methods {
+		snapshot[method] = MethodStats{
+			Calls:         totals.calls,
+			Errors:        totals.errors,
+			AvgDurationMs: durationMillis(totals.total) / float64(totals.calls),
+			MaxDurationMs: durationMillis(totals.max),
+		}
+	}
+	return snapshot
+}
+
+// durationMillis converts a duration to fractional milliseconds
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
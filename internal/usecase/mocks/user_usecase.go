@@ -0,0 +1,99 @@
+// Package mocks provides testify mocks of the usecase interfaces
+package mocks
+
+import (
+	"context"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockUserUsecase is a testify mock implementing usecase.UserUsecase
+type MockUserUsecase struct {
+	mock.Mock
+}
+
+// NewMockUserUsecase creates a mock whose expectations are asserted when the test ends
+func NewMockUserUsecase(t mock.TestingT) *MockUserUsecase {
+	m := &MockUserUsecase{}
+	m.Test(t)
+	if c, ok := t.(interface{ Cleanup(func()) }); ok {
+		c.Cleanup(func() { m.AssertExpectations(t) })
+	}
+	return m
+}
+
+// CreateUser mocks UserUsecase.CreateUser
+func (m *MockUserUsecase) CreateUser(ctx context.Context, req entity.UserRequest) (*entity.UserResponse, error) {
+	args := m.Called(ctx, req)
+	user, _ := args.Get(0).(*entity.UserResponse)
+	return user, args.Error(1)
+}
+
+// GetUserByID mocks UserUsecase.GetUserByID
+func (m *MockUserUsecase) GetUserByID(ctx context.Context, id uint) (*entity.UserResponse, error) {
+	args := m.Called(ctx, id)
+	user, _ := args.Get(0).(*entity.UserResponse)
+	return user, args.Error(1)
+}
+
+// GetUserByEmail mocks UserUsecase.GetUserByEmail
+func (m *MockUserUsecase) GetUserByEmail(ctx context.Context, email string) (*entity.UserResponse, error) {
+	args := m.Called(ctx, email)
+	user, _ := args.Get(0).(*entity.UserResponse)
+	return user, args.Error(1)
+}
+
+// GetAllUsers mocks UserUsecase.GetAllUsers
+func (m *MockUserUsecase) GetAllUsers(ctx context.Context) ([]entity.UserResponse, error) {
+	args := m.Called(ctx)
+	users, _ := args.Get(0).([]entity.UserResponse)
+	return users, args.Error(1)
+}
+
+// UpdateUser mocks UserUsecase.UpdateUser
+func (m *MockUserUsecase) UpdateUser(ctx context.Context, id uint, req entity.UpdateUserRequest) (*entity.UserResponse, error) {
+	args := m.Called(ctx, id, req)
+	user, _ := args.Get(0).(*entity.UserResponse)
+	return user, args.Error(1)
+}
+
+// DeleteUser mocks UserUsecase.DeleteUser
+func (m *MockUserUsecase) DeleteUser(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// ChangePassword mocks UserUsecase.ChangePassword
+func (m *MockUserUsecase) ChangePassword(ctx context.Context, id uint, current, newPassword string) error {
+	args := m.Called(ctx, id, current, newPassword)
+	return args.Error(0)
+}
+
+// ChangeEmail mocks UserUsecase.ChangeEmail
+func (m *MockUserUsecase) ChangeEmail(ctx context.Context, id uint, currentPassword, newEmail string) error {
+	args := m.Called(ctx, id, currentPassword, newEmail)
+	return args.Error(0)
+}
+
+// Authenticate mocks UserUsecase.Authenticate
+func (m *MockUserUsecase) Authenticate(ctx context.Context, email, password string) (*entity.UserResponse, error) {
+	args := m.Called(ctx, email, password)
+	user, _ := args.Get(0).(*entity.UserResponse)
+	return user, args.Error(1)
+}
+
+// CountUsers mocks UserUsecase.CountUsers
+func (m *MockUserUsecase) CountUsers(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	count, _ := args.Get(0).(int64)
+	return count, args.Error(1)
+}
+
+// ListUsers mocks UserUsecase.ListUsers
+func (m *MockUserUsecase) ListUsers(ctx context.Context, filter repository.UserFilter, page, limit int) ([]entity.UserResponse, error) {
+	args := m.Called(ctx, filter, page, limit)
+	users, _ := args.Get(0).([]entity.UserResponse)
+	return users, args.Error(1)
+}
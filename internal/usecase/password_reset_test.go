@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/pkg/logging"
+	"github.com/example/go-clean-architecture/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestResetter returns a user usecase and a password resetter sharing an in-memory repository
+func newTestResetter(t *testing.T) (UserUsecase, *PasswordResetter, *recordingNotifier) {
+	t.Helper()
+	repo := repository.NewInMemoryUserRepository()
+	notifier := &recordingNotifier{}
+	resetter := NewPasswordResetter(repo, newMemoryTokenStore(), notifier, fakeHasher{}, "http://localhost:8080/password-reset", time.Hour)
+	uc := NewUserUsecaseWithHasher(repo, fakeHasher{})
+	createTestUser(t, uc)
+	return uc, resetter, notifier
+}
+
+func TestPasswordResetter_RequestReset(t *testing.T) {
+	ctx := context.Background()
+	_, resetter, notifier := newTestResetter(t)
+
+	require.NoError(t, resetter.RequestReset(ctx, " John.Doe@example.com "))
+
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, "john.doe@example.com", notifier.sent[0].to)
+	assert.NotEmpty(t, tokenFromLink(t, notifier.sent[0].body))
+}
+
+func TestPasswordResetter_RequestResetUnknownEmail(t *testing.T) {
+	ctx := context.Background()
+	_, resetter, notifier := newTestResetter(t)
+
+	assert.NoError(t, resetter.RequestReset(ctx, "nobody@example.com"))
+	assert.Empty(t, notifier.sent)
+}
+
+func TestPasswordResetter_ConfirmReset(t *testing.T) {
+	ctx := context.Background()
+	uc, resetter, notifier := newTestResetter(t)
+	require.NoError(t, resetter.RequestReset(ctx, "john.doe@example.com"))
+
+	require.NoError(t, resetter.ConfirmReset(ctx, tokenFromLink(t, notifier.sent[0].body), "N3wPassword!"))
+
+	_, err := uc.Authenticate(ctx, "john.doe@example.com", "N3wPassword!")
+	assert.NoError(t, err)
+	_, err = uc.Authenticate(ctx, "john.doe@example.com", "S3curePassword")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestPasswordResetter_ConfirmResetInvalidatesOtherLinks(t *testing.T) {
+	ctx := context.Background()
+	_, resetter, notifier := newTestResetter(t)
+	require.NoError(t, resetter.RequestReset(ctx, "john.doe@example.com"))
+	require.NoError(t, resetter.RequestReset(ctx, "john.doe@example.com"))
+
+	require.NoError(t, resetter.ConfirmReset(ctx, tokenFromLink(t, notifier.sent[1].body), "N3wPassword!"))
+
+	err := resetter.ConfirmReset(ctx, tokenFromLink(t, notifier.sent[0].body), "Att4ckerPassword!")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestPasswordResetter_ConfirmResetIsAudited(t *testing.T) {
+	ctx := context.Background()
+	_, resetter, notifier := newTestResetter(t)
+	writer := &recordingAuditWriter{}
+	resetter.SetAuditLog(writer)
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	resetter.now = func() time.Time { return now }
+	require.NoError(t, resetter.RequestReset(ctx, "john.doe@example.com"))
+
+	require.NoError(t, resetter.ConfirmReset(ctx, tokenFromLink(t, notifier.sent[0].body), "N3wPassword!"))
+
+	require.Len(t, writer.entries, 1)
+	entry := writer.entries[0]
+	assert.Equal(t, "john.doe@example.com", entry.Actor)
+	assert.Equal(t, entity.AuditActionResetPassword, entry.Action)
+	assert.Equal(t, uint(1), entry.TargetID)
+	assert.Equal(t, map[string]entity.AuditChange{"password": {After: logging.Redacted}}, entry.Changes)
+	assert.Equal(t, now, entry.Timestamp)
+}
+
+func TestPasswordResetter_ConfirmResetExpiredToken(t *testing.T) {
+	ctx := context.Background()
+	uc, resetter, notifier := newTestResetter(t)
+	require.NoError(t, resetter.RequestReset(ctx, "john.doe@example.com"))
+	resetter.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	err := resetter.ConfirmReset(ctx, tokenFromLink(t, notifier.sent[0].body), "N3wPassword!")
+
+	assert.ErrorIs(t, err, ErrTokenExpired)
+	_, err = uc.Authenticate(ctx, "john.doe@example.com", "S3curePassword")
+	assert.NoError(t, err, "the password must not change")
+}
+
+func TestPasswordResetter_ConfirmResetRejectsReuse(t *testing.T) {
+	ctx := context.Background()
+	_, resetter, notifier := newTestResetter(t)
+	require.NoError(t, resetter.RequestReset(ctx, "john.doe@example.com"))
+	token := tokenFromLink(t, notifier.sent[0].body)
+
+	require.NoError(t, resetter.ConfirmReset(ctx, token, "N3wPassword!"))
+	err := resetter.ConfirmReset(ctx, token, "An0therPassword!")
+
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestPasswordResetter_ConfirmResetWeakPasswordKeepsToken(t *testing.T) {
+	ctx := context.Background()
+	_, resetter, notifier := newTestResetter(t)
+	require.NoError(t, resetter.RequestReset(ctx, "john.doe@example.com"))
+	token := tokenFromLink(t, notifier.sent[0].body)
+
+	var strengthErr *utils.PasswordStrengthError
+	require.ErrorAs(t, resetter.ConfirmReset(ctx, token, "weak"), &strengthErr)
+
+	assert.NoError(t, resetter.ConfirmReset(ctx, token, "N3wPassword!"))
+}
+
+func TestPasswordResetter_ConfirmResetUsesPasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+	_, resetter, notifier := newTestResetter(t)
+	resetter.SetPasswordPolicy(utils.PasswordPolicy{MinLength: 16})
+	require.NoError(t, resetter.RequestReset(ctx, "john.doe@example.com"))
+	token := tokenFromLink(t, notifier.sent[0].body)
+
+	var strengthErr *utils.PasswordStrengthError
+	require.ErrorAs(t, resetter.ConfirmReset(ctx, token, "N3wPassword!"), &strengthErr)
+
+	assert.NoError(t, resetter.ConfirmReset(ctx, token, "correct horse battery"))
+}
+
+func TestPasswordResetter_ConfirmResetUnknownToken(t *testing.T) {
+	ctx := context.Background()
+	_, resetter, _ := newTestResetter(t)
+
+	assert.ErrorIs(t, resetter.ConfirmReset(ctx, "not-a-token", "N3wPassword!"), ErrInvalidToken)
+}
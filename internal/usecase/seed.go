@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+)
+
+// SeedAdmin creates an admin user with the given credentials when no users exist yet.
+// It reports whether a user was created and is a no-op on subsequent runs.
+func SeedAdmin(ctx context.Context, userUsecase UserUsecase, name, email, password string) (bool, error) {
+	if existing, _ := userUsecase.GetUserByEmail(ctx, email); existing != nil {
+		return false, nil
+	}
+
+	count, err := userUsecase.CountUsers(ctx)
+	if err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	_, err = userUsecase.CreateUser(ctx, entity.UserRequest{
+		Name:     name,
+		Email:    email,
+		Password: password,
+		Role:     entity.RoleAdmin,
+		Verified: true,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
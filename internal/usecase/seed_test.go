@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedAdmin_CreatesOnceAndSkipsAfterwards(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, testHasher, WithFirstUserAdmin(false))
+
+	created, err := SeedAdmin(ctx, uc, "Administrator", "admin@example.com", "S3curePassword")
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = SeedAdmin(ctx, uc, "Administrator", "admin@example.com", "S3curePassword")
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	users, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, entity.RoleAdmin, users[0].Role)
+	assert.True(t, users[0].Verified, "seeded admins need no email verification")
+}
+
+func TestSeedAdmin_SkipsWhenUsersExist(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, testHasher)
+	createTestUser(t, uc)
+
+	created, err := SeedAdmin(ctx, uc, "Administrator", "admin@example.com", "S3curePassword")
+	require.NoError(t, err)
+	assert.False(t, created)
+}
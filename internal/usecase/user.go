@@ -1,6 +1,12 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
 	"github.com/example/go-clean-architecture/internal/entity"
 	"github.com/example/go-clean-architecture/internal/repository"
 	"github.com/example/go-clean-architecture/pkg/utils"
@@ -8,36 +14,179 @@ import (
 
 // UserUsecase defines the interface for user business logic
 type UserUsecase interface {
-	CreateUser(req entity.UserRequest) (*entity.UserResponse, error)
-	GetUserByID(id uint) (*entity.UserResponse, error)
-	GetUserByEmail(email string) (*entity.UserResponse, error)
-	GetAllUsers() ([]entity.UserResponse, error)
-	UpdateUser(id uint, req entity.UserRequest) (*entity.UserResponse, error)
-	DeleteUser(id uint) error
+	CreateUser(ctx context.Context, req entity.UserRequest) (*entity.UserResponse, error)
+	GetUserByID(ctx context.Context, id uint) (*entity.UserResponse, error)
+	GetUserByEmail(ctx context.Context, email string) (*entity.UserResponse, error)
+	GetAllUsers(ctx context.Context) ([]entity.UserResponse, error)
+	UpdateUser(ctx context.Context, id uint, req entity.UpdateUserRequest) (*entity.UserResponse, error)
+	DeleteUser(ctx context.Context, id uint) error
+	ChangePassword(ctx context.Context, id uint, current, newPassword string) error
+	ChangeEmail(ctx context.Context, id uint, currentPassword, newEmail string) error
+	Authenticate(ctx context.Context, email, password string) (*entity.UserResponse, error)
+	CountUsers(ctx context.Context) (int64, error)
+	ListUsers(ctx context.Context, filter repository.UserFilter, page, limit int) ([]entity.UserResponse, error)
 }
 
 // userUsecase implements UserUsecase interface
 type userUsecase struct {
-	userRepo repository.UserRepository
+	userRepo        repository.UserRepository
+	firstUserAdmin  bool
+	emailDomains    EmailDomainChecker
+	hasher          utils.Hasher
+	passwordPolicy  utils.PasswordPolicy
+	maxUsers        int64
+	requireVerified bool
+	roles           map[string]bool
+	defaultRole     string
+}
+
+// EmailDomainChecker rejects emails whose domain cannot receive mail
+type EmailDomainChecker interface {
+	Check(ctx context.Context, email string) error
+}
+
+// UserUsecaseOption configures optional user usecase behavior
+type UserUsecaseOption func(*userUsecase)
+
+// WithFirstUserAdmin controls whether the first registered user is granted the admin role
+func WithFirstUserAdmin(enabled bool) UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.firstUserAdmin = enabled
+	}
+}
+
+// WithEmailDomainCheck makes CreateUser reject emails whose domain fails the checker
+func WithEmailDomainCheck(checker EmailDomainChecker) UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.emailDomains = checker
+	}
+}
+
+// WithHasher sets the password hasher; bcrypt at the default cost is used otherwise
+func WithHasher(hasher utils.Hasher) UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.hasher = hasher
+	}
+}
+
+// WithPasswordPolicy sets the rules new passwords must satisfy;
+// utils.DefaultPasswordPolicy is used otherwise
+func WithPasswordPolicy(policy utils.PasswordPolicy) UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.passwordPolicy = policy
+	}
+}
+
+// WithMaxUsers caps the number of registered users; zero means unlimited
+func WithMaxUsers(limit int64) UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.maxUsers = limit
+	}
+}
+
+// WithRequireVerifiedEmail controls whether users must verify their email before they can authenticate
+func WithRequireVerifiedEmail(enabled bool) UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.requireVerified = enabled
+	}
+}
+
+// DefaultRoles are the roles users may have unless WithRoles replaces them
+var DefaultRoles = []string{entity.RoleUser, entity.RoleAdmin}
+
+// WithRoles sets the roles users may be given; CreateUser and UpdateUser
+// reject any other role with an InvalidRoleError
+func WithRoles(roles ...string) UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.roles = roleSet(roles)
+	}
+}
+
+// roleSet returns roles as a set
+func roleSet(roles []string) map[string]bool {
+	set := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		set[role] = true
+	}
+	return set
+}
+
+// ValidateRoles reports whether roles and defaultRole can configure a user
+// usecase: the roles must include the admin role, which admin-only endpoints
+// and seeding rely on, and the default role, which must not be admin
+func ValidateRoles(roles []string, defaultRole string) error {
+	set := roleSet(roles)
+	if !set[entity.RoleAdmin] {
+		return fmt.Errorf("roles must include %q", entity.RoleAdmin)
+	}
+	if defaultRole == entity.RoleAdmin {
+		return fmt.Errorf("default role must not be %q, or every new user would be an admin", entity.RoleAdmin)
+	}
+	if !set[defaultRole] {
+		return fmt.Errorf("default role %q is not one of the roles %v", defaultRole, roles)
+	}
+	return nil
+}
+
+// WithDefaultRole sets the role of new users created without one, other than
+// a first user made admin
+func WithDefaultRole(role string) UserUsecaseOption {
+	return func(u *userUsecase) {
+		u.defaultRole = role
+	}
 }
 
 // NewUserUsecase creates a new user usecase
-func NewUserUsecase(userRepo repository.UserRepository) UserUsecase {
-	return &userUsecase{
-		userRepo: userRepo,
+func NewUserUsecase(userRepo repository.UserRepository, opts ...UserUsecaseOption) UserUsecase {
+	u := &userUsecase{
+		userRepo:       userRepo,
+		firstUserAdmin: true,
+		hasher:         utils.DefaultHasher,
+		passwordPolicy: utils.DefaultPasswordPolicy,
+		roles:          roleSet(DefaultRoles),
+		defaultRole:    entity.RoleUser,
+	}
+	for _, opt := range opts {
+		opt(u)
 	}
+	return u
+}
+
+// NewUserUsecaseWithHasher creates a new user usecase that hashes passwords with hasher,
+// such as a low-cost bcrypt hasher in tests
+func NewUserUsecaseWithHasher(userRepo repository.UserRepository, hasher utils.Hasher, opts ...UserUsecaseOption) UserUsecase {
+	return NewUserUsecase(userRepo, append(opts, WithHasher(hasher))...)
 }
 
 // CreateUser creates a new user
-func (u *userUsecase) CreateUser(req entity.UserRequest) (*entity.UserResponse, error) {
+func (u *userUsecase) CreateUser(ctx context.Context, req entity.UserRequest) (*entity.UserResponse, error) {
+	if err := normalizeUserRequest(&req); err != nil {
+		return nil, err
+	}
+	if err := u.checkRole(req.Role); err != nil {
+		return nil, err
+	}
+
 	// Check if user already exists
-	existingUser, _ := u.userRepo.GetByEmail(req.Email)
+	existingUser, _ := u.userRepo.GetByEmail(ctx, req.Email)
 	if existingUser != nil {
 		return nil, &EmailAlreadyExistsError{Email: req.Email}
 	}
 
+	// Validate password strength
+	if err := u.passwordPolicy.Validate(req.Password); err != nil {
+		return nil, err
+	}
+
+	// Reject email domains that cannot receive mail
+	if u.emailDomains != nil {
+		if err := u.emailDomains.Check(ctx, req.Email); err != nil {
+			return nil, err
+		}
+	}
+
 	// Hash the password
-	hashedPassword, err := utils.HashPassword(req.Password)
+	hashedPassword, err := u.hasher.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -47,123 +196,369 @@ func (u *userUsecase) CreateUser(req entity.UserRequest) (*entity.UserResponse,
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: hashedPassword,
+		Verified: req.Verified,
 	}
 
-	// Save user to repository
-	if err := u.userRepo.Create(user); err != nil {
+	// Count and save in one transaction so concurrent sign-ups cannot exceed
+	// the user limit or both become the first admin
+	err = u.userRepo.WithTransaction(ctx, func(tx repository.UserRepository) error {
+		if err := u.checkUserLimit(ctx, tx); err != nil {
+			return err
+		}
+
+		// Resolve the role for the new user
+		role, err := u.resolveRole(ctx, tx, req.Role)
+		if err != nil {
+			return err
+		}
+		user.Role = role
+
+		return tx.Create(ctx, user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Return response
-	response := &entity.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+	return newUserResponse(user), nil
+}
+
+// checkUserLimit returns ErrUserLimitReached when the user limit leaves no room for another user
+func (u *userUsecase) checkUserLimit(ctx context.Context, repo repository.UserRepository) error {
+	if u.maxUsers <= 0 {
+		return nil
 	}
 
-	return response, nil
+	count, err := repo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	if count >= u.maxUsers {
+		return ErrUserLimitReached
+	}
+	return nil
 }
 
-// GetUserByID retrieves a user by ID
-func (u *userUsecase) GetUserByID(id uint) (*entity.UserResponse, error) {
-	user, err := u.userRepo.GetByID(id)
-	if err != nil {
-		return nil, err
+// resolveRole returns the requested role, or the role for a new user: admin
+// for the first user when enabled and the configured default otherwise
+func (u *userUsecase) resolveRole(ctx context.Context, repo repository.UserRepository, requested string) (string, error) {
+	if requested != "" {
+		return requested, nil
 	}
 
-	response := &entity.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+	if u.firstUserAdmin {
+		count, err := repo.Count(ctx)
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return entity.RoleAdmin, nil
+		}
 	}
 
-	return response, nil
+	return u.defaultRole, nil
 }
 
-// GetUserByEmail retrieves a user by email
-func (u *userUsecase) GetUserByEmail(email string) (*entity.UserResponse, error) {
-	user, err := u.userRepo.GetByEmail(email)
+// checkRole returns InvalidRoleError for a requested role users may not have;
+// an empty role requests the default
+func (u *userUsecase) checkRole(role string) error {
+	if role != "" && !u.roles[role] {
+		return &InvalidRoleError{Role: role}
+	}
+	return nil
+}
+
+// GetUserByID retrieves a user by ID
+func (u *userUsecase) GetUserByID(ctx context.Context, id uint) (*entity.UserResponse, error) {
+	user, err := u.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	response := &entity.UserResponse{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+	return newUserResponse(user), nil
+}
+
+// GetUserByEmail retrieves a user by email
+func (u *userUsecase) GetUserByEmail(ctx context.Context, email string) (*entity.UserResponse, error) {
+	user, err := u.userRepo.GetByEmail(ctx, normalizeEmail(email))
+	if err != nil {
+		return nil, err
 	}
 
-	return response, nil
+	return newUserResponse(user), nil
 }
 
 // GetAllUsers retrieves all users
-func (u *userUsecase) GetAllUsers() ([]entity.UserResponse, error) {
-	users, err := u.userRepo.GetAll()
+func (u *userUsecase) GetAllUsers(ctx context.Context) ([]entity.UserResponse, error) {
+	users, err := u.userRepo.GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	var responses []entity.UserResponse
-	for _, user := range users {
-		response := entity.UserResponse{
-			ID:        user.ID,
-			Name:      user.Name,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-		}
-		responses = append(responses, response)
+	for i := range users {
+		responses = append(responses, *newUserResponse(&users[i]))
 	}
 
 	return responses, nil
 }
 
+// updatePreconditionContextKey carries the precondition an update must satisfy
+type updatePreconditionContextKey struct{}
+
+// ContextWithUpdatePrecondition returns a context whose UpdateUser call only
+// goes ahead while check accepts the stored user, e.g. an If-Match ETag. The
+// update is written only if the user is still unchanged, so a concurrent
+// update between the check and the write fails with ErrPreconditionFailed.
+func ContextWithUpdatePrecondition(ctx context.Context, check func(current *entity.UserResponse) bool) context.Context {
+	return context.WithValue(ctx, updatePreconditionContextKey{}, check)
+}
+
+// UpdatePrecondition returns the precondition set by ContextWithUpdatePrecondition, or nil
+func UpdatePrecondition(ctx context.Context) func(current *entity.UserResponse) bool {
+	check, _ := ctx.Value(updatePreconditionContextKey{}).(func(current *entity.UserResponse) bool)
+	return check
+}
+
 // UpdateUser updates a user
-func (u *userUsecase) UpdateUser(id uint, req entity.UserRequest) (*entity.UserResponse, error) {
-	// Get existing user
-	user, err := u.userRepo.GetByID(id)
+func (u *userUsecase) UpdateUser(ctx context.Context, id uint, req entity.UpdateUserRequest) (*entity.UserResponse, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, ErrBlankName
+	}
+	req.Email = normalizeEmail(req.Email)
+	if err := u.checkRole(req.Role); err != nil {
+		return nil, err
+	}
+
+	// Get existing user from the primary, as the update is based on it
+	ctx = repository.ContextWithPrimaryReads(ctx)
+	user, err := u.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	// Email changes need the current password and a uniqueness check, so
+	// they go through ChangeEmail instead
+	if user.Email != req.Email {
+		return nil, ErrEmailChangeNotAllowed
+	}
+
+	check := UpdatePrecondition(ctx)
+	if check != nil && !check(newUserResponse(user)) {
+		return nil, ErrPreconditionFailed
+	}
+	readAt := user.UpdatedAt
+
 	// Update user fields
 	user.Name = req.Name
-	user.Email = req.Email
+	if req.Role != "" {
+		user.Role = req.Role
+	}
 
-	// Hash the new password
-	hashedPassword, err := utils.HashPassword(req.Password)
+	// Save updated user, only over the version the precondition accepted
+	if check != nil {
+		err = u.userRepo.UpdateIfUnchanged(ctx, user, readAt)
+		if errors.Is(err, repository.ErrUserChanged) {
+			return nil, ErrPreconditionFailed
+		}
+	} else {
+		err = u.userRepo.Update(ctx, user)
+	}
 	if err != nil {
 		return nil, err
 	}
+
+	return newUserResponse(user), nil
+}
+
+// DeleteUser deletes a user by ID
+func (u *userUsecase) DeleteUser(ctx context.Context, id uint) error {
+	return u.userRepo.Delete(ctx, id)
+}
+
+// ChangePassword replaces a user's password after verifying the current one
+func (u *userUsecase) ChangePassword(ctx context.Context, id uint, current, newPassword string) error {
+	ctx = repository.ContextWithPrimaryReads(ctx)
+	user, err := u.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Verify the current password before accepting a new one
+	if !u.hasher.Compare(current, user.Password) {
+		return ErrIncorrectPassword
+	}
+
+	if err := u.passwordPolicy.Validate(newPassword); err != nil {
+		return err
+	}
+	hashedPassword, err := u.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
 	user.Password = hashedPassword
 
-	// Save updated user
-	if err := u.userRepo.Update(user); err != nil {
+	return u.userRepo.Update(ctx, user)
+}
+
+// ChangeEmail replaces a user's email after verifying their current password
+// and marks it unverified, so the new address has to be verified like a new
+// user's
+func (u *userUsecase) ChangeEmail(ctx context.Context, id uint, currentPassword, newEmail string) error {
+	newEmail = normalizeEmail(newEmail)
+	if newEmail == "" {
+		return ErrBlankEmail
+	}
+
+	ctx = repository.ContextWithPrimaryReads(ctx)
+	user, err := u.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !u.hasher.Compare(currentPassword, user.Password) {
+		return ErrIncorrectPassword
+	}
+	if user.Email == newEmail {
+		return nil
+	}
+
+	if err := checkEmailAvailable(ctx, u.userRepo, id, newEmail); err != nil {
+		return err
+	}
+	if u.emailDomains != nil {
+		if err := u.emailDomains.Check(ctx, newEmail); err != nil {
+			return err
+		}
+	}
+
+	user.Email = newEmail
+	user.Verified = false
+	return u.userRepo.Update(ctx, user)
+}
+
+// checkEmailAvailable returns EmailAlreadyExistsError when a user other than
+// id has the email
+func checkEmailAvailable(ctx context.Context, repo repository.UserRepository, id uint, email string) error {
+	existing, _ := repo.GetByEmail(ctx, email)
+	if existing != nil && existing.ID != id {
+		return &EmailAlreadyExistsError{Email: email}
+	}
+	return nil
+}
+
+// Authenticate verifies a user's credentials and returns the matching user.
+// The user is read from the primary, as cached users carry no password hash.
+func (u *userUsecase) Authenticate(ctx context.Context, email, password string) (*entity.UserResponse, error) {
+	ctx = repository.ContextWithPrimaryReads(ctx)
+	user, err := u.userRepo.GetByEmail(ctx, normalizeEmail(email))
+	if err != nil || !u.hasher.Compare(password, user.Password) {
+		return nil, ErrInvalidCredentials
+	}
+	if u.requireVerified && !user.Verified {
+		return nil, ErrEmailNotVerified
+	}
+	u.rehashPassword(ctx, user, password)
+
+	return newUserResponse(user), nil
+}
+
+// rehashPassword replaces a hash made by an algorithm other than the
+// configured one, now that the password is known. Failures are only logged,
+// since the stored hash still verifies.
+func (u *userUsecase) rehashPassword(ctx context.Context, user *entity.User, password string) {
+	rehasher, ok := u.hasher.(utils.Rehasher)
+	if !ok || !rehasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	hashedPassword, err := u.hasher.Hash(password)
+	if err == nil {
+		user.Password = hashedPassword
+		err = u.userRepo.Update(ctx, user)
+	}
+	if err != nil {
+		log.Printf("WARN: Failed to rehash the password of user %d: %v", user.ID, err)
+	}
+}
+
+// CountUsers returns the total number of users
+func (u *userUsecase) CountUsers(ctx context.Context) (int64, error) {
+	return u.userRepo.Count(ctx)
+}
+
+// ListUsers retrieves a page of users matching the filter
+func (u *userUsecase) ListUsers(ctx context.Context, filter repository.UserFilter, page, limit int) ([]entity.UserResponse, error) {
+	if err := filter.Validate(); err != nil {
 		return nil, err
 	}
 
-	// Return response
-	response := &entity.UserResponse{
+	users, err := u.userRepo.Find(ctx, filter, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]entity.UserResponse, 0, len(users))
+	for i := range users {
+		responses = append(responses, *newUserResponse(&users[i]))
+	}
+
+	return responses, nil
+}
+
+// normalizeUserRequest trims the name and normalizes the email so equivalent
+// input is stored identically, rejecting names that are blank after trimming
+func normalizeUserRequest(req *entity.UserRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return ErrBlankName
+	}
+	req.Email = normalizeEmail(req.Email)
+	return nil
+}
+
+// normalizeEmail trims and lower-cases an email address
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// newUserResponse maps a user entity to its response representation
+func newUserResponse(user *entity.User) *entity.UserResponse {
+	return &entity.UserResponse{
 		ID:        user.ID,
 		Name:      user.Name,
 		Email:     user.Email,
+		Role:      user.Role,
+		Verified:  user.Verified,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
-
-	return response, nil
 }
 
-// DeleteUser deletes a user by ID
-func (u *userUsecase) DeleteUser(id uint) error {
-	return u.userRepo.Delete(id)
-}
+// ErrBlankName is returned when a user's name is empty or only whitespace
+var ErrBlankName = errors.New("name must not be blank")
+
+// ErrBlankEmail is returned when a new email is empty or only whitespace
+var ErrBlankEmail = errors.New("email must not be blank")
+
+// ErrUserLimitReached is returned when registering another user would exceed the configured user limit
+var ErrUserLimitReached = errors.New("user limit reached")
+
+// ErrEmailChangeNotAllowed is returned when an update carries a different
+// email; emails are changed through ChangeEmail
+var ErrEmailChangeNotAllowed = errors.New("email cannot be changed by an update; use the change email endpoint")
+
+// ErrPreconditionFailed is returned when an update's precondition rejects
+// the stored user, or the user changes before the update is written
+var ErrPreconditionFailed = errors.New("user has changed since the precondition was checked")
+
+// ErrIncorrectPassword is returned when the supplied current password does not match
+var ErrIncorrectPassword = errors.New("current password is incorrect")
+
+// ErrInvalidCredentials is returned when authentication fails
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// ErrEmailNotVerified is returned when an unverified user authenticates while verification is required
+var ErrEmailNotVerified = errors.New("email address has not been verified")
 
 // EmailAlreadyExistsError represents an error when email already exists
 type EmailAlreadyExistsError struct {
@@ -173,3 +568,12 @@ type EmailAlreadyExistsError struct {
 func (e *EmailAlreadyExistsError) Error() string {
 	return "user with email " + e.Email + " already exists"
 }
+
+// InvalidRoleError is returned when a user is given a role that is not allowed
+type InvalidRoleError struct {
+	Role string
+}
+
+func (e *InvalidRoleError) Error() string {
+	return "role " + e.Role + " is not allowed"
+}
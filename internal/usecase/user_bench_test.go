@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/pkg/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BenchmarkCreateUser compares user creation at the production bcrypt cost
+// with the minimum cost used by the test suite
+func BenchmarkCreateUser(b *testing.B) {
+	ctx := context.Background()
+	for _, cost := range []int{bcrypt.DefaultCost, bcrypt.MinCost} {
+		b.Run(fmt.Sprintf("bcrypt-cost-%d", cost), func(b *testing.B) {
+			uc := NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), utils.BcryptHasher{Cost: cost})
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err := uc.CreateUser(ctx, entity.UserRequest{
+					Name:     "John Doe",
+					Email:    fmt.Sprintf("john.doe+%d@example.com", i),
+					Password: "S3curePassword",
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
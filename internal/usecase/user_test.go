@@ -1,37 +1,698 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/internal/repository/mocks"
+	"github.com/example/go-clean-architecture/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+// testHasher runs real bcrypt at the minimum cost to keep the suite fast
+var testHasher = utils.BcryptHasher{Cost: bcrypt.MinCost}
+
+// fakeHasher stands in for bcrypt so tests don't pay for its deliberate slowness
+type fakeHasher struct{}
+
+func (fakeHasher) Hash(password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+func (fakeHasher) Compare(password, hash string) bool {
+	return hash == "hashed:"+password
+}
+
 func TestUserUsecase_CreateUser(t *testing.T) {
-	// This is a placeholder test file to demonstrate the structure
-	// In a real implementation, you would:
-	// 1. Create mock implementations of your interfaces
-	// 2. Test each usecase method
-	// 3. Verify business logic
-	// 4. Check error handling
-
-	// Example of what a real test might look like:
-	// mockRepo := new(MockUserRepository)
-	// usecase := NewUserUsecase(mockRepo)
-	//
-	// req := entity.UserRequest{
-	//     Name:     "John Doe",
-	//     Email:    "john.doe@example.com",
-	//     Password: "password123",
-	// }
-	//
-	// mockRepo.On("GetByEmail", req.Email).Return(nil, errors.New("user not found"))
-	// mockRepo.On("Create", mock.AnythingOfType("*entity.User")).Return(nil)
-	//
-	// result, err := usecase.CreateUser(req)
-	//
-	// assert.NoError(t, err)
-	// assert.NotNil(t, result)
-	// assert.Equal(t, req.Name, result.Name)
-	// assert.Equal(t, req.Email, result.Email)
-
-	// For now, we'll just pass
-	t.Log("Placeholder test - in a real implementation, this would test the user usecase")
+	ctx := context.Background()
+	mockRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecaseWithHasher(mockRepo, fakeHasher{})
+
+	req := entity.UserRequest{
+		Name:     "John Doe",
+		Email:    "john.doe@example.com",
+		Password: "S3curePassword",
+	}
+
+	mockRepo.On("GetByEmail", mock.Anything, req.Email).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("Count", mock.Anything).Return(int64(1), nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(user *entity.User) bool {
+		return user.Name == req.Name &&
+			user.Email == req.Email &&
+			user.Role == entity.RoleUser &&
+			fakeHasher{}.Compare(req.Password, user.Password)
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*entity.User).ID = 2
+	}).Return(nil)
+
+	result, err := uc.CreateUser(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), result.ID)
+	assert.Equal(t, req.Name, result.Name)
+	assert.Equal(t, req.Email, result.Email)
+	assert.Equal(t, entity.RoleUser, result.Role)
+}
+
+func TestUserUsecase_CreateUserEmailExists(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecaseWithHasher(mockRepo, fakeHasher{})
+
+	mockRepo.On("GetByEmail", mock.Anything, "john.doe@example.com").Return(&entity.User{ID: 1, Email: "john.doe@example.com"}, nil)
+
+	_, err := uc.CreateUser(ctx, entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "S3curePassword"})
+
+	var existsErr *EmailAlreadyExistsError
+	require.ErrorAs(t, err, &existsErr)
+	assert.Equal(t, "john.doe@example.com", existsErr.Email)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUserUsecase_CreateUserHashError(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecase(mockRepo)
+
+	// bcrypt rejects passwords longer than 72 bytes
+	password := "S3cure" + strings.Repeat("x", 80)
+	mockRepo.On("GetByEmail", mock.Anything, "john.doe@example.com").Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := uc.CreateUser(ctx, entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: password})
+
+	assert.ErrorIs(t, err, bcrypt.ErrPasswordTooLong)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUserUsecase_GetUserByID(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecaseWithHasher(mockRepo, fakeHasher{})
+
+	mockRepo.On("GetByID", mock.Anything, uint(1)).Return(&entity.User{ID: 1, Name: "John Doe", Email: "john.doe@example.com", Password: "hash", Role: entity.RoleUser}, nil)
+	mockRepo.On("GetByID", mock.Anything, uint(2)).Return(nil, gorm.ErrRecordNotFound)
+
+	user, err := uc.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.UserResponse{ID: 1, Name: "John Doe", Email: "john.doe@example.com", Role: entity.RoleUser}, user)
+
+	_, err = uc.GetUserByID(ctx, 2)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestUserUsecase_UpdateUser(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecaseWithHasher(mockRepo, fakeHasher{})
+
+	mockRepo.On("GetByID", mock.Anything, uint(1)).Return(&entity.User{ID: 1, Name: "John Doe", Email: "john.doe@example.com", Password: "hash", Role: entity.RoleAdmin}, nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *entity.User) bool {
+		return user.ID == 1 &&
+			user.Name == "Johnny Doe" &&
+			user.Email == "john.doe@example.com" &&
+			user.Role == entity.RoleAdmin &&
+			user.Password == "hash"
+	})).Return(nil)
+
+	user, err := uc.UpdateUser(ctx, 1, entity.UpdateUserRequest{Name: "Johnny Doe", Email: "john.doe@example.com"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Johnny Doe", user.Name)
+	assert.Equal(t, entity.RoleAdmin, user.Role)
+}
+
+func TestUserUsecase_UpdateUserRejectsEmailChange(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, fakeHasher{})
+	user := createTestUser(t, uc)
+
+	_, err := uc.UpdateUser(ctx, user.ID, entity.UpdateUserRequest{Name: "John Doe", Email: "john.new@example.com"})
+
+	assert.ErrorIs(t, err, ErrEmailChangeNotAllowed)
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "john.doe@example.com", stored.Email)
+}
+
+func TestUserUsecase_UpdateUserKeepsPassword(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, fakeHasher{})
+	user := createTestUser(t, uc)
+	before, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	hash := before.Password
+
+	_, err = uc.UpdateUser(ctx, user.ID, entity.UpdateUserRequest{Name: "Johnny Doe", Email: "john.doe@example.com"})
+
+	require.NoError(t, err)
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hash, stored.Password)
+}
+
+func TestUserUsecase_UpdateUserPrecondition(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, fakeHasher{})
+	user := createTestUser(t, uc)
+	req := entity.UpdateUserRequest{Name: "Johnny Doe", Email: "john.doe@example.com"}
+
+	t.Run("rejected", func(t *testing.T) {
+		rejected := ContextWithUpdatePrecondition(ctx, func(*entity.UserResponse) bool { return false })
+
+		_, err := uc.UpdateUser(rejected, user.ID, req)
+
+		assert.ErrorIs(t, err, ErrPreconditionFailed)
+		stored, err := repo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", stored.Name)
+	})
+
+	t.Run("changed after the check", func(t *testing.T) {
+		racing := ContextWithUpdatePrecondition(ctx, func(current *entity.UserResponse) bool {
+			// Another writer updates the user between the check and the write
+			other, err := repo.GetByID(ctx, current.ID)
+			require.NoError(t, err)
+			other.Name = "Jack Doe"
+			require.NoError(t, repo.Update(ctx, other))
+			return true
+		})
+
+		_, err := uc.UpdateUser(racing, user.ID, req)
+
+		assert.ErrorIs(t, err, ErrPreconditionFailed)
+		stored, err := repo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Jack Doe", stored.Name, "the other writer's update is kept")
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		accepted := ContextWithUpdatePrecondition(ctx, func(*entity.UserResponse) bool { return true })
+
+		updated, err := uc.UpdateUser(accepted, user.ID, req)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Johnny Doe", updated.Name)
+	})
+}
+
+func TestUserUsecase_UpdateUserNotFound(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecaseWithHasher(mockRepo, fakeHasher{})
+
+	mockRepo.On("GetByID", mock.Anything, uint(1)).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := uc.UpdateUser(ctx, 1, entity.UpdateUserRequest{Name: "Johnny Doe", Email: "johnny.doe@example.com"})
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUserUsecase_DeleteUser(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecaseWithHasher(mockRepo, fakeHasher{})
+
+	mockRepo.On("Delete", mock.Anything, uint(1)).Return(nil).Once()
+	mockRepo.On("Delete", mock.Anything, uint(2)).Return(errors.New("connection refused")).Once()
+
+	assert.NoError(t, uc.DeleteUser(ctx, 1))
+	assert.EqualError(t, uc.DeleteUser(ctx, 2), "connection refused")
+}
+
+func createTestUser(t *testing.T, uc UserUsecase) *entity.UserResponse {
+	ctx := context.Background()
+	t.Helper()
+
+	user, err := uc.CreateUser(ctx, entity.UserRequest{
+		Name:     "John Doe",
+		Email:    "john.doe@example.com",
+		Password: "S3curePassword",
+	})
+	require.NoError(t, err)
+	return user
+}
+
+func TestUserUsecase_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, testHasher)
+	user := createTestUser(t, uc)
+
+	err := uc.ChangePassword(ctx, user.ID, "S3curePassword", "N3wSecurePassword")
+	require.NoError(t, err)
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, testHasher.Compare("N3wSecurePassword", stored.Password))
+}
+
+func TestUserUsecase_ChangePasswordWrongCurrent(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, testHasher)
+	user := createTestUser(t, uc)
+
+	err := uc.ChangePassword(ctx, user.ID, "WrongPassword1", "N3wSecurePassword")
+	assert.ErrorIs(t, err, ErrIncorrectPassword)
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, testHasher.Compare("S3curePassword", stored.Password))
+}
+
+func TestUserUsecase_ChangeEmailResetsVerification(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, fakeHasher{})
+	user, err := uc.CreateUser(ctx, entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "S3curePassword", Verified: true})
+	require.NoError(t, err)
+
+	err = uc.ChangeEmail(ctx, user.ID, "S3curePassword", "  John.New@Example.com ")
+	require.NoError(t, err)
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "john.new@example.com", stored.Email)
+	assert.False(t, stored.Verified)
+}
+
+func TestUserUsecase_ChangeEmailToSameEmailKeepsVerification(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, fakeHasher{})
+	user, err := uc.CreateUser(ctx, entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "S3curePassword", Verified: true})
+	require.NoError(t, err)
+
+	require.NoError(t, uc.ChangeEmail(ctx, user.ID, "S3curePassword", "JOHN.DOE@example.com"))
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.Verified)
+}
+
+func TestUserUsecase_ChangeEmailRejectsTakenEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, fakeHasher{})
+	user := createTestUser(t, uc)
+	_, err := uc.CreateUser(ctx, entity.UserRequest{Name: "Jane Doe", Email: "jane.doe@example.com", Password: "S3curePassword"})
+	require.NoError(t, err)
+
+	err = uc.ChangeEmail(ctx, user.ID, "S3curePassword", "jane.doe@example.com")
+
+	var existsErr *EmailAlreadyExistsError
+	require.ErrorAs(t, err, &existsErr)
+	assert.Equal(t, "jane.doe@example.com", existsErr.Email)
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "john.doe@example.com", stored.Email)
+}
+
+func TestUserUsecase_ChangeEmailRequiresCurrentPassword(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, fakeHasher{})
+	user := createTestUser(t, uc)
+
+	err := uc.ChangeEmail(ctx, user.ID, "WrongPassword1", "john.new@example.com")
+
+	assert.ErrorIs(t, err, ErrIncorrectPassword)
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "john.doe@example.com", stored.Email)
+}
+
+func TestUserUsecase_ChangeEmailRejectsBlankEmail(t *testing.T) {
+	ctx := context.Background()
+	uc := NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), fakeHasher{})
+	user := createTestUser(t, uc)
+
+	assert.ErrorIs(t, uc.ChangeEmail(ctx, user.ID, "S3curePassword", "   "), ErrBlankEmail)
+}
+
+func TestUserUsecase_CreateUserFirstUserBecomesAdmin(t *testing.T) {
+	ctx := context.Background()
+	uc := NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), testHasher)
+
+	first := createTestUser(t, uc)
+	second, err := uc.CreateUser(ctx, entity.UserRequest{
+		Name:     "Jane Doe",
+		Email:    "jane.doe@example.com",
+		Password: "S3curePassword",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, entity.RoleAdmin, first.Role)
+	assert.Equal(t, entity.RoleUser, second.Role)
+}
+
+func TestUserUsecase_CreateUserFirstUserAdminDisabled(t *testing.T) {
+	uc := NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), testHasher, WithFirstUserAdmin(false))
+
+	first := createTestUser(t, uc)
+
+	assert.Equal(t, entity.RoleUser, first.Role)
+}
+
+func TestUserUsecase_CreateUserAppliesDefaultRole(t *testing.T) {
+	ctx := context.Background()
+	uc := NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), fakeHasher{},
+		WithRoles("viewer", entity.RoleUser, entity.RoleAdmin), WithDefaultRole("viewer"))
+
+	first := createTestUser(t, uc)
+	second, err := uc.CreateUser(ctx, entity.UserRequest{Name: "Jane Doe", Email: "jane.doe@example.com", Password: "S3curePassword"})
+	require.NoError(t, err)
+	third, err := uc.CreateUser(ctx, entity.UserRequest{Name: "Jim Doe", Email: "jim.doe@example.com", Password: "S3curePassword", Role: entity.RoleUser})
+	require.NoError(t, err)
+
+	assert.Equal(t, entity.RoleAdmin, first.Role, "the first user still becomes admin")
+	assert.Equal(t, "viewer", second.Role)
+	assert.Equal(t, entity.RoleUser, third.Role)
+}
+
+func TestUserUsecase_RejectsUnknownRoles(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, fakeHasher{}, WithRoles(entity.RoleUser, entity.RoleAdmin))
+	user := createTestUser(t, uc)
+
+	_, err := uc.CreateUser(ctx, entity.UserRequest{Name: "Jane Doe", Email: "jane.doe@example.com", Password: "S3curePassword", Role: "superuser"})
+	var roleErr *InvalidRoleError
+	require.ErrorAs(t, err, &roleErr)
+	assert.Equal(t, "superuser", roleErr.Role)
+	count, err := repo.Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	_, err = uc.UpdateUser(ctx, user.ID, entity.UpdateUserRequest{Name: "John Doe", Email: "john.doe@example.com", Role: "superuser"})
+	assert.ErrorAs(t, err, &roleErr)
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.RoleAdmin, stored.Role)
+}
+
+func TestValidateRoles(t *testing.T) {
+	assert.NoError(t, ValidateRoles(DefaultRoles, entity.RoleUser))
+	assert.NoError(t, ValidateRoles([]string{"viewer", entity.RoleAdmin}, "viewer"))
+	assert.ErrorContains(t, ValidateRoles([]string{entity.RoleUser}, entity.RoleUser), `include "admin"`)
+	assert.ErrorContains(t, ValidateRoles(DefaultRoles, "viewer"), `default role "viewer"`)
+	assert.ErrorContains(t, ValidateRoles(DefaultRoles, entity.RoleAdmin), `default role must not be "admin"`)
+}
+
+func TestUserUsecase_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	uc := NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), testHasher)
+	created := createTestUser(t, uc)
+
+	user, err := uc.Authenticate(ctx, "john.doe@example.com", "S3curePassword")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, user.ID)
+
+	_, err = uc.Authenticate(ctx, "john.doe@example.com", "WrongPassword1")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestUserUsecase_AuthenticateRehashesOtherAlgorithms(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	created := createTestUser(t, NewUserUsecaseWithHasher(repo, testHasher))
+	argon2id := utils.Argon2idHasher{Time: 1, Memory: 1024, Threads: 1, KeyLen: 32, SaltLen: 16}
+	uc := NewUserUsecaseWithHasher(repo, utils.MigratingHasher{Hasher: argon2id})
+
+	_, err := uc.Authenticate(ctx, "john.doe@example.com", "S3curePassword")
+	require.NoError(t, err)
+
+	stored, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stored.Password, "$argon2id$"), stored.Password)
+	_, err = uc.Authenticate(ctx, "john.doe@example.com", "S3curePassword")
+	assert.NoError(t, err)
+}
+
+func TestUserUsecase_CreateUserDuplicateEmail(t *testing.T) {
+	ctx := context.Background()
+	uc := NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), testHasher)
+	createTestUser(t, uc)
+
+	_, err := uc.CreateUser(ctx, entity.UserRequest{
+		Name:     "Another John",
+		Email:    "john.doe@example.com",
+		Password: "S3curePassword",
+	})
+
+	var existsErr *EmailAlreadyExistsError
+	assert.ErrorAs(t, err, &existsErr)
+}
+
+func TestUserUsecase_ListUsers(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := mocks.NewMockUserRepository(t)
+	userUsecase := NewUserUsecaseWithHasher(mockRepo, fakeHasher{})
+
+	filter := repository.UserFilter{Query: "john"}
+	mockRepo.On("Find", mock.Anything, filter, 2, 10).Return([]entity.User{{ID: 11, Name: "John Doe", Password: "hash"}}, nil)
+
+	users, err := userUsecase.ListUsers(ctx, filter, 2, 10)
+
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, uint(11), users[0].ID)
+	assert.Equal(t, "John Doe", users[0].Name)
+}
+
+func TestUserUsecase_ListUsersRejectsInvertedDateRange(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := mocks.NewMockUserRepository(t)
+	userUsecase := NewUserUsecaseWithHasher(mockRepo, fakeHasher{})
+
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := userUsecase.ListUsers(ctx, repository.UserFilter{CreatedFrom: &from, CreatedTo: &to}, 1, 10)
+
+	assert.ErrorIs(t, err, repository.ErrInvalidDateRange)
+	mockRepo.AssertNotCalled(t, "Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// stubEmailDomainChecker rejects every email with the configured error
+type stubEmailDomainChecker struct {
+	err    error
+	checks []string
+}
+
+func (c *stubEmailDomainChecker) Check(ctx context.Context, email string) error {
+	c.checks = append(c.checks, email)
+	return c.err
+}
+
+func TestUserUsecase_CreateUserRejectsEmailDomain(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := mocks.NewMockUserRepository(t)
+	checker := &stubEmailDomainChecker{err: &utils.EmailDomainError{Domain: "bogus.invalid"}}
+	userUsecase := NewUserUsecase(mockRepo, WithEmailDomainCheck(checker))
+
+	mockRepo.On("GetByEmail", mock.Anything, "john@bogus.invalid").Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := userUsecase.CreateUser(ctx, entity.UserRequest{Name: "John", Email: "john@bogus.invalid", Password: "S3curePassword"})
+
+	var domainErr *utils.EmailDomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, []string{"john@bogus.invalid"}, checker.checks)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestUserUsecase_WithPasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy := utils.PasswordPolicy{MinLength: 16, Blocklist: []string{"AcmeCorp2024!"}}
+	uc := NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), fakeHasher{}, WithPasswordPolicy(policy))
+
+	// S3curePassword satisfies the default policy but is too short for this one
+	_, err := uc.CreateUser(ctx, entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "S3curePassword"})
+	var strengthErr *utils.PasswordStrengthError
+	require.ErrorAs(t, err, &strengthErr)
+
+	user, err := uc.CreateUser(ctx, entity.UserRequest{Name: "John Doe", Email: "john.doe@example.com", Password: "correct horse battery"})
+	require.NoError(t, err)
+	err = uc.ChangePassword(ctx, user.ID, "correct horse battery", "acmecorp2024!")
+	require.ErrorAs(t, err, &strengthErr)
+	assert.Equal(t, []string{"must be at least 16 characters long", "is too common"}, strengthErr.Failures)
+}
+
+func TestUserUsecase_UsesInjectedHasher(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, utils.DefaultArgon2idHasher)
+	user := createTestUser(t, uc)
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(stored.Password, "$argon2id$"), stored.Password)
+
+	_, err = uc.Authenticate(ctx, "john.doe@example.com", "S3curePassword")
+	assert.NoError(t, err)
+}
+
+func TestNewUserUsecase_HashesAtDefaultBcryptCost(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	user := createTestUser(t, NewUserUsecase(repo))
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	cost, err := bcrypt.Cost([]byte(stored.Password))
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+}
+
+func TestUserUsecase_CreateUserNormalizesInput(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, testHasher)
+
+	user, err := uc.CreateUser(ctx, entity.UserRequest{Name: "  Bob  ", Email: " BOB@X.com ", Password: "S3curePassword"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", user.Name)
+	assert.Equal(t, "bob@x.com", user.Email)
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", stored.Name)
+	assert.Equal(t, "bob@x.com", stored.Email)
+
+	// Differently cased or padded emails refer to the same user
+	_, err = uc.CreateUser(ctx, entity.UserRequest{Name: "Bobby", Email: "bob@X.COM", Password: "S3curePassword"})
+	var existsErr *EmailAlreadyExistsError
+	require.ErrorAs(t, err, &existsErr)
+
+	_, err = uc.Authenticate(ctx, " Bob@x.com", "S3curePassword")
+	assert.NoError(t, err)
+	found, err := uc.GetUserByEmail(ctx, "BOB@X.COM")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+}
+
+func TestUserUsecase_UpdateUserNormalizesInput(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewInMemoryUserRepository()
+	uc := NewUserUsecaseWithHasher(repo, testHasher)
+	user := createTestUser(t, uc)
+
+	updated, err := uc.UpdateUser(ctx, user.ID, entity.UpdateUserRequest{Name: "  Bob  ", Email: " John.Doe@Example.com "})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", updated.Name)
+	assert.Equal(t, "john.doe@example.com", updated.Email)
+}
+
+func TestUserUsecase_RejectsBlankNames(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := mocks.NewMockUserRepository(t)
+	uc := NewUserUsecaseWithHasher(mockRepo, fakeHasher{})
+
+	for _, name := range []string{"", "   ", "\t\n"} {
+		_, err := uc.CreateUser(ctx, entity.UserRequest{Name: name, Email: "bob@x.com", Password: "S3curePassword"})
+		assert.ErrorIs(t, err, ErrBlankName)
+
+		_, err = uc.UpdateUser(ctx, 1, entity.UpdateUserRequest{Name: name, Email: "bob@x.com"})
+		assert.ErrorIs(t, err, ErrBlankName)
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUserUsecase_CreateUserLimit(t *testing.T) {
+	ctx := context.Background()
+	uc := NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), testHasher, WithMaxUsers(2))
+
+	for _, email := range []string{"one@example.com", "two@example.com"} {
+		_, err := uc.CreateUser(ctx, entity.UserRequest{Name: "User", Email: email, Password: "S3curePassword"})
+		require.NoError(t, err)
+	}
+
+	_, err := uc.CreateUser(ctx, entity.UserRequest{Name: "User", Email: "three@example.com", Password: "S3curePassword"})
+	assert.ErrorIs(t, err, ErrUserLimitReached)
+
+	count, err := uc.CountUsers(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// Deleting a user frees a seat
+	require.NoError(t, uc.DeleteUser(ctx, 1))
+	_, err = uc.CreateUser(ctx, entity.UserRequest{Name: "User", Email: "three@example.com", Password: "S3curePassword"})
+	assert.NoError(t, err)
+}
+
+func TestUserUsecase_CreateUserLimitBoundary(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		maxUsers int64
+		existing int64
+		wantErr  error
+	}{
+		{"unlimited", 0, 1000, nil},
+		{"below limit", 5, 4, nil},
+		{"at limit", 5, 5, ErrUserLimitReached},
+		{"above limit", 5, 6, ErrUserLimitReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockUserRepository(t)
+			mockRepo.On("GetByEmail", mock.Anything, "new@example.com").Return(nil, gorm.ErrRecordNotFound)
+			if tt.maxUsers > 0 {
+				mockRepo.On("Count", mock.Anything).Return(tt.existing, nil).Once()
+			}
+			if tt.wantErr == nil {
+				mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.User")).Return(nil)
+			}
+			uc := NewUserUsecaseWithHasher(mockRepo, fakeHasher{}, WithFirstUserAdmin(false), WithMaxUsers(tt.maxUsers))
+
+			_, err := uc.CreateUser(ctx, entity.UserRequest{Name: "New", Email: "new@example.com", Password: "S3curePassword"})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestUserUsecase_CreateUserLimitConcurrent(t *testing.T) {
+	ctx := context.Background()
+	uc := NewUserUsecaseWithHasher(repository.NewInMemoryUserRepository(), fakeHasher{}, WithMaxUsers(3))
+
+	var wg sync.WaitGroup
+	var created atomic.Int64
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			email := "user" + strconv.Itoa(i) + "@example.com"
+			if _, err := uc.CreateUser(ctx, entity.UserRequest{Name: "User", Email: email, Password: "S3curePassword"}); err == nil {
+				created.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(3), created.Load())
 }
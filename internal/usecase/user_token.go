@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+)
+
+// ErrInvalidToken is returned for tokens that are unknown or were already used
+var ErrInvalidToken = errors.New("token is invalid")
+
+// ErrTokenExpired is returned for tokens used after they expired
+var ErrTokenExpired = errors.New("token has expired")
+
+// UserTokenStore persists single-use user tokens
+type UserTokenStore interface {
+	Create(token *entity.UserToken) error
+	Consume(purpose, id string) (*entity.UserToken, error)
+	DeleteForUser(purpose string, userID uint) error
+}
+
+// issueUserToken stores a new token for the user, to be sent to email, and
+// returns the raw token to send
+func issueUserToken(tokens UserTokenStore, purpose string, userID uint, email string, now time.Time, ttl time.Duration) (string, error) {
+	token, hash, err := newUserToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = tokens.Create(&entity.UserToken{
+		ID:        hash,
+		Purpose:   purpose,
+		UserID:    userID,
+		Email:     email,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeUserToken redeems a raw token, returning ErrInvalidToken when it is
+// unknown or used and ErrTokenExpired when it has expired
+func consumeUserToken(tokens UserTokenStore, purpose, token string, now time.Time) (*entity.UserToken, error) {
+	stored, err := tokens.Consume(purpose, hashUserToken(token))
+	if errors.Is(err, repository.ErrUserTokenNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stored.Expired(now) {
+		return nil, ErrTokenExpired
+	}
+	return stored, nil
+}
+
+// newUserToken returns a random URL-safe token and the hash it is stored under
+func newUserToken() (token, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(raw)
+	return token, hashUserToken(token), nil
+}
+
+// hashUserToken returns the hex SHA-256 hash a token is stored under
+func hashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/pkg/utils"
+)
+
+// Notifier delivers a message to a user's email address
+type Notifier interface {
+	Notify(ctx context.Context, to, subject, body string) error
+}
+
+// EmailVerifier issues email verification links and marks users verified
+// when a link is followed
+type EmailVerifier struct {
+	users        repository.UserRepository
+	tokens       UserTokenStore
+	notifier     Notifier
+	hasher       utils.Hasher
+	emailDomains EmailDomainChecker
+	audit        AuditLogWriter
+	verifyURL    string
+	ttl          time.Duration
+	keepOldEmail bool
+	now          func() time.Time
+}
+
+// NewEmailVerifier creates an email verifier whose links point at verifyURL
+// and stay valid for ttl. The hasher checks the current password of users
+// requesting an email change.
+func NewEmailVerifier(users repository.UserRepository, tokens UserTokenStore, notifier Notifier, hasher utils.Hasher, verifyURL string, ttl time.Duration) *EmailVerifier {
+	return &EmailVerifier{
+		users:     users,
+		tokens:    tokens,
+		notifier:  notifier,
+		hasher:    hasher,
+		verifyURL: verifyURL,
+		ttl:       ttl,
+		now:       time.Now,
+	}
+}
+
+// SetEmailDomainCheck rejects email change requests for addresses whose
+// domain fails the checker, as the user usecase does for direct changes
+func (v *EmailVerifier) SetEmailDomainCheck(checker EmailDomainChecker) {
+	v.emailDomains = checker
+}
+
+// SetAuditLog records the email changes completed by following a link in
+// audit, attributed to the user whose email changed. They bypass the user
+// usecase, and with it the audit decorator, which only sees the request.
+func (v *EmailVerifier) SetAuditLog(audit AuditLogWriter) {
+	v.audit = audit
+}
+
+// SetKeepOldEmail controls whether email changes wait for the new address to
+// be verified. While they wait, the user keeps the old, still verified
+// address; otherwise the new address replaces it at once, unverified.
+func (v *EmailVerifier) SetKeepOldEmail(enabled bool) {
+	v.keepOldEmail = enabled
+}
+
+// SendVerification stores a new verification token for the user and sends
+// them the link that verifies it
+func (v *EmailVerifier) SendVerification(ctx context.Context, user *entity.UserResponse) error {
+	return v.send(ctx, entity.TokenPurposeEmailVerification, user.ID, user.Name, user.Email)
+}
+
+// RequestEmailChange verifies the user's current password and sends a link to
+// newEmail that replaces the user's email with it when followed. The user's
+// email is left unchanged until then.
+func (v *EmailVerifier) RequestEmailChange(ctx context.Context, id uint, currentPassword, newEmail string) error {
+	newEmail = normalizeEmail(newEmail)
+	if newEmail == "" {
+		return ErrBlankEmail
+	}
+
+	ctx = repository.ContextWithPrimaryReads(ctx)
+	user, err := v.users.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !v.hasher.Compare(currentPassword, user.Password) {
+		return ErrIncorrectPassword
+	}
+	if user.Email == newEmail {
+		return nil
+	}
+	if err := checkEmailAvailable(ctx, v.users, id, newEmail); err != nil {
+		return err
+	}
+	if v.emailDomains != nil {
+		if err := v.emailDomains.Check(ctx, newEmail); err != nil {
+			return err
+		}
+	}
+
+	return v.send(ctx, entity.TokenPurposeEmailChange, user.ID, user.Name, newEmail)
+}
+
+// send stores a new token for the user and sends the link that redeems it to email
+func (v *EmailVerifier) send(ctx context.Context, purpose string, userID uint, name, email string) error {
+	token, err := issueUserToken(v.tokens, purpose, userID, email, v.now(), v.ttl)
+	if err != nil {
+		return err
+	}
+
+	link := v.verifyURL + "?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hi %s,\n\nConfirm your email address by opening %s\n\nThe link expires in %s.", name, link, v.ttl)
+	return v.notifier.Notify(ctx, email, "Verify your email address", body)
+}
+
+// Verify consumes a verification or email change token and marks its user
+// verified, first switching them to the new address of an email change.
+// Links sent to an address the user has since changed away from are invalid.
+func (v *EmailVerifier) Verify(ctx context.Context, token string) (*entity.UserResponse, error) {
+	stored, err := consumeUserToken(v.tokens, entity.TokenPurposeEmailVerification, token, v.now())
+	if errors.Is(err, ErrInvalidToken) {
+		stored, err = consumeUserToken(v.tokens, entity.TokenPurposeEmailChange, token, v.now())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = repository.ContextWithPrimaryReads(ctx)
+	user, err := v.users.GetByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	oldEmail := user.Email
+	switch {
+	case stored.Purpose == entity.TokenPurposeEmailChange:
+		// Another user may have taken the address since the link was sent
+		if err := checkEmailAvailable(ctx, v.users, user.ID, stored.Email); err != nil {
+			return nil, err
+		}
+		user.Email = stored.Email
+	case stored.Email != "" && stored.Email != user.Email:
+		return nil, ErrInvalidToken
+	case user.Verified:
+		return newUserResponse(user), nil
+	}
+
+	user.Verified = true
+	if err := v.users.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	if user.Email != oldEmail {
+		v.recordEmailChange(user.ID, oldEmail, user.Email)
+	}
+	return newUserResponse(user), nil
+}
+
+// recordEmailChange writes the audit entry of a completed email change.
+// Failures are logged rather than returned, since the change has been made.
+func (v *EmailVerifier) recordEmailChange(userID uint, oldEmail, newEmail string) {
+	if v.audit == nil {
+		return
+	}
+	entry := &entity.AuditLog{
+		Actor:     oldEmail,
+		Action:    entity.AuditActionChangeEmail,
+		TargetID:  userID,
+		Changes:   map[string]entity.AuditChange{"email": {Before: oldEmail, After: newEmail}},
+		Timestamp: v.now(),
+	}
+	if err := v.audit.Create(entry); err != nil {
+		log.Printf("ERROR: Failed to write audit log for %s of user %d: %v", entry.Action, userID, err)
+	}
+}
+
+// VerificationDecorator sends a verification link to every user created unverified
+func VerificationDecorator(verifier *EmailVerifier) UserUsecaseDecorator {
+	return func(next UserUsecase) UserUsecase {
+		return &VerifyingUserUsecase{UserUsecase: next, verifier: verifier}
+	}
+}
+
+// VerifyingUserUsecase decorates a UserUsecase and sends a verification link
+// after each user is created and each email change. Sending failures are
+// logged rather than returned, since the change has already been made.
+type VerifyingUserUsecase struct {
+	UserUsecase
+	verifier *EmailVerifier
+}
+
+// WithActor returns a copy of the decorator whose inner usecase is bound to actor
+func (u *VerifyingUserUsecase) WithActor(actor string) UserUsecase {
+	return &VerifyingUserUsecase{UserUsecase: ForActor(u.UserUsecase, actor), verifier: u.verifier}
+}
+
+// CreateUser creates a user and sends it a verification link unless it is already verified
+func (u *VerifyingUserUsecase) CreateUser(ctx context.Context, req entity.UserRequest) (*entity.UserResponse, error) {
+	user, err := u.UserUsecase.CreateUser(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if !user.Verified {
+		if err := u.verifier.SendVerification(ctx, user); err != nil {
+			log.Printf("ERROR: Failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+	return user, nil
+}
+
+// ChangeEmail changes a user's email and sends a verification link to the new
+// address. When the verifier keeps old emails, the change itself waits for the
+// link to be followed.
+func (u *VerifyingUserUsecase) ChangeEmail(ctx context.Context, id uint, currentPassword, newEmail string) error {
+	if u.verifier.keepOldEmail {
+		return u.verifier.RequestEmailChange(ctx, id, currentPassword, newEmail)
+	}
+
+	if err := u.UserUsecase.ChangeEmail(ctx, id, currentPassword, newEmail); err != nil {
+		return err
+	}
+	user, err := u.UserUsecase.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !user.Verified {
+		if err := u.verifier.SendVerification(ctx, user); err != nil {
+			log.Printf("ERROR: Failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/internal/entity"
+	"github.com/example/go-clean-architecture/internal/repository"
+	"github.com/example/go-clean-architecture/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTokenStore is a map-backed UserTokenStore
+type memoryTokenStore struct {
+	tokens map[string]entity.UserToken
+}
+
+func newMemoryTokenStore() *memoryTokenStore {
+	return &memoryTokenStore{tokens: make(map[string]entity.UserToken)}
+}
+
+func (s *memoryTokenStore) Create(token *entity.UserToken) error {
+	s.tokens[token.ID] = *token
+	return nil
+}
+
+func (s *memoryTokenStore) Consume(purpose, id string) (*entity.UserToken, error) {
+	token, ok := s.tokens[id]
+	if !ok || token.Purpose != purpose {
+		return nil, repository.ErrUserTokenNotFound
+	}
+	delete(s.tokens, id)
+	return &token, nil
+}
+
+func (s *memoryTokenStore) DeleteForUser(purpose string, userID uint) error {
+	for id, token := range s.tokens {
+		if token.Purpose == purpose && token.UserID == userID {
+			delete(s.tokens, id)
+		}
+	}
+	return nil
+}
+
+// sentMessage is a message captured by recordingNotifier
+type sentMessage struct {
+	to, subject, body string
+}
+
+// recordingNotifier captures messages instead of sending them
+type recordingNotifier struct {
+	sent []sentMessage
+	err  error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, to, subject, body string) error {
+	n.sent = append(n.sent, sentMessage{to: to, subject: subject, body: body})
+	return n.err
+}
+
+// tokenFromLink extracts the token query parameter from the link in a message body
+func tokenFromLink(t *testing.T, body string) string {
+	t.Helper()
+	start := strings.Index(body, "http://")
+	require.GreaterOrEqual(t, start, 0, "message contains no link")
+	link := strings.Fields(body[start:])[0]
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	return parsed.Query().Get("token")
+}
+
+// newTestVerifier returns a verifying usecase over an in-memory repository.
+// Email changes apply at once unless the test calls SetKeepOldEmail.
+func newTestVerifier(opts ...UserUsecaseOption) (UserUsecase, *EmailVerifier, *recordingNotifier) {
+	repo := repository.NewInMemoryUserRepository()
+	notifier := &recordingNotifier{}
+	verifier := NewEmailVerifier(repo, newMemoryTokenStore(), notifier, fakeHasher{}, "http://localhost:8080/users/verify", time.Hour)
+	uc := Chain(NewUserUsecaseWithHasher(repo, fakeHasher{}, opts...), VerificationDecorator(verifier))
+	return uc, verifier, notifier
+}
+
+func TestEmailVerifier_VerifySuccess(t *testing.T) {
+	ctx := context.Background()
+	uc, verifier, notifier := newTestVerifier()
+
+	user := createTestUser(t, uc)
+	assert.False(t, user.Verified)
+	require.Len(t, notifier.sent, 1)
+	assert.Equal(t, user.Email, notifier.sent[0].to)
+
+	verified, err := verifier.Verify(ctx, tokenFromLink(t, notifier.sent[0].body))
+	require.NoError(t, err)
+	assert.True(t, verified.Verified)
+
+	stored, err := uc.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.Verified)
+}
+
+func TestEmailVerifier_VerifyExpiredToken(t *testing.T) {
+	ctx := context.Background()
+	uc, verifier, notifier := newTestVerifier()
+	user := createTestUser(t, uc)
+	verifier.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	_, err := verifier.Verify(ctx, tokenFromLink(t, notifier.sent[0].body))
+	assert.ErrorIs(t, err, ErrTokenExpired)
+
+	stored, err := uc.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Verified)
+}
+
+func TestEmailVerifier_VerifyUnknownToken(t *testing.T) {
+	ctx := context.Background()
+	_, verifier, _ := newTestVerifier()
+
+	_, err := verifier.Verify(ctx, "not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestEmailVerifier_TokensAreSingleUse(t *testing.T) {
+	ctx := context.Background()
+	uc, verifier, notifier := newTestVerifier()
+	createTestUser(t, uc)
+	token := tokenFromLink(t, notifier.sent[0].body)
+
+	_, err := verifier.Verify(ctx, token)
+	require.NoError(t, err)
+	_, err = verifier.Verify(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerificationDecorator_SkipsVerifiedUsers(t *testing.T) {
+	ctx := context.Background()
+	uc, _, notifier := newTestVerifier()
+
+	user, err := uc.CreateUser(ctx, entity.UserRequest{Name: "Admin", Email: "admin@example.com", Password: "S3curePassword", Verified: true})
+	require.NoError(t, err)
+
+	assert.True(t, user.Verified)
+	assert.Empty(t, notifier.sent)
+}
+
+func TestVerificationDecorator_NotifyFailureDoesNotFailCreate(t *testing.T) {
+	uc, _, notifier := newTestVerifier()
+	notifier.err = errors.New("smtp unavailable")
+
+	user := createTestUser(t, uc)
+
+	assert.NotZero(t, user.ID)
+}
+
+func TestUserUsecase_AuthenticateRequiresVerifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	uc, verifier, notifier := newTestVerifier(WithRequireVerifiedEmail(true))
+	createTestUser(t, uc)
+
+	_, err := uc.Authenticate(ctx, "john.doe@example.com", "S3curePassword")
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+
+	_, err = verifier.Verify(ctx, tokenFromLink(t, notifier.sent[0].body))
+	require.NoError(t, err)
+	_, err = uc.Authenticate(ctx, "john.doe@example.com", "S3curePassword")
+	assert.NoError(t, err)
+}
+
+func TestUserUsecase_AuthenticateChecksPasswordBeforeVerification(t *testing.T) {
+	ctx := context.Background()
+	uc, _, _ := newTestVerifier(WithRequireVerifiedEmail(true))
+	createTestUser(t, uc)
+
+	_, err := uc.Authenticate(ctx, "john.doe@example.com", "WrongPassword1")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestVerificationDecorator_ChangeEmailVerifiesNewAddress(t *testing.T) {
+	ctx := context.Background()
+	uc, verifier, notifier := newTestVerifier()
+	user := createTestUser(t, uc)
+	_, err := verifier.Verify(ctx, tokenFromLink(t, notifier.sent[0].body))
+	require.NoError(t, err)
+
+	require.NoError(t, uc.ChangeEmail(ctx, user.ID, "S3curePassword", "john.new@example.com"))
+
+	changed, err := uc.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "john.new@example.com", changed.Email)
+	assert.False(t, changed.Verified)
+	require.Len(t, notifier.sent, 2)
+	assert.Equal(t, "john.new@example.com", notifier.sent[1].to)
+
+	verified, err := verifier.Verify(ctx, tokenFromLink(t, notifier.sent[1].body))
+	require.NoError(t, err)
+	assert.Equal(t, "john.new@example.com", verified.Email)
+	assert.True(t, verified.Verified)
+}
+
+func TestVerificationDecorator_ChangeEmailInvalidatesOldAddressLinks(t *testing.T) {
+	ctx := context.Background()
+	uc, verifier, notifier := newTestVerifier()
+	user := createTestUser(t, uc)
+
+	require.NoError(t, uc.ChangeEmail(ctx, user.ID, "S3curePassword", "john.new@example.com"))
+
+	_, err := verifier.Verify(ctx, tokenFromLink(t, notifier.sent[0].body))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	stored, err := uc.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Verified)
+}
+
+func TestEmailVerifier_KeepOldEmailUntilVerified(t *testing.T) {
+	ctx := context.Background()
+	uc, verifier, notifier := newTestVerifier(WithRequireVerifiedEmail(true))
+	verifier.SetKeepOldEmail(true)
+	user := createTestUser(t, uc)
+	_, err := verifier.Verify(ctx, tokenFromLink(t, notifier.sent[0].body))
+	require.NoError(t, err)
+
+	require.NoError(t, uc.ChangeEmail(ctx, user.ID, "S3curePassword", "John.New@example.com"))
+
+	// The old address keeps working until the new one is verified
+	unchanged, err := uc.Authenticate(ctx, "john.doe@example.com", "S3curePassword")
+	require.NoError(t, err)
+	assert.Equal(t, "john.doe@example.com", unchanged.Email)
+	assert.True(t, unchanged.Verified)
+	require.Len(t, notifier.sent, 2)
+	assert.Equal(t, "john.new@example.com", notifier.sent[1].to)
+
+	changed, err := verifier.Verify(ctx, tokenFromLink(t, notifier.sent[1].body))
+	require.NoError(t, err)
+	assert.Equal(t, "john.new@example.com", changed.Email)
+	assert.True(t, changed.Verified)
+	_, err = uc.Authenticate(ctx, "john.new@example.com", "S3curePassword")
+	assert.NoError(t, err)
+	_, err = uc.Authenticate(ctx, "john.doe@example.com", "S3curePassword")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestEmailVerifier_KeepOldEmailAuditsChange(t *testing.T) {
+	ctx := context.Background()
+	uc, verifier, notifier := newTestVerifier()
+	verifier.SetKeepOldEmail(true)
+	writer := &recordingAuditWriter{}
+	verifier.SetAuditLog(writer)
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	verifier.now = func() time.Time { return now }
+	user := createTestUser(t, uc)
+	_, err := verifier.Verify(ctx, tokenFromLink(t, notifier.sent[0].body))
+	require.NoError(t, err)
+	assert.Empty(t, writer.entries, "verifying an unchanged address is not an email change")
+
+	require.NoError(t, uc.ChangeEmail(ctx, user.ID, "S3curePassword", "john.new@example.com"))
+	_, err = verifier.Verify(ctx, tokenFromLink(t, notifier.sent[1].body))
+	require.NoError(t, err)
+
+	require.Len(t, writer.entries, 1)
+	entry := writer.entries[0]
+	assert.Equal(t, "john.doe@example.com", entry.Actor)
+	assert.Equal(t, entity.AuditActionChangeEmail, entry.Action)
+	assert.Equal(t, user.ID, entry.TargetID)
+	assert.Equal(t, map[string]entity.AuditChange{
+		"email": {Before: "john.doe@example.com", After: "john.new@example.com"},
+	}, entry.Changes)
+	assert.Equal(t, now, entry.Timestamp)
+}
+
+func TestEmailVerifier_KeepOldEmailRequiresCurrentPassword(t *testing.T) {
+	ctx := context.Background()
+	uc, verifier, notifier := newTestVerifier()
+	verifier.SetKeepOldEmail(true)
+	user := createTestUser(t, uc)
+
+	err := uc.ChangeEmail(ctx, user.ID, "WrongPassword1", "john.new@example.com")
+
+	assert.ErrorIs(t, err, ErrIncorrectPassword)
+	assert.Len(t, notifier.sent, 1, "no link is sent without the current password")
+}
+
+func TestEmailVerifier_KeepOldEmailChecksEmailDomain(t *testing.T) {
+	ctx := context.Background()
+	uc, verifier, notifier := newTestVerifier()
+	verifier.SetKeepOldEmail(true)
+	checker := &stubEmailDomainChecker{err: &utils.EmailDomainError{Domain: "bogus.invalid"}}
+	verifier.SetEmailDomainCheck(checker)
+	user := createTestUser(t, uc)
+
+	err := uc.ChangeEmail(ctx, user.ID, "S3curePassword", "john@bogus.invalid")
+
+	var domainErr *utils.EmailDomainError
+	assert.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, []string{"john@bogus.invalid"}, checker.checks)
+	assert.Len(t, notifier.sent, 1, "no link is sent to an undeliverable address")
+}
+
+func TestEmailVerifier_KeepOldEmailRejectsTakenEmail(t *testing.T) {
+	ctx := context.Background()
+	uc, verifier, notifier := newTestVerifier()
+	verifier.SetKeepOldEmail(true)
+	user := createTestUser(t, uc)
+	_, err := uc.CreateUser(ctx, entity.UserRequest{Name: "Jane Doe", Email: "jane.doe@example.com", Password: "S3curePassword"})
+	require.NoError(t, err)
+
+	err = uc.ChangeEmail(ctx, user.ID, "S3curePassword", "jane.doe@example.com")
+
+	var existsErr *EmailAlreadyExistsError
+	assert.ErrorAs(t, err, &existsErr)
+	assert.Len(t, notifier.sent, 2, "no link is sent for a taken email")
+}
+
+func TestEmailVerifier_KeepOldEmailRejectsEmailTakenBeforeVerification(t *testing.T) {
+	ctx := context.Background()
+	uc, verifier, notifier := newTestVerifier()
+	verifier.SetKeepOldEmail(true)
+	user := createTestUser(t, uc)
+	require.NoError(t, uc.ChangeEmail(ctx, user.ID, "S3curePassword", "john.new@example.com"))
+	_, err := uc.CreateUser(ctx, entity.UserRequest{Name: "John New", Email: "john.new@example.com", Password: "S3curePassword"})
+	require.NoError(t, err)
+
+	_, err = verifier.Verify(ctx, tokenFromLink(t, notifier.sent[1].body))
+
+	var existsErr *EmailAlreadyExistsError
+	assert.ErrorAs(t, err, &existsErr)
+	stored, err := uc.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "john.doe@example.com", stored.Email)
+}
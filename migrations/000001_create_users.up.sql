@@ -0,0 +1,13 @@
+-- IF NOT EXISTS lets databases created by GORM's AutoMigrate adopt this migration unchanged.
+CREATE TABLE IF NOT EXISTS users (
+    id         bigserial PRIMARY KEY,
+    name       text        NOT NULL,
+    email      text        NOT NULL,
+    password   text        NOT NULL,
+    role       text        NOT NULL DEFAULT 'user',
+    verified   boolean     NOT NULL DEFAULT false,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
@@ -0,0 +1 @@
+-- The columns belong to the users table created by 000001, so they are left in place.
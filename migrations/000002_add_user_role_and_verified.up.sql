@@ -0,0 +1,3 @@
+-- Tables that 000001 adopted from GORM's AutoMigrate may predate these columns.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role text NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verified boolean NOT NULL DEFAULT false;
@@ -0,0 +1,9 @@
+// Package migrations holds the versioned SQL migrations of the PostgreSQL schema
+package migrations
+
+import "embed"
+
+// FS holds the migration files, named {version}_{name}.up.sql and {version}_{name}.down.sql
+//
+//go:embed *.sql
+var FS embed.FS
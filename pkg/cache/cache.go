@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrMiss is returned by Cache.Get when the key is not cached
+var ErrMiss = errors.New("cache: miss")
+
+// Cache is a byte-oriented key/value cache with per-entry expiry
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// MemoryCache is an in-process Cache backed by an LRU
+type MemoryCache struct {
+	lru *LRU[string, []byte]
+}
+
+// NewMemoryCache creates an in-memory cache holding at most size entries
+func NewMemoryCache(size int) *MemoryCache {
+	return &MemoryCache{lru: NewLRU[string, []byte](size, 0)}
+}
+
+// Get returns the cached value for key or ErrMiss
+func (m *MemoryCache) Get(_ context.Context, key string) ([]byte, error) {
+	value, ok := m.lru.Get(key)
+	if !ok {
+		return nil, ErrMiss
+	}
+	return value, nil
+}
+
+// Set stores value under key for the given ttl
+func (m *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.lru.SetWithTTL(key, value, ttl)
+	return nil
+}
+
+// Delete removes keys from the cache
+func (m *MemoryCache) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		m.lru.Remove(key)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache_SetGetDelete(t *testing.T) {
+	c := NewMemoryCache(10)
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "key", []byte("value"), time.Minute))
+	value, err := c.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	require.NoError(t, c.Delete(ctx, "key"))
+	_, err = c.Get(ctx, "key")
+	assert.ErrorIs(t, err, ErrMiss)
+}
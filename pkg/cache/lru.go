@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is a concurrency-safe, size-bounded cache that evicts the least recently
+// used entry when full and treats entries older than the TTL as missing
+type LRU[K comparable, V any] struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[K]*list.Element
+	now     func() time.Time
+}
+
+// lruEntry is a cached value together with its expiry time
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// NewLRU creates a new LRU cache holding at most size entries; a zero ttl disables expiry
+func NewLRU[K comparable, V any](size int, ttl time.Duration) *LRU[K, V] {
+	return &LRU[K, V]{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[K]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// Get returns the cached value for key and whether it was found
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+
+	entry := element.Value.(*lruEntry[K, V])
+	if !entry.expiresAt.IsZero() && c.now().After(entry.expiresAt) {
+		c.removeElement(element)
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set stores value under key using the cache TTL, evicting the least recently
+// used entry if the cache is full
+func (c *LRU[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.ttl)
+}
+
+// SetWithTTL stores value under key with its own TTL; a zero ttl never expires
+func (c *LRU[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry[K, V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	if c.size > 0 && c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Remove deletes key from the cache
+func (c *LRU[K, V]) Remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+// Len returns the number of cached entries, including expired ones not yet evicted
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// removeElement unlinks an element from the list and the index; callers must hold the lock
+func (c *LRU[K, V]) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry[K, V]).key)
+}
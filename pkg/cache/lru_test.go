@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU[string, int](2, 0)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Set("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+
+	value, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestLRU_ExpiresEntriesAfterTTL(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := NewLRU[string, int](10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Set("a", 1)
+
+	now = now.Add(30 * time.Second)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestLRU_Remove(t *testing.T) {
+	c := NewLRU[string, int](10, 0)
+	c.Set("a", 1)
+
+	c.Remove("a")
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+}
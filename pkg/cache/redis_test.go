@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisCache(t *testing.T) (*RedisCache, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisCache(client, "test:"), server
+}
+
+func TestRedisCache_SetGetDelete(t *testing.T) {
+	c, server := newTestRedisCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "key", []byte("value"), time.Minute))
+	assert.True(t, server.Exists("test:key"))
+
+	value, err := c.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	require.NoError(t, c.Delete(ctx, "key"))
+	_, err = c.Get(ctx, "key")
+	assert.ErrorIs(t, err, ErrMiss)
+}
+
+func TestRedisCache_ExpiresAfterTTL(t *testing.T) {
+	c, server := newTestRedisCache(t)
+	ctx := context.Background()
+
+	require.NoError(t, c.Set(ctx, "key", []byte("value"), time.Minute))
+	server.FastForward(2 * time.Minute)
+
+	_, err := c.Get(ctx, "key")
+	assert.ErrorIs(t, err, ErrMiss)
+}
+
+func TestRedisCache_ReportsConnectionErrors(t *testing.T) {
+	c, server := newTestRedisCache(t)
+	server.Close()
+
+	_, err := c.Get(context.Background(), "key")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrMiss)
+}
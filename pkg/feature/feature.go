@@ -0,0 +1,96 @@
+// Package feature provides on/off flags for optional functionality, so
+// endpoints can be shipped dark and enabled per deployment
+package feature
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// EnvPrefix starts the name of the environment variable setting each flag
+const EnvPrefix = "FEATURE_"
+
+// Set is a fixed set of named flags. Names that are not in the set are
+// reported as disabled.
+type Set struct {
+	flags map[string]bool
+}
+
+// NewSet creates a set with the given flag states
+func NewSet(flags map[string]bool) *Set {
+	s := &Set{flags: make(map[string]bool, len(flags))}
+	for name, enabled := range flags {
+		s.flags[name] = enabled
+	}
+	return s
+}
+
+// EnvVar returns the environment variable that sets the named flag, such as
+// FEATURE_BULK_IMPORT for bulk_import
+func EnvVar(name string) string {
+	return EnvPrefix + strings.ToUpper(name)
+}
+
+// Load creates a set of the flags in defaults, overriding each default with
+// its environment variable when lookup finds one. lookup is usually
+// os.LookupEnv. A value strconv.ParseBool rejects is an error, so a typo
+// never silently leaves a feature in the wrong state.
+func Load(defaults map[string]bool, lookup func(string) (string, bool)) (*Set, error) {
+	s := NewSet(defaults)
+	for name := range s.flags {
+		value, ok := lookup(EnvVar(name))
+		if !ok || value == "" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: must be true or false", EnvVar(name), value)
+		}
+		s.flags[name] = enabled
+	}
+	return s, nil
+}
+
+// Enabled reports whether the named flag is on
+func (s *Set) Enabled(name string) bool {
+	return s.flags[name]
+}
+
+// Flags returns a copy of every flag's state
+func (s *Set) Flags() map[string]bool {
+	flags := make(map[string]bool, len(s.flags))
+	for name, enabled := range s.flags {
+		flags[name] = enabled
+	}
+	return flags
+}
+
+// String lists the flags by name, such as "bulk_import=off, export=on"
+func (s *Set) String() string {
+	names := make([]string, 0, len(s.flags))
+	for name := range s.flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		state := "off"
+		if s.flags[name] {
+			state = "on"
+		}
+		parts[i] = name + "=" + state
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Handler returns a Fiber handler reporting the state of every flag
+func Handler(s *Set) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"features": s.Flags()})
+	}
+}
@@ -0,0 +1,61 @@
+package feature
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// envLookup looks variables up in env, like os.LookupEnv
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func TestLoad(t *testing.T) {
+	defaults := map[string]bool{"bulk_import": false, "export": true, "schemas": true}
+
+	set, err := Load(defaults, envLookup(map[string]string{
+		"FEATURE_BULK_IMPORT": "true",
+		"FEATURE_EXPORT":      "0",
+		"FEATURE_SCHEMAS":     "",
+		"FEATURE_UNKNOWN":     "true",
+	}))
+	require.NoError(t, err)
+
+	assert.True(t, set.Enabled("bulk_import"))
+	assert.False(t, set.Enabled("export"))
+	assert.True(t, set.Enabled("schemas"), "empty values keep the default")
+	assert.False(t, set.Enabled("unknown"), "only known flags are loaded")
+	assert.Equal(t, map[string]bool{"bulk_import": true, "export": false, "schemas": true}, set.Flags())
+	assert.Equal(t, "bulk_import=on, export=off, schemas=on", set.String())
+	assert.False(t, defaults["bulk_import"], "defaults are not modified")
+}
+
+func TestLoad_RejectsInvalidValues(t *testing.T) {
+	_, err := Load(map[string]bool{"export": false}, envLookup(map[string]string{"FEATURE_EXPORT": "yes"}))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid FEATURE_EXPORT "yes"`)
+}
+
+func TestHandler(t *testing.T) {
+	app := fiber.New()
+	app.Get("/features", Handler(NewSet(map[string]bool{"export": true, "bulk_import": false})))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/features", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Features map[string]bool `json:"features"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, map[string]bool{"export": true, "bulk_import": false}, body.Features)
+}
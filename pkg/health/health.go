@@ -0,0 +1,252 @@
+// Package health runs named component health checks concurrently and
+// aggregates their results
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Status is the health of a component or of the whole service
+type Status string
+
+// Statuses from best to worst
+const (
+	StatusHealthy   Status = "healthy"
+	StatusDegraded  Status = "degraded"
+	StatusUnhealthy Status = "unhealthy"
+)
+
+// severity orders statuses; unknown statuses count as unhealthy
+func (s Status) severity() int {
+	switch s {
+	case StatusHealthy:
+		return 0
+	case StatusDegraded:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Worse returns the more severe of two statuses
+func Worse(a, b Status) Status {
+	if b.severity() > a.severity() {
+		return b
+	}
+	return a
+}
+
+// DefaultTimeout is how long a check may run when it was registered without its own timeout
+const DefaultTimeout = 2 * time.Second
+
+// Checker reports the health of one component. A non-nil error explains a
+// status other than healthy.
+type Checker interface {
+	Check(ctx context.Context) (Status, error)
+}
+
+// CheckFunc adapts a function to Checker
+type CheckFunc func(ctx context.Context) (Status, error)
+
+// Check calls f
+func (f CheckFunc) Check(ctx context.Context) (Status, error) {
+	return f(ctx)
+}
+
+// Pinger is a dependency that can report whether it is reachable
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// PingCheck returns a check that is unhealthy while p cannot be pinged
+func PingCheck(p Pinger) Checker {
+	return CheckFunc(func(ctx context.Context) (Status, error) {
+		if err := p.Ping(ctx); err != nil {
+			return StatusUnhealthy, err
+		}
+		return StatusHealthy, nil
+	})
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Status    Status  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latencyMs"`
+}
+
+// Report is the outcome of every registered check; Status is the worst of them
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// check is a registered checker with its timeout
+type check struct {
+	checker Checker
+	timeout time.Duration
+}
+
+// Registry holds named checks
+type Registry struct {
+	mu     sync.RWMutex
+	checks map[string]check
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{checks: make(map[string]check)}
+}
+
+// Register adds a check with DefaultTimeout, replacing any check registered under the same name
+func (r *Registry) Register(name string, checker Checker) {
+	r.RegisterWithTimeout(name, DefaultTimeout, checker)
+}
+
+// RegisterWithTimeout adds a check that is reported unhealthy when it runs
+// longer than timeout, replacing any check registered under the same name.
+// A non-positive timeout means DefaultTimeout.
+func (r *Registry) RegisterWithTimeout(name string, timeout time.Duration, checker Checker) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check{checker: checker, timeout: timeout}
+}
+
+// Names returns the registered check names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run executes every check concurrently and waits for all of them. A check
+// that outlives its timeout is reported unhealthy without waiting for it to
+// return, and a check that panics is reported unhealthy.
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make(map[string]check, len(r.checks))
+	for name, c := range r.checks {
+		checks[name] = c
+	}
+	r.mu.RUnlock()
+
+	report := Report{Status: StatusHealthy, Checks: make(map[string]Result, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := run(ctx, c)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			report.Status = Worse(report.Status, result.Status)
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+// outcome is what a checker returned
+type outcome struct {
+	status Status
+	err    error
+}
+
+// run executes one check within its timeout
+func run(ctx context.Context, c check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- outcome{StatusUnhealthy, fmt.Errorf("check panicked: %v", p)}
+			}
+		}()
+		status, err := c.checker.Check(ctx)
+		done <- outcome{status, err}
+	}()
+
+	var out outcome
+	select {
+	case out = <-done:
+	case <-ctx.Done():
+		out = outcome{StatusUnhealthy, fmt.Errorf("check timed out after %s", c.timeout)}
+	}
+
+	result := Result{
+		Status:    out.status,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if result.Status != StatusHealthy && result.Status != StatusDegraded {
+		result.Status = StatusUnhealthy
+	}
+	if out.err != nil {
+		result.Error = out.err.Error()
+	}
+	return result
+}
+
+// HandlerOption configures Handler
+type HandlerOption func(*handlerConfig)
+
+// handlerConfig holds the optional Handler settings
+type handlerConfig struct {
+	info func() map[string]interface{}
+}
+
+// WithInfo adds the fields returned by info, such as uptime, to every response
+func WithInfo(info func() map[string]interface{}) HandlerOption {
+	return func(cfg *handlerConfig) {
+		cfg.info = info
+	}
+}
+
+// Handler runs the registry's checks and responds with the report, using
+// 503 while any check is unhealthy
+func Handler(r *Registry, opts ...HandlerOption) fiber.Handler {
+	var cfg handlerConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(c *fiber.Ctx) error {
+		report := r.Run(c.UserContext())
+
+		body := fiber.Map{}
+		if cfg.info != nil {
+			for key, value := range cfg.info() {
+				body[key] = value
+			}
+		}
+		body["status"] = report.Status
+		body["checks"] = report.Checks
+		body["timestamp"] = time.Now().UTC()
+
+		status := fiber.StatusOK
+		if report.Status == StatusUnhealthy {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(body)
+	}
+}
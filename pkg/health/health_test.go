@@ -0,0 +1,167 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// static returns a check that always reports status and err
+func static(status Status, err error) Checker {
+	return CheckFunc(func(context.Context) (Status, error) {
+		return status, err
+	})
+}
+
+type fakePinger struct{ err error }
+
+func (p fakePinger) Ping(context.Context) error { return p.err }
+
+func TestRegistry_AggregatesWorstStatus(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("database", static(StatusHealthy, nil))
+	registry.Register("memory", static(StatusDegraded, errors.New("sampling is stale")))
+	registry.Register("mongo", PingCheck(fakePinger{err: errors.New("server selection timeout")}))
+	registry.Register("cache", static("confused", nil))
+
+	report := registry.Run(context.Background())
+
+	assert.Equal(t, StatusUnhealthy, report.Status)
+	assert.Equal(t, []string{"cache", "database", "memory", "mongo"}, registry.Names())
+	assert.Equal(t, StatusHealthy, report.Checks["database"].Status)
+	assert.Equal(t, Result{Status: StatusDegraded, Error: "sampling is stale", LatencyMs: report.Checks["memory"].LatencyMs}, report.Checks["memory"])
+	assert.Equal(t, StatusUnhealthy, report.Checks["mongo"].Status)
+	assert.Equal(t, "server selection timeout", report.Checks["mongo"].Error)
+	assert.Equal(t, StatusUnhealthy, report.Checks["cache"].Status, "unknown statuses count as unhealthy")
+}
+
+func TestRegistry_EmptyIsHealthy(t *testing.T) {
+	report := NewRegistry().Run(context.Background())
+
+	assert.Equal(t, StatusHealthy, report.Status)
+	assert.Empty(t, report.Checks)
+}
+
+func TestRegistry_RegisterReplaces(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("database", static(StatusUnhealthy, nil))
+	registry.Register("database", static(StatusHealthy, nil))
+
+	assert.Equal(t, StatusHealthy, registry.Run(context.Background()).Status)
+	assert.Equal(t, []string{"database"}, registry.Names())
+}
+
+func TestRegistry_RunsChecksConcurrently(t *testing.T) {
+	const checks = 5
+	var started sync.WaitGroup
+	started.Add(checks)
+
+	// Each check waits for all of them to start, which only happens when they run concurrently
+	registry := NewRegistry()
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		registry.RegisterWithTimeout(name, time.Second, CheckFunc(func(ctx context.Context) (Status, error) {
+			started.Done()
+			allStarted := make(chan struct{})
+			go func() {
+				started.Wait()
+				close(allStarted)
+			}()
+			select {
+			case <-allStarted:
+				return StatusHealthy, nil
+			case <-ctx.Done():
+				return StatusUnhealthy, ctx.Err()
+			}
+		}))
+	}
+
+	report := registry.Run(context.Background())
+
+	assert.Equal(t, StatusHealthy, report.Status)
+	assert.Len(t, report.Checks, checks)
+}
+
+func TestRegistry_TimesOutSlowChecks(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	registry := NewRegistry()
+	registry.RegisterWithTimeout("ignores context", 20*time.Millisecond, CheckFunc(func(context.Context) (Status, error) {
+		<-release
+		return StatusHealthy, nil
+	}))
+	registry.RegisterWithTimeout("honours context", 20*time.Millisecond, CheckFunc(func(ctx context.Context) (Status, error) {
+		<-ctx.Done()
+		return StatusUnhealthy, ctx.Err()
+	}))
+	registry.Register("fast", static(StatusHealthy, nil))
+
+	start := time.Now()
+	report := registry.Run(context.Background())
+
+	assert.Less(t, time.Since(start), time.Second, "Run does not wait for checks past their timeout")
+	assert.Equal(t, StatusUnhealthy, report.Status)
+	assert.Equal(t, StatusUnhealthy, report.Checks["ignores context"].Status)
+	assert.Contains(t, report.Checks["ignores context"].Error, "timed out after 20ms")
+	assert.Equal(t, StatusUnhealthy, report.Checks["honours context"].Status)
+	assert.Equal(t, StatusHealthy, report.Checks["fast"].Status)
+}
+
+func TestRegistry_RecoversPanickingChecks(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("broken", CheckFunc(func(context.Context) (Status, error) {
+		panic("nil map")
+	}))
+
+	report := registry.Run(context.Background())
+
+	assert.Equal(t, StatusUnhealthy, report.Checks["broken"].Status)
+	assert.Contains(t, report.Checks["broken"].Error, "nil map")
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		status Status
+		code   int
+	}{
+		{"healthy", StatusHealthy, fiber.StatusOK},
+		{"degraded", StatusDegraded, fiber.StatusOK},
+		{"unhealthy", StatusUnhealthy, fiber.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+			registry.Register("database", static(StatusHealthy, nil))
+			registry.Register("mongo", static(tt.status, nil))
+
+			app := fiber.New()
+			app.Get("/health", Handler(registry, WithInfo(func() map[string]interface{} {
+				return map[string]interface{}{"goroutines": 7, "status": "overridden"}
+			})))
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/health", nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.code, resp.StatusCode)
+
+			var body struct {
+				Status     Status            `json:"status"`
+				Checks     map[string]Result `json:"checks"`
+				Goroutines int               `json:"goroutines"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			assert.Equal(t, tt.status, body.Status, "info cannot override the status")
+			assert.Len(t, body.Checks, 2)
+			assert.Equal(t, 7, body.Goroutines)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Package jsonnum writes unsigned integers that JavaScript cannot represent
+// exactly as JSON strings, for clients that parse numbers into float64
+package jsonnum
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// MaxSafeInteger is the largest integer that a float64, and so a JavaScript
+// number, represents exactly (Number.MAX_SAFE_INTEGER)
+const MaxSafeInteger = 1<<53 - 1
+
+// LargeUintsAsStrings sets whether Uint64 values above MaxSafeInteger are
+// written as JSON strings. Smaller values are always written as numbers.
+var LargeUintsAsStrings = false
+
+// Uint64 is a uint64 written as a JSON number, or as a decimal string when
+// LargeUintsAsStrings is set and the value is above MaxSafeInteger. It reads
+// either form.
+type Uint64 uint64
+
+// MarshalJSON implements json.Marshaler
+func (n Uint64) MarshalJSON() ([]byte, error) {
+	b := strconv.AppendUint(nil, uint64(n), 10)
+	if LargeUintsAsStrings && n > MaxSafeInteger {
+		return strconv.AppendQuote(nil, string(b)), nil
+	}
+	return b, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (n *Uint64) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	text := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	}
+	value, err := strconv.ParseUint(text, 10, 64)
+	if err != nil {
+		return fmt.Errorf("jsonnum: invalid unsigned integer %s", data)
+	}
+	*n = Uint64(value)
+	return nil
+}
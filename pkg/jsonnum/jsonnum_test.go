@@ -0,0 +1,54 @@
+package jsonnum
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setLargeUintsAsStrings sets LargeUintsAsStrings for the duration of a test
+func setLargeUintsAsStrings(t *testing.T, enabled bool) {
+	previous := LargeUintsAsStrings
+	LargeUintsAsStrings = enabled
+	t.Cleanup(func() { LargeUintsAsStrings = previous })
+}
+
+func TestUint64_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		strings bool
+		value   uint64
+		want    string
+	}{
+		{"small", true, 1024, `1024`},
+		{"max safe integer", true, MaxSafeInteger, `9007199254740991`},
+		{"above 2^53", true, 1<<53 + 1, `"9007199254740993"`},
+		{"max uint64", true, 1<<64 - 1, `"18446744073709551615"`},
+		{"above 2^53 as number", false, 1<<53 + 1, `9007199254740993`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLargeUintsAsStrings(t, tt.strings)
+
+			data, err := json.Marshal(Uint64(tt.value))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+}
+
+func TestUint64_UnmarshalJSON(t *testing.T) {
+	for _, input := range []string{`9007199254740993`, `"9007199254740993"`} {
+		var n Uint64
+		require.NoError(t, json.Unmarshal([]byte(input), &n), input)
+		assert.Equal(t, Uint64(1<<53+1), n, input)
+	}
+
+	for _, input := range []string{`-1`, `"abc"`, `1.5`, `""`} {
+		var n Uint64
+		assert.Error(t, json.Unmarshal([]byte(input), &n), input)
+	}
+}
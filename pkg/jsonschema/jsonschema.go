@@ -0,0 +1,196 @@
+// Package jsonschema generates JSON Schemas from Go types by reflection, so
+// API documentation can follow the request and response types it describes
+package jsonschema
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Draft is the JSON Schema dialect served by Handler
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema that Reflect produces. It is also a
+// valid OpenAPI 3.0 schema object, so it can populate components.schemas.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Reflect returns the schema of v's type. Properties follow the json tags;
+// the binding tag's required, email, url, min, max and oneof rules become
+// constraints, and a jsonschema tag can set format=... and enum=a|b.
+func Reflect(v interface{}) *Schema {
+	t := reflect.TypeOf(v)
+	schema := reflectType(t)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	schema.Title = t.Name()
+	return schema
+}
+
+// reflectType returns the schema of a type
+func reflectType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json writes byte slices as base64 strings
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: reflectType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: reflectType(t.Elem())}
+	case reflect.Struct:
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		addFields(schema, t)
+		sort.Strings(schema.Required)
+		return schema
+	default:
+		// Interfaces and other dynamic values accept anything
+		return &Schema{}
+	}
+}
+
+// addFields adds the exported fields of a struct as properties, flattening
+// embedded structs the way encoding/json does
+func addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addFields(schema, ft)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := reflectType(field.Type)
+		if applyBinding(property, field.Tag.Get("binding")) {
+			schema.Required = append(schema.Required, name)
+		}
+		applySchemaTag(property, field.Tag.Get("jsonschema"))
+		schema.Properties[name] = property
+	}
+}
+
+// applyBinding turns binding tag rules into constraints and reports whether
+// the field is required. Unknown rules are ignored.
+func applyBinding(schema *Schema, tag string) (required bool) {
+	for _, rule := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url":
+			schema.Format = "uri"
+		case "oneof":
+			schema.Enum = strings.Fields(value)
+		case "min", "max":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			applyBound(schema, key == "min", n)
+		}
+	}
+	return required
+}
+
+// applyBound sets a minimum or maximum, on the length of strings and on the
+// value of numbers
+func applyBound(schema *Schema, isMin bool, n float64) {
+	switch schema.Type {
+	case "string":
+		length := int(n)
+		if isMin {
+			schema.MinLength = &length
+		} else {
+			schema.MaxLength = &length
+		}
+	case "integer", "number":
+		if isMin {
+			schema.Minimum = &n
+		} else {
+			schema.Maximum = &n
+		}
+	}
+}
+
+// applySchemaTag applies the format and enum settings of a jsonschema tag
+func applySchemaTag(schema *Schema, tag string) {
+	for _, setting := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(setting, "=")
+		switch key {
+		case "format":
+			schema.Format = value
+		case "enum":
+			schema.Enum = strings.Split(value, "|")
+		}
+	}
+}
+
+// Handler returns a Fiber handler serving the schema named by the :name
+// route parameter, as a standalone JSON Schema document
+func Handler(schemas map[string]*Schema) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		schema, ok := schemas[c.Params("name")]
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Schema not found"})
+		}
+		document := *schema
+		document.Schema = Draft
+		return c.JSON(document)
+	}
+}
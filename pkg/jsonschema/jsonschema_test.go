@@ -0,0 +1,94 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type signupRequest struct {
+	Name     string `json:"name" binding:"required,max=50"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required,min=8" jsonschema:"format=password"`
+	Website  string `json:"website,omitempty" binding:"url"`
+	Plan     string `json:"plan,omitempty" binding:"oneof=free pro"`
+	Internal bool   `json:"-"`
+	secret   string
+}
+
+type auditFields struct {
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+type account struct {
+	auditFields
+	ID     uint              `json:"id"`
+	Age    int               `json:"age" binding:"min=13,max=130"`
+	Score  float64           `json:"score"`
+	Tags   []string          `json:"tags"`
+	Labels map[string]string `json:"labels"`
+	Avatar []byte            `json:"avatar"`
+	Owner  *signupRequest    `json:"owner"`
+	Extra  interface{}       `json:"extra"`
+	Status string            `json:"status" jsonschema:"enum=active|locked"`
+	NoTag  bool
+}
+
+func TestReflect_BindingTagsBecomeConstraints(t *testing.T) {
+	schema := Reflect(signupRequest{})
+
+	assert.Equal(t, "signupRequest", schema.Title)
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, []string{"email", "name", "password"}, schema.Required)
+	assert.Equal(t, "email", schema.Properties["email"].Format)
+	assert.Equal(t, "password", schema.Properties["password"].Format)
+	assert.Equal(t, 8, *schema.Properties["password"].MinLength)
+	assert.Equal(t, 50, *schema.Properties["name"].MaxLength)
+	assert.Equal(t, "uri", schema.Properties["website"].Format)
+	assert.Equal(t, []string{"free", "pro"}, schema.Properties["plan"].Enum)
+	assert.Len(t, schema.Properties, 5, "ignored and unexported fields are left out")
+}
+
+func TestReflect_Types(t *testing.T) {
+	schema := Reflect(&account{})
+
+	assert.Equal(t, "account", schema.Title)
+	assert.Empty(t, schema.Required)
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, schema.Properties["created_at"], "embedded fields are flattened")
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, schema.Properties["deleted_at"])
+	assert.Equal(t, "integer", schema.Properties["id"].Type)
+	assert.Equal(t, 0.0, *schema.Properties["id"].Minimum)
+	assert.Equal(t, 13.0, *schema.Properties["age"].Minimum)
+	assert.Equal(t, 130.0, *schema.Properties["age"].Maximum)
+	assert.Equal(t, "number", schema.Properties["score"].Type)
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}}, schema.Properties["tags"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}, schema.Properties["labels"])
+	assert.Equal(t, &Schema{Type: "string", Format: "byte"}, schema.Properties["avatar"])
+	assert.Equal(t, []string{"email", "name", "password"}, schema.Properties["owner"].Required)
+	assert.Equal(t, &Schema{}, schema.Properties["extra"])
+	assert.Equal(t, []string{"active", "locked"}, schema.Properties["status"].Enum)
+	assert.Equal(t, "boolean", schema.Properties["NoTag"].Type)
+}
+
+func TestHandler(t *testing.T) {
+	app := fiber.New()
+	app.Get("/schemas/:name", Handler(map[string]*Schema{"signupRequest": Reflect(signupRequest{})}))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/schemas/signupRequest", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, Draft, body["$schema"])
+	assert.Equal(t, []interface{}{"email", "name", "password"}, body["required"])
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/schemas/unknown", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,51 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// RequestIDAttr is the log attribute holding the request ID
+const RequestIDAttr = "requestId"
+
+// requestIDContextKey is the context key holding the request ID
+type requestIDContextKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or "" if there is none
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// contextHandler adds the request ID carried by a record's context, so logs
+// written with the *Context methods, such as database query logs, can be
+// traced back to the HTTP request that caused them
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle adds the request ID attribute, if any, before passing the record on
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		record.AddAttrs(slog.String(RequestIDAttr, id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs keeps the request ID lookup on the derived handler
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps the request ID lookup on the derived handler
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_AddsRequestIDFromContext(t *testing.T) {
+	var logs bytes.Buffer
+	logger := New(&logs).With("component", "test")
+
+	logger.InfoContext(ContextWithRequestID(context.Background(), "req-123"), "with request")
+	logger.InfoContext(context.Background(), "without request")
+
+	lines := bytes.Split(bytes.TrimSpace(logs.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	assert.Contains(t, string(lines[0]), `"requestId":"req-123"`)
+	assert.Contains(t, string(lines[0]), `"component":"test"`)
+	assert.NotContains(t, string(lines[1]), "requestId")
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	assert.Equal(t, "", RequestIDFromContext(context.Background()))
+	assert.Equal(t, "abc", RequestIDFromContext(ContextWithRequestID(context.Background(), "abc")))
+}
+
+func TestNewWithFormat(t *testing.T) {
+	tests := []struct {
+		format  Format
+		pattern string
+	}{
+		{FormatJSON, `^\{"time":"\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}Z","level":"INFO","msg":"user created","userId":7,"requestId":"req-1"\}$`},
+		{FormatText, `^time=\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{3}Z level=INFO msg="user created" userId=7 requestId=req-1$`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var logs bytes.Buffer
+			logger := NewWithFormat(&logs, tt.format)
+
+			logger.InfoContext(ContextWithRequestID(context.Background(), "req-1"), "user created", "userId", 7)
+
+			assert.Regexp(t, tt.pattern, strings.TrimSpace(logs.String()))
+		})
+	}
+}
+
+func TestFormat_Validate(t *testing.T) {
+	assert.NoError(t, FormatJSON.Validate())
+	assert.NoError(t, FormatText.Validate())
+	assert.ErrorContains(t, Format("logfmt").Validate(), `got "logfmt"`)
+}
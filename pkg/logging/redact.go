@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Redacted replaces the value of sensitive fields in logged payloads
+const Redacted = "[REDACTED]"
+
+// DefaultRedactFields are the payload fields redacted when no list is configured
+var DefaultRedactFields = []string{
+	"password", "currentPassword", "newPassword",
+	"token", "accessToken", "refreshToken", "secret",
+}
+
+// Redactor masks sensitive fields in JSON payloads before they are logged
+type Redactor struct {
+	fields map[string]bool
+}
+
+// NewRedactor creates a redactor for the given field names, matched case-insensitively
+func NewRedactor(fields []string) *Redactor {
+	r := &Redactor{fields: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		r.fields[strings.ToLower(field)] = true
+	}
+	return r
+}
+
+// Redact returns the decoded JSON body with sensitive fields masked at any
+// depth. Bodies that are not JSON are replaced by a size placeholder so they
+// can never leak into logs.
+func (r *Redactor) Redact(body []byte) interface{} {
+	var payload interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Sprintf("[%d bytes omitted]", len(body))
+	}
+	return r.redactValue(payload)
+}
+
+// redactValue walks a decoded JSON value and masks sensitive object fields
+func (r *Redactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if r.fields[strings.ToLower(key)] {
+				v[key] = Redacted
+			} else {
+				v[key] = r.redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactValue(item)
+		}
+	}
+	return value
+}
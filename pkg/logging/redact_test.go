@@ -0,0 +1,31 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	redactor := NewRedactor(DefaultRedactFields)
+
+	redacted := redactor.Redact([]byte(`{
+		"name": "John Doe",
+		"Password": "S3curePassword",
+		"sessions": [{"accessToken": "abc", "device": "phone"}]
+	}`))
+
+	assert.Equal(t, map[string]interface{}{
+		"name":     "John Doe",
+		"Password": Redacted,
+		"sessions": []interface{}{
+			map[string]interface{}{"accessToken": Redacted, "device": "phone"},
+		},
+	}, redacted)
+}
+
+func TestRedactor_RedactNonJSON(t *testing.T) {
+	redactor := NewRedactor(DefaultRedactFields)
+
+	assert.Equal(t, "[23 bytes omitted]", redactor.Redact([]byte("password=S3curePassword")))
+}
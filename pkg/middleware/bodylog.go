@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/example/go-clean-architecture/pkg/logging"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestBodyLogger returns a middleware that logs request bodies with the
+// given sensitive fields redacted
+func RequestBodyLogger(logger *slog.Logger, redactFields []string) fiber.Handler {
+	redactor := logging.NewRedactor(redactFields)
+
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+
+		if body := c.Body(); len(body) > 0 {
+			logger.Info("request body",
+				"requestId", RequestID(c),
+				"method", c.Method(),
+				"path", c.Path(),
+				"status", c.Response().StatusCode(),
+				"body", redactor.Redact(body))
+		}
+
+		return err
+	}
+}
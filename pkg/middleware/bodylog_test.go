@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/example/go-clean-architecture/pkg/logging"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestBodyLogger_RedactsPassword(t *testing.T) {
+	var logs bytes.Buffer
+	app := fiber.New()
+	app.Use(RequestBodyLogger(logging.New(&logs), logging.DefaultRedactFields))
+	app.Post("/users", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	req := httptest.NewRequest(fiber.MethodPost, "/users", strings.NewReader(
+		`{"name":"John Doe","email":"john.doe@example.com","password":"S3curePassword"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	_, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Contains(t, logs.String(), `"password":"[REDACTED]"`)
+	assert.Contains(t, logs.String(), `"email":"john.doe@example.com"`)
+	assert.Contains(t, logs.String(), `"status":201`)
+	assert.NotContains(t, logs.String(), "S3curePassword")
+}
+
+func TestRequestBodyLogger_ConfigurableFields(t *testing.T) {
+	var logs bytes.Buffer
+	app := fiber.New()
+	app.Use(RequestBodyLogger(logging.New(&logs), []string{"email"}))
+	app.Post("/users", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	_, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/users", strings.NewReader(`{"email":"john.doe@example.com"}`)))
+	require.NoError(t, err)
+
+	assert.Contains(t, logs.String(), `"email":"[REDACTED]"`)
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ClientIP returns the originating client IP for a request.
+//
+// The proxy header (e.g. X-Forwarded-For) is only honoured when the app has
+// EnableTrustedProxyCheck set and the direct peer is one of its TrustedProxies.
+// The header is then walked right to left, skipping further trusted proxies, so
+// entries a client prepends itself cannot spoof the result.
+func ClientIP(c *fiber.Ctx) string {
+	remoteIP := c.Context().RemoteIP().String()
+
+	config := c.App().Config()
+	if !config.EnableTrustedProxyCheck || config.ProxyHeader == "" || !c.IsProxyTrusted() {
+		return remoteIP
+	}
+
+	hops := strings.Split(c.Get(config.ProxyHeader), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			// A malformed hop means the chain can no longer be trusted
+			break
+		}
+		if !isTrustedProxy(ip, config.TrustedProxies) {
+			return ip.String()
+		}
+		remoteIP = ip.String()
+	}
+
+	return remoteIP
+}
+
+// isTrustedProxy reports whether ip matches one of the trusted IPs or CIDR ranges
+func isTrustedProxy(ip net.IP, trusted []string) bool {
+	for _, entry := range trusted {
+		if strings.Contains(entry, "/") {
+			if _, ipNet, err := net.ParseCIDR(entry); err == nil && ipNet.Contains(ip) {
+				return true
+			}
+		} else if trustedIP := net.ParseIP(entry); trustedIP != nil && trustedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
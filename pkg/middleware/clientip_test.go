@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClientIPTestApp returns an app echoing ClientIP; app.Test connections
+// originate from 0.0.0.0
+func newClientIPTestApp(trustedProxies ...string) *fiber.App {
+	config := fiber.Config{}
+	if len(trustedProxies) > 0 {
+		config.EnableTrustedProxyCheck = true
+		config.TrustedProxies = trustedProxies
+		config.ProxyHeader = fiber.HeaderXForwardedFor
+	}
+
+	app := fiber.New(config)
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(ClientIP(c))
+	})
+	return app
+}
+
+func requestClientIP(t *testing.T, app *fiber.App, forwardedFor string) string {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	if forwardedFor != "" {
+		req.Header.Set(fiber.HeaderXForwardedFor, forwardedFor)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestClientIP_TrustedProxyUsesForwardedFor(t *testing.T) {
+	app := newClientIPTestApp("0.0.0.0")
+
+	assert.Equal(t, "203.0.113.7", requestClientIP(t, app, "203.0.113.7"))
+}
+
+func TestClientIP_TrustedProxyIgnoresSpoofedLeftmostHop(t *testing.T) {
+	app := newClientIPTestApp("0.0.0.0", "10.0.0.0/8")
+
+	// The client sent "1.2.3.4" itself; the edge proxy appended the real peer
+	assert.Equal(t, "203.0.113.7", requestClientIP(t, app, "1.2.3.4, 203.0.113.7, 10.0.0.5"))
+}
+
+func TestClientIP_UntrustedSourceIgnoresForwardedFor(t *testing.T) {
+	app := newClientIPTestApp("10.0.0.0/8")
+
+	assert.Equal(t, "0.0.0.0", requestClientIP(t, app, "203.0.113.7"))
+}
+
+func TestClientIP_NoTrustedProxiesIgnoresForwardedFor(t *testing.T) {
+	app := newClientIPTestApp()
+
+	assert.Equal(t, "0.0.0.0", requestClientIP(t, app, "203.0.113.7"))
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewConcurrencyLimiter returns a middleware that allows at most max requests
+// in flight at once and rejects the rest with 503 instead of queueing them. A
+// slot is released when the rest of the chain returns, including by panic.
+// Requests for exemptPaths, such as health probes, never take a slot, so a
+// busy server is not mistaken for a dead one. A max of zero or less disables
+// the limit.
+func NewConcurrencyLimiter(max int, exemptPaths ...string) fiber.Handler {
+	if max <= 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+	slots := make(chan struct{}, max)
+	return func(c *fiber.Ctx) error {
+		if exempt[c.Path()] {
+			return c.Next()
+		}
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			return c.Next()
+		default:
+			c.Set(fiber.HeaderRetryAfter, "1")
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Server is too busy, please retry",
+			})
+		}
+	}
+}
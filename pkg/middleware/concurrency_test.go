@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrencyLimiter_RejectsOverflow(t *testing.T) {
+	const max = 3
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	app := fiber.New()
+	app.Use(NewConcurrencyLimiter(max))
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		started <- struct{}{}
+		<-release
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/fast", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	// Saturate every slot with a blocked request
+	var wg sync.WaitGroup
+	statuses := make(chan int, max)
+	for i := 0; i < max; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/slow", nil), -1)
+			if assert.NoError(t, err) {
+				statuses <- resp.StatusCode
+			}
+		}()
+		<-started
+	}
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/fast", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get(fiber.HeaderRetryAfter))
+
+	close(release)
+	wg.Wait()
+	close(statuses)
+	for status := range statuses {
+		assert.Equal(t, fiber.StatusOK, status)
+	}
+
+	// Slots are released once the blocked requests finish
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/fast", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestConcurrencyLimiter_ReleasesOnPanic(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) (err error) {
+		defer func() {
+			if recover() != nil {
+				err = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}()
+		return c.Next()
+	})
+	app.Use(NewConcurrencyLimiter(1))
+	app.Get("/panic", func(c *fiber.Ctx) error {
+		panic("boom")
+	})
+	app.Get("/ok", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/panic", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/ok", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestConcurrencyLimiter_Disabled(t *testing.T) {
+	app := fiber.New()
+	app.Use(NewConcurrencyLimiter(0))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
+
+func TestConcurrencyLimiter_ExemptPathsSkipTheLimit(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	app := fiber.New()
+	app.Use(NewConcurrencyLimiter(1, "/livez"))
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		started <- struct{}{}
+		<-release
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/livez", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/slow", nil), -1)
+		assert.NoError(t, err)
+	}()
+	<-started
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/livez", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	close(release)
+	<-done
+}
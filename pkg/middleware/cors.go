@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// DefaultCORSExposeHeaders are the custom response headers browser clients
+// may read: the request ID and the request timing and memory measurements
+var DefaultCORSExposeHeaders = []string{
+	fiber.HeaderXRequestID,
+	fiber.HeaderServerTiming,
+	"X-Memory-Before",
+	"X-Memory-After",
+	"X-Memory-Diff",
+	"X-Request-Duration",
+	"X-Num-Goroutines",
+	"X-Goroutines-Before",
+	"X-Goroutines-After",
+	"X-Goroutines-Diff",
+}
+
+// CORSConfig configures the CORS middleware
+type CORSConfig struct {
+	// AllowOrigins lists the origins allowed to call the API. "*" allows any
+	// origin and "https://*.example.com" any subdomain.
+	AllowOrigins []string
+	// AllowCredentials lets browsers send cookies and Authorization headers
+	AllowCredentials bool
+	// ExposeHeaders lists the response headers browser scripts may read
+	ExposeHeaders []string
+	// MaxAge is how long browsers may cache a preflight response. Zero omits
+	// Access-Control-Max-Age, leaving browsers to their own default.
+	MaxAge time.Duration
+}
+
+// Validate reports whether the configuration can be served. Browsers refuse
+// credentialed responses allowed for any origin, so that combination is an
+// error rather than a silently broken setup.
+func (c CORSConfig) Validate() error {
+	if len(c.AllowOrigins) == 0 {
+		return errors.New("at least one allowed origin is required")
+	}
+	for _, origin := range c.AllowOrigins {
+		if origin == "*" && c.AllowCredentials {
+			return errors.New(`credentials cannot be allowed for the "*" origin; list the origins instead`)
+		}
+	}
+	if c.MaxAge < 0 {
+		return fmt.Errorf("max age must not be negative, got %s", c.MaxAge)
+	}
+	return nil
+}
+
+// CORS returns a middleware answering preflight requests and setting the
+// Access-Control-* headers for the configured origins
+func CORS(config CORSConfig) fiber.Handler {
+	return cors.New(cors.Config{
+		AllowOrigins:     strings.Join(config.AllowOrigins, ","),
+		AllowCredentials: config.AllowCredentials,
+		ExposeHeaders:    strings.Join(config.ExposeHeaders, ","),
+		MaxAge:           int(config.MaxAge / time.Second),
+	})
+}
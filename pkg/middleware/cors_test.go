@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORS_ExposesConfiguredHeaders(t *testing.T) {
+	app := fiber.New()
+	app.Use(CORS(CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com"},
+		AllowCredentials: true,
+		ExposeHeaders:    DefaultCORSExposeHeaders,
+		MaxAge:           10 * time.Minute,
+	}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://app.example.com")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://app.example.com", resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "true", resp.Header.Get(fiber.HeaderAccessControlAllowCredentials))
+	exposed := strings.Split(resp.Header.Get(fiber.HeaderAccessControlExposeHeaders), ",")
+	assert.Equal(t, DefaultCORSExposeHeaders, exposed)
+	assert.Contains(t, exposed, "X-Request-ID")
+	assert.Contains(t, exposed, "X-Memory-Diff")
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	app := fiber.New()
+	app.Use(CORS(CORSConfig{
+		AllowOrigins:  []string{"https://app.example.com"},
+		ExposeHeaders: []string{"X-Request-ID"},
+		MaxAge:        10 * time.Minute,
+	}))
+
+	req := httptest.NewRequest(fiber.MethodOptions, "/users", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://app.example.com")
+	req.Header.Set(fiber.HeaderAccessControlRequestMethod, fiber.MethodPost)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "https://app.example.com", resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "600", resp.Header.Get(fiber.HeaderAccessControlMaxAge))
+	assert.Empty(t, resp.Header.Get(fiber.HeaderAccessControlAllowCredentials))
+}
+
+func TestCORS_RejectsUnknownOrigins(t *testing.T) {
+	app := fiber.New()
+	app.Use(CORS(CORSConfig{AllowOrigins: []string{"https://app.example.com"}}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://evil.example.org")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Empty(t, resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+}
+
+func TestCORSConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  CORSConfig
+		wantErr string
+	}{
+		{"valid", CORSConfig{AllowOrigins: []string{"https://app.example.com"}, AllowCredentials: true}, ""},
+		{"any origin", CORSConfig{AllowOrigins: []string{"*"}, MaxAge: time.Hour}, ""},
+		{"no origins", CORSConfig{}, "at least one allowed origin"},
+		{"any origin with credentials", CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}, "credentials cannot be allowed"},
+		{"negative max age", CORSConfig{AllowOrigins: []string{"*"}, MaxAge: -time.Second}, "must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NormalizeMode selects how requests for a non-canonical path are handled
+type NormalizeMode string
+
+const (
+	// NormalizeRewrite serves the canonical path's route in place
+	NormalizeRewrite NormalizeMode = "rewrite"
+	// NormalizeRedirect sends clients a permanent redirect to the canonical path
+	NormalizeRedirect NormalizeMode = "redirect"
+)
+
+// PathNormalizerConfig configures the NormalizePath middleware
+type PathNormalizerConfig struct {
+	Mode NormalizeMode
+	// TrailingSlashPaths lists paths whose canonical form ends in a slash,
+	// such as "/debug/pprof/", whose page uses relative links
+	TrailingSlashPaths []string
+}
+
+// Validate reports whether the configuration can be served
+func (c PathNormalizerConfig) Validate() error {
+	if c.Mode != NormalizeRewrite && c.Mode != NormalizeRedirect {
+		return fmt.Errorf(`path normalization mode must be "rewrite" or "redirect", got %q`, c.Mode)
+	}
+	for _, path := range c.TrailingSlashPaths {
+		if !strings.HasPrefix(path, "/") || !strings.HasSuffix(path, "/") {
+			return fmt.Errorf("trailing slash path must start and end with a slash, got %q", path)
+		}
+	}
+	return nil
+}
+
+// NormalizePath returns a middleware that collapses repeated slashes and
+// strips trailing slashes, so /users/ and //users reach the /users route. It
+// is meant for apps with StrictRouting enabled, where routes only match their
+// canonical path. Redirects use 301 for GET and HEAD and 308 otherwise, so
+// clients repeat the method and body.
+func NormalizePath(config PathNormalizerConfig) fiber.Handler {
+	slashPaths := make(map[string]bool, len(config.TrailingSlashPaths))
+	for _, path := range config.TrailingSlashPaths {
+		slashPaths[path] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		path := c.Path()
+		canonical := canonicalPath(path, slashPaths)
+		if canonical == path {
+			return c.Next()
+		}
+
+		if config.Mode == NormalizeRedirect {
+			location := canonical
+			if query := c.Request().URI().QueryString(); len(query) > 0 {
+				location += "?" + string(query)
+			}
+			status := fiber.StatusPermanentRedirect
+			if c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead {
+				status = fiber.StatusMovedPermanently
+			}
+			return c.Redirect(location, status)
+		}
+
+		c.Path(canonical)
+		return c.Next()
+	}
+}
+
+// canonicalPath collapses repeated slashes and removes the trailing slash,
+// except from the root and the given trailing slash paths
+func canonicalPath(path string, slashPaths map[string]bool) string {
+	for strings.Contains(path, "//") {
+		path = strings.ReplaceAll(path, "//", "/")
+	}
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	if path == "" {
+		return "/"
+	}
+	if slashPaths[path+"/"] {
+		return path + "/"
+	}
+	return path
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNormalizedApp serves /users and /debug/pprof/ with strict routing, so
+// only the canonical paths match without the middleware
+func newNormalizedApp(mode NormalizeMode) *fiber.App {
+	app := fiber.New(fiber.Config{StrictRouting: true, CaseSensitive: true})
+	app.Use(NormalizePath(PathNormalizerConfig{Mode: mode, TrailingSlashPaths: []string{"/debug/pprof/"}}))
+	app.Get("/users", func(c *fiber.Ctx) error {
+		return c.SendString("list " + c.Query("page"))
+	})
+	app.Post("/users", func(c *fiber.Ctx) error {
+		return c.SendString("create")
+	})
+	app.Get("/debug/pprof/", func(c *fiber.Ctx) error {
+		return c.SendString("index")
+	})
+	return app
+}
+
+func TestNormalizePath_Rewrite(t *testing.T) {
+	app := newNormalizedApp(NormalizeRewrite)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{fiber.MethodGet, "/users", "list 2"},
+		{fiber.MethodGet, "/users/", "list 2"},
+		{fiber.MethodGet, "//users//", "list 2"},
+		{fiber.MethodPost, "/users/", "create"},
+		{fiber.MethodGet, "/debug/pprof/", "index"},
+		{fiber.MethodGet, "/debug/pprof", "index"},
+		{fiber.MethodGet, "/debug//pprof//", "index"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path+"?page=2", nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
+
+func TestNormalizePath_Redirect(t *testing.T) {
+	app := newNormalizedApp(NormalizeRedirect)
+
+	tests := []struct {
+		method   string
+		path     string
+		status   int
+		location string
+	}{
+		{fiber.MethodGet, "/users/?page=2", fiber.StatusMovedPermanently, "/users?page=2"},
+		{fiber.MethodGet, "//users", fiber.StatusMovedPermanently, "/users"},
+		{fiber.MethodPost, "/users/", fiber.StatusPermanentRedirect, "/users"},
+		{fiber.MethodGet, "/debug/pprof", fiber.StatusMovedPermanently, "/debug/pprof/"},
+		{fiber.MethodGet, "/users", fiber.StatusOK, ""},
+		{fiber.MethodGet, "/", fiber.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.location, resp.Header.Get(fiber.HeaderLocation))
+		})
+	}
+}
+
+func TestNormalizePath_KeepsCase(t *testing.T) {
+	app := newNormalizedApp(NormalizeRewrite)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/Users/", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+}
+
+func TestPathNormalizerConfig_Validate(t *testing.T) {
+	assert.NoError(t, PathNormalizerConfig{Mode: NormalizeRewrite}.Validate())
+	assert.NoError(t, PathNormalizerConfig{Mode: NormalizeRedirect, TrailingSlashPaths: []string{"/debug/pprof/"}}.Validate())
+	assert.ErrorContains(t, PathNormalizerConfig{Mode: "strip"}.Validate(), `got "strip"`)
+	assert.ErrorContains(t, PathNormalizerConfig{Mode: NormalizeRewrite, TrailingSlashPaths: []string{"/debug/pprof"}}.Validate(), "must start and end with a slash")
+}
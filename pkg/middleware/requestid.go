@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"github.com/example/go-clean-architecture/pkg/logging"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// RequestIDKey is the context locals key holding the request ID
+const RequestIDKey = "requestid"
+
+// NewRequestID returns a middleware that assigns each request an ID, reusing
+// an incoming X-Request-ID header and echoing it in the response. The ID is
+// also stored in the request's user context, so logs written further down,
+// such as database query logs, carry it too.
+func NewRequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Get(fiber.HeaderXRequestID)
+		if id == "" {
+			id = utils.UUID()
+		}
+
+		c.Set(fiber.HeaderXRequestID, id)
+		c.Locals(RequestIDKey, id)
+		c.SetUserContext(logging.ContextWithRequestID(c.UserContext(), id))
+		return c.Next()
+	}
+}
+
+// RequestID returns the ID assigned to the request, or "" if there is none
+func RequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(RequestIDKey).(string)
+	return id
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/example/go-clean-architecture/pkg/logging"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	app := fiber.New()
+	app.Use(NewRequestID())
+	app.Get("/", func(c *fiber.Ctx) error {
+		if logging.RequestIDFromContext(c.UserContext()) != RequestID(c) {
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		return c.SendString(RequestID(c))
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.NotEmpty(t, body)
+	assert.Equal(t, string(body), resp.Header.Get(fiber.HeaderXRequestID))
+
+	req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+	req.Header.Set(fiber.HeaderXRequestID, "upstream-id")
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "upstream-id", string(body))
+}
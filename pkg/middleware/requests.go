@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestCounter counts the requests served by an application and those
+// still in flight
+type RequestCounter struct {
+	total    atomic.Uint64
+	inFlight atomic.Int64
+}
+
+// NewRequestCounter creates a new request counter
+func NewRequestCounter() *RequestCounter {
+	return &RequestCounter{}
+}
+
+// Handler returns a middleware that counts every request passing through it
+func (r *RequestCounter) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		r.total.Add(1)
+		r.inFlight.Add(1)
+		defer r.inFlight.Add(-1)
+		return c.Next()
+	}
+}
+
+// Total returns the number of requests counted so far
+func (r *RequestCounter) Total() uint64 {
+	return r.total.Load()
+}
+
+// InFlight returns the number of requests currently being handled
+func (r *RequestCounter) InFlight() int64 {
+	return r.inFlight.Load()
+}
+
+// WaitIdle waits until no requests are in flight or the timeout expires, and
+// returns the number of requests still in flight
+func (r *RequestCounter) WaitIdle(timeout time.Duration) int64 {
+	const pollInterval = 10 * time.Millisecond
+
+	deadline := time.Now().Add(timeout)
+	for {
+		inFlight := r.InFlight()
+		if inFlight <= 0 || !time.Now().Before(deadline) {
+			return inFlight
+		}
+		time.Sleep(min(pollInterval, time.Until(deadline)))
+	}
+}
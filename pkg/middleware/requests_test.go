@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestCounter(t *testing.T) {
+	counter := NewRequestCounter()
+	app := fiber.New()
+	app.Use(counter.Handler())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+		require.NoError(t, err)
+	}
+	// Unmatched routes are still served and counted
+	_, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/missing", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(4), counter.Total())
+}
+
+func TestRequestCounter_InFlight(t *testing.T) {
+	counter := NewRequestCounter()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	app := fiber.New()
+	app.Use(counter.Handler())
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		<-release
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/slow", nil), -1)
+		assert.NoError(t, err)
+	}()
+	<-started
+
+	assert.Equal(t, int64(1), counter.InFlight())
+	assert.Equal(t, int64(1), counter.WaitIdle(20*time.Millisecond))
+
+	close(release)
+	assert.Equal(t, int64(0), counter.WaitIdle(time.Second))
+	<-done
+}
+
+func TestRequestCounter_WaitIdle(t *testing.T) {
+	counter := NewRequestCounter()
+
+	// Idle counters return immediately
+	start := time.Now()
+	assert.Equal(t, int64(0), counter.WaitIdle(time.Hour))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// Waits until the last request finishes
+	counter.inFlight.Add(2)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		counter.inFlight.Add(-1)
+		time.Sleep(20 * time.Millisecond)
+		counter.inFlight.Add(-1)
+	}()
+	assert.Equal(t, int64(0), counter.WaitIdle(time.Second))
+
+	// Gives up at the timeout and reports what is left
+	counter.inFlight.Add(3)
+	start = time.Now()
+	assert.Equal(t, int64(3), counter.WaitIdle(30*time.Millisecond))
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CSPNonceKey is the context locals key holding the request's CSP nonce
+const CSPNonceKey = "cspnonce"
+
+// CSPNoncePlaceholder is replaced by a fresh nonce on every request wherever
+// it appears in a Content-Security-Policy
+const CSPNoncePlaceholder = "{nonce}"
+
+// DefaultContentSecurityPolicy only allows same-origin resources plus inline
+// scripts and styles carrying the request's nonce
+const DefaultContentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self' 'nonce-" + CSPNoncePlaceholder + "'; " +
+	"style-src 'self' 'nonce-" + CSPNoncePlaceholder + "'; " +
+	"img-src 'self' data:; object-src 'none'; base-uri 'none'; frame-ancestors 'none'"
+
+// DefaultReferrerPolicy keeps URLs, which may carry tokens, out of Referer headers
+const DefaultReferrerPolicy = "no-referrer"
+
+// SecurityHeadersConfig configures the SecurityHeaders middleware
+type SecurityHeadersConfig struct {
+	// ContentSecurityPolicy is sent as the Content-Security-Policy header, with
+	// every CSPNoncePlaceholder replaced by the request's nonce. Empty omits it.
+	ContentSecurityPolicy string
+	// ReferrerPolicy is sent as the Referrer-Policy header. Empty omits it.
+	ReferrerPolicy string
+}
+
+// SecurityHeaders returns a middleware that sets X-Content-Type-Options,
+// X-Frame-Options, Referrer-Policy and Content-Security-Policy on every
+// response. When the policy uses CSPNoncePlaceholder, each request gets a new
+// nonce that handlers read with CSPNonce to mark their inline scripts and styles.
+func SecurityHeaders(config SecurityHeadersConfig) fiber.Handler {
+	useNonce := strings.Contains(config.ContentSecurityPolicy, CSPNoncePlaceholder)
+
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+		c.Set(fiber.HeaderXFrameOptions, "DENY")
+		if config.ReferrerPolicy != "" {
+			c.Set(fiber.HeaderReferrerPolicy, config.ReferrerPolicy)
+		}
+
+		if policy := config.ContentSecurityPolicy; policy != "" {
+			if useNonce {
+				nonce, err := newCSPNonce()
+				if err != nil {
+					return err
+				}
+				c.Locals(CSPNonceKey, nonce)
+				policy = strings.ReplaceAll(policy, CSPNoncePlaceholder, nonce)
+			}
+			c.Set(fiber.HeaderContentSecurityPolicy, policy)
+		}
+
+		return c.Next()
+	}
+}
+
+// CSPNonce returns the request's CSP nonce, or "" if the policy has none
+func CSPNonce(c *fiber.Ctx) string {
+	nonce, _ := c.Locals(CSPNonceKey).(string)
+	return nonce
+}
+
+// newCSPNonce returns 128 random bits encoded for use in a CSP source
+func newCSPNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
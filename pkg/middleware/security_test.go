@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	app := fiber.New()
+	app.Use(SecurityHeaders(SecurityHeadersConfig{
+		ContentSecurityPolicy: DefaultContentSecurityPolicy,
+		ReferrerPolicy:        DefaultReferrerPolicy,
+	}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(CSPNonce(c))
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	nonce := string(body)
+
+	assert.Equal(t, "nosniff", resp.Header.Get(fiber.HeaderXContentTypeOptions))
+	assert.Equal(t, "DENY", resp.Header.Get(fiber.HeaderXFrameOptions))
+	assert.Equal(t, "no-referrer", resp.Header.Get(fiber.HeaderReferrerPolicy))
+
+	require.NotEmpty(t, nonce)
+	policy := resp.Header.Get(fiber.HeaderContentSecurityPolicy)
+	assert.Equal(t, strings.ReplaceAll(DefaultContentSecurityPolicy, CSPNoncePlaceholder, nonce), policy)
+	assert.NotContains(t, policy, CSPNoncePlaceholder)
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	require.NoError(t, err)
+	body, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.NotEqual(t, nonce, string(body), "every request gets a fresh nonce")
+}
+
+func TestSecurityHeaders_PolicyWithoutNonce(t *testing.T) {
+	app := fiber.New()
+	app.Use(SecurityHeaders(SecurityHeadersConfig{ContentSecurityPolicy: "default-src 'none'"}))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(CSPNonce(c))
+	})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Empty(t, body)
+	assert.Equal(t, "default-src 'none'", resp.Header.Get(fiber.HeaderContentSecurityPolicy))
+	assert.Empty(t, resp.Header.Get(fiber.HeaderReferrerPolicy))
+	assert.Equal(t, "nosniff", resp.Header.Get(fiber.HeaderXContentTypeOptions))
+}
@@ -0,0 +1,237 @@
+// Package migrate applies and rolls back versioned SQL migrations on PostgreSQL
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// lockKey is the PostgreSQL advisory lock key that serializes migration runs,
+// so replicas starting at the same time apply each migration once
+const lockKey = 4_201_606_620
+
+// Migration is one versioned schema change together with its rollback
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// String returns the migration's file name stem, e.g. 000001_create_users
+func (m Migration) String() string {
+	return fmt.Sprintf("%06d_%s", m.Version, m.Name)
+}
+
+// migrationFile matches {version}_{name}.up.sql and {version}_{name}.down.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load reads the migrations in the root of fsys, ordered by version. Every
+// version needs both an up and a down file.
+func Load(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, name := range names {
+		match := migrationFile.FindStringSubmatch(name)
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named {version}_{name}.up.sql or .down.sql", name)
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("migration file %s has an invalid version", name)
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %s needs both an up and a down file", migration)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies migrations to a database and records the current version
+// in a schema_migrations table laid out like golang-migrate's
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New creates a migrator for the given migrations, ordered by version
+func New(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// Version returns the version of the last applied migration, or 0 for an empty database
+func (m *Migrator) Version(ctx context.Context) (uint64, error) {
+	var version uint64
+	err := m.locked(ctx, func(tx *sql.Tx, current uint64) error {
+		version = current
+		return nil
+	})
+	return version, err
+}
+
+// Applied returns the migrations up to and including the current version
+func (m *Migrator) Applied(ctx context.Context) ([]Migration, error) {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var applied []Migration
+	for _, migration := range m.migrations {
+		if migration.Version <= version {
+			applied = append(applied, migration)
+		}
+	}
+	return applied, nil
+}
+
+// Up applies every pending migration in order, each in its own transaction,
+// and returns the ones it applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	for {
+		var step *Migration
+		err := m.locked(ctx, func(tx *sql.Tx, current uint64) error {
+			for i := range m.migrations {
+				if m.migrations[i].Version > current {
+					step = &m.migrations[i]
+					break
+				}
+			}
+			if step == nil {
+				return nil
+			}
+			if _, err := tx.ExecContext(ctx, step.Up); err != nil {
+				return fmt.Errorf("migration %s failed: %w", step, err)
+			}
+			return setVersion(ctx, tx, step.Version)
+		})
+		if err != nil {
+			return applied, err
+		}
+		if step == nil {
+			return applied, nil
+		}
+		applied = append(applied, *step)
+	}
+}
+
+// ErrUnknownVersion is returned when the database is at a version this build has no migration for
+var ErrUnknownVersion = errors.New("database schema version is not a known migration")
+
+// Down rolls back the last n applied migrations, newest first, each in its own
+// transaction, and returns the ones it rolled back
+func (m *Migrator) Down(ctx context.Context, n int) ([]Migration, error) {
+	var rolledBack []Migration
+	for len(rolledBack) < n {
+		var step *Migration
+		err := m.locked(ctx, func(tx *sql.Tx, current uint64) error {
+			if current == 0 {
+				return nil
+			}
+			var previous uint64
+			for i := range m.migrations {
+				if m.migrations[i].Version == current {
+					step = &m.migrations[i]
+					break
+				}
+				previous = m.migrations[i].Version
+			}
+			if step == nil {
+				return fmt.Errorf("%w: %d", ErrUnknownVersion, current)
+			}
+			if _, err := tx.ExecContext(ctx, step.Down); err != nil {
+				return fmt.Errorf("rollback of migration %s failed: %w", step, err)
+			}
+			return setVersion(ctx, tx, previous)
+		})
+		if err != nil {
+			return rolledBack, err
+		}
+		if step == nil {
+			break
+		}
+		rolledBack = append(rolledBack, *step)
+	}
+	return rolledBack, nil
+}
+
+// locked runs fn in a transaction holding the migration lock, passing the
+// current version, and commits when fn returns nil
+func (m *Migrator) locked(ctx context.Context, fn func(tx *sql.Tx, current uint64) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Waiting for the lock and building indexes can outlast a statement
+	// timeout set for application queries
+	if _, err := tx.ExecContext(ctx, "SET LOCAL statement_timeout = 0"); err != nil {
+		return fmt.Errorf("failed to clear the statement timeout: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("failed to acquire the migration lock: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)"); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var current uint64
+	err = tx.QueryRowContext(ctx, "SELECT version FROM schema_migrations LIMIT 1").Scan(&current)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read the schema version: %w", err)
+	}
+
+	if err := fn(tx, current); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// setVersion records version as the current schema version; 0 clears it.
+// Migrations run in a transaction with the version update, so the row is
+// never left dirty.
+func setVersion(ctx context.Context, tx *sql.Tx, version uint64) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+	if version == 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)", version)
+	return err
+}
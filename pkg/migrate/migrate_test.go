@@ -0,0 +1,191 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/example/go-clean-architecture/migrations"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "create_widgets", Up: "CREATE TABLE widgets (id bigint)", Down: "DROP TABLE widgets"},
+	{Version: 2, Name: "add_widget_name", Up: "ALTER TABLE widgets ADD COLUMN name text", Down: "ALTER TABLE widgets DROP COLUMN name"},
+}
+
+// expectLocked expects a migration transaction that finds the database at version
+func expectLocked(mock sqlmock.Sqlmock, version uint64) {
+	mock.ExpectBegin()
+	mock.ExpectExec("SET LOCAL statement_timeout = 0").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SELECT pg_advisory_xact_lock($1)").WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"version"})
+	if version > 0 {
+		rows.AddRow(version)
+	}
+	mock.ExpectQuery("SELECT version FROM schema_migrations LIMIT 1").WillReturnRows(rows)
+}
+
+// expectStep expects sql to run and the version to move to version, then a commit
+func expectStep(mock sqlmock.Sqlmock, sql string, version uint64) {
+	mock.ExpectExec(sql).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM schema_migrations").WillReturnResult(sqlmock.NewResult(0, 1))
+	if version > 0 {
+		mock.ExpectExec("INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)").
+			WithArgs(version).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+}
+
+func newMockMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	})
+	return New(db, testMigrations), mock
+}
+
+func TestMigrator_UpThenDown(t *testing.T) {
+	migrator, mock := newMockMigrator(t)
+	ctx := context.Background()
+
+	expectLocked(mock, 0)
+	expectStep(mock, testMigrations[0].Up, 1)
+	expectLocked(mock, 1)
+	expectStep(mock, testMigrations[1].Up, 2)
+	expectLocked(mock, 2)
+	mock.ExpectCommit()
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, testMigrations, applied)
+
+	expectLocked(mock, 2)
+	expectStep(mock, testMigrations[1].Down, 1)
+	expectLocked(mock, 1)
+	expectStep(mock, testMigrations[0].Down, 0)
+
+	rolledBack, err := migrator.Down(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []Migration{testMigrations[1], testMigrations[0]}, rolledBack)
+}
+
+func TestMigrator_UpIsNoOpWhenCurrent(t *testing.T) {
+	migrator, mock := newMockMigrator(t)
+
+	expectLocked(mock, 2)
+	mock.ExpectCommit()
+
+	applied, err := migrator.Up(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+func TestMigrator_FailedMigrationRollsBack(t *testing.T) {
+	migrator, mock := newMockMigrator(t)
+
+	expectLocked(mock, 1)
+	mock.ExpectExec(testMigrations[1].Up).WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	applied, err := migrator.Up(context.Background())
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	assert.ErrorContains(t, err, "000002_add_widget_name")
+	assert.Empty(t, applied)
+}
+
+func TestMigrator_DownRejectsUnknownVersion(t *testing.T) {
+	migrator, mock := newMockMigrator(t)
+
+	expectLocked(mock, 7)
+	mock.ExpectRollback()
+
+	_, err := migrator.Down(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(fstest.MapFS{
+		"000002_add_widget_name.up.sql":   {Data: []byte(testMigrations[1].Up)},
+		"000002_add_widget_name.down.sql": {Data: []byte(testMigrations[1].Down)},
+		"000001_create_widgets.up.sql":    {Data: []byte(testMigrations[0].Up)},
+		"000001_create_widgets.down.sql":  {Data: []byte(testMigrations[0].Down)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, testMigrations, migrations)
+	assert.Equal(t, "000001_create_widgets", migrations[0].String())
+}
+
+func TestLoad_RejectsInvalidFiles(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"missing down": {"000001_create_widgets.up.sql": {Data: []byte("SELECT 1")}},
+		"bad name":     {"create_widgets.sql": {Data: []byte("SELECT 1")}},
+		"zero version": {"0_create_widgets.up.sql": {Data: []byte("SELECT 1")}, "0_create_widgets.down.sql": {Data: []byte("SELECT 1")}},
+		"duplicate version": {
+			"000001_create_widgets.up.sql": {Data: []byte("SELECT 1")}, "000001_create_widgets.down.sql": {Data: []byte("SELECT 1")},
+			"000001_create_gadgets.up.sql": {Data: []byte("SELECT 1")}, "000001_create_gadgets.down.sql": {Data: []byte("SELECT 1")},
+		},
+	}
+
+	for name, fsys := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoad_ProjectMigrations(t *testing.T) {
+	migrations, err := Load(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	assert.Equal(t, "000001_create_users", migrations[0].String())
+}
+
+// TestMigrator_PostgreSQL applies and rolls back the project migrations on the
+// PostgreSQL database at DATABASE_TEST_URL, skipping the test when it is unset.
+// The database should be empty, since the migrations drop what they create.
+func TestMigrator_PostgreSQL(t *testing.T) {
+	url := os.Getenv("DATABASE_TEST_URL")
+	if url == "" {
+		t.Skip("DATABASE_TEST_URL not set")
+	}
+
+	db, err := sql.Open("pgx", url)
+	require.NoError(t, err)
+	defer db.Close()
+
+	loaded, err := Load(migrations.FS)
+	require.NoError(t, err)
+	migrator := New(db, loaded)
+	ctx := context.Background()
+
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	version, err := migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, loaded[len(loaded)-1].Version, version)
+
+	var exists bool
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT to_regclass('users') IS NOT NULL").Scan(&exists))
+	assert.True(t, exists)
+
+	_, err = migrator.Down(ctx, len(loaded))
+	require.NoError(t, err)
+	version, err = migrator.Version(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, version)
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT to_regclass('users') IS NOT NULL").Scan(&exists))
+	assert.False(t, exists)
+}
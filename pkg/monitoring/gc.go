@@ -0,0 +1,37 @@
+package monitoring
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GCHandler returns a Fiber handler that forces a garbage collection and
+// reports memory statistics from before and after it. With
+// ?freeOSMemory=true it also returns as much memory to the OS as possible.
+func GCHandler(monitor *MemoryMonitor) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		freeOSMemory := c.QueryBool("freeOSMemory")
+
+		before := monitor.GetMemoryStats()
+		start := time.Now()
+		if freeOSMemory {
+			// FreeOSMemory runs a garbage collection itself
+			debug.FreeOSMemory()
+		} else {
+			runtime.GC()
+		}
+		duration := time.Since(start)
+		after := monitor.GetMemoryStats()
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"before":       before,
+			"after":        after,
+			"freed":        int64(before.Alloc) - int64(after.Alloc),
+			"freeOSMemory": freeOSMemory,
+			"duration":     duration.String(),
+		})
+	}
+}
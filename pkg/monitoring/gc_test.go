@@ -0,0 +1,36 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGCHandler(t *testing.T) {
+	for _, path := range []string{"/debug/gc", "/debug/gc?freeOSMemory=true"} {
+		t.Run(path, func(t *testing.T) {
+			app := fiber.New()
+			app.Post("/debug/gc", GCHandler(NewMemoryMonitor(0)))
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, path, nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var body struct {
+				Before       MemoryStats `json:"before"`
+				After        MemoryStats `json:"after"`
+				FreeOSMemory bool        `json:"freeOSMemory"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+			assert.NotZero(t, body.Before.Sys)
+			assert.NotZero(t, body.After.Sys)
+			assert.Greater(t, body.After.NumGC, body.Before.NumGC)
+			assert.Equal(t, path != "/debug/gc", body.FreeOSMemory)
+		})
+	}
+}
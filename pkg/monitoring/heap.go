@@ -0,0 +1,16 @@
+package monitoring
+
+import (
+	"bytes"
+	"runtime/pprof"
+)
+
+// CaptureHeapProfile returns a gzipped pprof heap profile of the process, as
+// served by /debug/pprof/heap
+func CaptureHeapProfile() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pprof.WriteHeapProfile(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
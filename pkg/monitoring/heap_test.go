@@ -0,0 +1,17 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureHeapProfile(t *testing.T) {
+	profile, err := CaptureHeapProfile()
+	require.NoError(t, err)
+
+	// Profiles are gzip-compressed protocol buffers
+	require.Greater(t, len(profile), 2)
+	assert.Equal(t, []byte{0x1f, 0x8b}, profile[:2])
+}
@@ -2,11 +2,14 @@ package monitoring
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"runtime"
 	"sync"
 	"time"
 
+	"github.com/example/go-clean-architecture/pkg/health"
+	"github.com/example/go-clean-architecture/pkg/jsonnum"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -20,31 +23,80 @@ type MemoryStats struct {
 	NumGoroutine  int     `json:"numGoroutine"`  // number of goroutines
 }
 
+// memoryStatsJSON is MemoryStats with its byte counts encoded by jsonnum
+type memoryStatsJSON struct {
+	plainMemoryStats
+	Alloc      jsonnum.Uint64 `json:"alloc"`
+	TotalAlloc jsonnum.Uint64 `json:"totalAlloc"`
+	Sys        jsonnum.Uint64 `json:"sys"`
+}
+
+// plainMemoryStats has MemoryStats' fields without its JSON methods
+type plainMemoryStats MemoryStats
+
+// MarshalJSON writes the byte counts with jsonnum, so clients parsing them
+// as JavaScript numbers can keep them exact
+func (s MemoryStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.jsonFields())
+}
+
+// jsonFields returns the stats as they are written to JSON
+func (s MemoryStats) jsonFields() memoryStatsJSON {
+	return memoryStatsJSON{
+		plainMemoryStats: plainMemoryStats(s),
+		Alloc:            jsonnum.Uint64(s.Alloc),
+		TotalAlloc:       jsonnum.Uint64(s.TotalAlloc),
+		Sys:              jsonnum.Uint64(s.Sys),
+	}
+}
+
+// UnmarshalJSON reads byte counts written as numbers or strings
+func (s *MemoryStats) UnmarshalJSON(data []byte) error {
+	var v memoryStatsJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = MemoryStats(v.plainMemoryStats)
+	s.Alloc, s.TotalAlloc, s.Sys = uint64(v.Alloc), uint64(v.TotalAlloc), uint64(v.Sys)
+	return nil
+}
+
 // MemoryMonitor represents a memory monitoring service
 type MemoryMonitor struct {
 	mu             sync.RWMutex
 	stats          MemoryStats
 	maxAlloc       uint64
-	alertThreshold float64
+	alertThreshold AlertThreshold
 	alertHandler   func(MemoryStats)
+	alertCooldown  time.Duration
+	overThreshold  bool
+	lastAlert      time.Time
+	interval       time.Duration
+	lastSample     MemoryStats
+	lastSampleAt   time.Time
 }
 
-// NewMemoryMonitor creates a new memory monitor
+// DefaultAlertCooldown is the minimum time between two memory alerts
+const DefaultAlertCooldown = 5 * time.Minute
+
+// NewMemoryMonitor creates a new memory monitor alerting when Alloc exceeds
+// alertThreshold times Sys
 func NewMemoryMonitor(alertThreshold float64) *MemoryMonitor {
 	return &MemoryMonitor{
-		alertThreshold: alertThreshold,
+		alertThreshold: RatioThreshold(alertThreshold),
+		alertCooldown:  DefaultAlertCooldown,
 		maxAlloc:       0,
 	}
 }
 
-// GetMemoryStats returns current memory statistics
+// GetMemoryStats returns current memory statistics. The alert handler is
+// called after the monitor's lock is released, so a slow handler does not
+// block other readers and a handler may call back into the monitor.
 func (m *MemoryMonitor) GetMemoryStats() MemoryStats {
 	var ms runtime.MemStats
 	runtime.ReadMemStats(&ms)
 
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
+	m.mu.Lock()
 	stats := MemoryStats{
 		Alloc:         ms.Alloc,
 		TotalAlloc:    ms.TotalAlloc,
@@ -60,15 +112,34 @@ func (m *MemoryMonitor) GetMemoryStats() MemoryStats {
 	}
 
 	// Check for memory leak alert
-	if m.alertThreshold > 0 && float64(ms.Alloc) > m.alertThreshold*float64(ms.Sys) {
-		if m.alertHandler != nil {
-			m.alertHandler(stats)
-		}
+	var handler func(MemoryStats)
+	if m.shouldAlert(stats, time.Now()) {
+		handler = m.alertHandler
 	}
+	m.mu.Unlock()
 
+	if handler != nil {
+		handler(stats)
+	}
 	return stats
 }
 
+// shouldAlert reports whether stats warrant an alert. Alerts are
+// edge-triggered: they fire when usage crosses the threshold, not on every
+// sample above it, and never more than once per cooldown. The caller must
+// hold the write lock.
+func (m *MemoryMonitor) shouldAlert(stats MemoryStats, now time.Time) bool {
+	over := m.alertThreshold.Exceeded(stats)
+	crossed := over && !m.overThreshold
+	m.overThreshold = over
+
+	if !crossed || (!m.lastAlert.IsZero() && now.Sub(m.lastAlert) < m.alertCooldown) {
+		return false
+	}
+	m.lastAlert = now
+	return true
+}
+
 // GetMaxAlloc returns the maximum memory allocation observed
 func (m *MemoryMonitor) GetMaxAlloc() uint64 {
 	m.mu.RLock()
@@ -83,21 +154,76 @@ func (m *MemoryMonitor) SetAlertHandler(handler func(MemoryStats)) {
 	m.alertHandler = handler
 }
 
-// StartMonitoring starts periodic memory monitoring
+// SetAlertThreshold replaces the threshold memory alerts fire above
+func (m *MemoryMonitor) SetAlertThreshold(threshold AlertThreshold) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.alertThreshold = threshold
+}
+
+// SetAlertCooldown sets the minimum time between two memory alerts
+func (m *MemoryMonitor) SetAlertCooldown(cooldown time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.alertCooldown = cooldown
+}
+
+// StartMonitoring samples memory statistics every interval until ctx is cancelled
 func (m *MemoryMonitor) StartMonitoring(ctx context.Context, interval time.Duration) {
+	m.mu.Lock()
+	m.interval = interval
+	m.mu.Unlock()
+
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	m.sample()
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			m.GetMemoryStats()
+			m.sample()
 		}
 	}
 }
 
+// sample reads memory statistics and records them as the last sample
+func (m *MemoryMonitor) sample() {
+	stats := m.GetMemoryStats()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastSample = stats
+	m.lastSampleAt = time.Now()
+}
+
+// LastSample returns the last sample taken by the monitoring loop and when it
+// was taken; the time is zero if monitoring has not sampled yet
+func (m *MemoryMonitor) LastSample() (MemoryStats, time.Time) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastSample, m.lastSampleAt
+}
+
+// SampleStale reports whether the monitoring loop has missed several samples
+// in a row, which means it has stopped. It is false if monitoring never started.
+func (m *MemoryMonitor) SampleStale(now time.Time) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.interval > 0 && now.Sub(m.lastSampleAt) > 3*m.interval
+}
+
+// Check implements health.Checker, reporting degraded while the monitoring
+// loop has stopped sampling
+func (m *MemoryMonitor) Check(ctx context.Context) (health.Status, error) {
+	if m.SampleStale(time.Now()) {
+		_, at := m.LastSample()
+		return health.StatusDegraded, fmt.Errorf("memory has not been sampled since %s", at.UTC().Format(time.RFC3339))
+	}
+	return health.StatusHealthy, nil
+}
+
 // FormatBytes formats bytes into a human-readable string
 func FormatBytes(bytes uint64) string {
 	const unit = 1024
@@ -117,8 +243,20 @@ func MemoryHealthCheckHandler(monitor *MemoryMonitor) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		stats := monitor.GetMemoryStats()
 
+		status := "healthy"
+		stale := monitor.SampleStale(time.Now())
+		if stale {
+			status = "degraded"
+		}
+
+		var lastSampleAt *time.Time
+		if _, at := monitor.LastSample(); !at.IsZero() {
+			at = at.UTC()
+			lastSampleAt = &at
+		}
+
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"status": "healthy",
+			"status": status,
 			"memory": fiber.Map{
 				"alloc":         FormatBytes(stats.Alloc),
 				"totalAlloc":    FormatBytes(stats.TotalAlloc),
@@ -128,6 +266,10 @@ func MemoryHealthCheckHandler(monitor *MemoryMonitor) fiber.Handler {
 				"numGoroutine":  stats.NumGoroutine,
 				"maxAlloc":      FormatBytes(monitor.GetMaxAlloc()),
 			},
+			"sampling": fiber.Map{
+				"lastSampleAt": lastSampleAt,
+				"stale":        stale,
+			},
 			"timestamp": time.Now().UTC(),
 		})
 	}
@@ -0,0 +1,234 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/pkg/health"
+	"github.com/example/go-clean-architecture/pkg/jsonnum"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryMonitor_AlertsOnceWhileOverThreshold(t *testing.T) {
+	// Any allocation at all exceeds this threshold
+	monitor := NewMemoryMonitor(1e-12)
+	var alerts int
+	monitor.SetAlertHandler(func(MemoryStats) { alerts++ })
+
+	for i := 0; i < 10; i++ {
+		monitor.GetMemoryStats()
+	}
+
+	assert.Equal(t, 1, alerts)
+}
+
+func TestMemoryMonitor_AlertHandlerCanCallMonitor(t *testing.T) {
+	monitor := NewMemoryMonitor(1e-12)
+	var inner MemoryStats
+	monitor.SetAlertHandler(func(MemoryStats) {
+		// Both calls would deadlock if the handler ran under the monitor's lock
+		inner = monitor.GetMemoryStats()
+		monitor.SetAlertHandler(nil)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		monitor.GetMemoryStats()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetMemoryStats deadlocked calling the alert handler")
+	}
+	assert.NotZero(t, inner.Sys)
+}
+
+func TestMemoryMonitor_SlowAlertHandlerDoesNotBlockReads(t *testing.T) {
+	monitor := NewMemoryMonitor(1e-12)
+	release := make(chan struct{})
+	called := make(chan struct{})
+	monitor.SetAlertHandler(func(MemoryStats) {
+		close(called)
+		<-release
+	})
+	go monitor.GetMemoryStats()
+	<-called
+	defer close(release)
+
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		monitor.GetMaxAlloc()
+		monitor.GetMemoryStats()
+	}()
+
+	select {
+	case <-read:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reads blocked while the alert handler ran")
+	}
+}
+
+func TestMemoryMonitor_ShouldAlert(t *testing.T) {
+	high := MemoryStats{Alloc: 90, Sys: 100}
+	low := MemoryStats{Alloc: 10, Sys: 100}
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		steps  []MemoryStats
+		offset []time.Duration
+		alerts []bool
+	}{
+		{
+			name:   "fires once while staying high",
+			steps:  []MemoryStats{high, high, high},
+			offset: []time.Duration{0, time.Hour, 2 * time.Hour},
+			alerts: []bool{true, false, false},
+		},
+		{
+			name:   "re-alerts after dropping and crossing again",
+			steps:  []MemoryStats{high, low, high},
+			offset: []time.Duration{0, 10 * time.Minute, 20 * time.Minute},
+			alerts: []bool{true, false, true},
+		},
+		{
+			name:   "crossing again within the cooldown is suppressed",
+			steps:  []MemoryStats{high, low, high, low, high},
+			offset: []time.Duration{0, time.Minute, 2 * time.Minute, 3 * time.Minute, 6 * time.Minute},
+			alerts: []bool{true, false, false, false, true},
+		},
+		{
+			name:   "never fires below threshold",
+			steps:  []MemoryStats{low, low},
+			offset: []time.Duration{0, time.Hour},
+			alerts: []bool{false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := NewMemoryMonitor(0.8)
+
+			for i, stats := range tt.steps {
+				assert.Equal(t, tt.alerts[i], monitor.shouldAlert(stats, start.Add(tt.offset[i])), "step %d", i)
+			}
+		})
+	}
+}
+
+func TestMemoryMonitor_SetAlertCooldown(t *testing.T) {
+	monitor := NewMemoryMonitor(0.8)
+	monitor.SetAlertCooldown(0)
+	high := MemoryStats{Alloc: 90, Sys: 100}
+	low := MemoryStats{Alloc: 10, Sys: 100}
+	now := time.Now()
+
+	assert.True(t, monitor.shouldAlert(high, now))
+	assert.False(t, monitor.shouldAlert(low, now))
+	assert.True(t, monitor.shouldAlert(high, now))
+}
+
+func TestMemoryMonitor_StartMonitoringUpdatesLastSample(t *testing.T) {
+	monitor := NewMemoryMonitor(0)
+	_, at := monitor.LastSample()
+	assert.True(t, at.IsZero())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		monitor.StartMonitoring(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		_, at := monitor.LastSample()
+		return !at.IsZero()
+	}, time.Second, time.Millisecond)
+	stats, first := monitor.LastSample()
+	assert.NotZero(t, stats.Sys)
+
+	require.Eventually(t, func() bool {
+		_, at := monitor.LastSample()
+		return at.After(first)
+	}, time.Second, time.Millisecond)
+	assert.False(t, monitor.SampleStale(time.Now()))
+
+	cancel()
+	<-done
+	_, last := monitor.LastSample()
+	assert.True(t, monitor.SampleStale(last.Add(time.Second)))
+}
+
+func TestMemoryMonitor_SampleStaleBeforeMonitoring(t *testing.T) {
+	assert.False(t, NewMemoryMonitor(0).SampleStale(time.Now()))
+}
+
+func TestMemoryHealthCheckHandler_ReportsStaleSampling(t *testing.T) {
+	monitor := NewMemoryMonitor(0)
+	monitor.interval = time.Second
+	monitor.lastSampleAt = time.Now().Add(-time.Minute)
+
+	app := fiber.New()
+	app.Get("/health/memory", MemoryHealthCheckHandler(monitor))
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/health/memory", nil))
+	require.NoError(t, err)
+
+	var body struct {
+		Status   string `json:"status"`
+		Sampling struct {
+			LastSampleAt *time.Time `json:"lastSampleAt"`
+			Stale        bool       `json:"stale"`
+		} `json:"sampling"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	assert.Equal(t, "degraded", body.Status)
+	assert.True(t, body.Sampling.Stale)
+	require.NotNil(t, body.Sampling.LastSampleAt)
+}
+
+func TestMemoryMonitor_Check(t *testing.T) {
+	monitor := NewMemoryMonitor(0.8)
+
+	status, err := monitor.Check(context.Background())
+	assert.Equal(t, health.StatusHealthy, status, "a monitor that never started is not stale")
+	assert.NoError(t, err)
+
+	monitor.mu.Lock()
+	monitor.interval = time.Second
+	monitor.lastSampleAt = time.Now().Add(-time.Minute)
+	monitor.mu.Unlock()
+
+	status, err = monitor.Check(context.Background())
+	assert.Equal(t, health.StatusDegraded, status)
+	assert.ErrorContains(t, err, "has not been sampled since")
+}
+
+func TestMemoryStats_JSONLargeUintsAsStrings(t *testing.T) {
+	previous := jsonnum.LargeUintsAsStrings
+	jsonnum.LargeUintsAsStrings = true
+	t.Cleanup(func() { jsonnum.LargeUintsAsStrings = previous })
+
+	stats := MemoryStats{Alloc: 1024, TotalAlloc: 1<<53 + 1, Sys: 1<<60 + 3, NumGC: 7, NumGoroutine: 4}
+
+	data, err := json.Marshal(stats)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, float64(1024), fields["alloc"], "safe integers stay numbers")
+	assert.Equal(t, "9007199254740993", fields["totalAlloc"])
+	assert.Equal(t, "1152921504606846979", fields["sys"])
+	assert.Equal(t, float64(7), fields["numGC"])
+
+	var decoded MemoryStats
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, stats, decoded)
+}
@@ -2,45 +2,172 @@ package monitoring
 
 import (
 	"fmt"
+	"math/rand/v2"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
 )
 
-// MemoryMiddleware tracks memory usage for each request
-func MemoryMiddleware(monitor *MemoryMonitor) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		// Get memory stats before request
-		before := monitor.GetMemoryStats()
+// memoryMiddlewareConfig holds the optional MemoryMiddleware settings
+type memoryMiddlewareConfig struct {
+	sampleRate    float64
+	random        func() float64
+	exposeHeaders bool
+	legacyHeaders bool
+	observer      func(RequestMeasurement)
+}
+
+// sample decides whether to measure memory for the next request. Reading
+// memory stats briefly stops the world, so it is skipped when neither the
+// headers nor an observer would report the result.
+func (cfg memoryMiddlewareConfig) sample() bool {
+	if !cfg.exposeHeaders && cfg.observer == nil {
+		return false
+	}
+	return cfg.sampleRate >= 1 || cfg.random() < cfg.sampleRate
+}
+
+// MemoryMiddlewareOption configures MemoryMiddleware
+type MemoryMiddlewareOption func(*memoryMiddlewareConfig)
+
+// WithSampleRate sets the fraction of requests, between 0 and 1, whose memory
+// usage is measured. Reading memory stats briefly stops the world, so busy
+// services can measure a sample instead of every request.
+func WithSampleRate(rate float64) MemoryMiddlewareOption {
+	return func(cfg *memoryMiddlewareConfig) {
+		cfg.sampleRate = rate
+	}
+}
+
+// WithExposeHeaders sets whether memory and goroutine measurements are
+// reported to clients in response headers. Without them Server-Timing only
+// carries the request duration, and the measurements only reach the observer.
+func WithExposeHeaders(enabled bool) MemoryMiddlewareOption {
+	return func(cfg *memoryMiddlewareConfig) {
+		cfg.exposeHeaders = enabled
+	}
+}
+
+// WithObserver sets a function called with every request's measurement,
+// whether or not it is exposed in headers, for example to feed metrics
+func WithObserver(observer func(RequestMeasurement)) MemoryMiddlewareOption {
+	return func(cfg *memoryMiddlewareConfig) {
+		cfg.observer = observer
+	}
+}
+
+// WithLegacyHeaders sets whether the X-Memory-*, X-Request-Duration,
+// X-Num-Goroutines and X-Goroutines-* headers are set next to Server-Timing
+// when headers are exposed
+func WithLegacyHeaders(enabled bool) MemoryMiddlewareOption {
+	return func(cfg *memoryMiddlewareConfig) {
+		cfg.legacyHeaders = enabled
+	}
+}
 
-		// Record start time
+// HeaderServerTiming is the standard header browsers and APM tools read request timings from
+const HeaderServerTiming = "Server-Timing"
+
+// MemoryMiddleware measures the duration, goroutine count and, for each
+// sampled request, by default all of them, the memory usage of every request.
+// The measurements are reported together in a Server-Timing header and, by
+// default, in the legacy custom headers; WithExposeHeaders(false) keeps them
+// out of responses, and without an observer memory is then not measured.
+func MemoryMiddleware(monitor *MemoryMonitor, opts ...MemoryMiddlewareOption) fiber.Handler {
+	cfg := memoryMiddlewareConfig{sampleRate: 1, random: rand.Float64, exposeHeaders: true, legacyHeaders: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(c *fiber.Ctx) error {
+		// Skip the stats reads entirely for unsampled requests
+		m := RequestMeasurement{Sampled: cfg.sample()}
+		if m.Sampled {
+			m.AllocBefore = monitor.GetMemoryStats().Alloc
+		}
+		m.GoroutinesBefore = runtime.NumGoroutine()
 		start := time.Now()
 
-		// Process request
 		err := c.Next()
 
-		// Calculate duration
-		duration := time.Since(start)
+		m.Duration = time.Since(start)
+		m.GoroutinesAfter = runtime.NumGoroutine()
+		if m.Sampled {
+			m.AllocAfter = monitor.GetMemoryStats().Alloc
+		}
 
-		// Get memory stats after request
-		after := monitor.GetMemoryStats()
+		if cfg.observer != nil {
+			cfg.observer(m)
+		}
 
-		// Calculate memory difference
-		memoryDiff := int64(after.Alloc) - int64(before.Alloc)
+		header := &c.Response().Header
+		if !cfg.exposeHeaders {
+			header.Add(HeaderServerTiming, m.durationTiming())
+			return err
+		}
+		header.Add(HeaderServerTiming, m.serverTiming())
+		if cfg.legacyHeaders {
+			m.setLegacyHeaders(header)
+		}
+		return err
+	}
+}
 
-		// Add memory usage info to response headers (optional)
-		c.Response().Header.Set("X-Memory-Before", FormatBytes(before.Alloc))
-		c.Response().Header.Set("X-Memory-After", FormatBytes(after.Alloc))
-		c.Response().Header.Set("X-Memory-Diff", fmt.Sprintf("%+d", memoryDiff))
-		c.Response().Header.Set("X-Request-Duration", duration.String())
-		c.Response().Header.Set("X-Num-Goroutines", fmt.Sprintf("%d", after.NumGoroutine))
+// RequestMeasurement is what MemoryMiddleware measured for one request. The
+// allocation fields are only set when the request was sampled.
+type RequestMeasurement struct {
+	Sampled          bool
+	Duration         time.Duration
+	AllocBefore      uint64
+	AllocAfter       uint64
+	GoroutinesBefore int
+	GoroutinesAfter  int
+}
 
-		return err
+// serverTiming formats the measurement as Server-Timing metrics: the request
+// duration in milliseconds, the change in allocated memory when sampled, and
+// the goroutine count before and after
+func (m RequestMeasurement) serverTiming() string {
+	var b strings.Builder
+	b.WriteString(m.durationTiming())
+	if m.Sampled {
+		fmt.Fprintf(&b, `, mem;desc="%s -> %s (%+d B)"`, FormatBytes(m.AllocBefore), FormatBytes(m.AllocAfter), m.AllocDiff())
+	}
+	fmt.Fprintf(&b, `, goroutines;desc="%d -> %d"`, m.GoroutinesBefore, m.GoroutinesAfter)
+	return b.String()
+}
+
+// durationTiming formats the request duration as a Server-Timing metric in milliseconds
+func (m RequestMeasurement) durationTiming() string {
+	return fmt.Sprintf("app;dur=%.3f", float64(m.Duration.Microseconds())/1000)
+}
+
+// setLegacyHeaders sets the custom headers that predate Server-Timing
+func (m RequestMeasurement) setLegacyHeaders(header *fasthttp.ResponseHeader) {
+	if m.Sampled {
+		header.Set("X-Memory-Before", FormatBytes(m.AllocBefore))
+		header.Set("X-Memory-After", FormatBytes(m.AllocAfter))
+		header.Set("X-Memory-Diff", fmt.Sprintf("%+d", m.AllocDiff()))
+		header.Set("X-Request-Duration", m.Duration.String())
+		header.Set("X-Num-Goroutines", strconv.Itoa(m.GoroutinesAfter))
 	}
+	header.Set("X-Goroutines-Before", strconv.Itoa(m.GoroutinesBefore))
+	header.Set("X-Goroutines-After", strconv.Itoa(m.GoroutinesAfter))
+	header.Set("X-Goroutines-Diff", strconv.Itoa(m.GoroutinesAfter-m.GoroutinesBefore))
+}
+
+// AllocDiff is the change in allocated memory over the request, in bytes
+func (m RequestMeasurement) AllocDiff() int64 {
+	return int64(m.AllocAfter) - int64(m.AllocBefore)
 }
 
 // SimpleGoroutineMiddleware tracks goroutine count changes
+//
+// Deprecated: MemoryMiddleware reports goroutine counts itself.
 func SimpleGoroutineMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Record goroutine count before request
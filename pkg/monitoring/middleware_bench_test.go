@@ -0,0 +1,44 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// benchmarkHandler measures a no-op route behind the given middleware,
+// calling the app handler directly so only routing and middleware are timed
+func benchmarkHandler(b *testing.B, middleware ...fiber.Handler) {
+	app := fiber.New()
+	for _, m := range middleware {
+		app.Use(m)
+	}
+	app.Get("/", func(c *fiber.Ctx) error { return nil })
+	handler := app.Handler()
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("/")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler(&ctx)
+	}
+}
+
+func BenchmarkNoMiddleware(b *testing.B) {
+	benchmarkHandler(b)
+}
+
+func BenchmarkMemoryMiddleware(b *testing.B) {
+	benchmarkHandler(b, MemoryMiddleware(NewMemoryMonitor(0)))
+}
+
+func BenchmarkSimpleGoroutineMiddleware(b *testing.B) {
+	benchmarkHandler(b, SimpleGoroutineMiddleware())
+}
+
+func BenchmarkAllMonitoringMiddleware(b *testing.B) {
+	benchmarkHandler(b, MemoryMiddleware(NewMemoryMonitor(0)), SimpleGoroutineMiddleware())
+}
@@ -0,0 +1,164 @@
+package monitoring
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestMemoryMiddleware_SetsHeaders(t *testing.T) {
+	app := fiber.New()
+	app.Use(MemoryMiddleware(NewMemoryMonitor(0)))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+	require.NoError(t, err)
+
+	for _, header := range []string{"X-Memory-Before", "X-Memory-After", "X-Memory-Diff", "X-Request-Duration", "X-Num-Goroutines"} {
+		assert.NotEmpty(t, resp.Header.Get(header), header)
+	}
+}
+
+// legacyHeaders are the custom headers that predate Server-Timing
+var legacyHeaders = []string{"X-Memory-Before", "X-Memory-After", "X-Memory-Diff", "X-Request-Duration",
+	"X-Num-Goroutines", "X-Goroutines-Before", "X-Goroutines-After", "X-Goroutines-Diff"}
+
+func TestMemoryMiddleware_ServerTiming(t *testing.T) {
+	serverTiming := regexp.MustCompile(`^app;dur=\d+\.\d{3}, mem;desc="\d+(\.\d)? [KMGTPE]?B -> \d+(\.\d)? [KMGTPE]?B \([+-]\d+ B\)", goroutines;desc="\d+ -> \d+"$`)
+	unsampled := regexp.MustCompile(`^app;dur=\d+\.\d{3}, goroutines;desc="\d+ -> \d+"$`)
+
+	tests := []struct {
+		name    string
+		opts    []MemoryMiddlewareOption
+		pattern *regexp.Regexp
+		legacy  []string
+	}{
+		{"sampled", nil, serverTiming, legacyHeaders},
+		{"unsampled", []MemoryMiddlewareOption{WithSampleRate(0)}, unsampled, []string{"X-Goroutines-Before", "X-Goroutines-After", "X-Goroutines-Diff"}},
+		{"without legacy headers", []MemoryMiddlewareOption{WithLegacyHeaders(false)}, serverTiming, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(MemoryMiddleware(NewMemoryMonitor(0), tt.opts...))
+			app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			require.NoError(t, err)
+
+			assert.Regexp(t, tt.pattern, resp.Header.Get(HeaderServerTiming))
+			for _, header := range legacyHeaders {
+				assert.Equal(t, slices.Contains(tt.legacy, header), resp.Header.Get(header) != "", header)
+			}
+		})
+	}
+}
+
+func TestMemoryMiddleware_ExposeHeaders(t *testing.T) {
+	for _, expose := range []bool{false, true} {
+		t.Run(fmt.Sprint(expose), func(t *testing.T) {
+			var measured []RequestMeasurement
+			app := fiber.New()
+			app.Use(MemoryMiddleware(NewMemoryMonitor(0),
+				WithExposeHeaders(expose),
+				WithObserver(func(m RequestMeasurement) { measured = append(measured, m) })))
+			app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			require.NoError(t, err)
+
+			for _, header := range legacyHeaders {
+				assert.Equal(t, expose, resp.Header.Get(header) != "", header)
+			}
+			assert.Equal(t, expose, strings.Contains(resp.Header.Get(HeaderServerTiming), "mem;"))
+			assert.Regexp(t, `^app;dur=\d+\.\d{3}`, resp.Header.Get(HeaderServerTiming))
+
+			// The measurement is taken either way
+			require.Len(t, measured, 1)
+			assert.True(t, measured[0].Sampled)
+			assert.NotZero(t, measured[0].AllocAfter)
+			assert.NotZero(t, measured[0].GoroutinesAfter)
+		})
+	}
+}
+
+func TestMemoryMiddlewareConfig_SampleOnlyWhenReported(t *testing.T) {
+	never := func() float64 { t.Fatal("sampling consulted the random source"); return 0 }
+
+	unreported := memoryMiddlewareConfig{sampleRate: 1, random: never}
+	assert.False(t, unreported.sample(), "nothing would see the measurement")
+
+	headers := memoryMiddlewareConfig{sampleRate: 1, random: never, exposeHeaders: true}
+	assert.True(t, headers.sample())
+
+	observed := memoryMiddlewareConfig{sampleRate: 1, random: never, observer: func(RequestMeasurement) {}}
+	assert.True(t, observed.sample())
+}
+
+func TestRequestMeasurement_ServerTiming(t *testing.T) {
+	m := RequestMeasurement{
+		Sampled:          true,
+		Duration:         1234567 * time.Nanosecond,
+		AllocBefore:      2048,
+		AllocAfter:       1024,
+		GoroutinesBefore: 12,
+		GoroutinesAfter:  13,
+	}
+	assert.Equal(t, `app;dur=1.234, mem;desc="2.0 KB -> 1.0 KB (-1024 B)", goroutines;desc="12 -> 13"`, m.serverTiming())
+
+	m.Sampled = false
+	assert.Equal(t, `app;dur=1.234, goroutines;desc="12 -> 13"`, m.serverTiming())
+}
+
+func TestMemoryMiddleware_SampleRate(t *testing.T) {
+	const requests = 4000
+
+	tests := []struct {
+		rate     float64
+		min, max int
+	}{
+		{0, 0, 0},
+		{0.25, 850, 1150},
+		{1, requests, requests},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.rate), func(t *testing.T) {
+			app := fiber.New()
+			app.Use(MemoryMiddleware(NewMemoryMonitor(0), WithSampleRate(tt.rate)))
+			app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+			handler := app.Handler()
+
+			sampled := 0
+			for i := 0; i < requests; i++ {
+				var ctx fasthttp.RequestCtx
+				ctx.Request.SetRequestURI("/")
+				handler(&ctx)
+				if len(ctx.Response.Header.Peek("X-Memory-Before")) > 0 {
+					sampled++
+				}
+			}
+
+			assert.GreaterOrEqual(t, sampled, tt.min)
+			assert.LessOrEqual(t, sampled, tt.max)
+		})
+	}
+}
+
+func BenchmarkMemoryMiddleware_SampleRate(b *testing.B) {
+	for _, rate := range []float64{1, 0.1, 0.01} {
+		b.Run(fmt.Sprint(rate), func(b *testing.B) {
+			benchmarkHandler(b, MemoryMiddleware(NewMemoryMonitor(0), WithSampleRate(rate)))
+		})
+	}
+}
@@ -7,10 +7,15 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/adaptor"
 )
 
-// RegisterPprofRoutes registers pprof routes with the Fiber application
-func RegisterPprofRoutes(app *fiber.App) {
+// PprofIndexPath is the pprof index page. Its links are relative, so the
+// trailing slash is part of the path.
+const PprofIndexPath = "/debug/pprof/"
+
+// RegisterPprofRoutes registers pprof routes with the Fiber application,
+// running guards (e.g. authentication) before every pprof handler
+func RegisterPprofRoutes(app fiber.Router, guards ...fiber.Handler) {
 	// Create a new group for pprof endpoints
-	pprofGroup := app.Group("/debug/pprof")
+	pprofGroup := app.Group("/debug/pprof", guards...)
 
 	// Register pprof handlers
 	pprofGroup.Get("/", adaptor.HTTPHandlerFunc(pprof.Index))
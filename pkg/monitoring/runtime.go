@@ -0,0 +1,27 @@
+package monitoring
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RuntimeInfoHandler returns a Fiber handler reporting Go runtime information
+// and the process uptime since startTime
+func RuntimeInfoHandler(monitor *MemoryMonitor, startTime time.Time) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		uptime := time.Since(startTime)
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"goVersion":     runtime.Version(),
+			"goMaxProcs":    runtime.GOMAXPROCS(0),
+			"numCPU":        runtime.NumCPU(),
+			"numGoroutine":  runtime.NumGoroutine(),
+			"memory":        monitor.GetMemoryStats(),
+			"startTime":     startTime.UTC(),
+			"uptime":        uptime.Round(time.Second).String(),
+			"uptimeSeconds": int64(uptime.Seconds()),
+		})
+	}
+}
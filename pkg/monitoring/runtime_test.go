@@ -0,0 +1,37 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeInfoHandler(t *testing.T) {
+	app := fiber.New()
+	app.Get("/debug/runtime", RuntimeInfoHandler(NewMemoryMonitor(0), time.Now().Add(-90*time.Second)))
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/debug/runtime", nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+	for _, key := range []string{"goVersion", "goMaxProcs", "numCPU", "numGoroutine", "memory", "startTime", "uptime", "uptimeSeconds"} {
+		assert.Contains(t, body, key)
+	}
+	assert.Equal(t, runtime.Version(), body["goVersion"])
+	assert.Equal(t, "1m30s", body["uptime"])
+
+	memory, ok := body["memory"].(map[string]interface{})
+	require.True(t, ok)
+	for _, key := range []string{"alloc", "totalAlloc", "sys", "numGC", "gcCPUFraction", "numGoroutine"} {
+		assert.Contains(t, memory, key)
+	}
+}
@@ -0,0 +1,33 @@
+package monitoring
+
+import (
+	"runtime"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxStackDumpSize caps the stack dump buffer so a runaway goroutine count
+// cannot exhaust memory; a larger dump is truncated
+const maxStackDumpSize = 64 << 20
+
+// StacksHandler returns a Fiber handler that dumps the stacks of all
+// goroutines as plain text, in the format of an unrecovered panic
+func StacksHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.Send(stackDump())
+	}
+}
+
+// stackDump returns the stacks of all goroutines, growing the buffer until
+// the dump fits or reaches maxStackDumpSize
+func stackDump() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxStackDumpSize {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,39 @@
+package monitoring
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStacksHandler(t *testing.T) {
+	// Park a goroutine in a known function so its frame shows up in the dump
+	release := make(chan struct{})
+	parked := make(chan struct{})
+	go parkForStackDump(parked, release)
+	<-parked
+	defer close(release)
+
+	app := fiber.New()
+	app.Get("/debug/stacks", StacksHandler())
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/debug/stacks", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, fiber.MIMETextPlainCharsetUTF8, resp.Header.Get(fiber.HeaderContentType))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "goroutine 1 [")
+	assert.Contains(t, string(body), "monitoring.parkForStackDump(")
+	assert.Contains(t, string(body), "stacks_test.go:")
+}
+
+func parkForStackDump(parked chan<- struct{}, release <-chan struct{}) {
+	close(parked)
+	<-release
+}
@@ -0,0 +1,77 @@
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ThresholdMode selects how an alert threshold is compared with memory statistics
+type ThresholdMode string
+
+const (
+	// ThresholdRatio alerts when Alloc exceeds Value times Sys
+	ThresholdRatio ThresholdMode = "ratio"
+	// ThresholdBytes alerts when Alloc exceeds Value bytes
+	ThresholdBytes ThresholdMode = "bytes"
+)
+
+// AlertThreshold is the memory usage above which the monitor alerts. A zero
+// Value disables alerting.
+type AlertThreshold struct {
+	Mode  ThresholdMode
+	Value float64
+}
+
+// RatioThreshold alerts when Alloc exceeds ratio times Sys
+func RatioThreshold(ratio float64) AlertThreshold {
+	return AlertThreshold{Mode: ThresholdRatio, Value: ratio}
+}
+
+// BytesThreshold alerts when Alloc exceeds bytes
+func BytesThreshold(bytes uint64) AlertThreshold {
+	return AlertThreshold{Mode: ThresholdBytes, Value: float64(bytes)}
+}
+
+// Validate reports whether the threshold can be used. Ratios lie between 0
+// and 1, since Alloc never exceeds Sys, and byte counts are whole numbers.
+func (t AlertThreshold) Validate() error {
+	if t.Mode != ThresholdRatio && t.Mode != ThresholdBytes {
+		return fmt.Errorf(`threshold mode must be "ratio" or "bytes", got %q`, t.Mode)
+	}
+	if math.IsNaN(t.Value) {
+		return errors.New("threshold is not set")
+	}
+	if math.IsInf(t.Value, 0) || t.Value < 0 {
+		return fmt.Errorf("threshold must be a non-negative number, got %v", t.Value)
+	}
+	if t.Mode == ThresholdRatio && t.Value > 1 {
+		return fmt.Errorf("ratio threshold must be between 0 and 1, got %v", t.Value)
+	}
+	if t.Mode == ThresholdBytes && t.Value != math.Trunc(t.Value) {
+		return fmt.Errorf("bytes threshold must be a whole number of bytes, got %v", t.Value)
+	}
+	return nil
+}
+
+// Exceeded reports whether stats are above the threshold
+func (t AlertThreshold) Exceeded(stats MemoryStats) bool {
+	if t.Value <= 0 {
+		return false
+	}
+	if t.Mode == ThresholdBytes {
+		return float64(stats.Alloc) > t.Value
+	}
+	return float64(stats.Alloc) > t.Value*float64(stats.Sys)
+}
+
+// String describes the threshold for logs
+func (t AlertThreshold) String() string {
+	if t.Value <= 0 {
+		return "disabled"
+	}
+	if t.Mode == ThresholdBytes {
+		return "Alloc > " + FormatBytes(uint64(t.Value))
+	}
+	return fmt.Sprintf("Alloc > %g%% of Sys", t.Value*100)
+}
@@ -0,0 +1,79 @@
+package monitoring
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertThreshold_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold AlertThreshold
+		err       string
+	}{
+		{"ratio", RatioThreshold(0.8), ""},
+		{"ratio disabled", RatioThreshold(0), ""},
+		{"ratio of one", RatioThreshold(1), ""},
+		{"ratio above one", RatioThreshold(1.5), "between 0 and 1"},
+		{"bytes", BytesThreshold(512 << 20), ""},
+		{"fractional bytes", AlertThreshold{Mode: ThresholdBytes, Value: 0.8}, "whole number"},
+		{"negative", AlertThreshold{Mode: ThresholdBytes, Value: -1}, "non-negative"},
+		{"infinite", AlertThreshold{Mode: ThresholdBytes, Value: math.Inf(1)}, "non-negative"},
+		{"unset", AlertThreshold{Mode: ThresholdBytes, Value: math.NaN()}, "not set"},
+		{"unknown mode", AlertThreshold{Mode: "percent", Value: 80}, `"ratio" or "bytes"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.threshold.Validate()
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestAlertThreshold_Exceeded(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold AlertThreshold
+		stats     MemoryStats
+		exceeded  bool
+	}{
+		{"ratio above", RatioThreshold(0.8), MemoryStats{Alloc: 90, Sys: 100}, true},
+		{"ratio at", RatioThreshold(0.8), MemoryStats{Alloc: 80, Sys: 100}, false},
+		{"ratio below", RatioThreshold(0.8), MemoryStats{Alloc: 10, Sys: 100}, false},
+		{"ratio disabled", RatioThreshold(0), MemoryStats{Alloc: 100, Sys: 100}, false},
+		// A small heap on a large Sys never crosses a ratio, only an absolute limit
+		{"bytes above", BytesThreshold(1 << 20), MemoryStats{Alloc: 2 << 20, Sys: 1 << 30}, true},
+		{"bytes at", BytesThreshold(1 << 20), MemoryStats{Alloc: 1 << 20, Sys: 1 << 30}, false},
+		{"bytes below", BytesThreshold(1 << 20), MemoryStats{Alloc: 1 << 19, Sys: 1 << 19}, false},
+		{"bytes disabled", BytesThreshold(0), MemoryStats{Alloc: 1 << 30, Sys: 1 << 30}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.exceeded, tt.threshold.Exceeded(tt.stats))
+		})
+	}
+}
+
+func TestAlertThreshold_String(t *testing.T) {
+	assert.Equal(t, "Alloc > 80% of Sys", RatioThreshold(0.8).String())
+	assert.Equal(t, "Alloc > 512.0 MB", BytesThreshold(512<<20).String())
+	assert.Equal(t, "disabled", BytesThreshold(0).String())
+}
+
+func TestMemoryMonitor_BytesThresholdAlerts(t *testing.T) {
+	monitor := NewMemoryMonitor(0.8)
+	monitor.SetAlertThreshold(BytesThreshold(1 << 20))
+	now := time.Now()
+
+	assert.False(t, monitor.shouldAlert(MemoryStats{Alloc: 1 << 19, Sys: 1 << 19}, now))
+	assert.True(t, monitor.shouldAlert(MemoryStats{Alloc: 2 << 20, Sys: 1 << 30}, now))
+}
@@ -0,0 +1,135 @@
+package monitoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// webhookAlert is the payload posted for a memory alert. The text field makes
+// it usable as a Slack incoming webhook message. The stats are embedded by
+// their JSON fields, since embedding MemoryStats would promote its
+// MarshalJSON and drop the text.
+type webhookAlert struct {
+	Text string `json:"text"`
+	memoryStatsJSON
+}
+
+// webhookAlertSender posts memory alerts to a webhook URL
+type webhookAlertSender struct {
+	url         string
+	client      *http.Client
+	minInterval time.Duration
+	attempts    int
+	retryDelay  time.Duration
+
+	mu       sync.Mutex
+	lastSent time.Time
+}
+
+// WebhookOption configures a webhook alert handler
+type WebhookOption func(*webhookAlertSender)
+
+// WithWebhookClient sets the HTTP client used to post alerts
+func WithWebhookClient(client *http.Client) WebhookOption {
+	return func(s *webhookAlertSender) {
+		s.client = client
+	}
+}
+
+// WithWebhookMinInterval sets the minimum time between two posted alerts
+func WithWebhookMinInterval(interval time.Duration) WebhookOption {
+	return func(s *webhookAlertSender) {
+		s.minInterval = interval
+	}
+}
+
+// WithWebhookRetry sets how many times an alert is attempted and the delay
+// before the first retry, which doubles on each further retry
+func WithWebhookRetry(attempts int, delay time.Duration) WebhookOption {
+	return func(s *webhookAlertSender) {
+		s.attempts = attempts
+		s.retryDelay = delay
+	}
+}
+
+// WebhookAlertHandler returns an alert handler that POSTs the memory stats as
+// JSON to url. Alerts arriving within the minimum interval of the last posted
+// one are dropped, and posting happens in the background so the caller is
+// never blocked by a slow webhook.
+func WebhookAlertHandler(url string, opts ...WebhookOption) func(MemoryStats) {
+	s := &webhookAlertSender{
+		url:         url,
+		client:      &http.Client{Timeout: 5 * time.Second},
+		minInterval: 5 * time.Minute,
+		attempts:    3,
+		retryDelay:  time.Second,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return func(stats MemoryStats) {
+		if !s.allow(time.Now()) {
+			return
+		}
+		go func() {
+			if err := s.send(stats); err != nil {
+				log.Printf("ERROR: Failed to send memory alert webhook: %v", err)
+			}
+		}()
+	}
+}
+
+// allow reports whether an alert may be posted now and records it if so
+func (s *webhookAlertSender) allow(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.lastSent.IsZero() && now.Sub(s.lastSent) < s.minInterval {
+		return false
+	}
+	s.lastSent = now
+	return true
+}
+
+// send posts the alert, retrying on network errors and 5xx or 429 responses
+func (s *webhookAlertSender) send(stats MemoryStats) error {
+	body, err := json.Marshal(webhookAlert{
+		Text: fmt.Sprintf("High memory usage detected - Alloc: %s, Sys: %s",
+			FormatBytes(stats.Alloc), FormatBytes(stats.Sys)),
+		memoryStatsJSON: stats.jsonFields(),
+	})
+	if err != nil {
+		return err
+	}
+
+	delay := s.retryDelay
+	for attempt := 1; ; attempt++ {
+		retry, err := s.post(body)
+		if err == nil || !retry || attempt >= s.attempts {
+			return err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// post sends a single request and reports whether a failure is worth retrying
+func (s *webhookAlertSender) post(body []byte) (bool, error) {
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+}
@@ -0,0 +1,86 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookAlertHandler_PostsStats(t *testing.T) {
+	received := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var payload map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		received <- payload
+	}))
+	defer server.Close()
+
+	handler := WebhookAlertHandler(server.URL)
+	handler(MemoryStats{Alloc: 900, Sys: 1000, NumGC: 7, NumGoroutine: 12})
+
+	select {
+	case payload := <-received:
+		assert.Equal(t, float64(900), payload["alloc"])
+		assert.Equal(t, float64(1000), payload["sys"])
+		assert.Equal(t, float64(7), payload["numGC"])
+		assert.Equal(t, float64(12), payload["numGoroutine"])
+		assert.Contains(t, payload["text"], "High memory usage detected")
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+}
+
+func TestWebhookAlertHandler_RateLimits(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	handler := WebhookAlertHandler(server.URL, WithWebhookMinInterval(time.Hour))
+	for i := 0; i < 10; i++ {
+		handler(MemoryStats{Alloc: 900, Sys: 1000})
+	}
+
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestWebhookAlertHandler_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	handler := WebhookAlertHandler(server.URL, WithWebhookRetry(3, time.Millisecond))
+	handler(MemoryStats{Alloc: 900, Sys: 1000})
+
+	require.Eventually(t, func() bool { return calls.Load() == 3 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestWebhookAlertSender_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sender := &webhookAlertSender{url: server.URL, client: server.Client(), attempts: 3, retryDelay: time.Millisecond}
+
+	assert.Error(t, sender.send(MemoryStats{}))
+	assert.Equal(t, int32(1), calls.Load())
+}
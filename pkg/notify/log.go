@@ -0,0 +1,24 @@
+// Package notify delivers messages to users
+package notify
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogNotifier writes messages to a structured logger instead of delivering
+// them, for development and deployments without an email provider
+type LogNotifier struct {
+	logger *slog.Logger
+}
+
+// NewLogNotifier creates a notifier that logs every message at info level
+func NewLogNotifier(logger *slog.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+// Notify logs the message with its recipient and subject
+func (n *LogNotifier) Notify(ctx context.Context, to, subject, body string) error {
+	n.logger.InfoContext(ctx, "notification", "to", to, "subject", subject, "body", body)
+	return nil
+}
@@ -0,0 +1,70 @@
+// Package promtext writes metrics in the Prometheus text exposition format,
+// for tools that scrape or import samples recorded elsewhere
+package promtext
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContentType is the media type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Sample is a gauge value observed at a point in time
+type Sample struct {
+	Value     float64
+	Timestamp time.Time
+}
+
+// Gauge is a metric with no labels and its samples, oldest first
+type Gauge struct {
+	Name    string
+	Help    string
+	Samples []Sample
+}
+
+// Write writes each gauge's HELP and TYPE lines followed by one line per
+// sample, timestamped in milliseconds since the Unix epoch. Gauges without
+// samples are skipped.
+func Write(w io.Writer, gauges []Gauge) error {
+	bw := bufio.NewWriter(w)
+	for _, gauge := range gauges {
+		if len(gauge.Samples) == 0 {
+			continue
+		}
+		bw.WriteString("# HELP " + gauge.Name + " " + escapeHelp(gauge.Help) + "\n")
+		bw.WriteString("# TYPE " + gauge.Name + " gauge\n")
+		for _, sample := range gauge.Samples {
+			bw.WriteString(gauge.Name + " " + formatValue(sample.Value) + " " +
+				strconv.FormatInt(sample.Timestamp.UnixMilli(), 10) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// formatValue formats a sample value, spelling out the special values the
+// way Prometheus parses them
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// helpEscaper escapes the characters HELP text may not contain literally
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// escapeHelp escapes backslashes and line feeds in HELP text
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
@@ -0,0 +1,39 @@
+package promtext
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	at := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	gauges := []Gauge{
+		{Name: "heap_bytes", Help: "Heap in use.\nSampled by the app.", Samples: []Sample{
+			{Value: 1048576, Timestamp: at},
+			{Value: 2.5e9, Timestamp: at.Add(time.Minute)},
+		}},
+		{Name: "empty", Help: "Skipped without samples."},
+		{Name: "ratio", Help: `Share of CPU, as a C:\ path`, Samples: []Sample{
+			{Value: math.NaN(), Timestamp: at},
+			{Value: math.Inf(1), Timestamp: at},
+		}},
+	}
+	var buf bytes.Buffer
+
+	require.NoError(t, Write(&buf, gauges))
+
+	assert.Equal(t, `# HELP heap_bytes Heap in use.\nSampled by the app.
+# TYPE heap_bytes gauge
+heap_bytes 1.048576e+06 1710504000000
+heap_bytes 2.5e+09 1710504060000
+# HELP ratio Share of CPU, as a C:\\ path
+# TYPE ratio gauge
+ratio NaN 1710504000000
+ratio +Inf 1710504000000
+`, buf.String())
+}
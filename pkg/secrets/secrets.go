@@ -0,0 +1,59 @@
+// Package secrets reads secret settings such as connection strings, from the
+// files Docker and Kubernetes mount secrets as or from the environment
+package secrets
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/example/go-clean-architecture/pkg/logging"
+)
+
+// Env is a secret setting read from the file named by KEY_FILE when set, so
+// the secret need not sit in the environment, and from KEY otherwise
+type Env struct {
+	Key   string
+	File  string // the KEY_FILE path; empty when the value came from KEY
+	Value string
+	Err   error // reading the file failed
+}
+
+// FromEnv reads the secret setting key from the file named by key_FILE,
+// falling back to the key environment variable. Surrounding whitespace, such as
+// the trailing newline most editors add, is trimmed from file contents.
+func FromEnv(key string) Env {
+	secret := Env{Key: key, File: os.Getenv(key + "_FILE")}
+	if secret.File == "" {
+		secret.Value = os.Getenv(key)
+		return secret
+	}
+	data, err := os.ReadFile(secret.File)
+	if err != nil {
+		secret.Err = err
+		return secret
+	}
+	secret.Value = strings.TrimSpace(string(data))
+	return secret
+}
+
+// Validate reports whether the secrets file could be read
+func (s Env) Validate() error {
+	if s.Err != nil {
+		return fmt.Errorf("invalid %s_FILE: %w", s.Key, s.Err)
+	}
+	return nil
+}
+
+// LogValue names where the secret came from without revealing it
+func (s Env) LogValue() slog.Value {
+	switch {
+	case s.File != "":
+		return slog.StringValue(s.Key + "_FILE=" + s.File)
+	case s.Value != "":
+		return slog.StringValue(logging.Redacted)
+	default:
+		return slog.StringValue("")
+	}
+}
@@ -0,0 +1,135 @@
+// Package static serves files from an embedded filesystem
+package static
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// IndexFile is served for directory paths and, with WithSPAFallback, for
+// paths that match no file
+const IndexFile = "index.html"
+
+// DefaultMaxAge is how long clients may cache files other than IndexFile
+const DefaultMaxAge = time.Hour
+
+// config holds the optional Handler settings
+type config struct {
+	maxAge      time.Duration
+	spaFallback bool
+}
+
+// Option configures Handler and Register
+type Option func(*config)
+
+// WithMaxAge sets how long clients may cache files other than IndexFile.
+// Zero makes clients revalidate every file.
+func WithMaxAge(maxAge time.Duration) Option {
+	return func(cfg *config) {
+		cfg.maxAge = maxAge
+	}
+}
+
+// WithSPAFallback serves the root IndexFile for paths without a file
+// extension that match no file, so a single-page app can route them
+// client-side. Missing files with an extension, such as assets, stay 404.
+func WithSPAFallback() Option {
+	return func(cfg *config) {
+		cfg.spaFallback = true
+	}
+}
+
+// Register serves fsys under prefix on router, e.g. "/admin"
+func Register(router fiber.Router, prefix string, fsys fs.FS, opts ...Option) {
+	handler := Handler(fsys, opts...)
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix != "" {
+		router.Get(prefix, handler)
+	}
+	router.Get(prefix+"/*", handler)
+}
+
+// Handler returns a handler serving the file of fsys named by the route's
+// wildcard parameter. Content types follow the file extension and fall back
+// to sniffing the content. Responses carry an ETag and Cache-Control header;
+// IndexFile is always revalidated, since it links to the other files.
+func Handler(fsys fs.FS, opts ...Option) fiber.Handler {
+	cfg := config{maxAge: DefaultMaxAge}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	var etags sync.Map
+
+	return func(c *fiber.Ctx) error {
+		name := cleanPath(c.Params("*"))
+		if isDir(fsys, name) {
+			name = path.Join(name, IndexFile)
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) && cfg.spaFallback && path.Ext(name) == "" {
+			name = IndexFile
+			data, err = fs.ReadFile(fsys, name)
+		}
+		if errors.Is(err, fs.ErrNotExist) {
+			return c.SendStatus(fiber.StatusNotFound)
+		}
+		if err != nil {
+			return err
+		}
+
+		cached, ok := etags.Load(name)
+		if !ok {
+			sum := sha256.Sum256(data)
+			cached, _ = etags.LoadOrStore(name, `"`+hex.EncodeToString(sum[:16])+`"`)
+		}
+		etag := cached.(string)
+		c.Set(fiber.HeaderETag, etag)
+		if path.Base(name) == IndexFile || cfg.maxAge <= 0 {
+			c.Set(fiber.HeaderCacheControl, "no-cache")
+		} else {
+			c.Set(fiber.HeaderCacheControl, fmt.Sprintf("public, max-age=%d", int(cfg.maxAge.Seconds())))
+		}
+		if c.Get(fiber.HeaderIfNoneMatch) == etag {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+
+		c.Set(fiber.HeaderContentType, ContentType(name, data))
+		return c.Send(data)
+	}
+}
+
+// ContentType returns the MIME type for a file, by extension when it is
+// known and otherwise by sniffing data
+func ContentType(name string, data []byte) string {
+	if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(data)
+}
+
+// cleanPath turns a request path into an fs.FS name, resolving ".." so it
+// cannot escape the filesystem root
+func cleanPath(p string) string {
+	name := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// isDir reports whether name is a directory in fsys
+func isDir(fsys fs.FS, name string) bool {
+	info, err := fs.Stat(fsys, name)
+	return err == nil && info.IsDir()
+}
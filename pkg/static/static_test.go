@@ -0,0 +1,136 @@
+package static
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFS() fstest.MapFS {
+	return fstest.MapFS{
+		"index.html":       {Data: []byte("<!DOCTYPE html><title>Admin</title>")},
+		"app.js":           {Data: []byte("console.log('admin')")},
+		"styles/admin.css": {Data: []byte("body { margin: 0 }")},
+		"logo.png":         {Data: []byte("\x89PNG\r\n\x1a\n")},
+		"LICENSE":          {Data: []byte("MIT License")},
+		"docs/index.html":  {Data: []byte("<!DOCTYPE html><title>Docs</title>")},
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"index.html", "", "text/html; charset=utf-8"},
+		{"app.js", "", "text/javascript; charset=utf-8"},
+		{"admin.css", "", "text/css; charset=utf-8"},
+		{"logo.svg", "", "image/svg+xml"},
+		{"LICENSE", "MIT License", "text/plain; charset=utf-8"},
+		{"blob", "\x89PNG\r\n\x1a\n", "image/png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ContentType(tt.name, []byte(tt.data)))
+		})
+	}
+}
+
+func TestCleanPath_StaysInsideRoot(t *testing.T) {
+	assert.Equal(t, ".", cleanPath(""))
+	assert.Equal(t, ".", cleanPath("/"))
+	assert.Equal(t, "app.js", cleanPath("app.js"))
+	assert.Equal(t, "etc/passwd", cleanPath("../../etc/passwd"))
+	assert.Equal(t, "styles/admin.css", cleanPath("styles/./../styles/admin.css"))
+}
+
+func TestRegister_ServesFiles(t *testing.T) {
+	app := fiber.New()
+	Register(app, "/admin", newTestFS(), WithMaxAge(10*time.Minute))
+
+	tests := []struct {
+		path         string
+		status       int
+		body         string
+		contentType  string
+		cacheControl string
+	}{
+		{"/admin", fiber.StatusOK, "<!DOCTYPE html><title>Admin</title>", "text/html; charset=utf-8", "no-cache"},
+		{"/admin/", fiber.StatusOK, "<!DOCTYPE html><title>Admin</title>", "text/html; charset=utf-8", "no-cache"},
+		{"/admin/app.js", fiber.StatusOK, "console.log('admin')", "text/javascript; charset=utf-8", "public, max-age=600"},
+		{"/admin/styles/admin.css", fiber.StatusOK, "body { margin: 0 }", "text/css; charset=utf-8", "public, max-age=600"},
+		{"/admin/logo.png", fiber.StatusOK, "\x89PNG\r\n\x1a\n", "image/png", "public, max-age=600"},
+		{"/admin/docs", fiber.StatusOK, "<!DOCTYPE html><title>Docs</title>", "text/html; charset=utf-8", "no-cache"},
+		{"/admin/users/42", fiber.StatusNotFound, "Not Found", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.path, nil))
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.body, string(body))
+			if tt.status == fiber.StatusOK {
+				assert.Equal(t, tt.contentType, resp.Header.Get(fiber.HeaderContentType))
+				assert.Equal(t, tt.cacheControl, resp.Header.Get(fiber.HeaderCacheControl))
+				assert.NotEmpty(t, resp.Header.Get(fiber.HeaderETag))
+			}
+		})
+	}
+}
+
+func TestRegister_SPAFallback(t *testing.T) {
+	app := fiber.New()
+	Register(app, "/admin", newTestFS(), WithSPAFallback())
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/admin/users/42", fiber.StatusOK, "<!DOCTYPE html><title>Admin</title>"},
+		{"/admin/settings", fiber.StatusOK, "<!DOCTYPE html><title>Admin</title>"},
+		{"/admin/app.js", fiber.StatusOK, "console.log('admin')"},
+		{"/admin/missing.js", fiber.StatusNotFound, "Not Found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, tt.path, nil))
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
+
+func TestHandler_NotModified(t *testing.T) {
+	app := fiber.New()
+	Register(app, "/", newTestFS())
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/app.js", nil))
+	require.NoError(t, err)
+	etag := resp.Header.Get(fiber.HeaderETag)
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "public, max-age=3600", resp.Header.Get(fiber.HeaderCacheControl))
+
+	req := httptest.NewRequest(fiber.MethodGet, "/app.js", nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, etag)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/example/go-clean-architecture/pkg/cache"
+)
+
+// MXResolver looks up the mail exchangers of a domain; *net.Resolver satisfies it
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// EmailDomainError reports an email whose domain cannot receive mail
+type EmailDomainError struct {
+	Domain string
+}
+
+func (e *EmailDomainError) Error() string {
+	return "email domain " + e.Domain + " does not accept mail"
+}
+
+// mxCacheSize bounds the number of domains whose MX lookups are cached, so
+// sign-ups with many distinct domains cannot grow the cache without limit
+const mxCacheSize = 10000
+
+// MXChecker verifies that email domains publish MX records. Definitive answers
+// are cached for the configured TTL; lookups that fail for transient reasons
+// such as timeouts accept the email rather than block sign-up on DNS trouble.
+type MXChecker struct {
+	resolver MXResolver
+	timeout  time.Duration
+	ttl      time.Duration
+	now      func() time.Time
+	results  *cache.LRU[string, mxCacheEntry]
+}
+
+// mxCacheEntry records whether a domain had MX records and when that expires
+type mxCacheEntry struct {
+	valid   bool
+	expires time.Time
+}
+
+// NewMXChecker creates an MX checker; a nil resolver uses net.DefaultResolver
+func NewMXChecker(resolver MXResolver, timeout, ttl time.Duration) *MXChecker {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &MXChecker{
+		resolver: resolver,
+		timeout:  timeout,
+		ttl:      ttl,
+		now:      time.Now,
+		results:  cache.NewLRU[string, mxCacheEntry](mxCacheSize, 0),
+	}
+}
+
+// Check returns an *EmailDomainError when the email's domain clearly has no MX records
+func (c *MXChecker) Check(ctx context.Context, email string) error {
+	_, domain, ok := strings.Cut(email, "@")
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	if !ok || domain == "" {
+		return &EmailDomainError{Domain: domain}
+	}
+
+	valid, ok := c.cached(domain)
+	if !ok {
+		var definitive bool
+		valid, definitive = c.lookup(ctx, domain)
+		if !definitive {
+			return nil
+		}
+		c.store(domain, valid)
+	}
+
+	if !valid {
+		return &EmailDomainError{Domain: domain}
+	}
+	return nil
+}
+
+// lookup resolves the domain's MX records; definitive is false for transient failures
+func (c *MXChecker) lookup(ctx context.Context, domain string) (valid, definitive bool) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	records, err := c.resolver.LookupMX(ctx, domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, true
+		}
+		return false, false
+	}
+	// A single "." exchanger is a null MX (RFC 7505): the domain accepts no mail
+	if len(records) == 0 || (len(records) == 1 && records[0].Host == ".") {
+		return false, true
+	}
+	return true, true
+}
+
+// cached returns the unexpired cached result for the domain, if any
+func (c *MXChecker) cached(domain string) (valid, ok bool) {
+	entry, ok := c.results.Get(domain)
+	if !ok || !c.now().Before(entry.expires) {
+		return false, false
+	}
+	return entry.valid, true
+}
+
+// store caches the lookup result for the domain, evicting the least recently
+// used domain once the cache is full
+func (c *MXChecker) store(domain string, valid bool) {
+	c.results.Set(domain, mxCacheEntry{valid: valid, expires: c.now().Add(c.ttl)})
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/example/go-clean-architecture/pkg/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver serves canned MX answers and counts lookups per domain
+type fakeResolver struct {
+	records map[string][]*net.MX
+	errs    map[string]error
+	calls   map[string]int
+}
+
+func newFakeResolver() *fakeResolver {
+	return &fakeResolver{
+		records: make(map[string][]*net.MX),
+		errs:    make(map[string]error),
+		calls:   make(map[string]int),
+	}
+}
+
+func (r *fakeResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	r.calls[name]++
+	if err, ok := r.errs[name]; ok {
+		return nil, err
+	}
+	return r.records[name], nil
+}
+
+func TestMXChecker_Check(t *testing.T) {
+	resolver := newFakeResolver()
+	resolver.records["example.com"] = []*net.MX{{Host: "mail.example.com.", Pref: 10}}
+	resolver.records["nullmx.example"] = []*net.MX{{Host: ".", Pref: 0}}
+	resolver.errs["bogus.invalid"] = &net.DNSError{Err: "no such host", Name: "bogus.invalid", IsNotFound: true}
+	resolver.errs["slow.example"] = &net.DNSError{Err: "i/o timeout", Name: "slow.example", IsTimeout: true}
+	resolver.errs["broken.example"] = errors.New("resolver unavailable")
+	checker := NewMXChecker(resolver, time.Second, time.Hour)
+
+	tests := []struct {
+		email string
+		valid bool
+	}{
+		{"john@example.com", true},
+		{"john@EXAMPLE.com", true},
+		{"john@bogus.invalid", false},
+		{"john@nomx.example", false},
+		{"john@nullmx.example", false},
+		{"john@slow.example", true},
+		{"john@broken.example", true},
+		{"john", false},
+		{"john@", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			err := checker.Check(context.Background(), tt.email)
+			if tt.valid {
+				assert.NoError(t, err)
+				return
+			}
+			var domainErr *EmailDomainError
+			assert.ErrorAs(t, err, &domainErr)
+		})
+	}
+}
+
+func TestMXChecker_CachesDefinitiveResults(t *testing.T) {
+	resolver := newFakeResolver()
+	resolver.records["example.com"] = []*net.MX{{Host: "mail.example.com.", Pref: 10}}
+	resolver.errs["bogus.invalid"] = &net.DNSError{Err: "no such host", IsNotFound: true}
+	resolver.errs["slow.example"] = &net.DNSError{Err: "i/o timeout", IsTimeout: true}
+	checker := NewMXChecker(resolver, time.Second, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, checker.Check(context.Background(), "john@example.com"))
+		require.Error(t, checker.Check(context.Background(), "john@bogus.invalid"))
+		require.NoError(t, checker.Check(context.Background(), "john@slow.example"))
+	}
+
+	assert.Equal(t, 1, resolver.calls["example.com"])
+	assert.Equal(t, 1, resolver.calls["bogus.invalid"])
+	assert.Equal(t, 3, resolver.calls["slow.example"], "transient failures must not be cached")
+}
+
+func TestMXChecker_CacheExpires(t *testing.T) {
+	resolver := newFakeResolver()
+	resolver.records["example.com"] = []*net.MX{{Host: "mail.example.com.", Pref: 10}}
+	checker := NewMXChecker(resolver, time.Second, time.Minute)
+	now := time.Now()
+	checker.now = func() time.Time { return now }
+
+	require.NoError(t, checker.Check(context.Background(), "john@example.com"))
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, checker.Check(context.Background(), "john@example.com"))
+
+	assert.Equal(t, 2, resolver.calls["example.com"])
+}
+
+func TestMXChecker_CacheIsBounded(t *testing.T) {
+	resolver := newFakeResolver()
+	for _, domain := range []string{"a.example", "b.example", "c.example"} {
+		resolver.records[domain] = []*net.MX{{Host: "mail." + domain + ".", Pref: 10}}
+	}
+	checker := NewMXChecker(resolver, time.Second, time.Hour)
+	checker.results = cache.NewLRU[string, mxCacheEntry](2, 0)
+
+	for _, email := range []string{"john@a.example", "john@b.example", "john@c.example", "john@a.example"} {
+		require.NoError(t, checker.Check(context.Background(), email))
+	}
+
+	assert.Equal(t, 2, resolver.calls["a.example"], "the least recently used domain is evicted")
+	assert.Equal(t, 2, checker.results.Len())
+}
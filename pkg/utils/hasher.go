@@ -0,0 +1,190 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Hasher hashes passwords and verifies them against stored hashes
+type Hasher interface {
+	Hash(password string) (string, error)
+	Compare(password, hash string) bool
+}
+
+// DefaultHasher is the hasher used by HashPassword and CheckPasswordHash
+var DefaultHasher Hasher = BcryptHasher{Cost: bcrypt.DefaultCost}
+
+// ParseHasher returns the hasher named bcrypt or argon2id, in any case, with
+// its default parameters
+func ParseHasher(name string) (Hasher, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "bcrypt":
+		return DefaultHasher, nil
+	case "argon2id":
+		return DefaultArgon2idHasher, nil
+	}
+	return nil, fmt.Errorf("unknown password hasher %q; expected bcrypt or argon2id", name)
+}
+
+// PasswordHasherFromEnv returns the hasher the API and the seed command hash
+// passwords with: the algorithm named by the PASSWORD_HASHER environment
+// variable, wrapped in a MigratingHasher so hashes made with the other one
+// still verify. An unset or unknown name uses DefaultHasher; an unknown name
+// also returns the parse error, for the caller to report.
+func PasswordHasherFromEnv() (MigratingHasher, error) {
+	name := os.Getenv("PASSWORD_HASHER")
+	if name == "" {
+		return MigratingHasher{Hasher: DefaultHasher}, nil
+	}
+	hasher, err := ParseHasher(name)
+	if err != nil {
+		return MigratingHasher{Hasher: DefaultHasher}, err
+	}
+	return MigratingHasher{Hasher: hasher}, nil
+}
+
+// BcryptHasher hashes passwords with bcrypt; a zero Cost uses bcrypt.DefaultCost
+type BcryptHasher struct {
+	Cost int
+}
+
+// Hash hashes a password using bcrypt
+func (h BcryptHasher) Hash(password string) (string, error) {
+	cost := h.Cost
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	return string(bytes), err
+}
+
+// Compare reports whether the password matches the bcrypt hash
+func (h BcryptHasher) Compare(password, hash string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// Argon2idHasher hashes passwords with argon2id and encodes them in the PHC
+// string format, so hashes stay verifiable after the parameters change
+type Argon2idHasher struct {
+	Time    uint32
+	Memory  uint32 // in KiB
+	Threads uint8
+	KeyLen  uint32
+	SaltLen uint32
+}
+
+// DefaultArgon2idHasher uses the parameters recommended by RFC 9106 for memory-constrained systems
+var DefaultArgon2idHasher = Argon2idHasher{
+	Time:    3,
+	Memory:  64 * 1024,
+	Threads: 4,
+	KeyLen:  32,
+	SaltLen: 16,
+}
+
+// errInvalidArgon2Hash is returned when a stored hash is not a valid argon2id PHC string
+var errInvalidArgon2Hash = errors.New("invalid argon2id hash")
+
+// Hash hashes a password using argon2id with a random salt
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, h.KeyLen)
+	return fmt.Sprintf(argon2idPrefix+"v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Compare reports whether the password matches the argon2id hash, using the
+// parameters encoded in the hash rather than the hasher's own
+func (h Argon2idHasher) Compare(password, hash string) bool {
+	params, salt, key, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return false
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(candidate, key) == 1
+}
+
+// argon2idPrefix starts every hash produced by Argon2idHasher
+const argon2idPrefix = "$argon2id$"
+
+// isBcryptHash reports whether hash looks like a bcrypt hash
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// Rehasher is implemented by hashers that can tell when a stored hash should
+// be replaced by a fresh one, once the password is known again
+type Rehasher interface {
+	NeedsRehash(hash string) bool
+}
+
+// MigratingHasher hashes new passwords with its Hasher but verifies stored
+// hashes with the algorithm their prefix names, so switching algorithms does
+// not lock out users whose hashes predate the switch
+type MigratingHasher struct {
+	Hasher
+}
+
+// Compare reports whether the password matches the hash, verified with bcrypt
+// or argon2id as the hash's prefix says, or with the Hasher otherwise
+func (h MigratingHasher) Compare(password, hash string) bool {
+	switch {
+	case isBcryptHash(hash):
+		return BcryptHasher{}.Compare(password, hash)
+	case strings.HasPrefix(hash, argon2idPrefix):
+		return Argon2idHasher{}.Compare(password, hash)
+	}
+	return h.Hasher.Compare(password, hash)
+}
+
+// NeedsRehash reports whether the hash was made by another algorithm than the
+// one new passwords are hashed with
+func (h MigratingHasher) NeedsRehash(hash string) bool {
+	switch h.Hasher.(type) {
+	case BcryptHasher:
+		return !isBcryptHash(hash)
+	case Argon2idHasher:
+		return !strings.HasPrefix(hash, argon2idPrefix)
+	}
+	return false
+}
+
+// decodeArgon2Hash parses an argon2id PHC string into its parameters, salt and key
+func decodeArgon2Hash(hash string) (params Argon2idHasher, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		return params, nil, nil, errInvalidArgon2Hash
+	}
+	return params, salt, key, nil
+}
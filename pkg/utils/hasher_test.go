@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2idHasher keeps argon2id cheap enough for unit tests
+var testArgon2idHasher = Argon2idHasher{Time: 1, Memory: 1024, Threads: 1, KeyLen: 32, SaltLen: 16}
+
+func TestHashers(t *testing.T) {
+	hashers := map[string]Hasher{
+		"bcrypt":   BcryptHasher{Cost: bcrypt.MinCost},
+		"argon2id": testArgon2idHasher,
+	}
+
+	for name, hasher := range hashers {
+		t.Run(name, func(t *testing.T) {
+			hash, err := hasher.Hash("S3cure-Passphrase")
+			require.NoError(t, err)
+
+			assert.True(t, hasher.Compare("S3cure-Passphrase", hash))
+			assert.False(t, hasher.Compare("wrong", hash))
+			assert.False(t, hasher.Compare("S3cure-Passphrase", "not-a-hash"))
+
+			other, err := hasher.Hash("S3cure-Passphrase")
+			require.NoError(t, err)
+			assert.NotEqual(t, hash, other, "hashes must be salted")
+		})
+	}
+}
+
+func TestBcryptHasher_ZeroCostUsesDefault(t *testing.T) {
+	hash, err := BcryptHasher{}.Hash("S3cure-Passphrase")
+	require.NoError(t, err)
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+}
+
+func TestArgon2idHasher_EncodesParameters(t *testing.T) {
+	hash, err := testArgon2idHasher.Hash("S3cure-Passphrase")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"), hash)
+
+	// Hashes remain verifiable by a hasher configured with different parameters
+	assert.True(t, DefaultArgon2idHasher.Compare("S3cure-Passphrase", hash))
+}
+
+func TestArgon2idHasher_RejectsMalformedHashes(t *testing.T) {
+	for _, hash := range []string{
+		"",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA$",
+	} {
+		assert.False(t, testArgon2idHasher.Compare("S3cure-Passphrase", hash), hash)
+	}
+}
+
+func TestMigratingHasher_VerifiesByPrefix(t *testing.T) {
+	bcryptHash, err := BcryptHasher{Cost: bcrypt.MinCost}.Hash("S3cure-Passphrase")
+	require.NoError(t, err)
+	argon2Hash, err := testArgon2idHasher.Hash("S3cure-Passphrase")
+	require.NoError(t, err)
+
+	for name, hasher := range map[string]MigratingHasher{
+		"bcrypt":   {Hasher: BcryptHasher{Cost: bcrypt.MinCost}},
+		"argon2id": {Hasher: testArgon2idHasher},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.True(t, hasher.Compare("S3cure-Passphrase", bcryptHash))
+			assert.True(t, hasher.Compare("S3cure-Passphrase", argon2Hash))
+			assert.False(t, hasher.Compare("wrong", bcryptHash))
+			assert.False(t, hasher.Compare("wrong", argon2Hash))
+		})
+	}
+}
+
+func TestMigratingHasher_NeedsRehash(t *testing.T) {
+	bcryptHash, err := BcryptHasher{Cost: bcrypt.MinCost}.Hash("S3cure-Passphrase")
+	require.NoError(t, err)
+	argon2Hash, err := testArgon2idHasher.Hash("S3cure-Passphrase")
+	require.NoError(t, err)
+
+	toArgon2 := MigratingHasher{Hasher: testArgon2idHasher}
+	assert.True(t, toArgon2.NeedsRehash(bcryptHash))
+	assert.False(t, toArgon2.NeedsRehash(argon2Hash))
+
+	toBcrypt := MigratingHasher{Hasher: BcryptHasher{}}
+	assert.True(t, toBcrypt.NeedsRehash(argon2Hash))
+	assert.False(t, toBcrypt.NeedsRehash(bcryptHash))
+}
+
+func TestParseHasher(t *testing.T) {
+	for name, want := range map[string]Hasher{
+		"bcrypt":   DefaultHasher,
+		"argon2id": DefaultArgon2idHasher,
+		"Argon2ID": DefaultArgon2idHasher,
+		" BCRYPT ": DefaultHasher,
+	} {
+		hasher, err := ParseHasher(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, hasher, name)
+	}
+
+	_, err := ParseHasher("scrypt")
+	assert.ErrorContains(t, err, `unknown password hasher "scrypt"`)
+}
+
+func TestPasswordHasherFromEnv(t *testing.T) {
+	hasher, err := PasswordHasherFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, MigratingHasher{Hasher: DefaultHasher}, hasher)
+
+	t.Setenv("PASSWORD_HASHER", "argon2id")
+	hasher, err = PasswordHasherFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, MigratingHasher{Hasher: DefaultArgon2idHasher}, hasher)
+
+	t.Setenv("PASSWORD_HASHER", "scrypt")
+	hasher, err = PasswordHasherFromEnv()
+	assert.Error(t, err)
+	assert.Equal(t, MigratingHasher{Hasher: DefaultHasher}, hasher)
+}
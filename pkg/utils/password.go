@@ -1,17 +1,139 @@
 package utils
 
 import (
-	"golang.org/x/crypto/bcrypt"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
 )
 
-// HashPassword hashes a password using bcrypt
+// PasswordPolicy describes the rules a password must satisfy
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	Blocklist     []string
+}
+
+// DefaultPasswordPolicy is the policy used by ValidatePasswordStrength
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:    8,
+	RequireUpper: true,
+	RequireLower: true,
+	RequireDigit: true,
+	Blocklist:    CommonPasswords,
+}
+
+// CommonPasswords is a list of frequently used passwords that are always rejected
+var CommonPasswords = []string{
+	"123456", "12345678", "123456789", "1234567890", "111111", "000000",
+	"password", "password1", "password123", "passw0rd", "p@ssw0rd", "p@ssword1",
+	"qwerty", "qwerty123", "qwertyuiop", "1q2w3e4r", "abc123", "abcd1234",
+	"letmein", "letmein1", "welcome", "welcome1", "welcome123", "admin", "admin123",
+	"iloveyou", "monkey", "dragon", "football", "baseball", "sunshine",
+	"princess", "trustno1", "superman", "changeme", "changeme123",
+}
+
+// PasswordPolicyFromEnv returns DefaultPasswordPolicy with the rules set by
+// the PASSWORD_MIN_LENGTH, PASSWORD_REQUIRE_UPPER, PASSWORD_REQUIRE_LOWER,
+// PASSWORD_REQUIRE_DIGIT, PASSWORD_REQUIRE_SYMBOL and PASSWORD_BLOCKLIST
+// environment variables. Unset or invalid values keep the default rule; a
+// comma-separated PASSWORD_BLOCKLIST replaces CommonPasswords.
+func PasswordPolicyFromEnv() PasswordPolicy {
+	policy := DefaultPasswordPolicy
+	if n, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH")); err == nil {
+		policy.MinLength = n
+	}
+	envBool := func(key string, rule *bool) {
+		if b, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+			*rule = b
+		}
+	}
+	envBool("PASSWORD_REQUIRE_UPPER", &policy.RequireUpper)
+	envBool("PASSWORD_REQUIRE_LOWER", &policy.RequireLower)
+	envBool("PASSWORD_REQUIRE_DIGIT", &policy.RequireDigit)
+	envBool("PASSWORD_REQUIRE_SYMBOL", &policy.RequireSymbol)
+
+	var blocklist []string
+	for _, password := range strings.Split(os.Getenv("PASSWORD_BLOCKLIST"), ",") {
+		if password = strings.TrimSpace(password); password != "" {
+			blocklist = append(blocklist, password)
+		}
+	}
+	if len(blocklist) > 0 {
+		policy.Blocklist = blocklist
+	}
+	return policy
+}
+
+// PasswordStrengthError lists the rules a password failed to satisfy
+type PasswordStrengthError struct {
+	Failures []string
+}
+
+func (e *PasswordStrengthError) Error() string {
+	return "password is too weak: " + strings.Join(e.Failures, ", ")
+}
+
+// ValidatePasswordStrength checks a password against the default policy
+func ValidatePasswordStrength(password string) error {
+	return DefaultPasswordPolicy.Validate(password)
+}
+
+// Validate checks a password against the policy and reports every failed rule
+func (p PasswordPolicy) Validate(password string) error {
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	var failures []string
+	if p.MinLength > 0 && len([]rune(password)) < p.MinLength {
+		failures = append(failures, fmt.Sprintf("must be at least %d characters long", p.MinLength))
+	}
+	if p.RequireUpper && !hasUpper {
+		failures = append(failures, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		failures = append(failures, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		failures = append(failures, "must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		failures = append(failures, "must contain a symbol")
+	}
+	for _, common := range p.Blocklist {
+		if strings.EqualFold(password, common) {
+			failures = append(failures, "is too common")
+			break
+		}
+	}
+
+	if len(failures) > 0 {
+		return &PasswordStrengthError{Failures: failures}
+	}
+	return nil
+}
+
+// HashPassword hashes a password using the default hasher
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	return DefaultHasher.Hash(password)
 }
 
-// CheckPasswordHash compares a password with its hash
+// CheckPasswordHash compares a password with its hash using the default hasher
 func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	return DefaultHasher.Compare(password, hash)
 }
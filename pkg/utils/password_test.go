@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := PasswordPolicy{
+		MinLength:     8,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		failure  string
+	}{
+		{"too short", "Ab1!", "must be at least 8 characters long"},
+		{"missing uppercase", "abcdef1!", "must contain an uppercase letter"},
+		{"missing lowercase", "ABCDEF1!", "must contain a lowercase letter"},
+		{"missing digit", "Abcdefg!", "must contain a digit"},
+		{"missing symbol", "Abcdefg1", "must contain a symbol"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password)
+
+			var strengthErr *PasswordStrengthError
+			require.ErrorAs(t, err, &strengthErr)
+			assert.Equal(t, []string{tt.failure}, strengthErr.Failures)
+		})
+	}
+}
+
+func TestPasswordPolicy_ValidateListsAllFailures(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 8, RequireUpper: true, RequireDigit: true}
+
+	err := policy.Validate("abc")
+
+	var strengthErr *PasswordStrengthError
+	require.ErrorAs(t, err, &strengthErr)
+	assert.Len(t, strengthErr.Failures, 3)
+	assert.Contains(t, err.Error(), "must contain a digit")
+}
+
+func TestValidatePasswordStrength(t *testing.T) {
+	assert.NoError(t, ValidatePasswordStrength("S3cure-Passphrase"))
+}
+
+func TestValidatePasswordStrength_RejectsCommonPasswords(t *testing.T) {
+	for _, password := range []string{"Password123", "Welcome1", "P@ssw0rd"} {
+		t.Run(password, func(t *testing.T) {
+			err := ValidatePasswordStrength(password)
+
+			var strengthErr *PasswordStrengthError
+			require.ErrorAs(t, err, &strengthErr)
+			assert.Contains(t, strengthErr.Failures, "is too common")
+		})
+	}
+}
+
+func TestPasswordPolicyFromEnv(t *testing.T) {
+	t.Setenv("PASSWORD_MIN_LENGTH", "12")
+	t.Setenv("PASSWORD_REQUIRE_UPPER", "false")
+	t.Setenv("PASSWORD_REQUIRE_SYMBOL", "true")
+	t.Setenv("PASSWORD_REQUIRE_DIGIT", "not-a-bool")
+	t.Setenv("PASSWORD_BLOCKLIST", " Hunter2-Hunter2 , ,acme-corp-2024")
+
+	policy := PasswordPolicyFromEnv()
+
+	assert.Equal(t, PasswordPolicy{
+		MinLength:     12,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		Blocklist:     []string{"Hunter2-Hunter2", "acme-corp-2024"},
+	}, policy)
+	assert.Equal(t, 8, DefaultPasswordPolicy.MinLength, "the default policy is left unchanged")
+}
+
+func TestPasswordPolicyFromEnv_Defaults(t *testing.T) {
+	assert.Equal(t, DefaultPasswordPolicy, PasswordPolicyFromEnv())
+}
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("S3cure-Passphrase")
+	require.NoError(t, err)
+
+	assert.True(t, CheckPasswordHash("S3cure-Passphrase", hash))
+	assert.False(t, CheckPasswordHash("wrong", hash))
+}